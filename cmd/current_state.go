@@ -85,7 +85,7 @@ func runCurrentState(_ *cobra.Command, _ []string) error {
 		return nil
 	}
 
-	dagOut, err := queryDAG(migrationsDir, currentStateVerbose)
+	dagOut, err := queryDAG(migrationsDir, cfg.Migration.Loader, currentStateVerbose)
 	if err != nil {
 		return fmt.Errorf("querying migration DAG: %w", err)
 	}
@@ -133,7 +133,7 @@ func runDBDiff(cmd *cobra.Command) error {
 	}
 
 	if len(migrationFiles) > 0 {
-		dagOutput, dagErr := queryDAG(migrationsDir, verbose)
+		dagOutput, dagErr := queryDAG(migrationsDir, cfg.Migration.Loader, verbose)
 		if dagErr != nil {
 			return fmt.Errorf("failed to query migration DAG: %w", dagErr)
 		}
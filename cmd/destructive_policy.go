@@ -0,0 +1,191 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ocomsoft/makemigrations/internal/config"
+	yamlpkg "github.com/ocomsoft/makemigrations/internal/yaml"
+)
+
+// policyActions maps the action names accepted by a destructive-change
+// policy to prompt responses. "fail" stops generation like choosing Exit.
+var policyActions = map[string]yamlpkg.PromptResponse{
+	"generate":      yamlpkg.PromptGenerate,
+	"review":        yamlpkg.PromptReview,
+	"omit":          yamlpkg.PromptOmit,
+	"ignore-errors": yamlpkg.PromptIgnoreErrors,
+	"fail":          yamlpkg.PromptExit,
+}
+
+// changeTypeLike matches keys shaped like a change type (e.g. field_removed),
+// which are only accepted as table globs when the table exists.
+var changeTypeLike = regexp.MustCompile(`^[a-z]+(_[a-z]+)+$`)
+
+// policyRule is one entry of a destructivePolicy.
+type policyRule struct {
+	// key is the rule as written: a change type, a "table" or
+	// "table.field" glob, or "*".
+	key    string
+	action string
+	resp   yamlpkg.PromptResponse
+}
+
+// destructivePolicy decides destructive changes without prompting. Rules
+// are tried from most to least specific: "table.field" globs, then table
+// globs, then change types (e.g. field_removed), then "*". Among globs of
+// the same kind the longest pattern wins.
+type destructivePolicy struct {
+	fieldGlobs []policyRule
+	tableGlobs []policyRule
+	types      map[yamlpkg.ChangeType]policyRule
+	fallback   *policyRule
+}
+
+// parseDestructivePolicy builds a policy from the migration.destructive_policy
+// config rules and the --destructive rule=action flags; a flag overrides a
+// config rule with the same match. tables holds the table names of the
+// schemas being compared: a snake_case key without glob characters that is
+// neither a destructive change type nor one of them is rejected, since it is
+// most likely a mistyped or non-destructive change type that would silently
+// match nothing.
+func parseDestructivePolicy(cfgRules []config.DestructiveRule, specs []string, tables map[string]bool) (*destructivePolicy, error) {
+	rules := make(map[string]string, len(cfgRules)+len(specs))
+	for _, rule := range cfgRules {
+		rules[strings.TrimSpace(rule.Match)] = strings.TrimSpace(rule.Action)
+	}
+	for _, spec := range specs {
+		key, action, ok := strings.Cut(spec, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("--destructive %q: expected rule=action", spec)
+		}
+		rules[strings.TrimSpace(key)] = strings.TrimSpace(action)
+	}
+
+	p := &destructivePolicy{types: make(map[yamlpkg.ChangeType]policyRule)}
+	for key, action := range rules {
+		resp, ok := policyActions[action]
+		if !ok {
+			return nil, fmt.Errorf("destructive policy %q: unknown action %q (want generate, review, omit, ignore-errors or fail)",
+				key, action)
+		}
+		rule := policyRule{key: key, action: action, resp: resp}
+		switch {
+		case key == "*":
+			p.fallback = &rule
+		case yamlpkg.IsDestructiveOperation(yamlpkg.ChangeType(key)):
+			p.types[yamlpkg.ChangeType(key)] = rule
+		case changeTypeLike.MatchString(key) && !tables[key]:
+			return nil, fmt.Errorf("destructive policy %q: neither a destructive change type "+
+				"(e.g. field_removed, table_removed, field_modified) nor a table in the schema", key)
+		default:
+			if _, err := path.Match(key, ""); err != nil {
+				return nil, fmt.Errorf("destructive policy %q: %w", key, err)
+			}
+			if strings.Contains(key, ".") {
+				p.fieldGlobs = append(p.fieldGlobs, rule)
+			} else {
+				p.tableGlobs = append(p.tableGlobs, rule)
+			}
+		}
+	}
+	for _, globs := range [][]policyRule{p.fieldGlobs, p.tableGlobs} {
+		sort.Slice(globs, func(i, j int) bool {
+			if len(globs[i].key) != len(globs[j].key) {
+				return len(globs[i].key) > len(globs[j].key)
+			}
+			return globs[i].key < globs[j].key
+		})
+	}
+	return p, nil
+}
+
+// schemaTables returns the names of the tables in schemas, any of which may
+// be nil.
+func schemaTables(schemas ...*yamlpkg.Schema) map[string]bool {
+	tables := make(map[string]bool)
+	for _, schema := range schemas {
+		if schema == nil {
+			continue
+		}
+		for _, table := range schema.Tables {
+			tables[table.Name] = true
+		}
+	}
+	return tables
+}
+
+// match returns the rule deciding change, if any.
+func (p *destructivePolicy) match(change yamlpkg.Change) (policyRule, bool) {
+	if p == nil {
+		return policyRule{}, false
+	}
+	if change.FieldName != "" {
+		target := change.TableName + "." + change.FieldName
+		for _, rule := range p.fieldGlobs {
+			if ok, _ := path.Match(rule.key, target); ok {
+				return rule, true
+			}
+		}
+	}
+	for _, rule := range p.tableGlobs {
+		if ok, _ := path.Match(rule.key, change.TableName); ok {
+			return rule, true
+		}
+	}
+	if rule, ok := p.types[change.Type]; ok {
+		return rule, true
+	}
+	if p.fallback != nil {
+		return *p.fallback, true
+	}
+	return policyRule{}, false
+}
+
+// changeTarget describes where change applies, e.g. "users.email".
+func changeTarget(change yamlpkg.Change) string {
+	if change.FieldName != "" {
+		return change.TableName + "." + change.FieldName
+	}
+	return change.TableName
+}
+
+// policyActionName returns the policy action name of resp.
+func policyActionName(resp yamlpkg.PromptResponse) string {
+	if resp == yamlpkg.PromptGenerateAll {
+		return "generate"
+	}
+	for name, r := range policyActions {
+		if r == resp {
+			return name
+		}
+	}
+	return fmt.Sprintf("response %d", resp)
+}
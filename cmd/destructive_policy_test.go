@@ -0,0 +1,129 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/internal/config"
+	"github.com/ocomsoft/makemigrations/internal/ui"
+	yamlpkg "github.com/ocomsoft/makemigrations/internal/yaml"
+)
+
+func TestParseDestructivePolicy(t *testing.T) {
+	policy, err := parseDestructivePolicy([]config.DestructiveRule{
+		{Match: "field_removed", Action: "review"},
+		{Match: "legacy_*", Action: "generate"},
+		{Match: "users.password_*", Action: "fail"},
+		{Match: "*", Action: "omit"},
+	}, []string{"users.password_hint=ignore-errors"}, nil)
+	if err != nil {
+		t.Fatalf("parseDestructivePolicy: %v", err)
+	}
+
+	tests := []struct {
+		change yamlpkg.Change
+		rule   string
+		action string
+	}{
+		{yamlpkg.Change{Type: yamlpkg.ChangeTypeFieldRemoved, TableName: "users", FieldName: "phone"}, "field_removed", "review"},
+		{yamlpkg.Change{Type: yamlpkg.ChangeTypeFieldRemoved, TableName: "legacy_orders", FieldName: "note"}, "legacy_*", "generate"},
+		{yamlpkg.Change{Type: yamlpkg.ChangeTypeFieldModified, TableName: "users", FieldName: "password_hash"}, "users.password_*", "fail"},
+		// The longer, flag-supplied glob wins over users.password_*.
+		{yamlpkg.Change{Type: yamlpkg.ChangeTypeFieldRemoved, TableName: "users", FieldName: "password_hint"}, "users.password_hint", "ignore-errors"},
+		{yamlpkg.Change{Type: yamlpkg.ChangeTypeTableRemoved, TableName: "sessions"}, "*", "omit"},
+	}
+	for _, tt := range tests {
+		rule, ok := policy.match(tt.change)
+		if !ok || rule.key != tt.rule || rule.action != tt.action {
+			t.Errorf("match(%s %s) = (%q, %q, %v), want (%q, %q)",
+				tt.change.Type, changeTarget(tt.change), rule.key, rule.action, ok, tt.rule, tt.action)
+		}
+	}
+
+	if _, err := parseDestructivePolicy(nil, []string{"field_removed=drop"}, nil); err == nil {
+		t.Error("expected an unknown action to be rejected")
+	}
+	if _, err := parseDestructivePolicy(nil, []string{"field_removed"}, nil); err == nil {
+		t.Error("expected a rule without an action to be rejected")
+	}
+	for _, key := range []string{"field_remove", "field_added"} {
+		if _, err := parseDestructivePolicy(nil, []string{key + "=generate"}, nil); err == nil ||
+			!strings.Contains(err.Error(), "neither a destructive change type") {
+			t.Errorf("expected %s to be rejected, got %v", key, err)
+		}
+	}
+	// A snake_case key naming a table in the schema is a table rule.
+	policy, err = parseDestructivePolicy(nil, []string{"audit_log=generate"}, map[string]bool{"audit_log": true})
+	if err != nil {
+		t.Fatalf("parseDestructivePolicy (table): %v", err)
+	}
+	if rule, ok := policy.match(yamlpkg.Change{Type: yamlpkg.ChangeTypeTableRemoved, TableName: "audit_log"}); !ok || rule.key != "audit_log" {
+		t.Errorf("expected the audit_log table rule to match, got %q, %v", rule.key, ok)
+	}
+}
+
+func TestPromptGoMigDecisions(t *testing.T) {
+	diff := &yamlpkg.SchemaDiff{Changes: []yamlpkg.Change{
+		{Type: yamlpkg.ChangeTypeFieldAdded, TableName: "users", FieldName: "email"},
+		{Type: yamlpkg.ChangeTypeFieldRemoved, TableName: "users", FieldName: "phone"},
+		{Type: yamlpkg.ChangeTypeTableRemoved, TableName: "sessions"},
+	}}
+	policy, err := parseDestructivePolicy(nil, []string{"field_removed=review"}, nil)
+	if err != nil {
+		t.Fatalf("parseDestructivePolicy: %v", err)
+	}
+	prompted := 0
+	prompt := func(string, yamlpkg.ChangeType) (yamlpkg.PromptResponse, ui.PromptScope, error) {
+		prompted++
+		return yamlpkg.PromptOmit, ui.ScopeOne, nil
+	}
+
+	// Interactive: the policy decides field_removed, the prompt the rest.
+	decisions, notes, err := promptGoMigDecisions(diff, policy, prompt, true)
+	if err != nil {
+		t.Fatalf("promptGoMigDecisions: %v", err)
+	}
+	if prompted != 1 || decisions[1] != yamlpkg.PromptReview || decisions[2] != yamlpkg.PromptOmit {
+		t.Errorf("unexpected decisions %v after %d prompts", decisions, prompted)
+	}
+	if notes[1] != `field_removed users.phone: review (policy "field_removed")` || notes[2] != "table_removed sessions: omit (prompt)" {
+		t.Errorf("unexpected notes %v", notes)
+	}
+
+	// Non-interactive: an undecided change is an error, never a prompt.
+	_, _, err = promptGoMigDecisions(diff, policy, prompt, false)
+	if err == nil || !strings.Contains(err.Error(), "table_removed sessions") || prompted != 1 {
+		t.Fatalf("expected an error naming the undecided change, got %v", err)
+	}
+
+	// A "fail" rule stops generation.
+	policy, _ = parseDestructivePolicy(nil, []string{"*=generate", "sessions=fail"}, nil)
+	_, _, err = promptGoMigDecisions(diff, policy, prompt, false)
+	if err == nil || !strings.Contains(err.Error(), `refused by policy rule "sessions"`) {
+		t.Fatalf("expected the fail rule to stop generation, got %v", err)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -145,7 +146,7 @@ func runDumpData(_ *cobra.Command, args []string) error {
 	var schemaState *migrate.SchemaState
 
 	if len(migFiles) > 0 {
-		dagOut, dagErr := queryDAG(migrationsDir, dumpDataVerbose)
+		dagOut, dagErr := queryDAG(migrationsDir, cfg.Migration.Loader, dumpDataVerbose)
 		if dagErr != nil {
 			return fmt.Errorf("querying migration DAG: %w", dagErr)
 		}
@@ -209,7 +210,10 @@ func runDumpData(_ *cobra.Command, args []string) error {
 	}
 
 	// Build migration name.
-	name := BuildMigrationName(len(migFiles), dumpDataName, buildDumpAutoName(args))
+	name, err := newMigrationName(cfg, len(migFiles), time.Now(), dumpDataName, buildDumpAutoName(args))
+	if err != nil {
+		return err
+	}
 
 	if dumpDataVerbose {
 		fmt.Printf("Generating migration: %s\n", name)
@@ -242,7 +246,7 @@ func runDumpData(_ *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("Created %s\n", outPath)
-	return nil
+	return refreshSQLPreviews(migrationsDir, cfg.Migration.Loader, cfg.Migration.SQLPreview)
 }
 
 // resolveConflictKeys determines the conflict keys for a table. If --conflict-key
@@ -335,6 +339,8 @@ func driverForDBType(dbType string) string {
 		return "mysql"
 	case "sqlite":
 		return "sqlite3"
+	case "sqlserver":
+		return "sqlserver"
 	default:
 		return "postgres"
 	}
@@ -28,6 +28,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -93,7 +94,7 @@ func runEmpty(_ *cobra.Command, _ []string) error {
 	// Query DAG for current leaves (dependencies for the new migration).
 	var deps []string
 	if len(migFiles) > 0 {
-		dagOut, err := queryDAG(migrationsDir, emptyMigVerbose)
+		dagOut, err := queryDAG(migrationsDir, cfg.Migration.Loader, emptyMigVerbose)
 		if err != nil {
 			return fmt.Errorf("querying migration DAG: %w", err)
 		}
@@ -101,7 +102,10 @@ func runEmpty(_ *cobra.Command, _ []string) error {
 	}
 
 	count := len(migFiles)
-	name := BuildMigrationName(count, emptyMigName, "")
+	name, err := newMigrationName(cfg, count, time.Now(), emptyMigName, "")
+	if err != nil {
+		return err
+	}
 
 	if emptyMigVerbose {
 		fmt.Printf("Generating blank migration: %s\n", name)
@@ -132,5 +136,5 @@ func runEmpty(_ *cobra.Command, _ []string) error {
 
 	fmt.Printf("Created %s\n", outPath)
 	fmt.Println("Edit the file and add your migration operations.")
-	return nil
+	return refreshSQLPreviews(migrationsDir, cfg.Migration.Loader, cfg.Migration.SQLPreview)
 }
@@ -26,9 +26,17 @@ SOFTWARE.
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime/debug"
 	"sort"
 	"strings"
 	"time"
@@ -38,9 +46,12 @@ import (
 
 	"github.com/ocomsoft/makemigrations/internal/codegen"
 	"github.com/ocomsoft/makemigrations/internal/config"
+	"github.com/ocomsoft/makemigrations/internal/dagcache"
+	"github.com/ocomsoft/makemigrations/internal/dumpdata"
 	"github.com/ocomsoft/makemigrations/internal/interp"
 	"github.com/ocomsoft/makemigrations/internal/types"
 	"github.com/ocomsoft/makemigrations/internal/ui"
+	"github.com/ocomsoft/makemigrations/internal/version"
 	"github.com/ocomsoft/makemigrations/internal/workflow"
 	yamlpkg "github.com/ocomsoft/makemigrations/internal/yaml"
 	"github.com/ocomsoft/makemigrations/migrate"
@@ -54,6 +65,20 @@ var (
 	goMigMerge   bool
 	goMigName    string
 	goMigVerbose bool
+	// goMigBackfills holds --backfill table.field=expr values.
+	goMigBackfills []string
+	// goMigNewColumns holds --new-column table.field values.
+	goMigNewColumns []string
+	// goMigZeroDowntime splits the changes into expand and contract migrations.
+	goMigZeroDowntime bool
+	// goMigRenames holds --rename table.old=new values.
+	goMigRenames []string
+	// goMigAmend rewrites the latest migration instead of adding one.
+	goMigAmend bool
+	// goMigDestructive holds --destructive rule=action policy entries.
+	goMigDestructive []string
+	// goMigNonInteractive never prompts, even on a terminal.
+	goMigNonInteractive bool
 )
 
 // goMigrationsCmd is the Cobra command for generating Go migration files from
@@ -91,6 +116,21 @@ func init() {
 		"Custom migration name suffix")
 	goMigrationsCmd.Flags().BoolVar(&goMigVerbose, "verbose", false,
 		"Show detailed output")
+	goMigrationsCmd.Flags().StringArrayVar(&goMigBackfills, "backfill", nil,
+		"One-off SQL value for existing rows when a column becomes NOT NULL (table.field=expr, repeatable)")
+	goMigrationsCmd.Flags().StringArrayVar(&goMigNewColumns, "new-column", nil,
+		"Change this column's type by copying into a new column instead of altering in place (table.field, repeatable)")
+	goMigrationsCmd.Flags().BoolVar(&goMigZeroDowntime, "zero-downtime", false,
+		"Split breaking changes into separate expand and contract migrations")
+	goMigrationsCmd.Flags().StringArrayVar(&goMigRenames, "rename", nil,
+		"With --zero-downtime, treat a removed and an added field as a rename (table.old=new, repeatable)")
+	goMigrationsCmd.Flags().BoolVar(&goMigAmend, "amend", false,
+		"Rewrite the latest migration in place if it has not been applied to the dev database (or is a draft)")
+	goMigrationsCmd.Flags().StringArrayVar(&goMigDestructive, "destructive", nil,
+		"Decide destructive changes without prompting (rule=action; rule is a change type, table or table.field glob, or *; "+
+			"action is generate, review, omit, ignore-errors or fail; repeatable)")
+	goMigrationsCmd.Flags().BoolVar(&goMigNonInteractive, "non-interactive", false,
+		"Never prompt; destructive changes not covered by the policy are an error (implied when stdin is not a terminal)")
 }
 
 // runGoMakeMigrations is the main entry point for Go migration generation.
@@ -123,13 +163,28 @@ func runGoMakeMigrations(_ *cobra.Command, _ []string) error {
 	}
 
 	if len(migFiles) > 0 {
-		dagOut, err = queryDAG(migrationsDir, goMigVerbose)
+		dagOut, err = queryDAG(migrationsDir, cfg.Migration.Loader, goMigVerbose)
 		if err != nil {
 			return fmt.Errorf("querying migration DAG: %w", err)
 		}
 		prevSchema = schemaStateToYAMLSchema(dagOut.SchemaState, cfg.Database.Type)
 	}
 
+	// With --amend, diff against the state before the migration being rewritten.
+	var amend *amendTarget
+	if goMigAmend {
+		if goMigMerge || goMigCheck || goMigZeroDowntime {
+			return fmt.Errorf("--amend cannot be combined with --merge, --check or --zero-downtime")
+		}
+		if len(migFiles) == 0 {
+			return fmt.Errorf("--amend: no migrations to amend in %s", migrationsDir)
+		}
+		if amend, err = prepareAmend(cfg, migrationsDir); err != nil {
+			return err
+		}
+		prevSchema = schemaStateToYAMLSchema(amend.stateBefore, cfg.Database.Type)
+	}
+
 	// 2. Parse current YAML schema
 	dbType, err := yamlpkg.ParseDatabaseType(cfg.Database.Type)
 	if err != nil {
@@ -182,7 +237,10 @@ func runGoMakeMigrations(_ *cobra.Command, _ []string) error {
 
 	// 4. Handle merge if requested
 	if goMigMerge && dagOut != nil && dagOut.HasUnresolvedBranches {
-		return goGenerateMerge(migrationsDir, dagOut, goMigDryRun, goMigVerbose)
+		if err := goGenerateMerge(migrationsDir, cfg.Migration.Naming, dagOut, goMigDryRun, goMigVerbose); err != nil || goMigDryRun {
+			return err
+		}
+		return refreshSQLPreviews(migrationsDir, cfg.Migration.Loader, cfg.Migration.SQLPreview)
 	}
 
 	// 5. Check for unresolved branches (warn if present and not doing merge).
@@ -193,17 +251,32 @@ func runGoMakeMigrations(_ *cobra.Command, _ []string) error {
 		for i, leaf := range dagOut.Leaves {
 			fmt.Printf("  Branch %d: %s\n", i+1, leaf)
 		}
-		fmt.Println("Run 'makemigrations generate --merge' to generate a merge migration.")
+		fmt.Println("Run 'makemigrations generate --merge' to generate a merge migration, or 'makemigrations rebase' to move unapplied migrations onto the merged leaf.")
 	}
 
-	if !diff.HasChanges {
-		fmt.Println("No changes detected.")
-		return nil
+	if goMigCheck {
+		stale, err := syncSQLPreviews(migrationsDir, cfg.Migration.Loader, cfg.Migration.SQLPreview, false)
+		if err != nil {
+			return err
+		}
+		for _, path := range stale {
+			fmt.Printf("Stale SQL preview: %s\n", path)
+		}
+		if diff.HasChanges {
+			printChangeList(diff.Changes)
+			return fmt.Errorf("migrations needed: %d changes detected", len(diff.Changes))
+		}
+		if len(stale) > 0 {
+			return fmt.Errorf("SQL previews out of date: %d file(s); run 'makemigrations generate' to refresh them", len(stale))
+		}
 	}
 
-	if goMigCheck {
-		printChangeList(diff.Changes)
-		return fmt.Errorf("migrations needed: %d changes detected", len(diff.Changes))
+	if !diff.HasChanges && amend == nil {
+		fmt.Println("No changes detected.")
+		if goMigDryRun || goMigCheck {
+			return nil
+		}
+		return refreshSQLPreviews(migrationsDir, cfg.Migration.Loader, cfg.Migration.SQLPreview)
 	}
 
 	// 6. Determine next migration name
@@ -212,15 +285,69 @@ func runGoMakeMigrations(_ *cobra.Command, _ []string) error {
 		deps = dagOut.Leaves
 	}
 	count := len(migFiles)
-	name := BuildMigrationName(count, goMigName, diffEngine.GenerateMigrationName(diff))
+	name, err := newMigrationName(cfg, count, time.Now(), goMigName, diffEngine.GenerateMigrationName(diff))
+	if err != nil {
+		return err
+	}
+	if amend != nil {
+		name, deps = amend.migration.Name, amend.migration.Dependencies
+	}
 
-	// 7. Prompt for destructive operations and build per-change decisions.
-	decisions, err := promptGoMigDecisions(diff)
+	// 7. Decide destructive operations from the policy, prompting for the
+	// rest when stdin is a terminal.
+	interactive := stdinIsTerminal() && !goMigNonInteractive
+	policy, err := parseDestructivePolicy(cfg.Migration.DestructivePolicy, goMigDestructive, schemaTables(currentSchema, prevSchema))
+	if err != nil {
+		return err
+	}
+	decisions, notes, err := promptGoMigDecisions(diff, policy, ui.RunDestructivePrompt, interactive)
 	if err != nil {
 		return err // includes user-requested exit
 	}
+	gen.DecisionNotes = notes
 
-	// 8. Generate Go source
+	// 8. Collect one-off backfill values for columns that become NOT NULL.
+	// Under --zero-downtime, renamed fields are filled from their old
+	// column instead.
+	backfillDiff := diff
+	if goMigZeroDowntime {
+		if gen.Renames, err = parseRenames(goMigRenames); err != nil {
+			return err
+		}
+		warnPossibleRenames(diff, gen.Renames, os.Stdout)
+		backfillDiff = withoutRenameTargets(diff, gen.Renames)
+	} else if len(goMigRenames) > 0 {
+		return fmt.Errorf("--rename requires --zero-downtime")
+	}
+	gen.Backfills, err = collectBackfills(backfillDiff, goMigBackfills, os.Stdin, os.Stdout, interactive)
+	if err != nil {
+		return err
+	}
+
+	// 9. Choose how column type changes are applied. --zero-downtime always
+	// copies them into a dual-written replacement column.
+	if goMigZeroDowntime {
+		if len(goMigNewColumns) > 0 {
+			return fmt.Errorf("--new-column cannot be combined with --zero-downtime, which copies every type change into a new column")
+		}
+	} else if gen.NewColumns, err = chooseAlterMethods(diff, currentSchema, prevSchema, goMigNewColumns, os.Stdin, os.Stdout, interactive); err != nil {
+		return err
+	}
+
+	// 10. Generate Go source
+	if amend != nil {
+		if err := goAmendMigration(gen, amend, diff, currentSchema, prevSchema, decisions); err != nil || goMigDryRun {
+			return err
+		}
+		return refreshSQLPreviews(migrationsDir, cfg.Migration.Loader, cfg.Migration.SQLPreview)
+	}
+	if goMigZeroDowntime {
+		if err := goGenerateExpandContract(gen, cfg, migrationsDir, count, deps, diff, currentSchema, prevSchema,
+			decisions, dbType, diffEngine.GenerateMigrationName(diff)); err != nil || goMigDryRun {
+			return err
+		}
+		return refreshSQLPreviews(migrationsDir, cfg.Migration.Loader, cfg.Migration.SQLPreview)
+	}
 	src, err := gen.GenerateMigration(name, deps, diff, currentSchema, prevSchema, decisions)
 	if err != nil {
 		return fmt.Errorf("generating migration source: %w", err)
@@ -231,7 +358,7 @@ func runGoMakeMigrations(_ *cobra.Command, _ []string) error {
 		return nil
 	}
 
-	// 9. Write file
+	// 11. Write file
 	if err := os.MkdirAll(migrationsDir, 0o755); err != nil {
 		return fmt.Errorf("creating migrations directory: %w", err)
 	}
@@ -240,7 +367,7 @@ func runGoMakeMigrations(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("writing migration file: %w", err)
 	}
 	fmt.Printf("Created %s\n", outPath)
-	return nil
+	return refreshSQLPreviews(migrationsDir, cfg.Migration.Loader, cfg.Migration.SQLPreview)
 }
 
 // printChangeList prints a human-readable summary of schema changes grouped by type.
@@ -268,10 +395,21 @@ func printChangeList(changes []yamlpkg.Change) {
 		{yamlpkg.ChangeTypeFieldModified, "Fields modified"},
 		{yamlpkg.ChangeTypeIndexAdded, "Indexes added"},
 		{yamlpkg.ChangeTypeIndexRemoved, "Indexes removed"},
+		{yamlpkg.ChangeTypeUniqueConstraintAdded, "Unique constraints added"},
+		{yamlpkg.ChangeTypeUniqueConstraintRemoved, "Unique constraints removed"},
 		{yamlpkg.ChangeTypeForeignKeyAdded, "Foreign keys added"},
 		{yamlpkg.ChangeTypeForeignKeyRemoved, "Foreign keys removed"},
 		{yamlpkg.ChangeTypeDefaultsModified, "Defaults modified"},
 		{yamlpkg.ChangeTypeTypeMappingsModified, "Type mappings modified"},
+		{yamlpkg.ChangeTypeSequenceAdded, "Sequences added"},
+		{yamlpkg.ChangeTypeSequenceRemoved, "Sequences removed"},
+		{yamlpkg.ChangeTypeSequenceModified, "Sequences modified"},
+		{yamlpkg.ChangeTypeFunctionAdded, "Functions added"},
+		{yamlpkg.ChangeTypeFunctionRemoved, "Functions removed"},
+		{yamlpkg.ChangeTypeFunctionModified, "Functions modified"},
+		{yamlpkg.ChangeTypeTriggerAdded, "Triggers added"},
+		{yamlpkg.ChangeTypeTriggerRemoved, "Triggers removed"},
+		{yamlpkg.ChangeTypeTriggerModified, "Triggers modified"},
 	}
 
 	fmt.Println()
@@ -292,30 +430,61 @@ func printChangeList(changes []yamlpkg.Change) {
 	fmt.Println()
 }
 
-// promptGoMigDecisions iterates through diff.Changes and, for each destructive
-// operation, shows a bubbletea selector for the user to choose an action and
-// scope. The returned map is keyed by change index.
+// destructivePromptFunc asks how to handle one destructive change; it is
+// ui.RunDestructivePrompt outside tests.
+type destructivePromptFunc func(title string, changeType yamlpkg.ChangeType) (yamlpkg.PromptResponse, ui.PromptScope, error)
+
+// promptGoMigDecisions decides each destructive operation in diff.Changes.
+// A change matched by policy is decided by its rule; otherwise, when
+// interactive, prompt shows a bubbletea selector for the user to choose an
+// action and scope. When not interactive every destructive change must be
+// covered by the policy. The returned maps are keyed by change index: the
+// decisions, and a one-line note per decision for the summary comment in
+// the generated file.
 //
 // Scope is toggled with Tab: "This only" → "All remaining" → "All of this type".
 //
-// If the user chooses PromptOmit the generated operation will have SchemaOnly: true
-// (schema state advances but no SQL is executed). If the user chooses PromptExit
-// an error is returned and migration generation is cancelled.
-func promptGoMigDecisions(diff *yamlpkg.SchemaDiff) (map[int]yamlpkg.PromptResponse, error) {
+// If the decision is PromptOmit the generated operation will have SchemaOnly: true
+// (schema state advances but no SQL is executed). If the user chooses PromptExit,
+// or a policy rule says "fail", an error is returned and migration generation
+// is cancelled.
+func promptGoMigDecisions(
+	diff *yamlpkg.SchemaDiff,
+	policy *destructivePolicy,
+	prompt destructivePromptFunc,
+	interactive bool,
+) (map[int]yamlpkg.PromptResponse, map[int]string, error) {
 	decisions := make(map[int]yamlpkg.PromptResponse)
+	notes := make(map[int]string)
 	applyAll := yamlpkg.PromptResponse(0)
 	applyByType := make(map[yamlpkg.ChangeType]yamlpkg.PromptResponse)
+	var undecided []string
 
 	for i, change := range diff.Changes {
 		if !yamlpkg.IsDestructiveOperation(change.Type) {
 			continue
 		}
+		what := fmt.Sprintf("%s %s", change.Type, changeTarget(change))
+		if rule, ok := policy.match(change); ok {
+			if rule.resp == yamlpkg.PromptExit {
+				return nil, nil, fmt.Errorf("destructive change %s refused by policy rule %q", what, rule.key)
+			}
+			decisions[i] = rule.resp
+			notes[i] = fmt.Sprintf("%s: %s (policy %q)", what, rule.action, rule.key)
+			continue
+		}
+		if !interactive {
+			undecided = append(undecided, what)
+			continue
+		}
 		if applyAll != 0 {
 			decisions[i] = applyAll
+			notes[i] = fmt.Sprintf("%s: %s (prompt)", what, policyActionName(applyAll))
 			continue
 		}
 		if resp, ok := applyByType[change.Type]; ok {
 			decisions[i] = resp
+			notes[i] = fmt.Sprintf("%s: %s (prompt)", what, policyActionName(resp))
 			continue
 		}
 
@@ -326,15 +495,16 @@ func promptGoMigDecisions(diff *yamlpkg.SchemaDiff) (map[int]yamlpkg.PromptRespo
 			title = fmt.Sprintf("Destructive: %s on %q", change.Type, change.TableName)
 		}
 
-		resp, scope, err := ui.RunDestructivePrompt(title, change.Type)
+		resp, scope, err := prompt(title, change.Type)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if resp == yamlpkg.PromptExit {
-			return nil, fmt.Errorf("migration generation cancelled by user")
+			return nil, nil, fmt.Errorf("migration generation cancelled by user")
 		}
 
 		decisions[i] = resp
+		notes[i] = fmt.Sprintf("%s: %s (prompt)", what, policyActionName(resp))
 		switch scope {
 		case ui.ScopeAll:
 			applyAll = resp
@@ -342,26 +512,315 @@ func promptGoMigDecisions(diff *yamlpkg.SchemaDiff) (map[int]yamlpkg.PromptRespo
 			applyByType[change.Type] = resp
 		}
 	}
-	return decisions, nil
+	if len(undecided) > 0 {
+		return nil, nil, fmt.Errorf("no destructive-change policy for %s; not prompting because stdin is not a terminal "+
+			"(set migration.destructive_policy or pass --destructive <rule>=<action>)", strings.Join(undecided, ", "))
+	}
+	return decisions, notes, nil
+}
+
+// collectBackfills returns the one-off backfill expressions, keyed
+// "table.field", for the changes in diff that need existing rows filled before
+// NOT NULL can be enforced (see codegen.NeedsBackfill). Values come from the
+// --backfill specs first; any still missing are prompted for when interactive
+// is true. A change left without a value is generated as-is with a warning,
+// since it will fail on a table that has rows.
+func collectBackfills(diff *yamlpkg.SchemaDiff, specs []string, in io.Reader, out io.Writer, interactive bool) (map[string]string, error) {
+	given := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		key, expr, ok := strings.Cut(spec, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !strings.Contains(key, ".") || strings.TrimSpace(expr) == "" {
+			return nil, fmt.Errorf("invalid --backfill %q: expected table.field=expr", spec)
+		}
+		given[key] = strings.TrimSpace(expr)
+	}
+
+	backfills := make(map[string]string)
+	reader := bufio.NewReader(in)
+	for _, change := range diff.Changes {
+		if !codegen.NeedsBackfill(change) {
+			continue
+		}
+		key := change.TableName + "." + change.FieldName
+		if _, done := backfills[key]; done {
+			continue
+		}
+		if expr, ok := given[key]; ok {
+			backfills[key] = expr
+			delete(given, key)
+			continue
+		}
+		if interactive {
+			_, _ = fmt.Fprintf(out, "Field %q becomes NOT NULL without a default; existing rows need a value.\n", key)
+			_, _ = fmt.Fprint(out, "Enter a one-off SQL expression to fill them (e.g. 0 or 'n/a'), or leave blank to skip: ")
+			line, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return nil, fmt.Errorf("reading backfill value: %w", err)
+			}
+			if expr := strings.TrimSpace(line); expr != "" {
+				backfills[key] = expr
+				continue
+			}
+		}
+		_, _ = fmt.Fprintf(out, "WARNING: no backfill for %s; the migration will fail if the table has rows (use --backfill %s=<expr>)\n", key, key)
+	}
+	if len(given) > 0 {
+		unused := make([]string, 0, len(given))
+		for key := range given {
+			unused = append(unused, key)
+		}
+		sort.Strings(unused)
+		return nil, fmt.Errorf("--backfill %s does not match a column that becomes NOT NULL", strings.Join(unused, ", "))
+	}
+	return backfills, nil
 }
 
-// queryDAG loads the migrations directory with the yaegi interpreter and
-// returns the current migration graph state. No Go toolchain is invoked; the
-// migration .go files are interpreted in-process and registered with a fresh
+// chooseAlterMethods returns the "table.field" keys whose type change should
+// be generated with the new_column method (copy into a replacement column)
+// rather than altered in place. Columns named by --new-column are chosen
+// outright; for the other type changes the user is asked when interactive is
+// true.
+func chooseAlterMethods(diff *yamlpkg.SchemaDiff, currentSchema, prevSchema *yamlpkg.Schema, specs []string, in io.Reader, out io.Writer, interactive bool) (map[string]bool, error) {
+	given := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if !strings.Contains(spec, ".") {
+			return nil, fmt.Errorf("invalid --new-column %q: expected table.field", spec)
+		}
+		given[spec] = true
+	}
+
+	chosen := make(map[string]bool)
+	reader := bufio.NewReader(in)
+	for _, change := range diff.Changes {
+		if !codegen.IsTypeChange(change, currentSchema, prevSchema) {
+			continue
+		}
+		key := change.TableName + "." + change.FieldName
+		if given[key] {
+			chosen[key] = true
+			delete(given, key)
+			continue
+		}
+		if !interactive {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "Field %q changes type from %v to %v.\n", key, change.OldValue, change.NewValue)
+		_, _ = fmt.Fprintln(out, "  1) Alter the column in place")
+		_, _ = fmt.Fprintln(out, "  2) Copy into a new column (rename, add, copy with a cast, drop the old one)")
+		_, _ = fmt.Fprint(out, "Choice [1-2, default 1]: ")
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("reading alter method: %w", err)
+		}
+		if strings.TrimSpace(line) == "2" {
+			chosen[key] = true
+		}
+	}
+	if len(given) > 0 {
+		unused := make([]string, 0, len(given))
+		for key := range given {
+			unused = append(unused, key)
+		}
+		sort.Strings(unused)
+		return nil, fmt.Errorf("--new-column %s does not match a column type change", strings.Join(unused, ", "))
+	}
+	return chosen, nil
+}
+
+// parseRenames parses --rename table.old=new specs into the "table.old" →
+// "new" map used by codegen.GoGenerator.Renames.
+func parseRenames(specs []string) (map[string]string, error) {
+	renames := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		key, to, ok := strings.Cut(spec, "=")
+		key, to = strings.TrimSpace(key), strings.TrimSpace(to)
+		if !ok || !strings.Contains(key, ".") || to == "" {
+			return nil, fmt.Errorf("invalid --rename %q: expected table.old=new", spec)
+		}
+		renames[key] = to
+	}
+	return renames, nil
+}
+
+// warnPossibleRenames points out tables that lose and gain fields in diff
+// without a --rename pairing them. Under --zero-downtime an unpaired drop
+// is deferred to the contract migration, but the added field starts empty.
+func warnPossibleRenames(diff *yamlpkg.SchemaDiff, renames map[string]string, out io.Writer) {
+	removed := make(map[string][]string)
+	added := make(map[string][]string)
+	paired := make(map[string]bool)
+	for key, to := range renames {
+		table, _, _ := strings.Cut(key, ".")
+		paired[key] = true
+		paired[table+"."+to] = true
+	}
+	for _, change := range diff.Changes {
+		if paired[change.TableName+"."+change.FieldName] {
+			continue
+		}
+		switch change.Type {
+		case yamlpkg.ChangeTypeFieldRemoved:
+			removed[change.TableName] = append(removed[change.TableName], change.FieldName)
+		case yamlpkg.ChangeTypeFieldAdded:
+			added[change.TableName] = append(added[change.TableName], change.FieldName)
+		}
+	}
+	tables := make([]string, 0, len(removed))
+	for table := range removed {
+		if len(added[table]) > 0 {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		_, _ = fmt.Fprintf(out, "NOTE: %s drops %s and adds %s; if this is a rename, pass --rename %s.%s=%s\n",
+			table, strings.Join(removed[table], ", "), strings.Join(added[table], ", "),
+			table, removed[table][0], added[table][0])
+	}
+}
+
+// withoutRenameTargets returns diff without the FieldAdded changes that
+// renames pairs with a removed field.
+func withoutRenameTargets(diff *yamlpkg.SchemaDiff, renames map[string]string) *yamlpkg.SchemaDiff {
+	if len(renames) == 0 {
+		return diff
+	}
+	targets := make(map[string]bool, len(renames))
+	for key, to := range renames {
+		table, _, _ := strings.Cut(key, ".")
+		targets[table+"."+to] = true
+	}
+	filtered := &yamlpkg.SchemaDiff{HasChanges: diff.HasChanges}
+	for _, change := range diff.Changes {
+		if change.Type == yamlpkg.ChangeTypeFieldAdded && targets[change.TableName+"."+change.FieldName] {
+			continue
+		}
+		filtered.Changes = append(filtered.Changes, change)
+	}
+	return filtered
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// loadMigrations loads the migrations in migrationsDir with loader, the
+// migration.loader setting (see interp.Load). verbose lists the files the ast
+// loader could not read statically and handed to yaegi instead.
+func loadMigrations(migrationsDir, loader string, verbose bool) (*migrate.Registry, error) {
+	reg, fallback, err := interp.Load(migrationsDir, loader)
+	if err != nil {
+		return nil, fmt.Errorf("loading migrations: %w", err)
+	}
+	if verbose && len(fallback) > 0 {
+		fmt.Printf("Interpreted %d migration file(s) with yaegi:\n", len(fallback))
+		for _, path := range fallback {
+			fmt.Printf("  %s\n", path)
+		}
+	}
+	return reg, nil
+}
+
+// queryDAG loads the migrations directory with loader (see loadMigrations) and returns
+// the current migration graph state. No Go toolchain is invoked; the
+// migration .go files are read in-process and registered with a fresh
 // *migrate.Registry, then BuildGraph + ToDAGOutput run directly.
 //
-// The verbose parameter is preserved for API compatibility with callers but
-// has no effect now that no build step takes place.
-func queryDAG(migrationsDir string, _ bool) (*migrate.DAGOutput, error) {
-	reg, err := interp.LoadRegistry(migrationsDir)
+// Unless --no-cache is given, the result is cached (see dagcache) keyed by
+// the migration files and the tool build, and a cached result is returned
+// without interpreting anything. verbose reports cache hits and failures to
+// store an entry.
+func queryDAG(migrationsDir, loader string, verbose bool) (*migrate.DAGOutput, error) {
+	var key, cacheDir, toolVersion string
+	cacheable := !noCache
+	if cacheable {
+		toolVersion, cacheable = cacheToolVersion()
+	}
+	if cacheable {
+		files, err := interp.MigrationFiles(migrationsDir)
+		if err != nil {
+			return nil, fmt.Errorf("loading migrations: %w", err)
+		}
+		if key, err = dagcache.Key(files, toolVersion); err != nil {
+			return nil, fmt.Errorf("loading migrations: %w", err)
+		}
+		cacheDir = dagcache.DefaultDir(migrationsDir)
+		if out, ok := dagcache.Load(cacheDir, key); ok {
+			if verbose {
+				fmt.Printf("Using cached migration state from %s\n", cacheDir)
+			}
+			return out, nil
+		}
+	}
+
+	reg, err := loadMigrations(migrationsDir, loader, verbose)
 	if err != nil {
-		return nil, fmt.Errorf("loading migrations: %w", err)
+		return nil, err
 	}
 	g, err := migrate.BuildGraph(reg)
 	if err != nil {
 		return nil, fmt.Errorf("building migration graph: %w", err)
 	}
-	return g.ToDAGOutput()
+	out, err := g.ToDAGOutput()
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		// The cache only saves time; a read-only cache directory is fine.
+		if err := dagcache.Store(cacheDir, key, out); err != nil && verbose {
+			fmt.Printf("WARNING: not caching migration state: %v\n", err)
+		}
+	}
+	return out, nil
+}
+
+// cacheToolVersion identifies this build of makemigrations for dagcache
+// keys, so an upgrade never reads state reconstructed by another version.
+// Builds without version ldflags are told apart by their VCS revision. A
+// build from a modified checkout shares its revision with other edits of
+// the source, so it is identified by a hash of the executable instead; ok
+// is false when that cannot be read, and the cache is not used.
+func cacheToolVersion() (v string, ok bool) {
+	v = version.GetFullVersion()
+	info, hasInfo := debug.ReadBuildInfo()
+	if !hasInfo {
+		return v, true
+	}
+	v += " " + info.Main.Version
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" || s.Key == "vcs.modified" {
+			v += " " + s.Key + "=" + s.Value
+		}
+		if s.Key == "vcs.modified" && s.Value == "true" {
+			sum, err := executableHash()
+			if err != nil {
+				return "", false
+			}
+			v += " executable=" + sum
+		}
+	}
+	return v, true
+}
+
+// executableHash returns the SHA-256 of the running executable.
+func executableHash() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 // schemaStateToYAMLSchema converts a migrate.SchemaState (reconstructed from
@@ -389,6 +848,8 @@ func schemaStateToYAMLSchema(state *migrate.SchemaState, dbType string) *yamlpkg
 				Scale:      f.Scale,
 				AutoCreate: f.AutoCreate,
 				AutoUpdate: f.AutoUpdate,
+				Collation:  f.Collation,
+				Charset:    f.Charset,
 			}
 			if f.ForeignKey != nil {
 				// Only include the FK annotation when the constraint actually exists in
@@ -421,12 +882,68 @@ func schemaStateToYAMLSchema(state *migrate.SchemaState, dbType string) *yamlpkg
 				Where:  idx.Where,
 			})
 		}
+		for _, uc := range ts.UniqueConstraints {
+			t.UniqueConstraints = append(t.UniqueConstraints, yamlpkg.UniqueConstraint{
+				Name:             uc.Name,
+				Fields:           uc.Fields,
+				NullsNotDistinct: uc.NullsNotDistinct,
+			})
+		}
 		schema.Tables = append(schema.Tables, t)
 	}
 	// Sort tables for determinism
 	sort.Slice(schema.Tables, func(i, j int) bool {
 		return schema.Tables[i].Name < schema.Tables[j].Name
 	})
+	for _, seq := range state.Sequences {
+		schema.Sequences = append(schema.Sequences, yamlpkg.Sequence{
+			Name:        seq.Name,
+			Start:       seq.Start,
+			Increment:   seq.Increment,
+			MinValue:    seq.MinValue,
+			MaxValue:    seq.MaxValue,
+			Cycle:       seq.Cycle,
+			OwnedBy:     seq.OwnedBy,
+			HasStart:    seq.HasStart && seq.Start == 0,
+			HasMinValue: seq.HasMinValue && seq.MinValue == 0,
+		})
+	}
+	sort.Slice(schema.Sequences, func(i, j int) bool {
+		return schema.Sequences[i].Name < schema.Sequences[j].Name
+	})
+	// Function and trigger bodies in state belong to dbType; key them the
+	// same way Schema.SelectDatabase narrows the YAML so diffs compare bodies.
+	for _, fn := range state.Functions {
+		schema.Functions = append(schema.Functions, yamlpkg.Function{
+			Name:      fn.Name,
+			Arguments: fn.Arguments,
+			Returns:   fn.Returns,
+			Language:  fn.Language,
+			Procedure: fn.Procedure,
+			Body:      map[types.DatabaseType]string{types.DatabaseType(dbType): fn.Body},
+		})
+	}
+	sort.Slice(schema.Functions, func(i, j int) bool {
+		return schema.Functions[i].Name < schema.Functions[j].Name
+	})
+	for _, tr := range state.Triggers {
+		yt := yamlpkg.Trigger{
+			Name:     tr.Name,
+			Table:    tr.Table,
+			Timing:   tr.Timing,
+			Events:   tr.Events,
+			ForEach:  tr.ForEach,
+			When:     tr.When,
+			Function: tr.Function,
+		}
+		if tr.Body != "" {
+			yt.Body = map[types.DatabaseType]string{types.DatabaseType(dbType): tr.Body}
+		}
+		schema.Triggers = append(schema.Triggers, yt)
+	}
+	sort.Slice(schema.Triggers, func(i, j int) bool {
+		return schema.Triggers[i].Name < schema.Triggers[j].Name
+	})
 	// Populate the Defaults section so that defaults changes are detected on
 	// subsequent diff runs (the diff engine compares schema.Defaults).
 	if len(state.Defaults) > 0 {
@@ -482,20 +999,241 @@ func mapsEqual(a, b map[string]string) bool {
 // is used. As a final fallback a timestamp is appended.
 // Exported for testing.
 func BuildMigrationName(currentCount int, customName, autoName string) string {
-	num := codegen.NextMigrationNumber(currentCount)
+	return joinMigrationName(codegen.NextMigrationNumber(currentCount), customName, autoName)
+}
+
+// newMigrationName is BuildMigrationName with the prefix chosen by the
+// migration.naming scheme in cfg (see codegen.MigrationPrefix). now is the
+// creation time used by the timestamp and ULID schemes.
+func newMigrationName(cfg *config.Config, currentCount int, now time.Time, customName, autoName string) (string, error) {
+	prefix, err := codegen.MigrationPrefix(cfg.Migration.Naming, currentCount, now)
+	if err != nil {
+		return "", fmt.Errorf("migration.naming: %w", err)
+	}
+	return joinMigrationName(prefix, customName, autoName), nil
+}
+
+// joinMigrationName appends the normalized customName, else autoName, else
+// a timestamp to prefix.
+func joinMigrationName(prefix, customName, autoName string) string {
 	if customName != "" {
-		return fmt.Sprintf("%s_%s", num, strings.ToLower(strings.ReplaceAll(customName, " ", "_")))
+		return fmt.Sprintf("%s_%s", prefix, strings.ToLower(strings.ReplaceAll(customName, " ", "_")))
 	}
 	if autoName != "" {
-		return fmt.Sprintf("%s_%s", num, autoName)
+		return fmt.Sprintf("%s_%s", prefix, autoName)
+	}
+	return fmt.Sprintf("%s_%s", prefix, time.Now().Format("20060102150405"))
+}
+
+// amendTarget is the migration `generate --amend` rewrites.
+type amendTarget struct {
+	migration *migrate.Migration
+	// path is the migration's .go file.
+	path string
+	// stateBefore is the schema state without the migration.
+	stateBefore *migrate.SchemaState
+}
+
+// prepareAmend picks the latest migration in migrationsDir, the single leaf
+// of the migration graph, and checks that --amend may rewrite it: it must be
+// marked Draft or absent from the history table of the configured dev
+// database (DATABASE_URL, else database.default_url, of type DB_TYPE as for
+// the migrate command). A graph with several
+// leaves is refused, since it has no one latest migration.
+func prepareAmend(cfg *config.Config, migrationsDir string) (*amendTarget, error) {
+	reg, err := loadMigrations(migrationsDir, cfg.Migration.Loader, false)
+	if err != nil {
+		return nil, err
+	}
+	g, err := migrate.BuildGraph(reg)
+	if err != nil {
+		return nil, fmt.Errorf("building migration graph: %w", err)
+	}
+	leaves := g.Leaves()
+	switch {
+	case len(leaves) == 0:
+		return nil, fmt.Errorf("--amend: no migrations to amend in %s", migrationsDir)
+	case len(leaves) > 1:
+		return nil, fmt.Errorf("--amend: the migration graph has %d leaves (%s); run 'makemigrations generate --merge' first",
+			len(leaves), strings.Join(leaves, ", "))
+	}
+	latest, _ := reg.Get(leaves[0])
+	path := filepath.Join(migrationsDir, codegen.MigrationFileName(latest.Name))
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("--amend: cannot find the file for %s: %w", latest.Name, err)
+	}
+
+	if !latest.Draft {
+		dsn := os.Getenv("DATABASE_URL")
+		if dsn == "" {
+			dsn = cfg.Database.DefaultURL
+		}
+		if dsn == "" {
+			return nil, fmt.Errorf("--amend: set DATABASE_URL or database.default_url so %s can be checked "+
+				"against the dev database, or mark it Draft: true", latest.Name)
+		}
+		applied, err := isAppliedInDB(migrate.EnvOr("DB_TYPE", "postgresql"), dsn, latest.Name)
+		if err != nil {
+			return nil, fmt.Errorf("--amend: checking the dev database: %w", err)
+		}
+		if applied {
+			return nil, fmt.Errorf("--amend: %s is already applied to the dev database; "+
+				"roll it back with `migrate down` or mark it Draft: true", latest.Name)
+		}
+	}
+
+	state, err := g.ReconstructStateBefore(latest.Name)
+	if err != nil {
+		return nil, err
 	}
-	return fmt.Sprintf("%s_%s", num, time.Now().Format("20060102150405"))
+	return &amendTarget{migration: latest, path: path, stateBefore: state}, nil
+}
+
+// isAppliedInDB reports whether the history table of the dbType database at
+// dsn records name as applied. It only reads: a database without a history
+// table has nothing applied, and is left without one.
+func isAppliedInDB(dbType, dsn, name string) (bool, error) {
+	p, err := migrate.BuildProviderFromType(dbType)
+	if err != nil {
+		return false, err
+	}
+	db, err := dumpdata.OpenDB(driverForDBType(dbType), dsn)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+	rows, err := db.QueryContext(ctx, "SELECT name FROM makemigrations_history WHERE 1 = 0")
+	if err != nil {
+		// No history table (yet).
+		return false, nil
+	}
+	_ = rows.Close()
+	var one int
+	err = db.QueryRowContext(ctx, "SELECT 1 FROM makemigrations_history WHERE name = "+p.Placeholder(1), name).Scan(&one)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("querying makemigrations_history: %w", err)
+	}
+	return true, nil
+}
+
+// goAmendMigration rewrites the file of amend.migration from diff, keeping
+// its hand-written operations (see codegen.GoGenerator.AmendMigration). When
+// nothing is left the file is removed.
+func goAmendMigration(
+	gen *codegen.GoGenerator,
+	amend *amendTarget,
+	diff *yamlpkg.SchemaDiff,
+	currentSchema, prevSchema *yamlpkg.Schema,
+	decisions map[int]yamlpkg.PromptResponse,
+) error {
+	old, err := os.ReadFile(amend.path)
+	if err != nil {
+		return fmt.Errorf("reading migration file: %w", err)
+	}
+	src, err := gen.AmendMigration(amend.migration.Name, amend.migration.Dependencies, diff, currentSchema, prevSchema, decisions, old)
+	if err != nil {
+		return fmt.Errorf("generating migration source: %w", err)
+	}
+	if goMigDryRun {
+		if src == "" {
+			fmt.Printf("Would remove %s: no changes remain\n", amend.path)
+			return nil
+		}
+		fmt.Println(src)
+		return nil
+	}
+	if src == "" {
+		if err := os.Remove(amend.path); err != nil {
+			return fmt.Errorf("removing migration file: %w", err)
+		}
+		fmt.Printf("Removed %s: no changes remain\n", amend.path)
+		return nil
+	}
+	if err := os.WriteFile(amend.path, []byte(src), 0o644); err != nil {
+		return fmt.Errorf("writing migration file: %w", err)
+	}
+	fmt.Printf("Amended %s\n", amend.path)
+	return nil
+}
+
+// goGenerateExpandContract writes diff as an expand migration numbered after
+// the count existing ones and a contract migration after that (see
+// codegen.GoGenerator.GenerateExpandContract). When every change falls in
+// one phase only that migration is written.
+func goGenerateExpandContract(
+	gen *codegen.GoGenerator,
+	cfg *config.Config,
+	migrationsDir string,
+	count int,
+	deps []string,
+	diff *yamlpkg.SchemaDiff,
+	currentSchema, prevSchema *yamlpkg.Schema,
+	decisions map[int]yamlpkg.PromptResponse,
+	dbType yamlpkg.DatabaseType,
+	autoName string,
+) error {
+	// The contract migration is named a second later so that it sorts
+	// after the expand one under the timestamp and ULID schemes too.
+	now := time.Now()
+	expandName, err := newMigrationName(cfg, count, now, goMigName, autoName)
+	if err != nil {
+		return err
+	}
+	contractName, err := newMigrationName(cfg, count+1, now.Add(time.Second), goMigName, autoName)
+	if err != nil {
+		return err
+	}
+	expandName += "_expand"
+	contractName += "_contract"
+	expand, contract, err := gen.GenerateExpandContract(expandName, contractName, deps, diff, currentSchema, prevSchema, decisions, dbType)
+	if err != nil {
+		return fmt.Errorf("generating migration source: %w", err)
+	}
+	if expand == "" {
+		// Keep the numbering contiguous when there is nothing to expand.
+		contractName = strings.TrimSuffix(expandName, "_expand") + "_contract"
+		if _, contract, err = gen.GenerateExpandContract(expandName, contractName, deps, diff, currentSchema, prevSchema, decisions, dbType); err != nil {
+			return fmt.Errorf("generating migration source: %w", err)
+		}
+	}
+
+	files := []struct{ name, src string }{{expandName, expand}, {contractName, contract}}
+	if goMigDryRun {
+		for _, f := range files {
+			if f.src != "" {
+				fmt.Println(f.src)
+			}
+		}
+		return nil
+	}
+	if err := os.MkdirAll(migrationsDir, 0o755); err != nil {
+		return fmt.Errorf("creating migrations directory: %w", err)
+	}
+	for _, f := range files {
+		if f.src == "" {
+			continue
+		}
+		outPath := filepath.Join(migrationsDir, codegen.MigrationFileName(f.name))
+		if err := os.WriteFile(outPath, []byte(f.src), 0o644); err != nil {
+			return fmt.Errorf("writing migration file: %w", err)
+		}
+		fmt.Printf("Created %s\n", outPath)
+	}
+	if expand != "" && contract != "" {
+		fmt.Println("Deploy with `migrate up --phase expand`, roll out the new code, then `migrate up --phase contract`.")
+	}
+	return nil
 }
 
 // goGenerateMerge generates a merge migration for detected branches. It uses
 // codegen.MergeGenerator to produce a .go file that depends on all branch
 // leaves but contains no operations, thus unifying the DAG.
-func goGenerateMerge(migrationsDir string, dagOut *migrate.DAGOutput, dryRun, verbose bool) error {
+func goGenerateMerge(migrationsDir, naming string, dagOut *migrate.DAGOutput, dryRun, verbose bool) error {
 	// Count existing migration files
 	count := 0
 	goFiles, _ := filepath.Glob(filepath.Join(migrationsDir, "*.go"))
@@ -505,11 +1243,14 @@ func goGenerateMerge(migrationsDir string, dagOut *migrate.DAGOutput, dryRun, ve
 		}
 	}
 
-	name := fmt.Sprintf("%s_merge_%s", codegen.NextMigrationNumber(count),
-		strings.Join(dagOut.Leaves, "_and_"))
+	prefix, err := codegen.MigrationPrefix(naming, count, time.Now())
+	if err != nil {
+		return fmt.Errorf("migration.naming: %w", err)
+	}
+	name := fmt.Sprintf("%s_merge_%s", prefix, strings.Join(dagOut.Leaves, "_and_"))
 	// Truncate if too long
 	if len(name) > 80 {
-		name = fmt.Sprintf("%s_merge", codegen.NextMigrationNumber(count))
+		name = fmt.Sprintf("%s_merge", prefix)
 	}
 
 	mergeGen := codegen.NewMergeGenerator()
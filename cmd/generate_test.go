@@ -24,11 +24,18 @@ SOFTWARE.
 package cmd
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/ocomsoft/makemigrations/internal/config"
+	"github.com/ocomsoft/makemigrations/internal/dagcache"
+	"github.com/ocomsoft/makemigrations/internal/interp"
 	"github.com/ocomsoft/makemigrations/internal/ui"
 	yamlpkg "github.com/ocomsoft/makemigrations/internal/yaml"
 	"github.com/ocomsoft/makemigrations/migrate"
@@ -366,7 +373,7 @@ func TestGoGenerateMerge_WritesMergeFile(t *testing.T) {
 		Leaves:      []string{"0001_initial", "0002_feature"},
 		HasBranches: true,
 	}
-	err := goGenerateMerge(tmpDir, dagOut, false, false)
+	err := goGenerateMerge(tmpDir, "", dagOut, false, false)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -403,7 +410,7 @@ func TestGoGenerateMerge_DryRun(t *testing.T) {
 		Leaves:      []string{"0001_a", "0001_b"},
 		HasBranches: true,
 	}
-	err := goGenerateMerge(tmpDir, dagOut, true, false)
+	err := goGenerateMerge(tmpDir, "", dagOut, true, false)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -426,7 +433,7 @@ func TestGoGenerateMerge_LongNameTruncation(t *testing.T) {
 		},
 		HasBranches: true,
 	}
-	err := goGenerateMerge(tmpDir, dagOut, false, false)
+	err := goGenerateMerge(tmpDir, "", dagOut, false, false)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -550,3 +557,377 @@ func TestParsePromptInput(t *testing.T) {
 		}
 	}
 }
+
+func TestCollectBackfills(t *testing.T) {
+	notNull := false
+	diff := &yamlpkg.SchemaDiff{Changes: []yamlpkg.Change{
+		{Type: yamlpkg.ChangeTypeFieldAdded, TableName: "users", FieldName: "status", NewValue: yamlpkg.Field{Name: "status", Type: "varchar", Nullable: &notNull}},
+		{Type: yamlpkg.ChangeTypeFieldModified, TableName: "users", FieldName: "age", OldValue: true, NewValue: false, Property: yamlpkg.FieldPropertyNullable},
+		{Type: yamlpkg.ChangeTypeFieldAdded, TableName: "users", FieldName: "note", NewValue: yamlpkg.Field{Name: "note", Type: "text"}},
+	}}
+
+	var out strings.Builder
+	got, err := collectBackfills(diff, []string{"users.status='active'"}, strings.NewReader("0\n"), &out, true)
+	if err != nil {
+		t.Fatalf("collectBackfills: %v", err)
+	}
+	if len(got) != 2 || got["users.status"] != "'active'" || got["users.age"] != "0" {
+		t.Errorf("unexpected backfills %v", got)
+	}
+	if !strings.Contains(out.String(), `"users.age" becomes NOT NULL`) {
+		t.Errorf("expected a prompt for users.age, got %q", out.String())
+	}
+
+	out.Reset()
+	got, err = collectBackfills(diff, nil, strings.NewReader(""), &out, false)
+	if err != nil {
+		t.Fatalf("collectBackfills (non-interactive): %v", err)
+	}
+	if len(got) != 0 || strings.Count(out.String(), "WARNING: no backfill") != 2 {
+		t.Errorf("expected two warnings and no backfills, got %v / %q", got, out.String())
+	}
+
+	if _, err := collectBackfills(diff, []string{"users.nope=1"}, strings.NewReader(""), &out, false); err == nil {
+		t.Error("expected an error for a --backfill that matches no change")
+	}
+	if _, err := collectBackfills(diff, []string{"status"}, strings.NewReader(""), &out, false); err == nil {
+		t.Error("expected an error for a malformed --backfill")
+	}
+}
+
+func TestCollectBackfills_PrimaryKeyRemoved(t *testing.T) {
+	prev := &yamlpkg.Schema{Tables: []yamlpkg.Table{{Name: "users", Fields: []yamlpkg.Field{
+		{Name: "code", Type: "varchar", Length: 20, PrimaryKey: true},
+	}}}}
+	curr := &yamlpkg.Schema{Tables: []yamlpkg.Table{{Name: "users", Fields: []yamlpkg.Field{
+		{Name: "code", Type: "varchar", Length: 20},
+	}}}}
+	diff, err := yamlpkg.NewDiffEngine(false).CompareSchemas(prev, curr)
+	if err != nil {
+		t.Fatalf("CompareSchemas: %v", err)
+	}
+
+	var out strings.Builder
+	got, err := collectBackfills(diff, nil, strings.NewReader(""), &out, false)
+	if err != nil {
+		t.Fatalf("collectBackfills: %v", err)
+	}
+	if len(got) != 0 || out.Len() != 0 {
+		t.Errorf("expected no backfill for a primary key removal, got %v / %q", got, out.String())
+	}
+}
+
+func TestChooseAlterMethods(t *testing.T) {
+	prev := &yamlpkg.Schema{Tables: []yamlpkg.Table{{Name: "orders", Fields: []yamlpkg.Field{
+		{Name: "code", Type: "varchar"}, {Name: "total", Type: "float"},
+	}}}}
+	curr := &yamlpkg.Schema{Tables: []yamlpkg.Table{{Name: "orders", Fields: []yamlpkg.Field{
+		{Name: "code", Type: "integer"}, {Name: "total", Type: "decimal"},
+	}}}}
+	diff := &yamlpkg.SchemaDiff{Changes: []yamlpkg.Change{
+		{Type: yamlpkg.ChangeTypeFieldModified, TableName: "orders", FieldName: "code", OldValue: "varchar", NewValue: "integer"},
+		{Type: yamlpkg.ChangeTypeFieldModified, TableName: "orders", FieldName: "total", OldValue: "float", NewValue: "decimal"},
+	}}
+
+	var out strings.Builder
+	got, err := chooseAlterMethods(diff, curr, prev, []string{"orders.code"}, strings.NewReader("2\n"), &out, true)
+	if err != nil {
+		t.Fatalf("chooseAlterMethods: %v", err)
+	}
+	if !got["orders.code"] || !got["orders.total"] || len(got) != 2 {
+		t.Errorf("expected both columns chosen, got %v", got)
+	}
+	if strings.Contains(out.String(), `"orders.code"`) {
+		t.Errorf("expected no prompt for a column given by flag, got %q", out.String())
+	}
+
+	got, err = chooseAlterMethods(diff, curr, prev, nil, strings.NewReader(""), &out, false)
+	if err != nil || len(got) != 0 {
+		t.Errorf("expected in-place alters when not interactive, got %v (%v)", got, err)
+	}
+	if _, err := chooseAlterMethods(diff, curr, prev, []string{"orders.id"}, strings.NewReader(""), &out, false); err == nil {
+		t.Error("expected an error for a --new-column that matches no type change")
+	}
+}
+
+func TestZeroDowntimeRenames(t *testing.T) {
+	renames, err := parseRenames([]string{"users.name=full_name"})
+	if err != nil || renames["users.name"] != "full_name" {
+		t.Fatalf("parseRenames = %v, %v", renames, err)
+	}
+	if _, err := parseRenames([]string{"name=full_name"}); err == nil {
+		t.Error("expected an error for a --rename without a table")
+	}
+
+	diff := &yamlpkg.SchemaDiff{HasChanges: true, Changes: []yamlpkg.Change{
+		{Type: yamlpkg.ChangeTypeFieldRemoved, TableName: "users", FieldName: "name"},
+		{Type: yamlpkg.ChangeTypeFieldAdded, TableName: "users", FieldName: "full_name", NewValue: yamlpkg.Field{Name: "full_name", Type: "text"}},
+		{Type: yamlpkg.ChangeTypeFieldRemoved, TableName: "posts", FieldName: "body"},
+		{Type: yamlpkg.ChangeTypeFieldAdded, TableName: "posts", FieldName: "content", NewValue: yamlpkg.Field{Name: "content", Type: "text"}},
+	}}
+	var out strings.Builder
+	warnPossibleRenames(diff, renames, &out)
+	if got := out.String(); !strings.Contains(got, "--rename posts.body=content") || strings.Contains(got, "users") {
+		t.Errorf("expected a note for posts only, got %q", got)
+	}
+
+	filtered := withoutRenameTargets(diff, renames)
+	if len(filtered.Changes) != 3 || filtered.Changes[1].TableName != "posts" {
+		t.Errorf("expected users.full_name to be filtered out, got %+v", filtered.Changes)
+	}
+}
+
+// writeAmendMigrations writes 0001_initial (users) and 0002_add_email to dir;
+// draft marks 0002_add_email as a draft.
+func writeAmendMigrations(t *testing.T, dir string, draft bool) {
+	t.Helper()
+	files := map[string]string{
+		"0001_initial.go": `package main
+
+import m "github.com/ocomsoft/makemigrations/migrate"
+
+func init() {
+	m.Register(&m.Migration{
+		Name:         "0001_initial",
+		Dependencies: []string{},
+		Operations: []m.Operation{
+			&m.CreateTable{Name: "users", Fields: []m.Field{{Name: "id", Type: "integer", PrimaryKey: true}}},
+		},
+	})
+}
+`,
+		"0002_add_email.go": `package main
+
+import m "github.com/ocomsoft/makemigrations/migrate"
+
+func init() {
+	m.Register(&m.Migration{
+		Name:         "0002_add_email",
+		Dependencies: []string{"0001_initial"},
+		Draft:        ` + strconv.FormatBool(draft) + `,
+		Operations: []m.Operation{
+			&m.AddField{Table: "users", Field: m.Field{Name: "email", Type: "text", Nullable: true}},
+		},
+	})
+}
+`,
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+}
+
+func TestPrepareAmend(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("DB_TYPE", "sqlite")
+	dir := t.TempDir()
+	writeAmendMigrations(t, dir, false)
+	cfg := config.DefaultConfig()
+
+	if _, err := prepareAmend(cfg, dir); err == nil || !strings.Contains(err.Error(), "DATABASE_URL") {
+		t.Fatalf("expected an error without a dev database, got %v", err)
+	}
+
+	cfg.Database.DefaultURL = filepath.Join(t.TempDir(), "dev.db")
+	target, err := prepareAmend(cfg, dir)
+	if err != nil {
+		t.Fatalf("prepareAmend: %v", err)
+	}
+	if target.migration.Name != "0002_add_email" || filepath.Base(target.path) != "0002_add_email.go" {
+		t.Fatalf("unexpected target %s (%s)", target.migration.Name, target.path)
+	}
+	if users := target.stateBefore.Tables["users"]; users == nil || len(users.Fields) != 1 {
+		t.Fatalf("expected users without email before the amended migration, got %+v", users)
+	}
+
+	// Checking the dev database only reads it.
+	db, err := sql.Open("sqlite3", cfg.Database.DefaultURL)
+	if err != nil {
+		t.Fatalf("opening dev database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables); err != nil {
+		t.Fatalf("listing tables: %v", err)
+	}
+	if tables != 0 {
+		t.Fatalf("expected --amend not to create tables in the dev database, found %d", tables)
+	}
+
+	// Apply everything to the dev database: the leaf can no longer be amended...
+	reg, err := interp.LoadRegistry(dir)
+	if err != nil {
+		t.Fatalf("LoadRegistry: %v", err)
+	}
+	m, err := migrate.NewMigrator(db, "sqlite", reg)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := m.Up(context.Background(), migrate.UpOptions{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if _, err := prepareAmend(cfg, dir); err == nil || !strings.Contains(err.Error(), "already applied") {
+		t.Fatalf("expected an applied migration to be refused, got %v", err)
+	}
+
+	// ...unless it is marked as a draft.
+	writeAmendMigrations(t, dir, true)
+	if _, err := prepareAmend(cfg, dir); err != nil {
+		t.Fatalf("expected a draft to be amendable, got %v", err)
+	}
+}
+
+func TestPrepareAmend_Leaf(t *testing.T) {
+	dir := t.TempDir()
+	writeAmendMigrations(t, dir, true)
+	// 0001b sorts before 0002_add_email but depends on it, so it is the leaf.
+	src := `package main
+
+import m "github.com/ocomsoft/makemigrations/migrate"
+
+func init() {
+	m.Register(&m.Migration{Name: "0001b_index", Dependencies: []string{"0002_add_email"}, Draft: true})
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "0001b_index.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("writing migration: %v", err)
+	}
+	target, err := prepareAmend(config.DefaultConfig(), dir)
+	if err != nil {
+		t.Fatalf("prepareAmend: %v", err)
+	}
+	if target.migration.Name != "0001b_index" {
+		t.Fatalf("expected the graph leaf 0001b_index, got %s", target.migration.Name)
+	}
+
+	// A second branch from 0001_initial leaves no single latest migration.
+	src = `package main
+
+import m "github.com/ocomsoft/makemigrations/migrate"
+
+func init() {
+	m.Register(&m.Migration{Name: "0003_other", Dependencies: []string{"0001_initial"}, Draft: true})
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "0003_other.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("writing migration: %v", err)
+	}
+	_, err = prepareAmend(config.DefaultConfig(), dir)
+	if err == nil || !strings.Contains(err.Error(), "2 leaves (0001b_index, 0003_other)") {
+		t.Fatalf("expected several leaves to be refused, got %v", err)
+	}
+}
+
+func TestQueryDAG_Cache(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	t.Setenv("HOME", cacheHome)
+	dir := t.TempDir()
+	writeAmendMigrations(t, dir, false)
+	cacheDir := dagcache.DefaultDir(dir)
+	entries := func() int {
+		files, _ := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+		return len(files)
+	}
+
+	cold, err := queryDAG(dir, "", false)
+	if err != nil {
+		t.Fatalf("queryDAG: %v", err)
+	}
+	if entries() != 1 {
+		t.Fatalf("expected one cache entry in %s, got %d", cacheDir, entries())
+	}
+	warm, err := queryDAG(dir, "", false)
+	if err != nil {
+		t.Fatalf("queryDAG (cached): %v", err)
+	}
+	coldJSON, _ := json.Marshal(cold)
+	warmJSON, _ := json.Marshal(warm)
+	if string(coldJSON) != string(warmJSON) {
+		t.Fatalf("cached output differs:\n%s\n%s", coldJSON, warmJSON)
+	}
+
+	// Editing a migration invalidates the entry.
+	path := filepath.Join(dir, "0002_add_email.go")
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src = []byte(strings.Replace(string(src), `Name: "email"`, `Name: "email_address"`, 1))
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	edited, err := queryDAG(dir, "", false)
+	if err != nil {
+		t.Fatalf("queryDAG (edited): %v", err)
+	}
+	if fields := edited.SchemaState.Tables["users"].Fields; len(fields) != 2 || fields[1].Name != "email_address" {
+		t.Fatalf("expected the edited migration to be replayed, got %+v", fields)
+	}
+
+	// --no-cache neither reads nor writes entries.
+	noCache = true
+	t.Cleanup(func() { noCache = false })
+	if err := os.WriteFile(path, []byte(strings.Replace(string(src), "email_address", "mail", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before := entries()
+	if _, err := queryDAG(dir, "", false); err != nil {
+		t.Fatalf("queryDAG (--no-cache): %v", err)
+	}
+	if entries() != before {
+		t.Error("expected --no-cache not to store an entry")
+	}
+}
+
+func TestExecutableHash(t *testing.T) {
+	first, err := executableHash()
+	if err != nil {
+		t.Fatalf("executableHash: %v", err)
+	}
+	second, err := executableHash()
+	if err != nil {
+		t.Fatalf("executableHash: %v", err)
+	}
+	if len(first) != 64 || first != second {
+		t.Errorf("expected a stable SHA-256 of the test binary, got %q and %q", first, second)
+	}
+}
+
+func TestLoadMigrations_VerboseListsYaegiFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeAmendMigrations(t, dir, false)
+	src := `package main
+
+import m "github.com/ocomsoft/makemigrations/migrate"
+
+func deps() []string { return []string{"0002_add_email"} }
+
+func init() {
+	m.Register(&m.Migration{Name: "0003_helper", Dependencies: deps()})
+}
+`
+	fallback := filepath.Join(dir, "0003_helper.go")
+	if err := os.WriteFile(fallback, []byte(src), 0o644); err != nil {
+		t.Fatalf("writing migration: %v", err)
+	}
+
+	var err error
+	out := captureStdout(t, func() { _, err = loadMigrations(dir, interp.LoaderAST, true) })
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	if !strings.Contains(out, "Interpreted 1 migration file(s) with yaegi") || !strings.Contains(out, fallback) {
+		t.Errorf("expected the fallback file to be listed, got:\n%s", out)
+	}
+	if strings.Contains(out, "0001_initial.go") {
+		t.Errorf("expected only fallback files to be listed, got:\n%s", out)
+	}
+
+	out = captureStdout(t, func() { _, err = loadMigrations(dir, interp.LoaderAST, false) })
+	if err != nil || out != "" {
+		t.Errorf("expected a quiet load without --verbose, got %v and:\n%s", err, out)
+	}
+}
@@ -25,6 +25,8 @@ package cmd
 
 import (
 	"fmt"
+	"os/exec"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -47,8 +49,15 @@ supports are available:
 
   makemigrations migrate up
   makemigrations migrate up --to 0005_add_index
+  makemigrations migrate up --lock-timeout 5s --lock-retries 3
+  makemigrations migrate up --targets targets.yaml --report run.json
+  makemigrations migrate up --phase expand
   makemigrations migrate down --steps 2
+  makemigrations migrate down --batch
   makemigrations migrate status
+  makemigrations migrate history
+  makemigrations migrate prune-history --dry-run
+  makemigrations migrate resolve 0004_add_orders --mark-op 2
   makemigrations migrate showsql
   makemigrations migrate fake 0001_initial
   makemigrations migrate dag`,
@@ -56,7 +65,7 @@ supports are available:
 	SilenceErrors:      true,
 	RunE: func(_ *cobra.Command, args []string) error {
 		cfg := config.LoadOrDefault(configFile)
-		return ExecuteMigrate(cfg.Migration.Directory, cfg.Database.DefaultURL, args)
+		return ExecuteMigrate(cfg, args)
 	},
 }
 
@@ -64,17 +73,52 @@ func init() {
 	rootCmd.AddCommand(migrateCmd)
 }
 
-// ExecuteMigrate loads migrationsDir with the yaegi interpreter and runs the
-// embedded migrate.App with the provided args. defaultURL is used as the
-// fallback database URL when the DATABASE_URL env var is not set.
-func ExecuteMigrate(migrationsDir string, defaultURL string, args []string) error {
-	reg, err := interp.LoadRegistry(migrationsDir)
+// ExecuteMigrate loads the configured migrations directory with the
+// migration.loader (yaegi by default) and runs the embedded migrate.App with the provided args.
+// database.default_url is used as the fallback database URL when the
+// DATABASE_URL env var is not set. The hook snippets configured for the
+// active database run around each migration, and the migration timeouts
+// become the defaults for the up and down flags.
+func ExecuteMigrate(cfg *config.Config, args []string) error {
+	reg, _, err := interp.Load(cfg.Migration.Directory, cfg.Migration.Loader)
 	if err != nil {
 		return fmt.Errorf("loading migrations: %w", err)
 	}
-	app := migrate.NewAppWithRegistry(migrate.Config{
-		DatabaseType: migrate.EnvOr("DB_TYPE", "postgresql"),
-		DatabaseURL:  migrate.EnvOr("DATABASE_URL", defaultURL),
-	}, reg)
+	dbType := migrate.EnvOr("DB_TYPE", "postgresql")
+	hookSQL, err := cfg.Hooks.ForDatabase(dbType)
+	if err != nil {
+		return fmt.Errorf("loading hooks: %w", err)
+	}
+	statement, lock, backoff, err := cfg.Migration.Durations()
+	if err != nil {
+		return err
+	}
+	meta := migrate.DefaultRunMetadata()
+	if meta.GitCommit == "" {
+		// Record the project's commit when migrating from a git checkout.
+		if out, err := exec.Command("git", "rev-parse", "HEAD").Output(); err == nil {
+			meta.GitCommit = strings.TrimSpace(string(out))
+		}
+	}
+	appCfg := migrate.Config{
+		DatabaseType: dbType,
+		DatabaseURL:  migrate.EnvOr("DATABASE_URL", cfg.Database.DefaultURL),
+		Metadata:     &meta,
+		Timeouts: migrate.Timeouts{
+			StatementTimeout: statement,
+			LockTimeout:      lock,
+			LockRetries:      cfg.Migration.LockRetries,
+			LockRetryBackoff: backoff,
+		},
+	}
+	if !hookSQL.IsEmpty() {
+		appCfg.Hooks = append(appCfg.Hooks, &migrate.SQLHooks{
+			BeforeMigrationSQL: hookSQL.BeforeMigration,
+			AfterMigrationSQL:  hookSQL.AfterMigration,
+			BeforeOperationSQL: hookSQL.BeforeOperation,
+			AfterOperationSQL:  hookSQL.AfterOperation,
+		})
+	}
+	app := migrate.NewAppWithRegistry(appCfg, reg)
 	return app.Run(args)
 }
@@ -0,0 +1,382 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ocomsoft/makemigrations/internal/codegen"
+	"github.com/ocomsoft/makemigrations/internal/config"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+var (
+	rebaseOnto    string
+	rebaseDryRun  bool
+	rebaseVerbose bool
+)
+
+// rebaseCmd is the "makemigrations rebase" subcommand. After a git merge
+// leaves two migration leaves, it moves our branch's migrations on top of
+// the other branch's leaf instead of generating a merge migration.
+var rebaseCmd = &cobra.Command{
+	Use:     "rebase",
+	GroupID: "schema",
+	Short:   "Renumber branch migrations onto the other branch's leaf after a merge",
+	Long: `After a git merge brings in migrations from another branch, the migration
+graph has two leaves. Instead of generating a merge migration, rebase moves
+the migrations of one branch on top of the other:
+
+  1. Every migration that is not an ancestor of the --onto leaf is renamed
+     with a fresh prefix (following migration.naming), keeping its suffix
+  2. Their Dependencies are rewritten so the first one depends on the
+     --onto leaf and the rest keep their order
+  3. The files are renamed and the graph is re-validated
+
+Without --onto, the leaf that came in with the merge (MERGE_HEAD, else the
+second parent of HEAD) is used.
+
+Only unapplied migrations may be rebased. When DATABASE_URL or
+database.default_url is set, rebase refuses to move a migration the dev
+database has already applied.
+
+Example:
+  git merge main
+  makemigrations rebase
+  makemigrations rebase --onto 0007_add_orders --dry-run`,
+	RunE: runRebase,
+}
+
+func init() {
+	rootCmd.AddCommand(rebaseCmd)
+	rebaseCmd.Flags().StringVar(&rebaseOnto, "onto", "",
+		"Leaf migration to rebase onto (default: the leaf from MERGE_HEAD or HEAD^2)")
+	rebaseCmd.Flags().BoolVar(&rebaseDryRun, "dry-run", false,
+		"Show the renames without writing")
+	rebaseCmd.Flags().BoolVar(&rebaseVerbose, "verbose", false,
+		"Show detailed output")
+}
+
+// rebaseMove is one migration moved by rebase.
+type rebaseMove struct {
+	// OldName and NewName are the migration names before and after.
+	OldName string
+	NewName string
+	// Dependencies are the rewritten dependencies.
+	Dependencies []string
+}
+
+// runRebase moves the migrations of one branch onto the other branch's leaf.
+func runRebase(_ *cobra.Command, _ []string) error {
+	cfg := config.LoadOrDefault(configFile)
+	migrationsDir := cfg.Migration.Directory
+
+	reg, err := loadMigrations(migrationsDir, cfg.Migration.Loader, rebaseVerbose)
+	if err != nil {
+		return err
+	}
+	g, err := migrate.BuildGraph(reg)
+	if err != nil {
+		return fmt.Errorf("building migration graph: %w", err)
+	}
+	leaves := g.Leaves()
+	sort.Strings(leaves)
+	if len(leaves) < 2 {
+		return fmt.Errorf("nothing to rebase: the migration graph has a single leaf")
+	}
+
+	onto := rebaseOnto
+	if onto == "" {
+		onto, err = detectRebaseOnto(migrationsDir, leaves)
+		if err != nil {
+			return err
+		}
+	}
+	if rebaseVerbose {
+		fmt.Printf("Rebasing onto %s (leaves: %s)\n", onto, strings.Join(leaves, ", "))
+	}
+
+	moves, err := planRebase(reg, onto, cfg.Migration.Naming, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := validateRebase(reg, moves); err != nil {
+		return err
+	}
+
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		dsn = cfg.Database.DefaultURL
+	}
+	if dsn != "" {
+		for _, mv := range moves {
+			applied, err := isAppliedInDB(migrate.EnvOr("DB_TYPE", "postgresql"), dsn, mv.OldName)
+			if err != nil {
+				return fmt.Errorf("checking the dev database: %w", err)
+			}
+			if applied {
+				return fmt.Errorf("%s is already applied to the dev database; roll it back with `migrate down` "+
+					"or use `generate --merge` instead", mv.OldName)
+			}
+		}
+	} else {
+		fmt.Println("NOTE: no DATABASE_URL or database.default_url set; make sure none of the moved migrations have been applied anywhere.")
+	}
+
+	for _, mv := range moves {
+		fmt.Printf("  %s -> %s (depends on %s)\n", mv.OldName, mv.NewName, strings.Join(mv.Dependencies, ", "))
+	}
+	if rebaseDryRun {
+		return nil
+	}
+
+	if err := applyRebase(migrationsDir, moves); err != nil {
+		return err
+	}
+
+	// Re-validate the files as written.
+	reg, err = loadMigrations(migrationsDir, cfg.Migration.Loader, false)
+	if err != nil {
+		return fmt.Errorf("re-validating rebased migrations: %w", err)
+	}
+	if err := validateRebase(reg, nil); err != nil {
+		return err
+	}
+	fmt.Printf("Rebased %d migration(s) onto %s\n", len(moves), onto)
+	return refreshSQLPreviews(migrationsDir, cfg.Migration.Loader, cfg.Migration.SQLPreview)
+}
+
+// planRebase works out how to move every migration in reg that is not an
+// ancestor of onto so that the graph becomes linear again. The moved
+// migrations keep their topological order and suffixes; their prefixes are
+// chosen by naming as if they were created after the others, one second
+// apart starting at now. A dependency on an unmoved migration is replaced
+// by onto.
+func planRebase(reg *migrate.Registry, onto, naming string, now time.Time) ([]rebaseMove, error) {
+	if _, ok := reg.Get(onto); !ok {
+		return nil, fmt.Errorf("--onto: migration %q not found", onto)
+	}
+	g, err := migrate.BuildGraph(reg)
+	if err != nil {
+		return nil, fmt.Errorf("building migration graph: %w", err)
+	}
+	order, err := g.Linearize()
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect onto and its ancestors; everything else moves.
+	keep := map[string]bool{}
+	stack := []string{onto}
+	for len(stack) > 0 {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if keep[name] {
+			continue
+		}
+		keep[name] = true
+		mig, _ := reg.Get(name)
+		stack = append(stack, mig.Dependencies...)
+	}
+
+	renamed := map[string]string{}
+	var moved []*migrate.Migration
+	for _, mig := range order {
+		if !keep[mig.Name] {
+			moved = append(moved, mig)
+		}
+	}
+	if len(moved) == 0 {
+		return nil, fmt.Errorf("nothing to rebase: every migration is an ancestor of %s", onto)
+	}
+
+	base := len(order) - len(moved)
+	for i, mig := range moved {
+		prefix, err := codegen.MigrationPrefix(naming, base+i, now.Add(time.Duration(i)*time.Second))
+		if err != nil {
+			return nil, fmt.Errorf("migration.naming: %w", err)
+		}
+		newName := prefix
+		if suffix := codegen.MigrationSuffix(mig.Name); suffix != "" {
+			newName += "_" + suffix
+		}
+		if _, exists := reg.Get(newName); exists && !containsMigration(moved, newName) {
+			return nil, fmt.Errorf("renaming %s would clash with existing migration %s", mig.Name, newName)
+		}
+		renamed[mig.Name] = newName
+	}
+
+	moves := make([]rebaseMove, 0, len(moved))
+	for _, mig := range moved {
+		var deps []string
+		hasMovedDep := false
+		for _, dep := range mig.Dependencies {
+			if _, ok := renamed[dep]; ok {
+				hasMovedDep = true
+			}
+		}
+		seen := map[string]bool{}
+		for _, dep := range mig.Dependencies {
+			newDep, ok := renamed[dep]
+			if !ok {
+				// Unmoved ancestors are reached through onto, or through
+				// another moved migration that already depends on it.
+				if hasMovedDep {
+					continue
+				}
+				newDep = onto
+			}
+			if !seen[newDep] {
+				seen[newDep] = true
+				deps = append(deps, newDep)
+			}
+		}
+		if len(deps) == 0 {
+			deps = []string{onto}
+		}
+		moves = append(moves, rebaseMove{OldName: mig.Name, NewName: renamed[mig.Name], Dependencies: deps})
+	}
+	return moves, nil
+}
+
+// containsMigration reports whether migs includes a migration named name.
+func containsMigration(migs []*migrate.Migration, name string) bool {
+	for _, mig := range migs {
+		if mig.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRebase checks that reg with moves applied forms a graph with a
+// single leaf whose schema state can be reconstructed.
+func validateRebase(reg *migrate.Registry, moves []rebaseMove) error {
+	byOld := make(map[string]rebaseMove, len(moves))
+	for _, mv := range moves {
+		byOld[mv.OldName] = mv
+	}
+	next := migrate.NewRegistry()
+	for _, mig := range reg.All() {
+		if mv, ok := byOld[mig.Name]; ok {
+			cp := *mig
+			cp.Name = mv.NewName
+			cp.Dependencies = mv.Dependencies
+			mig = &cp
+		}
+		next.Register(mig)
+	}
+	g, err := migrate.BuildGraph(next)
+	if err != nil {
+		return fmt.Errorf("rebased migration graph is invalid: %w", err)
+	}
+	if leaves := g.Leaves(); len(leaves) != 1 {
+		sort.Strings(leaves)
+		return fmt.Errorf("rebased migration graph still has %d leaves: %s", len(leaves), strings.Join(leaves, ", "))
+	}
+	if _, err := g.ReconstructState(); err != nil {
+		return fmt.Errorf("rebased migrations do not replay cleanly: %w", err)
+	}
+	return nil
+}
+
+// applyRebase rewrites and renames the file of every moved migration. All
+// files are read before any is written, since a new name may be the old
+// name of a later move.
+func applyRebase(migrationsDir string, moves []rebaseMove) error {
+	outs := make([][]byte, len(moves))
+	for i, mv := range moves {
+		oldPath := filepath.Join(migrationsDir, codegen.MigrationFileName(mv.OldName))
+		src, err := os.ReadFile(oldPath)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", oldPath, err)
+		}
+		outs[i], err = codegen.RewriteMigrationHeader(src, mv.NewName, mv.Dependencies)
+		if err != nil {
+			return fmt.Errorf("rewriting %s: %w", oldPath, err)
+		}
+	}
+	for _, mv := range moves {
+		oldPath := filepath.Join(migrationsDir, codegen.MigrationFileName(mv.OldName))
+		if err := os.Remove(oldPath); err != nil {
+			return fmt.Errorf("removing %s: %w", oldPath, err)
+		}
+	}
+	for i, mv := range moves {
+		newPath := filepath.Join(migrationsDir, codegen.MigrationFileName(mv.NewName))
+		if err := os.WriteFile(newPath, outs[i], 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", newPath, err)
+		}
+		if rebaseVerbose {
+			fmt.Printf("Wrote %s (was %s)\n", newPath, codegen.MigrationFileName(mv.OldName))
+		}
+	}
+	return nil
+}
+
+// detectRebaseOnto picks the leaf that came in with the merge being made
+// (MERGE_HEAD) or just made (HEAD^2): the one whose file exists there.
+func detectRebaseOnto(migrationsDir string, leaves []string) (string, error) {
+	rev := ""
+	for _, candidate := range []string{"MERGE_HEAD", "HEAD^2"} {
+		if err := exec.Command("git", "rev-parse", "-q", "--verify", candidate).Run(); err == nil {
+			rev = candidate
+			break
+		}
+	}
+	if rev == "" {
+		return "", fmt.Errorf("cannot find the merged branch (no MERGE_HEAD or HEAD^2); pass --onto with one of: %s",
+			strings.Join(leaves, ", "))
+	}
+	var found []string
+	for _, leaf := range leaves {
+		path := filepath.Join(migrationsDir, codegen.MigrationFileName(leaf))
+		if filepath.IsAbs(path) {
+			if cwd, err := os.Getwd(); err == nil {
+				if rel, err := filepath.Rel(cwd, path); err == nil {
+					path = rel
+				}
+			}
+		}
+		// "./" makes git resolve the path from the working directory.
+		spec := rev + ":./" + filepath.ToSlash(path)
+		if err := exec.Command("git", "cat-file", "-e", spec).Run(); err == nil {
+			found = append(found, leaf)
+		}
+	}
+	if len(found) != 1 {
+		return "", fmt.Errorf("cannot tell which leaf came from %s; pass --onto with one of: %s",
+			rev, strings.Join(leaves, ", "))
+	}
+	return found[0], nil
+}
@@ -0,0 +1,158 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ocomsoft/makemigrations/internal/interp"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+// writeRebaseMigrations writes a graph that a merge left with two leaves:
+// 0001_initial, then 0002_orders -> 0003_order_index from the merged branch
+// and 0002_add_email -> 0003_email_index from ours.
+func writeRebaseMigrations(t *testing.T, dir string) {
+	t.Helper()
+	files := map[string]string{
+		"0001_initial": `Dependencies: []string{},
+		Operations: []m.Operation{
+			&m.CreateTable{Name: "users", Fields: []m.Field{{Name: "id", Type: "integer", PrimaryKey: true}}},
+		},`,
+		"0002_orders": `Dependencies: []string{"0001_initial"},
+		Operations: []m.Operation{
+			&m.CreateTable{Name: "orders", Fields: []m.Field{{Name: "id", Type: "integer", PrimaryKey: true}}},
+		},`,
+		"0003_order_index": `Dependencies: []string{"0002_orders"},
+		Operations: []m.Operation{
+			&m.AddIndex{Table: "orders", Index: m.Index{Name: "orders_id_idx", Fields: []string{"id"}}},
+		},`,
+		"0002_add_email": `Dependencies: []string{"0001_initial"},
+		Operations: []m.Operation{
+			&m.AddField{Table: "users", Field: m.Field{Name: "email", Type: "text", Nullable: true}},
+		},`,
+		"0003_email_index": `Dependencies: []string{"0002_add_email", "0001_initial"},
+		Operations: []m.Operation{
+			&m.AddIndex{Table: "users", Index: m.Index{Name: "users_email_idx", Fields: []string{"email"}}},
+		},`,
+	}
+	for name, body := range files {
+		src := `package main
+
+import m "github.com/ocomsoft/makemigrations/migrate"
+
+func init() {
+	m.Register(&m.Migration{
+		Name: "` + name + `",
+		` + body + `
+	})
+}
+`
+		if err := os.WriteFile(filepath.Join(dir, name+".go"), []byte(src), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+}
+
+func TestPlanRebase(t *testing.T) {
+	dir := t.TempDir()
+	writeRebaseMigrations(t, dir)
+	reg, err := interp.LoadRegistry(dir)
+	if err != nil {
+		t.Fatalf("LoadRegistry: %v", err)
+	}
+
+	moves, err := planRebase(reg, "0003_order_index", "", time.Now())
+	if err != nil {
+		t.Fatalf("planRebase: %v", err)
+	}
+	want := []rebaseMove{
+		{OldName: "0002_add_email", NewName: "0004_add_email", Dependencies: []string{"0003_order_index"}},
+		{OldName: "0003_email_index", NewName: "0005_email_index", Dependencies: []string{"0004_add_email"}},
+	}
+	if !reflect.DeepEqual(moves, want) {
+		t.Fatalf("unexpected moves:\n got %+v\nwant %+v", moves, want)
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	moves, err = planRebase(reg, "0003_order_index", "timestamp", now)
+	if err != nil {
+		t.Fatalf("planRebase (timestamp): %v", err)
+	}
+	if moves[0].NewName != "20260301120000_add_email" || moves[1].NewName != "20260301120001_email_index" {
+		t.Errorf("unexpected timestamp names %s, %s", moves[0].NewName, moves[1].NewName)
+	}
+
+	if _, err := planRebase(reg, "0009_missing", "", now); err == nil {
+		t.Error("expected an error for an unknown --onto migration")
+	}
+}
+
+func TestApplyRebase(t *testing.T) {
+	dir := t.TempDir()
+	writeRebaseMigrations(t, dir)
+	reg, err := interp.LoadRegistry(dir)
+	if err != nil {
+		t.Fatalf("LoadRegistry: %v", err)
+	}
+	moves, err := planRebase(reg, "0003_order_index", "", time.Now())
+	if err != nil {
+		t.Fatalf("planRebase: %v", err)
+	}
+	if err := validateRebase(reg, moves); err != nil {
+		t.Fatalf("validateRebase: %v", err)
+	}
+	if err := applyRebase(dir, moves); err != nil {
+		t.Fatalf("applyRebase: %v", err)
+	}
+
+	for _, name := range []string{"0002_add_email.go", "0003_email_index.go"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be renamed, stat err = %v", name, err)
+		}
+	}
+	reg, err = interp.LoadRegistry(dir)
+	if err != nil {
+		t.Fatalf("reloading: %v", err)
+	}
+	g, err := migrate.BuildGraph(reg)
+	if err != nil {
+		t.Fatalf("BuildGraph: %v", err)
+	}
+	if leaves := g.Leaves(); len(leaves) != 1 || leaves[0] != "0005_email_index" {
+		t.Fatalf("expected a single leaf 0005_email_index, got %v", leaves)
+	}
+	mig, _ := reg.Get("0004_add_email")
+	if !reflect.DeepEqual(mig.Dependencies, []string{"0003_order_index"}) {
+		t.Errorf("unexpected dependencies for 0004_add_email: %v", mig.Dependencies)
+	}
+	if err := validateRebase(reg, nil); err != nil {
+		t.Errorf("rebased graph does not validate: %v", err)
+	}
+}
@@ -38,6 +38,7 @@ var (
 	cfgFile    string
 	configFile string // Config file path
 	verbose    bool
+	noCache    bool // Skip the cached migration state (see queryDAG)
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -74,6 +75,7 @@ func init() {
 
 	// Global flag for config file
 	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file path (default: migrations/makemigrations.config.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Reconstruct the schema state from the migration files instead of using the cache")
 }
 
 // initConfig reads in config file and ENV variables if set.
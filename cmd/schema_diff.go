@@ -35,6 +35,7 @@ import (
 	yaml "gopkg.in/yaml.v3"
 
 	"github.com/ocomsoft/makemigrations/internal/config"
+	"github.com/ocomsoft/makemigrations/internal/srcfs"
 	"github.com/ocomsoft/makemigrations/internal/types"
 	"github.com/ocomsoft/makemigrations/internal/workflow"
 	yamlpkg "github.com/ocomsoft/makemigrations/internal/yaml"
@@ -44,8 +45,13 @@ var (
 	diffVerbose bool
 	diffYAML    bool
 	diffJSON    bool
+	diffFrom    string
+	diffTo      string
 )
 
+// diffWorktree is the --to value naming the working tree.
+const diffWorktree = "worktree"
+
 var diffCmd = &cobra.Command{
 	Use:     "schema-diff",
 	Aliases: []string{"diff"},
@@ -57,6 +63,14 @@ and show the differences in both directions:
   In Schema, Not Yet Migrated  — tables/fields/indexes added to schema.yaml
   In Migrations, Not in Schema — tables/fields/indexes removed from schema.yaml
 
+With --from <ref>, the schema files of that git revision take the place of
+the migration state; with --to <ref>, they take the place of the working
+tree. The files, including their includes, are read from git objects
+without checking anything out:
+
+  makemigrations schema-diff --from v1.4.0 --to HEAD
+  makemigrations schema-diff --from main        # main vs. working tree
+
 Default output is a color-coded report with YAML snippets.
 Use --yaml for machine-readable YAML output, or --json for JSON.`,
 	RunE: runDiff,
@@ -67,49 +81,63 @@ func init() {
 	diffCmd.Flags().BoolVar(&diffVerbose, "verbose", false, "Show detailed output")
 	diffCmd.Flags().BoolVar(&diffYAML, "yaml", false, "Output as YAML (add/remove/modify sections)")
 	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "Output as JSON")
+	diffCmd.Flags().StringVar(&diffFrom, "from", "", "Git ref whose YAML schema to compare from (default: the migration state)")
+	diffCmd.Flags().StringVar(&diffTo, "to", diffWorktree, "Git ref whose YAML schema to compare to, or \"worktree\"")
 }
 
 func runDiff(_ *cobra.Command, _ []string) error {
 	cfg := config.LoadOrDefault(configFile)
 	migrationsDir := cfg.Migration.Directory
 
-	// 1. Query existing DAG for previous schema state
-	var prevSchema *yamlpkg.Schema
-
-	goFiles, err := filepath.Glob(filepath.Join(migrationsDir, "*.go"))
+	dbType, err := yamlpkg.ParseDatabaseType(cfg.Database.Type)
 	if err != nil {
-		return fmt.Errorf("scanning migrations directory: %w", err)
+		return fmt.Errorf("invalid database type: %w", err)
 	}
 
-	var migFiles []string
-	for _, f := range goFiles {
-		if filepath.Base(f) != "main.go" {
-			migFiles = append(migFiles, f)
-		}
-	}
+	// 1. Previous schema: the migration DAG state, or the YAML at --from
+	var prevSchema *yamlpkg.Schema
+	labels := migrationStateLabels
 
-	if len(migFiles) > 0 {
-		dagOut, err := queryDAG(migrationsDir, diffVerbose)
+	if diffFrom != "" {
+		prevSchema, err = loadSchemaAtRef(diffFrom, dbType)
 		if err != nil {
-			return fmt.Errorf("querying migration DAG: %w", err)
+			return fmt.Errorf("reading schema at %s: %w", diffFrom, err)
+		}
+		labels = refLabels(diffFrom, diffTo)
+	} else {
+		goFiles, err := filepath.Glob(filepath.Join(migrationsDir, "*.go"))
+		if err != nil {
+			return fmt.Errorf("scanning migrations directory: %w", err)
 		}
-		prevSchema = schemaStateToYAMLSchema(dagOut.SchemaState, cfg.Database.Type)
-	}
 
-	// 2. Parse current YAML schema
-	dbType, err := yamlpkg.ParseDatabaseType(cfg.Database.Type)
-	if err != nil {
-		return fmt.Errorf("invalid database type: %w", err)
-	}
-	components := workflow.InitializeYAMLComponents(dbType, diffVerbose)
-	allSchemas, err := workflow.ScanAndParseSchemas(components, diffVerbose)
-	if err != nil {
-		return fmt.Errorf("parsing YAML schema: %w", err)
+		var migFiles []string
+		for _, f := range goFiles {
+			if filepath.Base(f) != "main.go" {
+				migFiles = append(migFiles, f)
+			}
+		}
+
+		if len(migFiles) > 0 {
+			dagOut, err := queryDAG(migrationsDir, cfg.Migration.Loader, diffVerbose)
+			if err != nil {
+				return fmt.Errorf("querying migration DAG: %w", err)
+			}
+			prevSchema = schemaStateToYAMLSchema(dagOut.SchemaState, cfg.Database.Type)
+		}
+		if diffTo != diffWorktree {
+			labels = refLabels("migration state", diffTo)
+		}
 	}
 
-	currentSchema, err := workflow.MergeAndValidateSchemas(components, allSchemas, dbType, diffVerbose)
+	// 2. Current schema: the YAML in the working tree, or at --to
+	var currentSchema *yamlpkg.Schema
+	if diffTo == diffWorktree {
+		currentSchema, err = loadSchema(srcfs.OS, dbType)
+	} else {
+		currentSchema, err = loadSchemaAtRef(diffTo, dbType)
+	}
 	if err != nil {
-		return fmt.Errorf("merging YAML schemas: %w", err)
+		return err
 	}
 
 	// 3. Diff
@@ -124,18 +152,95 @@ func runDiff(_ *cobra.Command, _ []string) error {
 	case diffJSON:
 		return formatSchemaDiffJSON(os.Stdout, diff)
 	case diffYAML:
-		return formatSchemaDiffYAML(os.Stdout, diff)
+		return formatSchemaDiffYAML(os.Stdout, diff, labels)
 	default:
-		formatSchemaDiffReport(os.Stdout, diff, diffVerbose)
+		formatSchemaDiffReport(os.Stdout, diff, labels, diffVerbose)
 		return nil
 	}
 }
 
+// loadSchemaAtRef parses and merges the YAML schema files as they are at
+// the git revision ref.
+func loadSchemaAtRef(ref string, dbType yamlpkg.DatabaseType) (*yamlpkg.Schema, error) {
+	fsys, err := srcfs.Git(ref)
+	if err != nil {
+		return nil, err
+	}
+	return loadSchema(fsys, dbType)
+}
+
+// loadSchema scans, parses and merges the YAML schema files read from fsys.
+func loadSchema(fsys srcfs.FS, dbType yamlpkg.DatabaseType) (*yamlpkg.Schema, error) {
+	components := workflow.InitializeYAMLComponents(dbType, diffVerbose)
+	components.Scanner.SetFS(fsys)
+	components.Parser.SetFS(fsys)
+	allSchemas, err := workflow.ScanAndParseSchemas(components, diffVerbose)
+	if err != nil {
+		return nil, fmt.Errorf("parsing YAML schema: %w", err)
+	}
+
+	schema, err := workflow.MergeAndValidateSchemas(components, allSchemas, dbType, diffVerbose)
+	if err != nil {
+		return nil, fmt.Errorf("merging YAML schemas: %w", err)
+	}
+	return schema, nil
+}
+
+// diffLabels holds the wording the report and YAML output use for the two
+// schemas being compared.
+type diffLabels struct {
+	noChanges    string // both schemas are the same
+	added        string // heading for tables only in the new schema
+	removed      string // heading for tables only in the old schema
+	addedShort   string // summary label for added tables
+	removedShort string // summary label for removed tables
+	addedNote    string // snippet note for added tables
+	removedNote  string // snippet note for removed tables and fields
+	between      string // what the YAML header says was compared
+	addedYAML    string // meaning of the YAML 'add' section
+	removedYAML  string // meaning of the YAML 'remove' section
+}
+
+// migrationStateLabels describe the default comparison of the working tree
+// schema against the migration state.
+var migrationStateLabels = diffLabels{
+	noChanges:    "schema.yaml matches migration state",
+	added:        "In Schema, Not Yet Migrated",
+	removed:      "In Migrations, Removed from Schema",
+	addedShort:   "Schema-only",
+	removedShort: "Migration-only",
+	addedNote:    "in schema, not yet migrated",
+	removedNote:  "in migrations, removed from schema",
+	between:      "between YAML schema and migration state",
+	addedYAML:    "in schema.yaml but not yet migrated",
+	removedYAML:  "in migration state but removed from schema.yaml",
+}
+
+// refLabels describes a comparison from the schema at from to the one at to
+// (a git ref, "worktree" or "migration state").
+func refLabels(from, to string) diffLabels {
+	if to == diffWorktree {
+		to = "the working tree"
+	}
+	return diffLabels{
+		noChanges:    fmt.Sprintf("%s and %s have the same schema", from, to),
+		added:        "Added in " + to,
+		removed:      "Removed since " + from,
+		addedShort:   "Added",
+		removedShort: "Removed",
+		addedNote:    "added in " + to,
+		removedNote:  "removed since " + from,
+		between:      fmt.Sprintf("from %s to %s", from, to),
+		addedYAML:    fmt.Sprintf("in %s but not in %s", to, from),
+		removedYAML:  fmt.Sprintf("in %s but not in %s", from, to),
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Human-readable report (default)
 // ---------------------------------------------------------------------------
 
-func formatSchemaDiffReport(w io.Writer, diff *yamlpkg.SchemaDiff, verboseFlag bool) {
+func formatSchemaDiffReport(w io.Writer, diff *yamlpkg.SchemaDiff, labels diffLabels, verboseFlag bool) {
 	bold := color.New(color.Bold)
 	red := color.New(color.FgRed)
 	yellow := color.New(color.FgYellow)
@@ -147,7 +252,7 @@ func formatSchemaDiffReport(w io.Writer, diff *yamlpkg.SchemaDiff, verboseFlag b
 	_, _ = fmt.Fprintln(w)
 
 	if !diff.HasChanges {
-		_, _ = green.Fprintln(w, "No differences — schema.yaml matches migration state.")
+		_, _ = green.Fprintf(w, "No differences — %s.\n", labels.noChanges)
 		return
 	}
 
@@ -184,7 +289,7 @@ func formatSchemaDiffReport(w io.Writer, diff *yamlpkg.SchemaDiff, verboseFlag b
 
 	// --- In Schema, Not Yet Migrated ---
 	if len(schemaOnlyTables) > 0 {
-		_, _ = bold.Fprintf(w, "In Schema, Not Yet Migrated (%d table(s)):\n", len(schemaOnlyTables))
+		_, _ = bold.Fprintf(w, "%s (%d table(s)):\n", labels.added, len(schemaOnlyTables))
 		for _, ch := range schemaOnlyTables {
 			_, _ = yellow.Fprintf(w, "  + %s\n", ch.TableName)
 			if verboseFlag {
@@ -196,7 +301,7 @@ func formatSchemaDiffReport(w io.Writer, diff *yamlpkg.SchemaDiff, verboseFlag b
 
 	// --- In Migrations, Removed from Schema ---
 	if len(migOnlyTables) > 0 {
-		_, _ = bold.Fprintf(w, "In Migrations, Removed from Schema (%d table(s)):\n", len(migOnlyTables))
+		_, _ = bold.Fprintf(w, "%s (%d table(s)):\n", labels.removed, len(migOnlyTables))
 		for _, ch := range migOnlyTables {
 			_, _ = red.Fprintf(w, "  ✗ %s\n", ch.TableName)
 			if verboseFlag {
@@ -280,8 +385,8 @@ func formatSchemaDiffReport(w io.Writer, diff *yamlpkg.SchemaDiff, verboseFlag b
 	// --- Summary ---
 	_, _ = bold.Fprintln(w, "Summary:")
 	_, _ = fmt.Fprintf(w, "  Total differences:    %d\n", len(diff.Changes))
-	_, _ = fmt.Fprintf(w, "  Schema-only tables:   %d\n", len(schemaOnlyTables))
-	_, _ = fmt.Fprintf(w, "  Migration-only tables: %d\n", len(migOnlyTables))
+	_, _ = fmt.Fprintf(w, "  %-21s %d\n", labels.addedShort+" tables:", len(schemaOnlyTables))
+	_, _ = fmt.Fprintf(w, "  %-21s %d\n", labels.removedShort+" tables:", len(migOnlyTables))
 	_, _ = fmt.Fprintf(w, "  Field changes:        %d\n", totalFieldChanges+len(modifiedFields))
 	_, _ = fmt.Fprintf(w, "  Index changes:        %d\n", totalIndexChanges)
 	_, _ = fmt.Fprintf(w, "  FK changes:           %d\n", totalFKChanges)
@@ -309,7 +414,7 @@ func formatSchemaDiffReport(w io.Writer, diff *yamlpkg.SchemaDiff, verboseFlag b
 			continue
 		}
 		_, _ = fmt.Fprintln(w)
-		_, _ = cyan.Fprintf(w, "# Table '%s' (in schema, not yet migrated):\n", ch.TableName)
+		_, _ = cyan.Fprintf(w, "# Table '%s' (%s):\n", ch.TableName, labels.addedNote)
 		_, _ = fmt.Fprint(w, snippet)
 		snippetCount++
 	}
@@ -325,7 +430,7 @@ func formatSchemaDiffReport(w io.Writer, diff *yamlpkg.SchemaDiff, verboseFlag b
 			continue
 		}
 		_, _ = fmt.Fprintln(w)
-		_, _ = cyan.Fprintf(w, "# Table '%s' (in migrations, removed from schema):\n", ch.TableName)
+		_, _ = cyan.Fprintf(w, "# Table '%s' (%s):\n", ch.TableName, labels.removedNote)
 		_, _ = fmt.Fprint(w, snippet)
 		snippetCount++
 	}
@@ -366,7 +471,7 @@ func formatSchemaDiffReport(w io.Writer, diff *yamlpkg.SchemaDiff, verboseFlag b
 				continue
 			}
 			_, _ = fmt.Fprintln(w)
-			_, _ = cyan.Fprintf(w, "# Field '%s' on table '%s' (in migrations, removed from schema):\n", ch.FieldName, tableName)
+			_, _ = cyan.Fprintf(w, "# Field '%s' on table '%s' (%s):\n", ch.FieldName, tableName, labels.removedNote)
 			_, _ = fmt.Fprint(w, snippet)
 			snippetCount++
 		}
@@ -382,9 +487,9 @@ func formatSchemaDiffReport(w io.Writer, diff *yamlpkg.SchemaDiff, verboseFlag b
 // YAML output (--yaml)
 // ---------------------------------------------------------------------------
 
-func formatSchemaDiffYAML(w io.Writer, diff *yamlpkg.SchemaDiff) error {
+func formatSchemaDiffYAML(w io.Writer, diff *yamlpkg.SchemaDiff, labels diffLabels) error {
 	if !diff.HasChanges {
-		_, _ = fmt.Fprintln(w, "# No differences — schema.yaml matches migration state.")
+		_, _ = fmt.Fprintf(w, "# No differences — %s.\n", labels.noChanges)
 		return nil
 	}
 
@@ -395,9 +500,9 @@ func formatSchemaDiffYAML(w io.Writer, diff *yamlpkg.SchemaDiff) error {
 		return fmt.Errorf("marshalling diff output: %w", err)
 	}
 
-	_, _ = fmt.Fprintf(w, "# Schema diff: %d change(s) between YAML schema and migration state\n", len(diff.Changes))
-	_, _ = fmt.Fprintln(w, "# 'add' = in schema.yaml but not yet migrated")
-	_, _ = fmt.Fprintln(w, "# 'remove' = in migration state but removed from schema.yaml")
+	_, _ = fmt.Fprintf(w, "# Schema diff: %d change(s) %s\n", len(diff.Changes), labels.between)
+	_, _ = fmt.Fprintf(w, "# 'add' = %s\n", labels.addedYAML)
+	_, _ = fmt.Fprintf(w, "# 'remove' = %s\n", labels.removedYAML)
 	_, _ = fmt.Fprintln(w, "# 'modify' = field properties changed")
 	_, _ = fmt.Fprint(w, string(data))
 
@@ -26,6 +26,7 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/ocomsoft/makemigrations/internal/config"
 	"github.com/ocomsoft/makemigrations/internal/workflow"
 	yamlpkg "github.com/ocomsoft/makemigrations/internal/yaml"
 )
@@ -65,9 +66,10 @@ Database Support:
 
 // runDumpSQL executes the dump_sql command
 func runDumpSQL(cmd *cobra.Command, args []string) error {
+	loader := config.LoadOrDefault(configFile).Migration.Loader
 	dagQuerier := &workflow.DAGQuerier{
 		QueryPreviousSchema: func(migrationsDir string, dbType string, verbose bool) (*yamlpkg.Schema, error) {
-			dagOut, err := queryDAG(migrationsDir, verbose)
+			dagOut, err := queryDAG(migrationsDir, loader, verbose)
 			if err != nil {
 				return nil, err
 			}
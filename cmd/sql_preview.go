@@ -0,0 +1,167 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ocomsoft/makemigrations/internal/types"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+// sqlPreviewHeader starts every SQL preview file; only files starting with
+// it are ever removed.
+const sqlPreviewHeader = "-- Code generated by makemigrations"
+
+// sqlPreviewFileName returns the preview file name of migration name for
+// dbType, e.g. 0002_add_phone.postgresql.sql.
+func sqlPreviewFileName(name string, dbType types.DatabaseType) string {
+	return fmt.Sprintf("%s.%s.sql", name, dbType)
+}
+
+// syncSQLPreviews compares the SQL preview files in migrationsDir with the
+// SQL of the migrations there, rendered for each database type in dbTypes,
+// and returns the paths that are missing, out of date, or left over from a
+// migration that no longer exists. When write is true those files are
+// written or removed as well. loader is the migration.loader setting.
+func syncSQLPreviews(migrationsDir, loader string, dbTypes []string, write bool) ([]string, error) {
+	if len(dbTypes) == 0 {
+		return nil, nil
+	}
+	reg, err := loadMigrations(migrationsDir, loader, false)
+	if err != nil {
+		return nil, err
+	}
+	g, err := migrate.BuildGraph(reg)
+	if err != nil {
+		return nil, fmt.Errorf("building migration graph: %w", err)
+	}
+
+	var changed []string
+	for _, name := range dbTypes {
+		dbType, err := types.ParseDatabaseType(name)
+		if err != nil {
+			return nil, fmt.Errorf("migration.sql_preview: %w", err)
+		}
+		provider, err := migrate.BuildProviderFromType(string(dbType))
+		if err != nil {
+			return nil, err
+		}
+		rendered, err := g.RenderSQL(provider)
+		if err != nil {
+			return nil, fmt.Errorf("rendering %s SQL: %w", dbType, err)
+		}
+
+		want := make(map[string]bool, len(rendered))
+		for _, ms := range rendered {
+			path := filepath.Join(migrationsDir, sqlPreviewFileName(ms.Name, dbType))
+			want[path] = true
+			content := formatSQLPreview(ms, dbType)
+			if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, content) {
+				continue
+			}
+			changed = append(changed, path)
+			if write {
+				if err := os.WriteFile(path, content, 0o644); err != nil {
+					return nil, fmt.Errorf("writing SQL preview: %w", err)
+				}
+			}
+		}
+
+		// Previews of migrations that were removed or renamed.
+		existing, err := filepath.Glob(filepath.Join(migrationsDir, "*."+string(dbType)+".sql"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range existing {
+			if want[path] || !isSQLPreview(path) {
+				continue
+			}
+			changed = append(changed, path)
+			if write {
+				if err := os.Remove(path); err != nil {
+					return nil, fmt.Errorf("removing SQL preview: %w", err)
+				}
+			}
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// formatSQLPreview renders the preview file of ms for dbType.
+func formatSQLPreview(ms migrate.MigrationSQL, dbType types.DatabaseType) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s from %s. DO NOT EDIT.\n", sqlPreviewHeader, ms.Name+".go")
+	fmt.Fprintf(&b, "-- Migration: %s\n-- Database: %s\n", ms.Name, dbType)
+	for _, section := range []struct {
+		title string
+		stmts []string
+	}{{"Up", ms.Up}, {"Down", ms.Down}} {
+		fmt.Fprintf(&b, "\n-- %s\n", section.title)
+		if len(section.stmts) == 0 {
+			b.WriteString("\n-- (no SQL)\n")
+		}
+		for _, stmt := range section.stmts {
+			b.WriteString("\n" + strings.TrimRight(stmt, "\n") + "\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+// isSQLPreview reports whether the file at path was written by
+// syncSQLPreviews.
+func isSQLPreview(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+	head := make([]byte, len(sqlPreviewHeader))
+	n, _ := f.Read(head)
+	return string(head[:n]) == sqlPreviewHeader
+}
+
+// refreshSQLPreviews writes the SQL previews configured by
+// migration.sql_preview and reports the files it touched.
+func refreshSQLPreviews(migrationsDir, loader string, dbTypes []string) error {
+	changed, err := syncSQLPreviews(migrationsDir, loader, dbTypes, true)
+	if err != nil {
+		return err
+	}
+	for _, path := range changed {
+		if _, err := os.Stat(path); err != nil {
+			fmt.Printf("Removed %s\n", path)
+		} else {
+			fmt.Printf("Wrote %s\n", path)
+		}
+	}
+	return nil
+}
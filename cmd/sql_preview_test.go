@@ -0,0 +1,101 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncSQLPreviews(t *testing.T) {
+	dir := t.TempDir()
+	writeAmendMigrations(t, dir, false)
+	dbTypes := []string{"sqlite", "postgresql"}
+
+	stale, err := syncSQLPreviews(dir, "", dbTypes, false)
+	if err != nil {
+		t.Fatalf("syncSQLPreviews: %v", err)
+	}
+	if len(stale) != 4 {
+		t.Fatalf("expected 4 missing previews, got %v", stale)
+	}
+	if _, err := os.Stat(stale[0]); !os.IsNotExist(err) {
+		t.Fatalf("check mode must not write %s", stale[0])
+	}
+
+	if _, err := syncSQLPreviews(dir, "", dbTypes, true); err != nil {
+		t.Fatalf("syncSQLPreviews (write): %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "0002_add_email.sqlite.sql"))
+	if err != nil {
+		t.Fatalf("reading preview: %v", err)
+	}
+	preview := string(data)
+	for _, want := range []string{sqlPreviewHeader, "-- Up", "ADD COLUMN", "-- Down", "email"} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("expected %q in preview:\n%s", want, preview)
+		}
+	}
+	if strings.Index(preview, "-- Up") > strings.Index(preview, "-- Down") {
+		t.Errorf("expected Up before Down:\n%s", preview)
+	}
+	if stale, _ := syncSQLPreviews(dir, "", dbTypes, false); len(stale) != 0 {
+		t.Fatalf("expected fresh previews after writing, got %v", stale)
+	}
+
+	// An edited preview, and one left over from a removed migration, are
+	// stale; an unrelated .sql file is left alone.
+	edited := filepath.Join(dir, "0001_initial.postgresql.sql")
+	orphan := filepath.Join(dir, "0003_gone.postgresql.sql")
+	other := filepath.Join(dir, "seed.postgresql.sql")
+	if err := os.WriteFile(edited, []byte(sqlPreviewHeader+" by hand\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(orphan, []byte(sqlPreviewHeader+" from 0003_gone.go. DO NOT EDIT.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(other, []byte("INSERT INTO users VALUES (1);\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stale, err = syncSQLPreviews(dir, "", dbTypes, true)
+	if err != nil {
+		t.Fatalf("syncSQLPreviews: %v", err)
+	}
+	if len(stale) != 2 || stale[0] != edited || stale[1] != orphan {
+		t.Fatalf("expected the edited and orphaned previews, got %v", stale)
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Error("expected the orphaned preview to be removed")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("expected the unrelated .sql file to be kept: %v", err)
+	}
+
+	if _, err := syncSQLPreviews(dir, "", []string{"oracle"}, false); err == nil {
+		t.Error("expected an unknown database type to be rejected")
+	}
+}
@@ -0,0 +1,353 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/ocomsoft/makemigrations/internal/yaml"
+)
+
+// preservedOperations lists the operation types AmendMigration carries over
+// from the file it rewrites. The diff engine never produces them, so they
+// can only have been written by hand.
+var preservedOperations = map[string]bool{"RunSQL": true, "UpsertData": true}
+
+// operationLiteral is one element of a migration's Operations slice.
+type operationLiteral struct {
+	// src is the literal's source, including the comments written above it.
+	src string
+	// key identifies what the operation acts on (see operationKey).
+	key string
+	// end is the offset just past the literal's trailing comma and newline.
+	end int
+	// preserved is set for hand-written operations AmendMigration carries over.
+	preserved bool
+}
+
+// AmendMigration regenerates the migration file old, which registers the
+// migration name, from diff. The new file keeps:
+//   - the hand-written RunSQL and UpsertData operations of old, except
+//     those identical to a generated one. Each stays after the generated
+//     operations that preceded it in old and before those that followed
+//     it; when the regenerated operations make that impossible an error is
+//     returned rather than reordering them
+//   - any m.Migration fields other than Name, Dependencies and Operations,
+//     such as Draft or StatementTimeout, copied verbatim
+//
+// Backfill operations in old are the generator's own and are regenerated,
+// not kept. diff may be nil or empty. It returns "" when neither generated nor
+// preserved operations remain, meaning the migration should be removed.
+func (g *GoGenerator) AmendMigration(
+	name string,
+	deps []string,
+	diff *yaml.SchemaDiff,
+	currentSchema, previousSchema *yaml.Schema,
+	decisions map[int]yaml.PromptResponse,
+	old []byte,
+) (string, error) {
+	fields, oldOps, err := parseAmendable(old)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	var ops strings.Builder
+	summary := g.decisionSummary(allChanges(diff))
+	if diff != nil {
+		for i, change := range diff.Changes {
+			op, err := g.generateChange(change, currentSchema, previousSchema, decisions[i])
+			if err != nil {
+				return "", err
+			}
+			ops.WriteString(op)
+		}
+	}
+	generated := ops.String()
+	newOps, err := parseOperationList(generated)
+	if err != nil {
+		return "", fmt.Errorf("parsing generated operations: %w", err)
+	}
+
+	// Find each of old's generated operations among the new ones: the same
+	// literal if it is still generated, else one acting on the same thing.
+	matched := make([]int, len(oldOps))
+	used := make([]bool, len(newOps))
+	for i, op := range oldOps {
+		matched[i] = -1
+		if op.preserved {
+			continue
+		}
+		for _, sameKey := range []bool{false, true} {
+			for j, n := range newOps {
+				if used[j] || (sameKey && (op.key == "" || n.key != op.key)) ||
+					(!sameKey && normalizeSpace(n.src) != normalizeSpace(op.src)) {
+					continue
+				}
+				matched[i], used[j] = j, true
+				break
+			}
+			if matched[i] >= 0 {
+				break
+			}
+		}
+	}
+
+	// Insert each preserved operation after the last matched operation that
+	// preceded it, checking none of those that followed it comes earlier.
+	inserts := make(map[int][]string) // new operation index (-1: start) → sources
+	normalized := normalizeSpace(generated)
+	for i, op := range oldOps {
+		if !op.preserved || strings.Contains(normalized, normalizeSpace(op.src)) {
+			continue
+		}
+		after := -1
+		for _, j := range matched[:i] {
+			if j > after {
+				after = j
+			}
+		}
+		for k, j := range matched[i+1:] {
+			if j >= 0 && j <= after {
+				return "", fmt.Errorf("cannot keep %s in place: the regenerated operations put %s before it; "+
+					"edit %s by hand instead", firstLine(op.src), firstLine(oldOps[i+1+k].src), name)
+			}
+		}
+		inserts[after] = append(inserts[after], op.src)
+	}
+
+	var out strings.Builder
+	writeInserts := func(at int) {
+		for _, src := range inserts[at] {
+			out.WriteString("\t\t\t" + src + ",\n")
+		}
+	}
+	writeInserts(-1)
+	prev := 0
+	for j, n := range newOps {
+		out.WriteString(generated[prev:n.end])
+		prev = n.end
+		writeInserts(j)
+	}
+	out.WriteString(generated[prev:])
+	if out.Len() == 0 {
+		return "", nil
+	}
+	return renderMigration(name, deps, fields, summary+out.String())
+}
+
+// parseAmendable returns the source of the extra m.Migration fields and the
+// operations registered by the migration file src.
+func parseAmendable(src []byte) (fields []string, ops []operationLiteral, err error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lit *ast.CompositeLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		if cl, ok := n.(*ast.CompositeLit); ok && lit == nil && selectorName(cl.Type) == "Migration" {
+			lit = cl
+		}
+		return lit == nil
+	})
+	if lit == nil {
+		return nil, nil, fmt.Errorf("no m.Migration literal found")
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, _ := kv.Key.(*ast.Ident)
+		if key == nil {
+			continue
+		}
+		switch key.Name {
+		case "Name", "Dependencies":
+		case "Operations":
+			list, ok := kv.Value.(*ast.CompositeLit)
+			if !ok {
+				return nil, nil, fmt.Errorf("operations are not a slice literal")
+			}
+			ops = operationLiterals(fset, file, src, list)
+		default:
+			fields = append(fields, nodeText(fset, src, kv.Pos(), kv.End()))
+		}
+	}
+	return fields, ops, nil
+}
+
+// parseOperationList returns the operation literals in ops, a sequence of
+// generated "&m.T{...},\n" elements.
+func parseOperationList(ops string) ([]operationLiteral, error) {
+	const prefix = "package p\n\nvar _ = []any{\n"
+	src := []byte(prefix + ops + "}\n")
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var list *ast.CompositeLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		if cl, ok := n.(*ast.CompositeLit); ok && list == nil {
+			list = cl
+		}
+		return list == nil
+	})
+	lits := operationLiterals(fset, file, src, list)
+	for i := range lits {
+		lits[i].end -= len(prefix)
+	}
+	return lits, nil
+}
+
+// operationLiterals returns the elements of the Operations slice literal
+// list in file, whose source is src.
+func operationLiterals(fset *token.FileSet, file *ast.File, src []byte, list *ast.CompositeLit) []operationLiteral {
+	var lits []operationLiteral
+	prevEnd := list.Lbrace
+	for _, op := range list.Elts {
+		// Keep the comments written above the operation.
+		start := op.Pos()
+		for _, cg := range file.Comments {
+			if cg.Pos() > prevEnd && cg.End() <= start {
+				start = cg.Pos()
+				break
+			}
+		}
+		text := nodeText(fset, src, start, op.End())
+		end := fset.Position(op.End()).Offset
+		if nl := strings.IndexByte(string(src[end:]), '\n'); nl >= 0 {
+			end += nl + 1
+		}
+		lits = append(lits, operationLiteral{
+			src:       text,
+			key:       operationKey(op),
+			end:       end,
+			preserved: preservedOperations[operationType(op)],
+		})
+		prevEnd = op.End()
+	}
+	return lits
+}
+
+// operationKey identifies what the operation literal expr acts on: its type
+// followed by the table, field and object names it sets. It is "" for
+// operations that name nothing, such as RunSQL.
+func operationKey(expr ast.Expr) string {
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		expr = u.X
+	}
+	cl, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+	var names []string
+	for _, elt := range cl.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, _ := kv.Key.(*ast.Ident)
+		if key == nil {
+			continue
+		}
+		switch key.Name {
+		case "Table", "Name", "Field", "OldName", "NewName", "OldField", "NewField":
+			if name := literalName(kv.Value); name != "" {
+				names = append(names, key.Name+"="+name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return selectorName(cl.Type) + "{" + strings.Join(names, ",") + "}"
+}
+
+// literalName returns the value of a string literal, or of the Name field of
+// a composite literal such as m.Field{Name: "x"}.
+func literalName(expr ast.Expr) string {
+	switch v := expr.(type) {
+	case *ast.BasicLit:
+		if v.Kind == token.STRING {
+			return v.Value
+		}
+	case *ast.CompositeLit:
+		for _, elt := range v.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if key, _ := kv.Key.(*ast.Ident); key != nil && key.Name == "Name" {
+					return literalName(kv.Value)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// nodeText returns the source of src between from and to.
+func nodeText(fset *token.FileSet, src []byte, from, to token.Pos) string {
+	return string(src[fset.Position(from).Offset:fset.Position(to).Offset])
+}
+
+// firstLine returns the first line of s, ignoring leading comment lines.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "//") {
+			return line
+		}
+	}
+	return s
+}
+
+// operationType returns T for an operation literal &m.T{...}.
+func operationType(expr ast.Expr) string {
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		expr = u.X
+	}
+	if cl, ok := expr.(*ast.CompositeLit); ok {
+		return selectorName(cl.Type)
+	}
+	return ""
+}
+
+// selectorName returns Sel for a qualified identifier pkg.Sel, or "".
+func selectorName(expr ast.Expr) string {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		return sel.Sel.Name
+	}
+	return ""
+}
+
+// normalizeSpace collapses runs of whitespace so that operation literals
+// compare equal regardless of formatting.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
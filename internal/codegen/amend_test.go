@@ -0,0 +1,204 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package codegen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/internal/codegen"
+	"github.com/ocomsoft/makemigrations/internal/yaml"
+)
+
+const amendSource = `package main
+
+import (
+	m "github.com/ocomsoft/makemigrations/migrate"
+)
+
+func init() {
+	m.Register(&m.Migration{
+		Name:         "0002_add_status",
+		Dependencies: []string{"0001_initial"},
+		Draft:        true,
+		Operations: []m.Operation{
+			&m.AddField{
+				Table: "users",
+				Field: m.Field{Name: "status", Type: "varchar", Nullable: true, Length: 20},
+			},
+			&m.Backfill{Table: "users", Field: "status", Expr: "'active'"},
+			// Seed the admin account.
+			&m.UpsertData{
+				Table:        "users",
+				ConflictKeys: []string{"id"},
+				Rows:         []map[string]any{{"id": 1, "status": "admin"}},
+			},
+		},
+	})
+}
+`
+
+func TestGoGenerator_AmendMigration(t *testing.T) {
+	g := codegen.NewGoGenerator()
+	g.Backfills = map[string]string{"users.status": "'active'"}
+	notNull := false
+	diff := &yaml.SchemaDiff{
+		HasChanges: true,
+		Changes: []yaml.Change{
+			{Type: yaml.ChangeTypeFieldAdded, TableName: "users", FieldName: "status",
+				NewValue: yaml.Field{Name: "status", Type: "varchar", Length: 20, Nullable: &notNull}},
+			{Type: yaml.ChangeTypeFieldAdded, TableName: "users", FieldName: "email",
+				NewValue: yaml.Field{Name: "email", Type: "text"}},
+		},
+	}
+	src, err := g.AmendMigration("0002_add_status", []string{"0001_initial"}, diff, nil, nil, nil, []byte(amendSource))
+	if err != nil {
+		t.Fatalf("AmendMigration: %v", err)
+	}
+	// The seed data stays between the backfill and the NOT NULL AlterField.
+	assertInOrder(t, src, []string{
+		`Name:         "0002_add_status",`,
+		`Draft:        true,`,
+		`&m.Backfill{Table: "users", Field: "status", Expr: "'active'"}`,
+		`// Seed the admin account.`,
+		`&m.UpsertData{`,
+		`NewField: m.Field{Name: "status", Type: "varchar", Length: 20},`,
+		`Field: m.Field{Name: "email", Type: "text", Nullable: true},`,
+	})
+	if n := strings.Count(src, "&m.Backfill{"); n != 1 {
+		t.Errorf("expected the regenerated backfill not to be duplicated, found %d:\n%s", n, src)
+	}
+
+	// A new backfill expression replaces the old Backfill rather than joining it.
+	g.Backfills = map[string]string{"users.status": "'new'"}
+	src, err = g.AmendMigration("0002_add_status", []string{"0001_initial"}, diff, nil, nil, nil, []byte(amendSource))
+	if err != nil {
+		t.Fatalf("AmendMigration (new backfill): %v", err)
+	}
+	if strings.Contains(src, "'active'") || !strings.Contains(src, `Expr: "'new'"`) {
+		t.Errorf("expected only the regenerated backfill:\n%s", src)
+	}
+
+	// With the schema change reverted only the hand-written operation stays.
+	src, err = g.AmendMigration("0002_add_status", []string{"0001_initial"}, nil, nil, nil, nil, []byte(amendSource))
+	if err != nil {
+		t.Fatalf("AmendMigration (no changes): %v", err)
+	}
+	if strings.Contains(src, "AddField") || strings.Contains(src, "Backfill") || !strings.Contains(src, "UpsertData") {
+		t.Errorf("expected only the UpsertData operation:\n%s", src)
+	}
+}
+
+func TestGoGenerator_AmendMigration_HandWrittenNullFill(t *testing.T) {
+	// A hand-written UPDATE shaped like a backfill is kept; only Backfill
+	// operations are the generator's own.
+	old := strings.Replace(amendSource, `&m.Backfill{Table: "users", Field: "status", Expr: "'active'"},`,
+		`&m.RunSQL{ForwardSQL: "UPDATE \"Users\" SET status = 'legacy' WHERE status IS NULL"},`, 1)
+	g := codegen.NewGoGenerator()
+	g.Backfills = map[string]string{"users.status": "'active'"}
+	notNull := false
+	diff := &yaml.SchemaDiff{HasChanges: true, Changes: []yaml.Change{
+		{Type: yaml.ChangeTypeFieldAdded, TableName: "users", FieldName: "status",
+			NewValue: yaml.Field{Name: "status", Type: "varchar", Length: 20, Nullable: &notNull}},
+	}}
+	src, err := g.AmendMigration("0002_add_status", []string{"0001_initial"}, diff, nil, nil, nil, []byte(old))
+	if err != nil {
+		t.Fatalf("AmendMigration: %v", err)
+	}
+	assertInOrder(t, src, []string{
+		`Field: m.Field{Name: "status", Type: "varchar", Nullable: true, Length: 20},`,
+		`&m.RunSQL{ForwardSQL: "UPDATE \"Users\" SET status = 'legacy' WHERE status IS NULL"},`,
+		`&m.Backfill{Table: "users", Field: "status", Expr: "'active'"},`,
+	})
+}
+
+func TestGoGenerator_AmendMigration_KeepsPosition(t *testing.T) {
+	old := `package main
+
+import (
+	m "github.com/ocomsoft/makemigrations/migrate"
+)
+
+func init() {
+	m.Register(&m.Migration{
+		Name:         "0002_orders",
+		Dependencies: []string{"0001_initial"},
+		Operations: []m.Operation{
+			&m.AddField{
+				Table: "orders",
+				Field: m.Field{Name: "total", Type: "integer", Nullable: true},
+			},
+			&m.RunSQL{ForwardSQL: "UPDATE orders SET total = (SELECT SUM(amount) FROM lines WHERE order_id = orders.id)"},
+			&m.DropField{Table: "orders", Field: "legacy_total"},
+		},
+	})
+}
+`
+	change := func(typ yaml.ChangeType, field string) yaml.Change {
+		c := yaml.Change{Type: typ, TableName: "orders", FieldName: field}
+		if typ == yaml.ChangeTypeFieldAdded {
+			c.NewValue = yaml.Field{Name: field, Type: "bigint"}
+		}
+		return c
+	}
+	diff := &yaml.SchemaDiff{HasChanges: true, Changes: []yaml.Change{
+		change(yaml.ChangeTypeFieldAdded, "total"),
+		change(yaml.ChangeTypeFieldAdded, "currency"),
+		change(yaml.ChangeTypeFieldRemoved, "legacy_total"),
+	}}
+	src, err := codegen.NewGoGenerator().AmendMigration("0002_orders", nil, diff, nil, nil, nil, []byte(old))
+	if err != nil {
+		t.Fatalf("AmendMigration: %v", err)
+	}
+	// total changed type but is still the operation the SQL followed.
+	assertInOrder(t, src, []string{
+		`Field: m.Field{Name: "total", Type: "bigint", Nullable: true},`,
+		`&m.RunSQL{ForwardSQL: "UPDATE orders SET total = (SELECT SUM(amount)`,
+		`Field: m.Field{Name: "currency", Type: "bigint", Nullable: true},`,
+		`&m.DropField{Table: "orders", Field: "legacy_total"},`,
+	})
+
+	// Regenerated in the opposite order the SQL cannot keep its place.
+	diff.Changes[0], diff.Changes[2] = diff.Changes[2], diff.Changes[0]
+	if _, err := codegen.NewGoGenerator().AmendMigration("0002_orders", nil, diff, nil, nil, nil, []byte(old)); err == nil ||
+		!strings.Contains(err.Error(), "cannot keep &m.RunSQL") {
+		t.Errorf("expected amend to refuse reordering the hand-written SQL, got %v", err)
+	}
+}
+
+func TestGoGenerator_AmendMigration_Empty(t *testing.T) {
+	old := strings.Replace(amendSource, "Draft:        true,", "", 1)
+	old = old[:strings.Index(old, "\t\t\t&m.Backfill")] + "\t\t},\n\t})\n}\n"
+	src, err := codegen.NewGoGenerator().AmendMigration("0002_add_status", nil, nil, nil, nil, nil, []byte(old))
+	if err != nil {
+		t.Fatalf("AmendMigration: %v", err)
+	}
+	if src != "" {
+		t.Errorf("expected nothing left to write, got:\n%s", src)
+	}
+	if _, err := codegen.NewGoGenerator().AmendMigration("x", nil, nil, nil, nil, nil, []byte("package main\n")); err == nil {
+		t.Error("expected an error for a file without a migration")
+	}
+}
@@ -0,0 +1,474 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package codegen
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ocomsoft/makemigrations/internal/providers"
+	"github.com/ocomsoft/makemigrations/internal/yaml"
+)
+
+// fieldRename is a field rename carried out as an expand/contract pair.
+type fieldRename struct {
+	table, from, to string
+	// field is the definition of the renamed column.
+	field yaml.Field
+	// dropIndex is the index of the FieldRemoved change for from, or -1
+	// when the diff reported the rename directly.
+	dropIndex int
+}
+
+// GenerateExpandContract splits diff into two migrations for zero-downtime
+// deploys. The expand migration holds the additive changes old and new
+// application code can both run against; the contract migration, which
+// depends on it, removes what only the old code needed:
+//
+//   - added NOT NULL fields are added nullable (and backfilled) in expand
+//     and made NOT NULL in contract
+//   - a field whose type changes gets a replacement column with the new
+//     type in expand, kept in sync with the old column by triggers and
+//     backfilled through a cast; contract drops the triggers and the old
+//     column and renames the replacement into its place
+//   - nullable → NOT NULL changes, and dropped fields and tables go to
+//     contract
+//   - a renamed field (a FieldRenamed change, or a removed/added pair named
+//     in g.Renames) is added under its new name, kept in sync with the old
+//     column by triggers for dbType and backfilled in expand; contract
+//     drops the triggers and the old column
+//   - a renamed table is renamed in expand behind a view with the old name,
+//     which contract drops
+//   - everything else goes to expand
+//
+// Either result is empty when no change belongs to that phase; the contract
+// migration then depends on deps directly.
+func (g *GoGenerator) GenerateExpandContract(
+	expandName, contractName string,
+	deps []string,
+	diff *yaml.SchemaDiff,
+	currentSchema, previousSchema *yaml.Schema,
+	decisions map[int]yaml.PromptResponse,
+	dbType yaml.DatabaseType,
+) (expand, contract string, err error) {
+	if diff == nil || !diff.HasChanges {
+		return "", "", fmt.Errorf("no changes to generate migration for")
+	}
+	renames, err := g.pairRenames(diff, currentSchema)
+	if err != nil {
+		return "", "", err
+	}
+
+	retyped := make(map[string]bool)
+	for _, change := range diff.Changes {
+		if IsTypeChange(change, currentSchema, previousSchema) {
+			retyped[change.TableName+"."+change.FieldName] = true
+		}
+	}
+
+	var expandOps, contractOps strings.Builder
+	var expandChanges, contractChanges []int
+	done := make(map[*fieldRename]bool)
+	for i, change := range diff.Changes {
+		var e, c string
+		idx := i // the change whose decision applies
+		switch {
+		case change.Type == yaml.ChangeTypeFieldModified && retyped[change.TableName+"."+change.FieldName]:
+			// The replacement column carries the field's whole new
+			// definition, so its other property changes come with it.
+			if !IsTypeChange(change, currentSchema, previousSchema) {
+				continue
+			}
+			e, c, err = g.generateTypeChangeExpandContract(change, currentSchema, previousSchema, dbType)
+		case renames[i] != nil:
+			rn := renames[i]
+			if done[rn] {
+				continue
+			}
+			done[rn] = true
+			var decision yaml.PromptResponse
+			if rn.dropIndex >= 0 {
+				idx = rn.dropIndex
+				decision = decisions[idx]
+			}
+			e, c, err = generateRenameExpandContract(rn, dbType, decision)
+		case change.Type == yaml.ChangeTypeTableRenamed:
+			e, c, err = generateTableRenameExpandContract(change, dbType)
+		case change.Type == yaml.ChangeTypeFieldAdded && NeedsBackfill(change):
+			e, c, err = g.generateAddExpandContract(change)
+		case isContractChange(change):
+			c, err = g.generateChange(change, currentSchema, previousSchema, decisions[i])
+		default:
+			e, err = g.generateChange(change, currentSchema, previousSchema, decisions[i])
+		}
+		if err != nil {
+			return "", "", err
+		}
+		expandOps.WriteString(e)
+		contractOps.WriteString(c)
+		if e != "" {
+			expandChanges = append(expandChanges, idx)
+		}
+		if c != "" {
+			contractChanges = append(contractChanges, idx)
+		}
+	}
+
+	contractDeps := deps
+	if expandOps.Len() > 0 {
+		if expand, err = renderMigration(expandName, deps, []string{"Phase: m.PhaseExpand"},
+			g.decisionSummary(expandChanges)+expandOps.String()); err != nil {
+			return "", "", err
+		}
+		contractDeps = []string{expandName}
+	}
+	if contractOps.Len() > 0 {
+		if contract, err = renderMigration(contractName, contractDeps, []string{"Phase: m.PhaseContract"},
+			g.decisionSummary(contractChanges)+contractOps.String()); err != nil {
+			return "", "", err
+		}
+	}
+	return expand, contract, nil
+}
+
+// isContractChange reports whether change breaks application code that
+// still expects the previous schema, and so belongs in a contract migration.
+func isContractChange(change yaml.Change) bool {
+	switch change.Type {
+	case yaml.ChangeTypeFieldRemoved, yaml.ChangeTypeTableRemoved:
+		return true
+	case yaml.ChangeTypeFieldModified:
+		return NeedsBackfill(change)
+	}
+	return false
+}
+
+// pairRenames indexes the field renames in diff by the positions of the
+// changes they replace: FieldRenamed changes, and the FieldRemoved and
+// FieldAdded pairs named in g.Renames. It is an error for a g.Renames entry
+// not to match such a pair.
+func (g *GoGenerator) pairRenames(diff *yaml.SchemaDiff, currentSchema *yaml.Schema) (map[int]*fieldRename, error) {
+	renames := make(map[int]*fieldRename)
+	for i, change := range diff.Changes {
+		if change.Type != yaml.ChangeTypeFieldRenamed {
+			continue
+		}
+		to, ok := change.NewValue.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string for NewValue in field rename, got %T", change.NewValue)
+		}
+		field := lookupField(currentSchema, change.TableName, to)
+		renames[i] = &fieldRename{table: change.TableName, from: change.FieldName, to: to, field: field, dropIndex: -1}
+	}
+
+	keys := make([]string, 0, len(g.Renames))
+	for key := range g.Renames {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		table, from, _ := strings.Cut(key, ".")
+		to := g.Renames[key]
+		dropIndex, addIndex := -1, -1
+		for i, change := range diff.Changes {
+			if change.TableName != table {
+				continue
+			}
+			switch {
+			case change.Type == yaml.ChangeTypeFieldRemoved && change.FieldName == from:
+				dropIndex = i
+			case change.Type == yaml.ChangeTypeFieldAdded && change.FieldName == to:
+				addIndex = i
+			}
+		}
+		if dropIndex < 0 || addIndex < 0 {
+			return nil, fmt.Errorf("rename %s → %s does not match a removed and an added field of %s", key, to, table)
+		}
+		field, ok := diff.Changes[addIndex].NewValue.(yaml.Field)
+		if !ok {
+			return nil, fmt.Errorf("expected yaml.Field for NewValue, got %T", diff.Changes[addIndex].NewValue)
+		}
+		rn := &fieldRename{table: table, from: from, to: to, field: field, dropIndex: dropIndex}
+		renames[dropIndex] = rn
+		renames[addIndex] = rn
+	}
+	return renames, nil
+}
+
+// generateAddExpandContract splits an added NOT NULL field: expand adds it
+// as nullable and backfills it, contract backfills rows the old code wrote
+// in the meantime and sets NOT NULL.
+func (g *GoGenerator) generateAddExpandContract(change yaml.Change) (expand, contract string, err error) {
+	field, ok := change.NewValue.(yaml.Field)
+	if !ok {
+		return "", "", fmt.Errorf("expected yaml.Field for NewValue, got %T", change.NewValue)
+	}
+	nullable := nullableField(field)
+	if expand, err = g.generateAddField(yaml.Change{TableName: change.TableName, NewValue: nullable}, nil, false); err != nil {
+		return "", "", err
+	}
+	if expr, ok := g.Backfills[change.TableName+"."+change.FieldName]; ok {
+		expand += backfillLiteral(change.TableName, change.FieldName, expr)
+		contract = backfillLiteral(change.TableName, change.FieldName, expr)
+	}
+	contract += setNotNullLiteral(change.TableName, nullable, field)
+	return expand, contract, nil
+}
+
+// generateRenameExpandContract returns the expand and contract operations
+// for a field rename. decision applies to dropping the old column.
+func generateRenameExpandContract(rn *fieldRename, dbType yaml.DatabaseType, decision yaml.PromptResponse) (expand, contract string, err error) {
+	q, err := quoter(dbType)
+	if err != nil {
+		return "", "", err
+	}
+	expand, contract = dualWriteExpandContract(rn, dbType, q, q(rn.from), "", decision)
+	return expand, contract, nil
+}
+
+// generateTypeChangeExpandContract returns the expand and contract
+// operations for a field type change, carried out as a dual-written copy
+// into a replacement column named field__new. Contract drops the old column
+// and renames the replacement into its place, so neither phase rewrites the
+// live column in place.
+//
+// Indexes, constraints and foreign keys would not follow the copy, so a
+// field that has any is refused.
+func (g *GoGenerator) generateTypeChangeExpandContract(
+	change yaml.Change,
+	currentSchema, previousSchema *yaml.Schema,
+	dbType yaml.DatabaseType,
+) (expand, contract string, err error) {
+	table, name := change.TableName, change.FieldName
+	if reason := swapBlocker(previousSchema, table, name); reason != "" {
+		return "", "", fmt.Errorf("--zero-downtime: cannot change the type of %s.%s through a new column: %s; "+
+			"generate this change without --zero-downtime", table, name, reason)
+	}
+	p, err := providers.NewProvider(dbType, nil)
+	if err != nil {
+		return "", "", err
+	}
+	field := lookupField(currentSchema, table, name)
+	temp := name + "__new"
+	tempField := field
+	tempField.Name = temp
+	cast := fmt.Sprintf("CAST(%s AS %s)", p.QuoteName(name), p.ConvertFieldType(&tempField))
+	if cp, ok := p.(providers.CastProvider); ok {
+		cast = cp.GenerateCast(p.QuoteName(name), &tempField)
+	}
+	rn := &fieldRename{table: table, from: name, to: temp, field: field, dropIndex: -1}
+	expand, contract = dualWriteExpandContract(rn, dbType, p.QuoteName, cast, g.Backfills[table+"."+name], yaml.PromptGenerate)
+	contract += fmt.Sprintf("\t\t\t&m.RenameField{Table: %q, OldName: %q, NewName: %q},\n", table, temp, name)
+	return expand, contract, nil
+}
+
+// swapBlocker returns why table.field in schema cannot be replaced by a
+// copy, or "" when it can.
+func swapBlocker(schema *yaml.Schema, table, field string) string {
+	if schema == nil {
+		return ""
+	}
+	t := schema.GetTableByName(table)
+	if t == nil {
+		return ""
+	}
+	if f := t.GetFieldByName(field); f != nil {
+		switch {
+		case f.PrimaryKey:
+			return "it is the primary key"
+		case f.Unique:
+			return "it is unique"
+		case f.ForeignKey != nil:
+			return "it is a foreign key"
+		}
+	}
+	for _, idx := range t.Indexes {
+		if slices.Contains(idx.Fields, field) {
+			return fmt.Sprintf("index %s covers it", idx.Name)
+		}
+	}
+	for _, uc := range t.UniqueConstraints {
+		if slices.Contains(uc.Fields, field) {
+			return fmt.Sprintf("unique constraint %s covers it", uc.Name)
+		}
+	}
+	return ""
+}
+
+// dualWriteExpandContract returns the operations that move rn.table's data
+// from column rn.from to rn.to. Expand adds rn.to as nullable, syncs the two
+// columns with triggers for dbType (identifiers quoted with q) and fills
+// rn.to from the expression value. Contract drops the triggers, fills what
+// is still NULL with fill when it is set, applies NOT NULL, and drops
+// rn.from; decision applies to that drop.
+func dualWriteExpandContract(rn *fieldRename, dbType yaml.DatabaseType, q func(string) string, value, fill string, decision yaml.PromptResponse) (expand, contract string) {
+	nullable := nullableField(rn.field)
+	nullable.Name = rn.to
+
+	var e strings.Builder
+	e.WriteString(fmt.Sprintf("\t\t\t&m.AddField{\n\t\t\t\tTable: %q,\n\t\t\t\tField: %s,\n\t\t\t},\n",
+		rn.table, generateFieldLiteral(nullable)))
+	syncUp, syncDown := dualWriteSQL(dbType, q, rn.table, rn.from, rn.to)
+	if len(syncUp) == 0 {
+		e.WriteString(fmt.Sprintf("\t\t\t// TODO: keep %s.%s and %s.%s in sync until the contract migration runs\n",
+			rn.table, rn.from, rn.table, rn.to))
+	}
+	for i := range syncUp {
+		e.WriteString(fmt.Sprintf("\t\t\t&m.RunSQL{\n\t\t\t\tForwardSQL: %s,\n\t\t\t\tBackwardSQL: %q,\n\t\t\t},\n",
+			bodyLiteral(syncUp[i]), syncDown[i]))
+	}
+	e.WriteString(backfillLiteral(rn.table, rn.to, value))
+
+	var c strings.Builder
+	for i := len(syncUp) - 1; i >= 0; i-- {
+		c.WriteString(fmt.Sprintf("\t\t\t&m.RunSQL{\n\t\t\t\tForwardSQL: %q,\n\t\t\t\tBackwardSQL: %s,\n\t\t\t},\n",
+			syncDown[i], bodyLiteral(syncUp[i])))
+	}
+	if !rn.field.IsNullable() {
+		field := rn.field
+		field.Name = rn.to
+		if fill != "" {
+			c.WriteString(backfillLiteral(rn.table, rn.to, fill))
+		}
+		c.WriteString(setNotNullLiteral(rn.table, nullable, field))
+	}
+	if decision == yaml.PromptReview {
+		c.WriteString("\t\t\t// REVIEW: destructive operation — verify before running\n")
+	}
+	switch decision {
+	case yaml.PromptOmit:
+		c.WriteString(fmt.Sprintf("\t\t\t&m.DropField{Table: %q, Field: %q, SchemaOnly: true},\n", rn.table, rn.from))
+	case yaml.PromptIgnoreErrors:
+		c.WriteString(fmt.Sprintf("\t\t\t&m.DropField{Table: %q, Field: %q, IgnoreErrors: true},\n", rn.table, rn.from))
+	default:
+		c.WriteString(fmt.Sprintf("\t\t\t&m.DropField{Table: %q, Field: %q},\n", rn.table, rn.from))
+	}
+	return e.String(), c.String()
+}
+
+// generateTableRenameExpandContract renames a table in expand behind an
+// updatable view with the old name, and drops the view in contract.
+func generateTableRenameExpandContract(change yaml.Change, dbType yaml.DatabaseType) (expand, contract string, err error) {
+	newName, ok := change.NewValue.(string)
+	if !ok {
+		return "", "", fmt.Errorf("expected string for NewValue in table rename, got %T", change.NewValue)
+	}
+	q, err := quoter(dbType)
+	if err != nil {
+		return "", "", err
+	}
+	createView := fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", q(change.TableName), q(newName))
+	dropView := fmt.Sprintf("DROP VIEW %s", q(change.TableName))
+	expand = fmt.Sprintf("\t\t\t&m.RenameTable{OldName: %q, NewName: %q},\n", change.TableName, newName) +
+		fmt.Sprintf("\t\t\t&m.RunSQL{ForwardSQL: %q, BackwardSQL: %q},\n", createView, dropView)
+	contract = fmt.Sprintf("\t\t\t&m.RunSQL{ForwardSQL: %q, BackwardSQL: %q},\n", dropView, createView)
+	return expand, contract, nil
+}
+
+// quoter returns the identifier quoting of dbType's provider, for the raw
+// SQL the expand/contract operations carry.
+func quoter(dbType yaml.DatabaseType) (func(string) string, error) {
+	p, err := providers.NewProvider(dbType, nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.QuoteName, nil
+}
+
+// dualWriteSQL returns the statements that create triggers copying writes
+// between table.from and table.to, so code using either name sees the same
+// data, and the matching drop statements. Identifiers are quoted with q. It
+// returns nil for databases without row triggers the generator knows how to
+// write.
+func dualWriteSQL(dbType yaml.DatabaseType, q func(string) string, table, from, to string) (create, drop []string) {
+	name := fmt.Sprintf("%s_%s_%s_sync", table, from, to)
+	t, f, n := q(table), q(from), q(to)
+	switch dbType {
+	case yaml.DatabasePostgreSQL:
+		create = []string{
+			fmt.Sprintf(`CREATE FUNCTION %[1]s() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		IF NEW.%[3]s IS NULL THEN NEW.%[3]s := NEW.%[2]s; END IF;
+		IF NEW.%[2]s IS NULL THEN NEW.%[2]s := NEW.%[3]s; END IF;
+	ELSIF NEW.%[3]s IS DISTINCT FROM OLD.%[3]s THEN
+		NEW.%[2]s := NEW.%[3]s;
+	ELSIF NEW.%[2]s IS DISTINCT FROM OLD.%[2]s THEN
+		NEW.%[3]s := NEW.%[2]s;
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql`, q(name), f, n),
+			fmt.Sprintf("CREATE TRIGGER %s BEFORE INSERT OR UPDATE ON %s FOR EACH ROW EXECUTE FUNCTION %s()", q(name), t, q(name)),
+		}
+		drop = []string{
+			fmt.Sprintf("DROP FUNCTION IF EXISTS %s()", q(name)),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", q(name), t),
+		}
+	case yaml.DatabaseMySQL:
+		create = []string{
+			fmt.Sprintf(`CREATE TRIGGER %[1]s BEFORE INSERT ON %[2]s FOR EACH ROW
+BEGIN
+	IF NEW.%[4]s IS NULL THEN SET NEW.%[4]s = NEW.%[3]s; END IF;
+	IF NEW.%[3]s IS NULL THEN SET NEW.%[3]s = NEW.%[4]s; END IF;
+END`, q(name+"_ins"), t, f, n),
+			fmt.Sprintf(`CREATE TRIGGER %[1]s BEFORE UPDATE ON %[2]s FOR EACH ROW
+BEGIN
+	IF NOT (NEW.%[4]s <=> OLD.%[4]s) THEN SET NEW.%[3]s = NEW.%[4]s;
+	ELSEIF NOT (NEW.%[3]s <=> OLD.%[3]s) THEN SET NEW.%[4]s = NEW.%[3]s;
+	END IF;
+END`, q(name+"_upd"), t, f, n),
+		}
+		drop = []string{
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s", q(name+"_ins")),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s", q(name+"_upd")),
+		}
+	case yaml.DatabaseSQLite:
+		// SQLite cannot assign NEW in a BEFORE trigger, so the copies are
+		// made by UPDATEs after the write.
+		create = []string{
+			fmt.Sprintf(`CREATE TRIGGER %[1]s AFTER INSERT ON %[2]s
+BEGIN
+	UPDATE %[2]s SET %[4]s = COALESCE(NEW.%[4]s, NEW.%[3]s), %[3]s = COALESCE(NEW.%[3]s, NEW.%[4]s) WHERE rowid = NEW.rowid;
+END`, q(name+"_ins"), t, f, n),
+			fmt.Sprintf(`CREATE TRIGGER %[1]s AFTER UPDATE OF %[3]s ON %[2]s WHEN NEW.%[3]s IS NOT OLD.%[3]s
+BEGIN
+	UPDATE %[2]s SET %[4]s = NEW.%[3]s WHERE rowid = NEW.rowid;
+END`, q(name+"_from"), t, f, n),
+			fmt.Sprintf(`CREATE TRIGGER %[1]s AFTER UPDATE OF %[4]s ON %[2]s WHEN NEW.%[4]s IS NOT OLD.%[4]s
+BEGIN
+	UPDATE %[2]s SET %[3]s = NEW.%[4]s WHERE rowid = NEW.rowid;
+END`, q(name+"_to"), t, f, n),
+		}
+		drop = []string{
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s", q(name+"_ins")),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s", q(name+"_from")),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s", q(name+"_to")),
+		}
+	}
+	return create, drop
+}
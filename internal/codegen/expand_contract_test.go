@@ -0,0 +1,234 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package codegen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/internal/codegen"
+	"github.com/ocomsoft/makemigrations/internal/yaml"
+)
+
+func TestGoGenerator_ExpandContract(t *testing.T) {
+	notNull := false
+	g := codegen.NewGoGenerator()
+	g.Renames = map[string]string{"users.name": "full_name"}
+	g.Backfills = map[string]string{"users.status": "'active'"}
+	diff := &yaml.SchemaDiff{
+		HasChanges: true,
+		Changes: []yaml.Change{
+			{Type: yaml.ChangeTypeFieldRemoved, TableName: "users", FieldName: "name"},
+			{Type: yaml.ChangeTypeFieldAdded, TableName: "users", FieldName: "full_name",
+				NewValue: yaml.Field{Name: "full_name", Type: "text", Nullable: &notNull}},
+			{Type: yaml.ChangeTypeFieldAdded, TableName: "users", FieldName: "status",
+				NewValue: yaml.Field{Name: "status", Type: "varchar", Length: 20, Nullable: &notNull}},
+			{Type: yaml.ChangeTypeFieldAdded, TableName: "users", FieldName: "note",
+				NewValue: yaml.Field{Name: "note", Type: "text"}},
+			{Type: yaml.ChangeTypeTableRemoved, TableName: "legacy"},
+		},
+	}
+	expand, contract, err := g.GenerateExpandContract("0002_users_expand", "0003_users_contract",
+		[]string{"0001_initial"}, diff, nil, nil, nil, yaml.DatabasePostgreSQL)
+	if err != nil {
+		t.Fatalf("GenerateExpandContract: %v", err)
+	}
+
+	wantExpand := []string{
+		`Dependencies: []string{"0001_initial"},`,
+		`Phase:        m.PhaseExpand,`,
+		`Field: m.Field{Name: "full_name", Type: "text", Nullable: true},`,
+		`CREATE FUNCTION "users_name_full_name_sync"() RETURNS trigger`,
+		`IF NEW."full_name" IS NULL THEN NEW."full_name" := NEW."name"; END IF;`,
+		`CREATE TRIGGER "users_name_full_name_sync" BEFORE INSERT OR UPDATE ON "users"`,
+		`&m.Backfill{Table: "users", Field: "full_name", Expr: "\"name\""}`,
+		`Field: m.Field{Name: "status", Type: "varchar", Nullable: true, Length: 20},`,
+		`&m.Backfill{Table: "users", Field: "status", Expr: "'active'"}`,
+		`Field: m.Field{Name: "note", Type: "text", Nullable: true},`,
+	}
+	assertInOrder(t, expand, wantExpand)
+	if strings.Contains(expand, "DropField") || strings.Contains(expand, "DropTable") {
+		t.Errorf("expand migration must not drop anything:\n%s", expand)
+	}
+
+	wantContract := []string{
+		`Dependencies: []string{"0002_users_expand"},`,
+		`Phase:        m.PhaseContract,`,
+		`"DROP TRIGGER IF EXISTS \"users_name_full_name_sync\" ON \"users\"",`,
+		`"DROP FUNCTION IF EXISTS \"users_name_full_name_sync\"()",`,
+		`NewField: m.Field{Name: "full_name", Type: "text"},`,
+		`&m.DropField{Table: "users", Field: "name"},`,
+		`&m.Backfill{Table: "users", Field: "status", Expr: "'active'"}`,
+		`NewField: m.Field{Name: "status", Type: "varchar", Length: 20},`,
+		`&m.DropTable{Name: "legacy"},`,
+	}
+	assertInOrder(t, contract, wantContract)
+}
+
+func TestGoGenerator_ExpandContract_OnePhase(t *testing.T) {
+	g := codegen.NewGoGenerator()
+	diff := &yaml.SchemaDiff{
+		HasChanges: true,
+		Changes:    []yaml.Change{{Type: yaml.ChangeTypeFieldRemoved, TableName: "users", FieldName: "name"}},
+	}
+	expand, contract, err := g.GenerateExpandContract("0002_x_expand", "0002_x_contract",
+		[]string{"0001_initial"}, diff, nil, nil, nil, yaml.DatabaseSQLite)
+	if err != nil {
+		t.Fatalf("GenerateExpandContract: %v", err)
+	}
+	if expand != "" {
+		t.Errorf("expected no expand migration, got:\n%s", expand)
+	}
+	if !strings.Contains(contract, `Dependencies: []string{"0001_initial"},`) {
+		t.Errorf("expected the contract migration to depend on the leaf directly:\n%s", contract)
+	}
+
+	g.Renames = map[string]string{"users.name": "full_name"}
+	if _, _, err := g.GenerateExpandContract("a", "b", nil, diff, nil, nil, nil, yaml.DatabaseSQLite); err == nil {
+		t.Error("expected an error for a rename without a matching added field")
+	}
+}
+
+func TestGoGenerator_ExpandContract_TypeChange(t *testing.T) {
+	notNull := false
+	prev := &yaml.Schema{Tables: []yaml.Table{{Name: "users", Fields: []yaml.Field{
+		{Name: "id", Type: "integer", PrimaryKey: true},
+		{Name: "age", Type: "varchar", Length: 10},
+	}}}}
+	curr := &yaml.Schema{Tables: []yaml.Table{{Name: "users", Fields: []yaml.Field{
+		{Name: "id", Type: "integer", PrimaryKey: true},
+		{Name: "age", Type: "integer", Nullable: &notNull},
+	}}}}
+	diff, err := yaml.NewDiffEngine(false).CompareSchemas(prev, curr)
+	if err != nil {
+		t.Fatalf("CompareSchemas: %v", err)
+	}
+	g := codegen.NewGoGenerator()
+	g.Backfills = map[string]string{"users.age": "0"}
+	expand, contract, err := g.GenerateExpandContract("0002_users_expand", "0003_users_contract",
+		[]string{"0001_initial"}, diff, curr, prev, nil, yaml.DatabasePostgreSQL)
+	if err != nil {
+		t.Fatalf("GenerateExpandContract: %v", err)
+	}
+	assertInOrder(t, expand, []string{
+		`Field: m.Field{Name: "age__new", Type: "integer", Nullable: true},`,
+		`CREATE FUNCTION "users_age_age__new_sync"() RETURNS trigger`,
+		`CREATE TRIGGER "users_age_age__new_sync" BEFORE INSERT OR UPDATE ON "users"`,
+		`&m.Backfill{Table: "users", Field: "age__new", Expr: "\"age\"::INTEGER"}`,
+	})
+	if strings.Contains(expand, "AlterField") {
+		t.Errorf("expected the live column to be left alone in expand:\n%s", expand)
+	}
+	assertInOrder(t, contract, []string{
+		`"DROP TRIGGER IF EXISTS \"users_age_age__new_sync\" ON \"users\"",`,
+		`&m.Backfill{Table: "users", Field: "age__new", Expr: "0"}`,
+		`NewField: m.Field{Name: "age__new", Type: "integer"},`,
+		`&m.DropField{Table: "users", Field: "age"},`,
+		`&m.RenameField{Table: "users", OldName: "age__new", NewName: "age"},`,
+	})
+	if n := strings.Count(contract, "&m.AlterField{"); n != 1 {
+		t.Errorf("expected only the NOT NULL AlterField on the replacement column, found %d:\n%s", n, contract)
+	}
+
+	// An indexed column would lose its index in the swap.
+	prev.Tables[0].Indexes = []yaml.Index{{Name: "idx_users_age", Fields: []string{"age"}}}
+	curr.Tables[0].Indexes = prev.Tables[0].Indexes
+	if _, _, err := g.GenerateExpandContract("a", "b", nil, diff, curr, prev, nil, yaml.DatabasePostgreSQL); err == nil ||
+		!strings.Contains(err.Error(), "index idx_users_age covers it") {
+		t.Errorf("expected an indexed column to be refused, got %v", err)
+	}
+}
+
+func TestGoGenerator_ExpandContract_QuotesIdentifiers(t *testing.T) {
+	g := codegen.NewGoGenerator()
+	g.Renames = map[string]string{"order.user": "Owner"}
+	diff := &yaml.SchemaDiff{
+		HasChanges: true,
+		Changes: []yaml.Change{
+			{Type: yaml.ChangeTypeFieldRemoved, TableName: "order", FieldName: "user"},
+			{Type: yaml.ChangeTypeFieldAdded, TableName: "order", FieldName: "Owner",
+				NewValue: yaml.Field{Name: "Owner", Type: "text"}},
+			{Type: yaml.ChangeTypeTableRenamed, TableName: "Users", NewValue: "members"},
+		},
+	}
+	expand, contract, err := g.GenerateExpandContract("0002_expand", "0003_contract",
+		nil, diff, nil, nil, nil, yaml.DatabaseMySQL)
+	if err != nil {
+		t.Fatalf("GenerateExpandContract: %v", err)
+	}
+	assertInOrder(t, expand, []string{
+		"CREATE TRIGGER `order_user_Owner_sync_ins` BEFORE INSERT ON `order` FOR EACH ROW",
+		"IF NEW.`Owner` IS NULL THEN SET NEW.`Owner` = NEW.`user`; END IF;",
+		"CREATE TRIGGER `order_user_Owner_sync_upd` BEFORE UPDATE ON `order` FOR EACH ROW",
+		"&m.Backfill{Table: \"order\", Field: \"Owner\", Expr: \"`user`\"}",
+		"&m.RunSQL{ForwardSQL: \"CREATE VIEW `Users` AS SELECT * FROM `members`\", BackwardSQL: \"DROP VIEW `Users`\"}",
+	})
+	assertInOrder(t, contract, []string{
+		"\"DROP TRIGGER IF EXISTS `order_user_Owner_sync_upd`\"",
+		"\"DROP TRIGGER IF EXISTS `order_user_Owner_sync_ins`\"",
+		"&m.RunSQL{ForwardSQL: \"DROP VIEW `Users`\"",
+	})
+}
+
+func TestGoGenerator_ExpandContract_PrimaryKeyRemoved(t *testing.T) {
+	prev := &yaml.Schema{Tables: []yaml.Table{{Name: "users", Fields: []yaml.Field{
+		{Name: "code", Type: "varchar", Length: 20, PrimaryKey: true},
+	}}}}
+	curr := &yaml.Schema{Tables: []yaml.Table{{Name: "users", Fields: []yaml.Field{
+		{Name: "code", Type: "varchar", Length: 20},
+	}}}}
+	diff, err := yaml.NewDiffEngine(false).CompareSchemas(prev, curr)
+	if err != nil {
+		t.Fatalf("CompareSchemas: %v", err)
+	}
+	g := codegen.NewGoGenerator()
+	expand, contract, err := g.GenerateExpandContract("0002_users_expand", "0003_users_contract",
+		[]string{"0001_initial"}, diff, curr, prev, nil, yaml.DatabasePostgreSQL)
+	if err != nil {
+		t.Fatalf("GenerateExpandContract: %v", err)
+	}
+	if contract != "" {
+		t.Errorf("expected a primary key removal to stay out of the contract migration, got:\n%s", contract)
+	}
+	if !strings.Contains(expand, "&m.AlterField{") || strings.Contains(expand, "UPDATE users") {
+		t.Errorf("expected a plain AlterField in the expand migration:\n%s", expand)
+	}
+}
+
+// assertInOrder fails unless every string in want appears in src, in order.
+func assertInOrder(t *testing.T, src string, want []string) {
+	t.Helper()
+	last := -1
+	for _, w := range want {
+		i := strings.Index(src, w)
+		if i < 0 {
+			t.Fatalf("expected %q in output:\n%s", w, src)
+		}
+		if i < last {
+			t.Errorf("expected %q after the previous operation:\n%s", w, src)
+		}
+		last = i
+	}
+}
@@ -38,7 +38,24 @@ import (
 )
 
 // GoGenerator produces Go source code for migration files, main.go, and go.mod.
-type GoGenerator struct{}
+type GoGenerator struct {
+	// Backfills maps "table.field" to a one-off SQL expression used to fill
+	// existing rows when a change needs one (see NeedsBackfill). Such changes
+	// are emitted as add-nullable / Backfill / set NOT NULL sequences.
+	Backfills map[string]string
+	// NewColumns holds the "table.field" keys whose type change is emitted
+	// with Method: m.AlterMethodNewColumn (copy into a new column) instead of
+	// altering the column in place.
+	NewColumns map[string]bool
+	// Renames maps "table.old" to the new name of a field the diff reports
+	// as removed and re-added. GenerateExpandContract turns each pair into
+	// a dual-written copy rather than a drop and an unrelated add.
+	Renames map[string]string
+	// DecisionNotes maps a change index to a one-line note on how its
+	// destructive-change decision was made. The notes of the changes a
+	// migration contains are listed in a comment above its operations.
+	DecisionNotes map[int]string
+}
 
 // NewGoGenerator creates a new GoGenerator instance.
 func NewGoGenerator() *GoGenerator {
@@ -77,6 +94,49 @@ func (g *GoGenerator) GenerateMigration(
 		return "", fmt.Errorf("no changes to generate migration for")
 	}
 
+	var ops strings.Builder
+	ops.WriteString(g.decisionSummary(allChanges(diff)))
+	for i, change := range diff.Changes {
+		op, err := g.generateChange(change, currentSchema, previousSchema, decisions[i])
+		if err != nil {
+			return "", err
+		}
+		ops.WriteString(op)
+	}
+	return renderMigration(name, deps, nil, ops.String())
+}
+
+// decisionSummary returns a comment listing g.DecisionNotes for the changes
+// at indexes, or "" when none of them has a note.
+func (g *GoGenerator) decisionSummary(indexes []int) string {
+	var b strings.Builder
+	for _, i := range indexes {
+		if note, ok := g.DecisionNotes[i]; ok {
+			if b.Len() == 0 {
+				b.WriteString("\t\t\t// Destructive changes:\n")
+			}
+			b.WriteString("\t\t\t//   " + note + "\n")
+		}
+	}
+	return b.String()
+}
+
+// allChanges returns the indexes of every change in diff.
+func allChanges(diff *yaml.SchemaDiff) []int {
+	if diff == nil {
+		return nil
+	}
+	indexes := make([]int, len(diff.Changes))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// renderMigration wraps the operation literals in ops in a complete,
+// gofmt-formatted migration file. fields are extra "Key: value" entries
+// for the m.Migration literal, such as "Phase: m.PhaseExpand".
+func renderMigration(name string, deps []string, fields []string, ops string) (string, error) {
 	var b strings.Builder
 
 	// File header
@@ -100,25 +160,13 @@ func (g *GoGenerator) GenerateMigration(
 		b.WriteString(strings.Join(depStrs, ", "))
 	}
 	b.WriteString("},\n")
+	for _, f := range fields {
+		b.WriteString("\t\t" + f + ",\n")
+	}
 
 	// Operations
 	b.WriteString("\t\tOperations: []m.Operation{\n")
-	for i, change := range diff.Changes {
-		decision := decisions[i] // zero value (0) when map is nil or key absent
-		schemaOnly := decision == yaml.PromptOmit
-		review := decision == yaml.PromptReview
-		ignoreErrors := decision == yaml.PromptIgnoreErrors
-
-		op, err := g.generateOperation(change, currentSchema, previousSchema, schemaOnly, ignoreErrors)
-		if err != nil {
-			return "", fmt.Errorf("generating operation for change %s on %s: %w",
-				change.Type, change.TableName, err)
-		}
-		if review {
-			b.WriteString("\t\t\t// REVIEW: destructive operation — verify before running\n")
-		}
-		b.WriteString(op)
-	}
+	b.WriteString(ops)
 	b.WriteString("\t\t},\n")
 
 	b.WriteString("\t})\n")
@@ -132,6 +180,33 @@ func (g *GoGenerator) GenerateMigration(
 	return string(formatted), nil
 }
 
+// generateChange returns the operation literal for one change, applying its
+// prompt decision and any backfill registered in g.Backfills.
+func (g *GoGenerator) generateChange(
+	change yaml.Change,
+	currentSchema, previousSchema *yaml.Schema,
+	decision yaml.PromptResponse,
+) (string, error) {
+	schemaOnly := decision == yaml.PromptOmit
+	ignoreErrors := decision == yaml.PromptIgnoreErrors
+
+	var op string
+	var err error
+	if expr, ok := g.Backfills[change.TableName+"."+change.FieldName]; ok && NeedsBackfill(change) {
+		op, err = g.generateBackfill(change, expr, currentSchema, previousSchema)
+	} else {
+		op, err = g.generateOperation(change, currentSchema, previousSchema, schemaOnly, ignoreErrors)
+	}
+	if err != nil {
+		return "", fmt.Errorf("generating operation for change %s on %s: %w",
+			change.Type, change.TableName, err)
+	}
+	if decision == yaml.PromptReview {
+		return "\t\t\t// REVIEW: destructive operation — verify before running\n" + op, nil
+	}
+	return op, nil
+}
+
 // generateOperation converts a single yaml.Change into the Go source literal
 // for one migrate.Operation (e.g. &m.CreateTable{...}).
 // schemaOnly is passed to destructive operations (DropTable, DropField) to emit
@@ -161,6 +236,10 @@ func (g *GoGenerator) generateOperation(
 		return g.generateAddIndex(change)
 	case yaml.ChangeTypeIndexRemoved:
 		return g.generateDropIndex(change, ignoreErrors)
+	case yaml.ChangeTypeUniqueConstraintAdded:
+		return g.generateAddUniqueConstraint(change)
+	case yaml.ChangeTypeUniqueConstraintRemoved:
+		return g.generateDropUniqueConstraint(change, ignoreErrors)
 	case yaml.ChangeTypeForeignKeyAdded:
 		return g.generateAddForeignKey(change)
 	case yaml.ChangeTypeForeignKeyRemoved:
@@ -169,6 +248,24 @@ func (g *GoGenerator) generateOperation(
 		return g.generateSetDefaults(change)
 	case yaml.ChangeTypeTypeMappingsModified:
 		return g.generateSetTypeMappings(change)
+	case yaml.ChangeTypeSequenceAdded:
+		return g.generateCreateSequence(change, schemaOnly)
+	case yaml.ChangeTypeSequenceModified:
+		return g.generateAlterSequence(change)
+	case yaml.ChangeTypeSequenceRemoved:
+		return g.generateDropSequence(change, schemaOnly, ignoreErrors)
+	case yaml.ChangeTypeFunctionAdded:
+		return g.generateCreateFunction(change, schemaOnly)
+	case yaml.ChangeTypeFunctionModified:
+		return g.generateReplaceFunction(change)
+	case yaml.ChangeTypeFunctionRemoved:
+		return g.generateDropFunction(change, schemaOnly, ignoreErrors)
+	case yaml.ChangeTypeTriggerAdded:
+		return g.generateCreateTrigger(change, schemaOnly)
+	case yaml.ChangeTypeTriggerModified:
+		return g.generateRecreateTrigger(change)
+	case yaml.ChangeTypeTriggerRemoved:
+		return g.generateDropTrigger(change, schemaOnly, ignoreErrors)
 	default:
 		return "", fmt.Errorf("unsupported change type: %s", change.Type)
 	}
@@ -292,9 +389,9 @@ func (g *GoGenerator) generateAlterField(
 
 	// Try to get full field definitions from the schemas
 	if currentSchema != nil && previousSchema != nil {
-		nf := g.lookupField(currentSchema, change.TableName, change.FieldName)
+		nf := lookupField(currentSchema, change.TableName, change.FieldName)
 		newField = &nf
-		of := g.lookupField(previousSchema, change.TableName, change.FieldName)
+		of := lookupField(previousSchema, change.TableName, change.FieldName)
 		oldField = &of
 	}
 
@@ -326,13 +423,108 @@ func (g *GoGenerator) generateAlterField(
 	b.WriteString(generateFieldLiteral(*oldField))
 	b.WriteString(",\n\t\t\t\tNewField: ")
 	b.WriteString(generateFieldLiteral(*newField))
-	b.WriteString(",\n\t\t\t},\n")
+	b.WriteString(",\n")
+	if g.NewColumns[change.TableName+"."+change.FieldName] && IsTypeChange(change, currentSchema, previousSchema) {
+		b.WriteString("\t\t\t\tMethod: m.AlterMethodNewColumn,\n")
+	}
+	b.WriteString("\t\t\t},\n")
 	return b.String(), nil
 }
 
+// IsTypeChange reports whether change is the type part of a field
+// modification, i.e. the change AlterMethodNewColumn applies to. Both
+// schemas are needed to tell it from other string-valued modifications.
+func IsTypeChange(change yaml.Change, currentSchema, previousSchema *yaml.Schema) bool {
+	if change.Type != yaml.ChangeTypeFieldModified || currentSchema == nil || previousSchema == nil {
+		return false
+	}
+	oldType, oldOK := change.OldValue.(string)
+	newType, newOK := change.NewValue.(string)
+	if !oldOK || !newOK || oldType == newType {
+		return false
+	}
+	return lookupField(previousSchema, change.TableName, change.FieldName).Type == oldType &&
+		lookupField(currentSchema, change.TableName, change.FieldName).Type == newType
+}
+
+// NeedsBackfill reports whether change makes an existing column NOT NULL with
+// nothing to fill rows that are already there: a NOT NULL field without a
+// default added to an existing table, or a field changed from nullable to NOT
+// NULL. Applied as-is, such a change fails on any table with rows.
+func NeedsBackfill(change yaml.Change) bool {
+	switch change.Type {
+	case yaml.ChangeTypeFieldAdded:
+		f, ok := change.NewValue.(yaml.Field)
+		return ok && !f.IsNullable() && f.Default == "" && !f.PrimaryKey && !f.AutoCreate && !f.AutoUpdate
+	case yaml.ChangeTypeFieldModified:
+		oldNullable, oldOK := change.OldValue.(bool)
+		newNullable, newOK := change.NewValue.(bool)
+		return change.Property == yaml.FieldPropertyNullable && oldOK && newOK && oldNullable && !newNullable
+	}
+	return false
+}
+
+// generateBackfill emits the operations for a change that needs existing
+// rows filled with expr before NOT NULL can be enforced. An added field
+// becomes AddField (nullable), Backfill and AlterField (NOT NULL); a field
+// made NOT NULL gets the Backfill before its AlterField. Down reverses
+// the steps, so the column is made nullable again before it is dropped; the
+// backfilled values themselves are left in place.
+func (g *GoGenerator) generateBackfill(
+	change yaml.Change,
+	expr string,
+	currentSchema, previousSchema *yaml.Schema,
+) (string, error) {
+	update := backfillLiteral(change.TableName, change.FieldName, expr)
+	if change.Type == yaml.ChangeTypeFieldModified {
+		alter, err := g.generateAlterField(change, currentSchema, previousSchema)
+		if err != nil {
+			return "", err
+		}
+		return update + alter, nil
+	}
+
+	field, ok := change.NewValue.(yaml.Field)
+	if !ok {
+		return "", fmt.Errorf("expected yaml.Field for NewValue, got %T", change.NewValue)
+	}
+	nullable := nullableField(field)
+	add, err := g.generateAddField(yaml.Change{TableName: change.TableName, NewValue: nullable}, nil, false)
+	if err != nil {
+		return "", err
+	}
+	return add + update + setNotNullLiteral(change.TableName, nullable, field), nil
+}
+
+// backfillLiteral returns a Backfill literal that sets table.field to expr
+// in rows where it is NULL.
+func backfillLiteral(table, field, expr string) string {
+	return fmt.Sprintf("\t\t\t&m.Backfill{Table: %q, Field: %q, Expr: %q},\n", table, field, expr)
+}
+
+// nullableField returns a copy of f that allows NULL.
+func nullableField(f yaml.Field) yaml.Field {
+	isNullable := true
+	f.Nullable = &isNullable
+	return f
+}
+
+// setNotNullLiteral returns an AlterField literal taking a column added as
+// nullable to its final definition.
+func setNotNullLiteral(table string, nullable, field yaml.Field) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\t\t\t&m.AlterField{\n\t\t\t\tTable: %q,\n", table))
+	b.WriteString("\t\t\t\tOldField: ")
+	b.WriteString(generateFieldLiteral(nullable))
+	b.WriteString(",\n\t\t\t\tNewField: ")
+	b.WriteString(generateFieldLiteral(field))
+	b.WriteString(",\n\t\t\t},\n")
+	return b.String()
+}
+
 // lookupField finds a field by table and field name in a schema.
 // Returns a zero-value Field if not found.
-func (g *GoGenerator) lookupField(schema *yaml.Schema, tableName, fieldName string) yaml.Field {
+func lookupField(schema *yaml.Schema, tableName, fieldName string) yaml.Field {
 	if schema == nil {
 		return yaml.Field{Name: fieldName}
 	}
@@ -384,6 +576,33 @@ func (g *GoGenerator) generateDropIndex(change yaml.Change, ignoreErrors bool) (
 		change.TableName, change.FieldName), nil
 }
 
+// generateAddUniqueConstraint emits a &m.AddUniqueConstraint{...} literal.
+func (g *GoGenerator) generateAddUniqueConstraint(change yaml.Change) (string, error) {
+	uc, ok := change.NewValue.(yaml.UniqueConstraint)
+	if !ok {
+		return "", fmt.Errorf("expected yaml.UniqueConstraint for NewValue, got %T", change.NewValue)
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\t\t\t&m.AddUniqueConstraint{\n\t\t\t\tTable: %q,\n", change.TableName))
+	b.WriteString("\t\t\t\tConstraint: ")
+	b.WriteString(generateUniqueConstraintLiteral(uc))
+	b.WriteString(",\n\t\t\t},\n")
+	return b.String(), nil
+}
+
+// generateDropUniqueConstraint emits a &m.DropUniqueConstraint{...} literal.
+func (g *GoGenerator) generateDropUniqueConstraint(change yaml.Change, ignoreErrors bool) (string, error) {
+	if change.FieldName == "" {
+		return "", fmt.Errorf("drop_unique_constraint change for table %q has empty constraint name", change.TableName)
+	}
+	if ignoreErrors {
+		return fmt.Sprintf("\t\t\t&m.DropUniqueConstraint{Table: %q, Name: %q, IgnoreErrors: true},\n",
+			change.TableName, change.FieldName), nil
+	}
+	return fmt.Sprintf("\t\t\t&m.DropUniqueConstraint{Table: %q, Name: %q},\n",
+		change.TableName, change.FieldName), nil
+}
+
 // generateAddForeignKey emits a &m.AddForeignKey{...} literal.
 func (g *GoGenerator) generateAddForeignKey(change yaml.Change) (string, error) {
 	field, ok := change.NewValue.(yaml.Field)
@@ -458,6 +677,236 @@ func (g *GoGenerator) generateSetTypeMappings(change yaml.Change) (string, error
 	return b.String(), nil
 }
 
+// generateCreateSequence emits a &m.CreateSequence{...} literal.
+func (g *GoGenerator) generateCreateSequence(change yaml.Change, schemaOnly bool) (string, error) {
+	seq, ok := change.NewValue.(yaml.Sequence)
+	if !ok {
+		return "", fmt.Errorf("expected yaml.Sequence for NewValue, got %T", change.NewValue)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\t\t\t&m.CreateSequence{\n\t\t\t\tSequence: %s,\n", generateSequenceLiteral(seq))
+	if schemaOnly {
+		b.WriteString("\t\t\t\tSchemaOnly: true,\n")
+	}
+	b.WriteString("\t\t\t},\n")
+	return b.String(), nil
+}
+
+// generateAlterSequence emits a &m.AlterSequence{...} literal with both the
+// old and new definitions so the operation is reversible.
+func (g *GoGenerator) generateAlterSequence(change yaml.Change) (string, error) {
+	oldSeq, ok := change.OldValue.(yaml.Sequence)
+	if !ok {
+		return "", fmt.Errorf("expected yaml.Sequence for OldValue, got %T", change.OldValue)
+	}
+	newSeq, ok := change.NewValue.(yaml.Sequence)
+	if !ok {
+		return "", fmt.Errorf("expected yaml.Sequence for NewValue, got %T", change.NewValue)
+	}
+	return fmt.Sprintf("\t\t\t&m.AlterSequence{\n\t\t\t\tOldSequence: %s,\n\t\t\t\tNewSequence: %s,\n\t\t\t},\n",
+		generateSequenceLiteral(oldSeq), generateSequenceLiteral(newSeq)), nil
+}
+
+// generateDropSequence emits a &m.DropSequence{...} literal.
+func (g *GoGenerator) generateDropSequence(change yaml.Change, schemaOnly, ignoreErrors bool) (string, error) {
+	if schemaOnly {
+		return fmt.Sprintf("\t\t\t&m.DropSequence{Name: %q, SchemaOnly: true},\n", change.TableName), nil
+	}
+	if ignoreErrors {
+		return fmt.Sprintf("\t\t\t&m.DropSequence{Name: %q, IgnoreErrors: true},\n", change.TableName), nil
+	}
+	return fmt.Sprintf("\t\t\t&m.DropSequence{Name: %q},\n", change.TableName), nil
+}
+
+// generateSequenceLiteral converts a yaml.Sequence to a m.Sequence{...} Go literal string.
+// Only non-zero fields are included to keep the output clean; an explicit
+// zero Start or MinValue is written with its Has flag.
+func generateSequenceLiteral(seq yaml.Sequence) string {
+	parts := []string{fmt.Sprintf("Name: %q", seq.Name)}
+	if seq.Start != 0 {
+		parts = append(parts, fmt.Sprintf("Start: %d", seq.Start))
+	} else if seq.HasStart {
+		parts = append(parts, "Start: 0, HasStart: true")
+	}
+	if seq.Increment != 0 {
+		parts = append(parts, fmt.Sprintf("Increment: %d", seq.Increment))
+	}
+	if seq.MinValue != 0 {
+		parts = append(parts, fmt.Sprintf("MinValue: %d", seq.MinValue))
+	} else if seq.HasMinValue {
+		parts = append(parts, "MinValue: 0, HasMinValue: true")
+	}
+	if seq.MaxValue != 0 {
+		parts = append(parts, fmt.Sprintf("MaxValue: %d", seq.MaxValue))
+	}
+	if seq.Cycle {
+		parts = append(parts, "Cycle: true")
+	}
+	if seq.OwnedBy != "" {
+		parts = append(parts, fmt.Sprintf("OwnedBy: %q", seq.OwnedBy))
+	}
+	return fmt.Sprintf("m.Sequence{%s}", strings.Join(parts, ", "))
+}
+
+// generateCreateFunction emits a &m.CreateFunction{...} literal.
+func (g *GoGenerator) generateCreateFunction(change yaml.Change, schemaOnly bool) (string, error) {
+	fn, ok := change.NewValue.(yaml.Function)
+	if !ok {
+		return "", fmt.Errorf("expected yaml.Function for NewValue, got %T", change.NewValue)
+	}
+	lit, err := generateFunctionLiteral(fn)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\t\t\t&m.CreateFunction{\n\t\t\t\tFunction: %s,\n", lit)
+	if schemaOnly {
+		b.WriteString("\t\t\t\tSchemaOnly: true,\n")
+	}
+	b.WriteString("\t\t\t},\n")
+	return b.String(), nil
+}
+
+// generateReplaceFunction emits a &m.ReplaceFunction{...} literal. The previous
+// definition is not embedded — the operation reads it from SchemaState.
+func (g *GoGenerator) generateReplaceFunction(change yaml.Change) (string, error) {
+	fn, ok := change.NewValue.(yaml.Function)
+	if !ok {
+		return "", fmt.Errorf("expected yaml.Function for NewValue, got %T", change.NewValue)
+	}
+	lit, err := generateFunctionLiteral(fn)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("\t\t\t&m.ReplaceFunction{\n\t\t\t\tFunction: %s,\n\t\t\t},\n", lit), nil
+}
+
+// generateDropFunction emits a &m.DropFunction{...} literal.
+func (g *GoGenerator) generateDropFunction(change yaml.Change, schemaOnly, ignoreErrors bool) (string, error) {
+	if schemaOnly {
+		return fmt.Sprintf("\t\t\t&m.DropFunction{Name: %q, SchemaOnly: true},\n", change.TableName), nil
+	}
+	if ignoreErrors {
+		return fmt.Sprintf("\t\t\t&m.DropFunction{Name: %q, IgnoreErrors: true},\n", change.TableName), nil
+	}
+	return fmt.Sprintf("\t\t\t&m.DropFunction{Name: %q},\n", change.TableName), nil
+}
+
+// generateCreateTrigger emits a &m.CreateTrigger{...} literal.
+func (g *GoGenerator) generateCreateTrigger(change yaml.Change, schemaOnly bool) (string, error) {
+	tr, ok := change.NewValue.(yaml.Trigger)
+	if !ok {
+		return "", fmt.Errorf("expected yaml.Trigger for NewValue, got %T", change.NewValue)
+	}
+	lit, err := generateTriggerLiteral(tr)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\t\t\t&m.CreateTrigger{\n\t\t\t\tTrigger: %s,\n", lit)
+	if schemaOnly {
+		b.WriteString("\t\t\t\tSchemaOnly: true,\n")
+	}
+	b.WriteString("\t\t\t},\n")
+	return b.String(), nil
+}
+
+// generateRecreateTrigger emits a DropTrigger followed by a CreateTrigger.
+// Triggers cannot be altered in place on any supported database, and
+// DropTrigger.Down restores the previous definition from SchemaState.
+func (g *GoGenerator) generateRecreateTrigger(change yaml.Change) (string, error) {
+	create, err := g.generateCreateTrigger(change, false)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("\t\t\t&m.DropTrigger{Name: %q},\n", change.TableName) + create, nil
+}
+
+// generateDropTrigger emits a &m.DropTrigger{...} literal.
+func (g *GoGenerator) generateDropTrigger(change yaml.Change, schemaOnly, ignoreErrors bool) (string, error) {
+	if schemaOnly {
+		return fmt.Sprintf("\t\t\t&m.DropTrigger{Name: %q, SchemaOnly: true},\n", change.TableName), nil
+	}
+	if ignoreErrors {
+		return fmt.Sprintf("\t\t\t&m.DropTrigger{Name: %q, IgnoreErrors: true},\n", change.TableName), nil
+	}
+	return fmt.Sprintf("\t\t\t&m.DropTrigger{Name: %q},\n", change.TableName), nil
+}
+
+// generateFunctionLiteral converts a yaml.Function to a m.Function{...} Go
+// literal string. Only non-zero fields are included.
+func generateFunctionLiteral(fn yaml.Function) (string, error) {
+	body, err := activeBody(fn.Name, fn.Body)
+	if err != nil {
+		return "", err
+	}
+	parts := []string{fmt.Sprintf("Name: %q", fn.Name)}
+	if fn.Arguments != "" {
+		parts = append(parts, fmt.Sprintf("Arguments: %q", fn.Arguments))
+	}
+	if fn.Returns != "" {
+		parts = append(parts, fmt.Sprintf("Returns: %q", fn.Returns))
+	}
+	if fn.Language != "" {
+		parts = append(parts, fmt.Sprintf("Language: %q", fn.Language))
+	}
+	if fn.Procedure {
+		parts = append(parts, "Procedure: true")
+	}
+	parts = append(parts, "Body: "+bodyLiteral(body))
+	return fmt.Sprintf("m.Function{%s}", strings.Join(parts, ", ")), nil
+}
+
+// generateTriggerLiteral converts a yaml.Trigger to a m.Trigger{...} Go
+// literal string. Only non-zero fields are included.
+func generateTriggerLiteral(tr yaml.Trigger) (string, error) {
+	body, err := activeBody(tr.Name, tr.Body)
+	if err != nil {
+		return "", err
+	}
+	parts := []string{
+		fmt.Sprintf("Name: %q", tr.Name),
+		fmt.Sprintf("Table: %q", tr.Table),
+		fmt.Sprintf("Timing: %q", tr.Timing),
+		fmt.Sprintf("Events: %#v", tr.Events),
+	}
+	if tr.ForEach != "" {
+		parts = append(parts, fmt.Sprintf("ForEach: %q", tr.ForEach))
+	}
+	if tr.When != "" {
+		parts = append(parts, fmt.Sprintf("When: %q", tr.When))
+	}
+	if tr.Function != "" {
+		parts = append(parts, fmt.Sprintf("Function: %q", tr.Function))
+	}
+	if body != "" {
+		parts = append(parts, "Body: "+bodyLiteral(body))
+	}
+	return fmt.Sprintf("m.Trigger{%s}", strings.Join(parts, ", ")), nil
+}
+
+// activeBody returns the single body left after Schema.SelectDatabase has
+// narrowed a function or trigger to the active database.
+func activeBody(name string, bodies map[yaml.DatabaseType]string) (string, error) {
+	if len(bodies) > 1 {
+		return "", fmt.Errorf("%s: expected a body for one database, got %d", name, len(bodies))
+	}
+	for _, body := range bodies {
+		return body, nil
+	}
+	return "", nil
+}
+
+// bodyLiteral renders a routine body as a raw string literal so multi-line SQL
+// stays readable in the generated file, falling back to a quoted string when
+// the body cannot be represented verbatim in backticks.
+func bodyLiteral(body string) string {
+	if strings.ContainsAny(body, "`\r") {
+		return fmt.Sprintf("%q", body)
+	}
+	return "`" + body + "`"
+}
+
 // sortedMapKeys returns the keys of any map with string keys in sorted order.
 func sortedMapKeys[V any](m map[string]V) []string {
 	keys := make([]string, 0, len(m))
@@ -518,6 +967,12 @@ func generateFieldLiteral(f yaml.Field) string {
 	if f.ManyToMany != nil {
 		parts = append(parts, fmt.Sprintf("ManyToMany: &m.ManyToMany{Table: %q}", f.ManyToMany.Table))
 	}
+	if f.Collation != "" {
+		parts = append(parts, fmt.Sprintf("Collation: %q", f.Collation))
+	}
+	if f.Charset != "" {
+		parts = append(parts, fmt.Sprintf("Charset: %q", f.Charset))
+	}
 
 	return fmt.Sprintf("m.Field{%s}", strings.Join(parts, ", "))
 }
@@ -550,6 +1005,23 @@ func generateIndexLiteral(idx yaml.Index) string {
 	return fmt.Sprintf("m.Index{%s}", strings.Join(parts, ", "))
 }
 
+// generateUniqueConstraintLiteral converts a yaml.UniqueConstraint to a
+// m.UniqueConstraint{...} Go literal string.
+func generateUniqueConstraintLiteral(uc yaml.UniqueConstraint) string {
+	fieldStrs := make([]string, len(uc.Fields))
+	for i, f := range uc.Fields {
+		fieldStrs[i] = fmt.Sprintf("%q", f)
+	}
+	parts := []string{
+		fmt.Sprintf("Name: %q", uc.Name),
+		fmt.Sprintf("Fields: []string{%s}", strings.Join(fieldStrs, ", ")),
+	}
+	if uc.NullsNotDistinct {
+		parts = append(parts, "NullsNotDistinct: true")
+	}
+	return fmt.Sprintf("m.UniqueConstraint{%s}", strings.Join(parts, ", "))
+}
+
 // GenerateMainGo returns the source for a migrations/main.go file. The file
 // is **optional at runtime** — `makemigrations migrate` interprets the
 // migration .go files in-process via yaegi and never invokes main(). It is
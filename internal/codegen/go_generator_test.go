@@ -726,6 +726,36 @@ func TestGoGenerator_DropField_PromptIgnoreErrors(t *testing.T) {
 	}
 }
 
+func TestGoGenerator_DecisionNotes(t *testing.T) {
+	g := codegen.NewGoGenerator()
+	g.DecisionNotes = map[int]string{1: `field_removed users.phone: review (policy "field_removed")`}
+	diff := &yaml.SchemaDiff{
+		HasChanges: true,
+		Changes: []yaml.Change{
+			{Type: yaml.ChangeTypeTableAdded, TableName: "posts", NewValue: yaml.Table{Name: "posts"}},
+			{Type: yaml.ChangeTypeFieldRemoved, TableName: "users", FieldName: "phone"},
+		},
+	}
+	decisions := map[int]yaml.PromptResponse{1: yaml.PromptReview}
+	src, err := g.GenerateMigration("0052_notes", []string{}, diff, nil, nil, decisions)
+	if err != nil {
+		t.Fatalf("GenerateMigration: %v", err)
+	}
+	want := "// Destructive changes:\n\t\t\t//   field_removed users.phone: review (policy \"field_removed\")\n\t\t\t&m.CreateTable"
+	if !strings.Contains(src, want) {
+		t.Errorf("expected the decision summary above the operations, got:\n%s", src)
+	}
+
+	g.DecisionNotes = nil
+	src, err = g.GenerateMigration("0052_notes", []string{}, diff, nil, nil, decisions)
+	if err != nil {
+		t.Fatalf("GenerateMigration: %v", err)
+	}
+	if strings.Contains(src, "Destructive changes") {
+		t.Errorf("expected no summary without notes, got:\n%s", src)
+	}
+}
+
 // TestGoGenerator_SetTypeMappings verifies that a ChangeTypeTypeMappingsModified change
 // generates a valid &m.SetTypeMappings{...} literal with sorted keys.
 func TestGoGenerator_SetTypeMappings(t *testing.T) {
@@ -921,3 +951,219 @@ func TestGoGenerator_NewTableWithForeignKey_CorrectOrder(t *testing.T) {
 		t.Errorf("expected CreateTable before AddForeignKey; ctPos=%d fkPos=%d\n%s", ctPos, fkPos, code)
 	}
 }
+
+func TestGoGenerator_Sequences(t *testing.T) {
+	g := codegen.NewGoGenerator()
+	created := yaml.Sequence{Name: "invoice_seq", Start: 1000}
+	owned := yaml.Sequence{Name: "invoice_seq", Start: 1000, OwnedBy: "invoices.number"}
+	diff := &yaml.SchemaDiff{
+		HasChanges: true,
+		Changes: []yaml.Change{
+			{Type: yaml.ChangeTypeSequenceAdded, TableName: "invoice_seq", NewValue: created},
+			{Type: yaml.ChangeTypeSequenceModified, TableName: "invoice_seq", OldValue: created, NewValue: owned},
+			{Type: yaml.ChangeTypeSequenceRemoved, TableName: "order_seq", OldValue: yaml.Sequence{Name: "order_seq"}},
+			{Type: yaml.ChangeTypeSequenceAdded, TableName: "slot_seq", NewValue: yaml.Sequence{Name: "slot_seq", HasStart: true, HasMinValue: true}},
+		},
+	}
+	decisions := map[int]yaml.PromptResponse{2: yaml.PromptOmit}
+	src, err := g.GenerateMigration("0002_sequences", []string{"0001_initial"}, diff, nil, nil, decisions)
+	if err != nil {
+		t.Fatalf("GenerateMigration: %v", err)
+	}
+	for _, want := range []string{
+		`Sequence: m.Sequence{Name: "invoice_seq", Start: 1000}`,
+		`NewSequence: m.Sequence{Name: "invoice_seq", Start: 1000, OwnedBy: "invoices.number"}`,
+		`&m.DropSequence{Name: "order_seq", SchemaOnly: true}`,
+		`Sequence: m.Sequence{Name: "slot_seq", Start: 0, HasStart: true, MinValue: 0, HasMinValue: true}`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("expected %q in output:\n%s", want, src)
+		}
+	}
+}
+
+func TestGoGenerator_FunctionsAndTriggers(t *testing.T) {
+	g := codegen.NewGoGenerator()
+	fn := yaml.Function{
+		Name:    "touch",
+		Returns: "trigger",
+		Body:    map[yaml.DatabaseType]string{yaml.DatabasePostgreSQL: "BEGIN\n  NEW.updated_at = now();\n  RETURN NEW;\nEND;\n"},
+	}
+	oldTr := yaml.Trigger{Name: "orders_touch", Table: "orders", Timing: "BEFORE", Events: []string{"UPDATE"}, Function: "touch"}
+	newTr := oldTr
+	newTr.Events = []string{"INSERT", "UPDATE"}
+	diff := &yaml.SchemaDiff{
+		HasChanges: true,
+		Changes: []yaml.Change{
+			{Type: yaml.ChangeTypeFunctionModified, TableName: "touch", NewValue: fn},
+			{Type: yaml.ChangeTypeTriggerModified, TableName: "orders_touch", OldValue: oldTr, NewValue: newTr},
+			{Type: yaml.ChangeTypeFunctionRemoved, TableName: "legacy"},
+		},
+	}
+	decisions := map[int]yaml.PromptResponse{2: yaml.PromptOmit}
+	src, err := g.GenerateMigration("0003_routines", []string{"0002_sequences"}, diff, nil, nil, decisions)
+	if err != nil {
+		t.Fatalf("GenerateMigration: %v", err)
+	}
+	for _, want := range []string{
+		"&m.ReplaceFunction{",
+		"Function: m.Function{Name: \"touch\", Returns: \"trigger\", Body: `BEGIN\n  NEW.updated_at = now();\n  RETURN NEW;\nEND;\n`}",
+		`&m.DropTrigger{Name: "orders_touch"}`,
+		`Trigger: m.Trigger{Name: "orders_touch", Table: "orders", Timing: "BEFORE", Events: []string{"INSERT", "UPDATE"}, Function: "touch"}`,
+		`&m.DropFunction{Name: "legacy", SchemaOnly: true}`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("expected %q in output:\n%s", want, src)
+		}
+	}
+}
+
+func TestGoGenerator_AlterField_Collation(t *testing.T) {
+	g := codegen.NewGoGenerator()
+	prevSchema := &yaml.Schema{Tables: []yaml.Table{{Name: "users", Fields: []yaml.Field{
+		{Name: "email", Type: "varchar", Length: 255},
+	}}}}
+	currSchema := &yaml.Schema{Tables: []yaml.Table{{Name: "users", Fields: []yaml.Field{
+		{Name: "email", Type: "varchar", Length: 255, Charset: "utf8mb4", Collation: "utf8mb4_bin"},
+	}}}}
+	diff := &yaml.SchemaDiff{
+		HasChanges: true,
+		Changes: []yaml.Change{
+			{Type: yaml.ChangeTypeFieldModified, TableName: "users", FieldName: "email", OldValue: "", NewValue: "utf8mb4/utf8mb4_bin"},
+		},
+	}
+	src, err := g.GenerateMigration("0007_email_collation", []string{"0006_widen_email"}, diff, currSchema, prevSchema, nil)
+	if err != nil {
+		t.Fatalf("GenerateMigration: %v", err)
+	}
+	want := `NewField: m.Field{Name: "email", Type: "varchar", Nullable: true, Length: 255, Collation: "utf8mb4_bin", Charset: "utf8mb4"}`
+	if !strings.Contains(src, want) {
+		t.Errorf("expected %q in output:\n%s", want, src)
+	}
+}
+
+func TestGoGenerator_UniqueConstraints(t *testing.T) {
+	g := codegen.NewGoGenerator()
+	diff := &yaml.SchemaDiff{
+		HasChanges: true,
+		Changes: []yaml.Change{
+			{Type: yaml.ChangeTypeUniqueConstraintRemoved, TableName: "users", FieldName: "uq_users_email"},
+			{
+				Type:      yaml.ChangeTypeUniqueConstraintAdded,
+				TableName: "users",
+				FieldName: "uq_users_tenant_email",
+				NewValue:  yaml.UniqueConstraint{Name: "uq_users_tenant_email", Fields: []string{"tenant_id", "email"}, NullsNotDistinct: true},
+			},
+		},
+	}
+	src, err := g.GenerateMigration("0008_tenant_email", []string{"0007_email_collation"}, diff, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("GenerateMigration: %v", err)
+	}
+	for _, want := range []string{
+		`&m.DropUniqueConstraint{Table: "users", Name: "uq_users_email"}`,
+		`Constraint: m.UniqueConstraint{Name: "uq_users_tenant_email", Fields: []string{"tenant_id", "email"}, NullsNotDistinct: true}`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("expected %q in output:\n%s", want, src)
+		}
+	}
+}
+
+func TestGoGenerator_Backfill(t *testing.T) {
+	notNull := false
+	g := codegen.NewGoGenerator()
+	g.Backfills = map[string]string{"users.status": "'active'", "users.age": "0"}
+	diff := &yaml.SchemaDiff{
+		HasChanges: true,
+		Changes: []yaml.Change{
+			{
+				Type:      yaml.ChangeTypeFieldAdded,
+				TableName: "users",
+				FieldName: "status",
+				NewValue:  yaml.Field{Name: "status", Type: "varchar", Length: 20, Nullable: &notNull},
+			},
+			{Type: yaml.ChangeTypeFieldModified, TableName: "users", FieldName: "age", OldValue: true, NewValue: false, Property: yaml.FieldPropertyNullable},
+		},
+	}
+	prev := &yaml.Schema{Tables: []yaml.Table{{Name: "users", Fields: []yaml.Field{{Name: "age", Type: "integer"}}}}}
+	curr := &yaml.Schema{Tables: []yaml.Table{{Name: "users", Fields: []yaml.Field{
+		{Name: "age", Type: "integer", Nullable: &notNull},
+		{Name: "status", Type: "varchar", Length: 20, Nullable: &notNull},
+	}}}}
+	src, err := g.GenerateMigration("0009_backfill", []string{"0008_tenant_email"}, diff, curr, prev, nil)
+	if err != nil {
+		t.Fatalf("GenerateMigration: %v", err)
+	}
+	want := []string{
+		`Field: m.Field{Name: "status", Type: "varchar", Nullable: true, Length: 20}`,
+		`&m.Backfill{Table: "users", Field: "status", Expr: "'active'"}`,
+		`OldField: m.Field{Name: "status", Type: "varchar", Nullable: true, Length: 20},`,
+		`NewField: m.Field{Name: "status", Type: "varchar", Length: 20},`,
+		`&m.Backfill{Table: "users", Field: "age", Expr: "0"}`,
+		`NewField: m.Field{Name: "age", Type: "integer"},`,
+	}
+	last := -1
+	for _, w := range want {
+		i := strings.Index(src, w)
+		if i < 0 {
+			t.Fatalf("expected %q in output:\n%s", w, src)
+		}
+		if i < last {
+			t.Errorf("expected %q after the previous operation:\n%s", w, src)
+		}
+		last = i
+	}
+}
+
+func TestNeedsBackfill(t *testing.T) {
+	notNull := false
+	tests := []struct {
+		name   string
+		change yaml.Change
+		want   bool
+	}{
+		{"not null without default", yaml.Change{Type: yaml.ChangeTypeFieldAdded, NewValue: yaml.Field{Name: "a", Type: "integer", Nullable: &notNull}}, true},
+		{"not null with default", yaml.Change{Type: yaml.ChangeTypeFieldAdded, NewValue: yaml.Field{Name: "a", Type: "integer", Nullable: &notNull, Default: "0"}}, false},
+		{"nullable", yaml.Change{Type: yaml.ChangeTypeFieldAdded, NewValue: yaml.Field{Name: "a", Type: "integer"}}, false},
+		{"auto create", yaml.Change{Type: yaml.ChangeTypeFieldAdded, NewValue: yaml.Field{Name: "a", Type: "timestamp", Nullable: &notNull, AutoCreate: true}}, false},
+		{"made not null", yaml.Change{Type: yaml.ChangeTypeFieldModified, OldValue: true, NewValue: false, Property: yaml.FieldPropertyNullable}, true},
+		{"made nullable", yaml.Change{Type: yaml.ChangeTypeFieldModified, OldValue: false, NewValue: true, Property: yaml.FieldPropertyNullable}, false},
+		{"primary key removed", yaml.Change{Type: yaml.ChangeTypeFieldModified, OldValue: true, NewValue: false, Property: yaml.FieldPropertyPrimaryKey}, false},
+		{"type change", yaml.Change{Type: yaml.ChangeTypeFieldModified, OldValue: "integer", NewValue: "bigint"}, false},
+	}
+	for _, tt := range tests {
+		if got := codegen.NeedsBackfill(tt.change); got != tt.want {
+			t.Errorf("%s: NeedsBackfill = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGoGenerator_AlterField_NewColumn(t *testing.T) {
+	g := codegen.NewGoGenerator()
+	g.NewColumns = map[string]bool{"orders.code": true}
+	prev := &yaml.Schema{Tables: []yaml.Table{{Name: "orders", Fields: []yaml.Field{{Name: "code", Type: "varchar", Length: 20}}}}}
+	curr := &yaml.Schema{Tables: []yaml.Table{{Name: "orders", Fields: []yaml.Field{{Name: "code", Type: "integer"}}}}}
+	diff := &yaml.SchemaDiff{
+		HasChanges: true,
+		Changes: []yaml.Change{
+			{Type: yaml.ChangeTypeFieldModified, TableName: "orders", FieldName: "code", OldValue: "varchar", NewValue: "integer"},
+		},
+	}
+	src, err := g.GenerateMigration("0010_code_integer", []string{"0009_backfill"}, diff, curr, prev, nil)
+	if err != nil {
+		t.Fatalf("GenerateMigration: %v", err)
+	}
+	if !strings.Contains(src, "Method:   m.AlterMethodNewColumn,") {
+		t.Errorf("expected Method: m.AlterMethodNewColumn in output:\n%s", src)
+	}
+
+	g.NewColumns = nil
+	src, err = g.GenerateMigration("0010_code_integer", []string{"0009_backfill"}, diff, curr, prev, nil)
+	if err != nil {
+		t.Fatalf("GenerateMigration: %v", err)
+	}
+	if strings.Contains(src, "Method:") {
+		t.Errorf("expected no Method by default:\n%s", src)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package codegen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Migration naming schemes, selected by migration.naming in the config.
+const (
+	// NamingSequential prefixes names with a zero-padded count of the
+	// existing migrations: 0001, 0002, ...
+	NamingSequential = "sequential"
+	// NamingTimestamp prefixes names with the UTC creation time as
+	// YYYYMMDDHHMMSS.
+	NamingTimestamp = "timestamp"
+	// NamingULID prefixes names with a ULID: 26 characters that sort by
+	// creation time and are unique across machines.
+	NamingULID = "ulid"
+)
+
+// MigrationPrefix returns the name prefix of a new migration under naming
+// ("" means NamingSequential). count is the number of existing migrations;
+// now is the creation time.
+func MigrationPrefix(naming string, count int, now time.Time) (string, error) {
+	switch naming {
+	case "", NamingSequential:
+		return NextMigrationNumber(count), nil
+	case NamingTimestamp:
+		return now.UTC().Format("20060102150405"), nil
+	case NamingULID:
+		return NewULID(now, rand.Reader)
+	}
+	return "", fmt.Errorf("unknown migration naming %q (want %s, %s or %s)",
+		naming, NamingSequential, NamingTimestamp, NamingULID)
+}
+
+// MigrationSuffix returns name without its prefix: everything after the
+// first underscore, or "" when name has none.
+func MigrationSuffix(name string) string {
+	_, suffix, _ := strings.Cut(name, "_")
+	return suffix
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a ULID for t: a 48-bit millisecond timestamp followed by
+// 80 bits read from entropy, in Crockford base32.
+func NewULID(t time.Time, entropy io.Reader) (string, error) {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(t.UnixMilli())<<16)
+	if _, err := io.ReadFull(entropy, b[6:]); err != nil {
+		return "", fmt.Errorf("reading ULID entropy: %w", err)
+	}
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	// 26 characters of 5 bits hold the 128-bit value with 2 leading zero bits.
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:]), nil
+}
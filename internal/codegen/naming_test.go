@@ -0,0 +1,86 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package codegen_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ocomsoft/makemigrations/internal/codegen"
+)
+
+func TestMigrationPrefix(t *testing.T) {
+	now := time.Date(2026, 1, 18, 15, 30, 45, 0, time.FixedZone("CET", 3600))
+	tests := []struct {
+		naming string
+		want   string
+	}{
+		{"", "0004"},
+		{codegen.NamingSequential, "0004"},
+		{codegen.NamingTimestamp, "20260118143045"},
+	}
+	for _, tt := range tests {
+		got, err := codegen.MigrationPrefix(tt.naming, 3, now)
+		if err != nil || got != tt.want {
+			t.Errorf("MigrationPrefix(%q) = %q, %v; want %q", tt.naming, got, err, tt.want)
+		}
+	}
+	got, err := codegen.MigrationPrefix(codegen.NamingULID, 3, now)
+	if err != nil || len(got) != 26 {
+		t.Errorf("MigrationPrefix(ulid) = %q, %v", got, err)
+	}
+	if _, err := codegen.MigrationPrefix("random", 3, now); err == nil {
+		t.Error("expected an error for an unknown naming scheme")
+	}
+}
+
+func TestNewULID(t *testing.T) {
+	// Reference value from the ULID specification.
+	got, err := codegen.NewULID(time.UnixMilli(1469918176385), bytes.NewReader(make([]byte, 10)))
+	if err != nil {
+		t.Fatalf("NewULID: %v", err)
+	}
+	if got != "01ARYZ6S410000000000000000" {
+		t.Errorf("NewULID = %q", got)
+	}
+	later, _ := codegen.NewULID(time.UnixMilli(1469918176386), bytes.NewReader(make([]byte, 10)))
+	if later <= got {
+		t.Errorf("expected ULIDs to sort by time: %q <= %q", later, got)
+	}
+	if _, err := codegen.NewULID(time.Now(), strings.NewReader("short")); err == nil {
+		t.Error("expected an error when entropy runs out")
+	}
+}
+
+func TestMigrationSuffix(t *testing.T) {
+	if got := codegen.MigrationSuffix("0042_add_users"); got != "add_users" {
+		t.Errorf("MigrationSuffix = %q", got)
+	}
+	if got := codegen.MigrationSuffix("0042"); got != "" {
+		t.Errorf("MigrationSuffix without a name = %q", got)
+	}
+}
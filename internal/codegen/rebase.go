@@ -0,0 +1,105 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// RewriteMigrationHeader returns the migration file src with the Name and
+// Dependencies of its m.Migration literal replaced by name and deps. The
+// rest of the file, operations and comments included, is left untouched.
+func RewriteMigrationHeader(src []byte, name string, deps []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var lit *ast.CompositeLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		if cl, ok := n.(*ast.CompositeLit); ok && lit == nil && selectorName(cl.Type) == "Migration" {
+			lit = cl
+		}
+		return lit == nil
+	})
+	if lit == nil {
+		return nil, fmt.Errorf("no m.Migration literal found")
+	}
+
+	depStrs := make([]string, len(deps))
+	for i, d := range deps {
+		depStrs[i] = fmt.Sprintf("%q", d)
+	}
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, _ := kv.Key.(*ast.Ident)
+		if key == nil {
+			continue
+		}
+		var text string
+		switch key.Name {
+		case "Name":
+			text = fmt.Sprintf("%q", name)
+		case "Dependencies":
+			text = "[]string{" + strings.Join(depStrs, ", ") + "}"
+		default:
+			continue
+		}
+		edits = append(edits, edit{
+			start: fset.Position(kv.Value.Pos()).Offset,
+			end:   fset.Position(kv.Value.End()).Offset,
+			text:  text,
+		})
+	}
+	if len(edits) != 2 {
+		return nil, fmt.Errorf("expected Name and Dependencies in the m.Migration literal")
+	}
+
+	// Apply from the end so earlier offsets stay valid.
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := append([]byte(nil), src...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	formatted, err := format.Source(out)
+	if err != nil {
+		return out, fmt.Errorf("formatting rewritten migration: %w", err)
+	}
+	return formatted, nil
+}
@@ -0,0 +1,72 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package codegen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/internal/codegen"
+)
+
+func TestRewriteMigrationHeader(t *testing.T) {
+	src := `package main
+
+import m "github.com/ocomsoft/makemigrations/migrate"
+
+func init() {
+	m.Register(&m.Migration{
+		Name:         "0002_add_email",
+		Dependencies: []string{"0001_initial"},
+		Operations: []m.Operation{
+			// Keep me.
+			&m.RunSQL{ForwardSQL: "SELECT 1"},
+		},
+	})
+}
+`
+	out, err := codegen.RewriteMigrationHeader([]byte(src), "0004_add_email", []string{"0003_orders"})
+	if err != nil {
+		t.Fatalf("RewriteMigrationHeader: %v", err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		`Name:         "0004_add_email",`,
+		`Dependencies: []string{"0003_orders"},`,
+		"// Keep me.",
+		`&m.RunSQL{ForwardSQL: "SELECT 1"},`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "0001_initial") || strings.Contains(got, "0002_add_email") {
+		t.Errorf("expected the old name and dependency to be gone:\n%s", got)
+	}
+
+	if _, err := codegen.RewriteMigrationHeader([]byte("package main\n"), "x", nil); err == nil {
+		t.Error("expected an error for a file without a migration")
+	}
+}
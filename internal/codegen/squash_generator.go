@@ -119,6 +119,16 @@ func renderOperation(op migrate.Operation) (string, error) {
 	case *migrate.DropIndex:
 		return fmt.Sprintf("\t\t\t&m.DropIndex{Table: %q, Index: %q},\n",
 			o.Table, o.Index), nil
+	case *migrate.AddUniqueConstraint:
+		return fmt.Sprintf("\t\t\t&m.AddUniqueConstraint{Table: %q, Constraint: %s},\n",
+			o.Table, generateUniqueConstraintLiteral(yaml.UniqueConstraint{
+				Name:             o.Constraint.Name,
+				Fields:           o.Constraint.Fields,
+				NullsNotDistinct: o.Constraint.NullsNotDistinct,
+			})), nil
+	case *migrate.DropUniqueConstraint:
+		return fmt.Sprintf("\t\t\t&m.DropUniqueConstraint{Table: %q, Name: %q},\n",
+			o.Table, o.Name), nil
 	case *migrate.RunSQL:
 		return fmt.Sprintf("\t\t\t&m.RunSQL{ForwardSQL: %q, BackwardSQL: %q},\n",
 			o.ForwardSQL, o.BackwardSQL), nil
@@ -203,6 +213,8 @@ func migrateFieldToYAML(f migrate.Field) yaml.Field {
 		Scale:      f.Scale,
 		AutoCreate: f.AutoCreate,
 		AutoUpdate: f.AutoUpdate,
+		Collation:  f.Collation,
+		Charset:    f.Charset,
 	}
 	if f.ForeignKey != nil {
 		yf.ForeignKey = &yaml.ForeignKey{
@@ -29,10 +29,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	yaml "gopkg.in/yaml.v3"
+
+	"github.com/ocomsoft/makemigrations/internal/types"
 )
 
 // Config represents the makemigrations configuration
@@ -45,6 +49,9 @@ type Config struct {
 
 	// Output settings
 	Output OutputConfig `yaml:"output" mapstructure:"output"`
+
+	// SQL hooks run by `makemigrations migrate` around migrations and operations
+	Hooks HooksConfig `yaml:"hooks,omitempty" mapstructure:"hooks"`
 }
 
 // DatabaseConfig contains database-related settings
@@ -56,6 +63,66 @@ type DatabaseConfig struct {
 // MigrationConfig contains migration-related settings
 type MigrationConfig struct {
 	Directory string `yaml:"directory" mapstructure:"directory"` // Directory for migration files
+
+	// Naming selects the prefix of new migration names: "sequential"
+	// (0001, 0002, ...; the default when empty), "timestamp" (UTC
+	// YYYYMMDDHHMMSS) or "ulid". Timestamps and ULIDs do not collide
+	// when two branches generate migrations at the same time.
+	Naming string `yaml:"naming,omitempty" mapstructure:"naming"`
+
+	// Loader selects how migration files are read: "yaegi" (interpret
+	// every file; the default when empty) or "ast" (build migrations from
+	// the syntax tree, interpreting only files that need it).
+	Loader string `yaml:"loader,omitempty" mapstructure:"loader"`
+
+	// DestructivePolicy decides destructive changes in `generate` without
+	// prompting.
+	DestructivePolicy []DestructiveRule `yaml:"destructive_policy,omitempty" mapstructure:"destructive_policy"`
+
+	// SQLPreview lists the database types (postgresql, sqlite, ...) for which
+	// `generate` writes a NNNN_name.<type>.sql file with the Up and Down SQL
+	// of each migration. Empty disables the previews.
+	SQLPreview []string `yaml:"sql_preview,omitempty" mapstructure:"sql_preview"`
+
+	// Defaults for `makemigrations migrate up/down` timeouts, as Go durations
+	// ("30s", "1m"). Empty keeps the database default.
+	StatementTimeout string `yaml:"statement_timeout,omitempty" mapstructure:"statement_timeout"`
+	LockTimeout      string `yaml:"lock_timeout,omitempty" mapstructure:"lock_timeout"`
+	LockRetries      int    `yaml:"lock_retries,omitempty" mapstructure:"lock_retries"`             // Retries after a lock timeout
+	LockRetryBackoff string `yaml:"lock_retry_backoff,omitempty" mapstructure:"lock_retry_backoff"` // Delay before the first retry
+}
+
+// DestructiveRule maps the destructive changes selected by Match to Action.
+// Match is a change type (field_removed), a "table" or "table.field" glob,
+// or "*"; Action is generate, review, omit, ignore-errors or fail.
+type DestructiveRule struct {
+	Match  string `yaml:"match" mapstructure:"match"`
+	Action string `yaml:"action" mapstructure:"action"`
+}
+
+// Durations parses StatementTimeout, LockTimeout and LockRetryBackoff. Empty
+// values are returned as zero.
+func (m MigrationConfig) Durations() (statement, lock, backoff time.Duration, err error) {
+	parse := func(key, value string) (time.Duration, error) {
+		if value == "" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("migration.%s: %w", key, err)
+		}
+		return d, nil
+	}
+	if statement, err = parse("statement_timeout", m.StatementTimeout); err != nil {
+		return 0, 0, 0, err
+	}
+	if lock, err = parse("lock_timeout", m.LockTimeout); err != nil {
+		return 0, 0, 0, err
+	}
+	if backoff, err = parse("lock_retry_backoff", m.LockRetryBackoff); err != nil {
+		return 0, 0, 0, err
+	}
+	return statement, lock, backoff, nil
 }
 
 // OutputConfig contains output formatting settings
@@ -64,6 +131,71 @@ type OutputConfig struct {
 	ColorEnabled bool `yaml:"color_enabled" mapstructure:"color_enabled"` // Enable colored output
 }
 
+// HooksConfig contains SQL snippets keyed by database type (postgresql, mysql,
+// sqlite, ...). The snippets for the active database run inside each
+// migration's transaction at the named point.
+type HooksConfig struct {
+	BeforeMigration map[string][]string `yaml:"before_migration,omitempty" mapstructure:"before_migration"`
+	AfterMigration  map[string][]string `yaml:"after_migration,omitempty" mapstructure:"after_migration"`
+	BeforeOperation map[string][]string `yaml:"before_operation,omitempty" mapstructure:"before_operation"`
+	AfterOperation  map[string][]string `yaml:"after_operation,omitempty" mapstructure:"after_operation"`
+}
+
+// HookSQL holds the hook snippets that apply to a single database type.
+type HookSQL struct {
+	BeforeMigration []string
+	AfterMigration  []string
+	BeforeOperation []string
+	AfterOperation  []string
+}
+
+// IsEmpty reports whether no snippets are configured.
+func (h HookSQL) IsEmpty() bool {
+	return len(h.BeforeMigration)+len(h.AfterMigration)+len(h.BeforeOperation)+len(h.AfterOperation) == 0
+}
+
+// ForDatabase returns the snippets configured for dbType. Keys are parsed
+// with the same rules as database.type; an unknown key is an error so typos
+// do not silently disable a hook.
+func (h HooksConfig) ForDatabase(dbType string) (HookSQL, error) {
+	want, err := types.ParseDatabaseType(dbType)
+	if err != nil {
+		return HookSQL{}, err
+	}
+	pick := func(point string, m map[string][]string) ([]string, error) {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var out []string
+		for _, key := range keys {
+			dt, err := types.ParseDatabaseType(key)
+			if err != nil {
+				return nil, fmt.Errorf("hooks.%s: %w", point, err)
+			}
+			if dt == want {
+				out = append(out, m[key]...)
+			}
+		}
+		return out, nil
+	}
+	var res HookSQL
+	if res.BeforeMigration, err = pick("before_migration", h.BeforeMigration); err != nil {
+		return HookSQL{}, err
+	}
+	if res.AfterMigration, err = pick("after_migration", h.AfterMigration); err != nil {
+		return HookSQL{}, err
+	}
+	if res.BeforeOperation, err = pick("before_operation", h.BeforeOperation); err != nil {
+		return HookSQL{}, err
+	}
+	if res.AfterOperation, err = pick("after_operation", h.AfterOperation); err != nil {
+		return HookSQL{}, err
+	}
+	return res, nil
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -172,6 +304,12 @@ func setDefaults(v *viper.Viper, cfg *Config) {
 	v.SetDefault("database.type", cfg.Database.Type)
 	v.SetDefault("database.default_url", cfg.Database.DefaultURL)
 	v.SetDefault("migration.directory", cfg.Migration.Directory)
+	v.SetDefault("migration.naming", cfg.Migration.Naming)
+	v.SetDefault("migration.loader", cfg.Migration.Loader)
+	v.SetDefault("migration.statement_timeout", cfg.Migration.StatementTimeout)
+	v.SetDefault("migration.lock_timeout", cfg.Migration.LockTimeout)
+	v.SetDefault("migration.lock_retries", cfg.Migration.LockRetries)
+	v.SetDefault("migration.lock_retry_backoff", cfg.Migration.LockRetryBackoff)
 	v.SetDefault("output.verbose", cfg.Output.Verbose)
 	v.SetDefault("output.color_enabled", cfg.Output.ColorEnabled)
 }
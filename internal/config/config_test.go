@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -371,3 +373,125 @@ func TestLoadWithEnvOverride(t *testing.T) {
 		t.Errorf("expected env override to set database type 'mysql', got %q", cfg.Database.Type)
 	}
 }
+
+func TestLoadHooksForDatabase(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+
+	content := `database:
+  type: postgresql
+hooks:
+  before_migration:
+    postgresql:
+      - SET lock_timeout = '5s'
+    mysql:
+      - SET SESSION lock_wait_timeout = 5
+  after_migration:
+    postgresql:
+      - REFRESH MATERIALIZED VIEW report_totals
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	pg, err := cfg.Hooks.ForDatabase("postgresql")
+	if err != nil {
+		t.Fatalf("ForDatabase returned error: %v", err)
+	}
+	if len(pg.BeforeMigration) != 1 || pg.BeforeMigration[0] != "SET lock_timeout = '5s'" {
+		t.Errorf("unexpected before_migration hooks: %v", pg.BeforeMigration)
+	}
+	if len(pg.AfterMigration) != 1 || pg.IsEmpty() {
+		t.Errorf("unexpected after_migration hooks: %v", pg.AfterMigration)
+	}
+
+	lite, err := cfg.Hooks.ForDatabase("sqlite")
+	if err != nil {
+		t.Fatalf("ForDatabase returned error: %v", err)
+	}
+	if !lite.IsEmpty() {
+		t.Errorf("expected no sqlite hooks, got %+v", lite)
+	}
+
+	cfg.Hooks.AfterOperation = map[string][]string{"postgress": {"SELECT 1"}}
+	if _, err := cfg.Hooks.ForDatabase("postgresql"); err == nil {
+		t.Error("expected unknown database key to be rejected")
+	}
+}
+
+func TestLoadMigrationTimeouts(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+
+	content := `migration:
+  directory: migrations
+  naming: timestamp
+  lock_timeout: 5s
+  lock_retries: 3
+  lock_retry_backoff: 500ms
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	statement, lock, backoff, err := cfg.Migration.Durations()
+	if err != nil {
+		t.Fatalf("Durations returned error: %v", err)
+	}
+	if statement != 0 || lock != 5*time.Second || backoff != 500*time.Millisecond || cfg.Migration.LockRetries != 3 {
+		t.Errorf("unexpected timeouts: statement=%v lock=%v backoff=%v retries=%d", statement, lock, backoff, cfg.Migration.LockRetries)
+	}
+	if cfg.Migration.Naming != "timestamp" {
+		t.Errorf("expected naming timestamp, got %q", cfg.Migration.Naming)
+	}
+
+	cfg.Migration.StatementTimeout = "30"
+	if _, _, _, err := cfg.Migration.Durations(); err == nil {
+		t.Error("expected a duration without a unit to be rejected")
+	}
+}
+
+func TestLoadGenerateSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+
+	content := `migration:
+  destructive_policy:
+    - match: field_removed
+      action: review
+    - match: "users.password_*"
+      action: fail
+  sql_preview: [postgresql, sqlite]
+  loader: ast
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	want := []DestructiveRule{
+		{Match: "field_removed", Action: "review"},
+		{Match: "users.password_*", Action: "fail"},
+	}
+	if !reflect.DeepEqual(cfg.Migration.DestructivePolicy, want) {
+		t.Errorf("unexpected destructive policy %v", cfg.Migration.DestructivePolicy)
+	}
+	if !reflect.DeepEqual(cfg.Migration.SQLPreview, []string{"postgresql", "sqlite"}) {
+		t.Errorf("unexpected sql_preview %v", cfg.Migration.SQLPreview)
+	}
+	if cfg.Migration.Loader != "ast" {
+		t.Errorf("expected loader ast, got %q", cfg.Migration.Loader)
+	}
+}
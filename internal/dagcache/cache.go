@@ -0,0 +1,149 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package dagcache caches the migrate.DAGOutput reconstructed from a
+// migrations directory, so commands that only need the schema state skip
+// interpreting and replaying every migration file. Entries are content
+// addressed: the key hashes the migration files and the tool version, so
+// any edit, added or removed file, or upgrade selects a new entry.
+//
+// The compiled migrate binary does not use the cache: its migrations are
+// compiled in rather than interpreted, so building the graph is cheap, and
+// it needs every operation to render SQL, not only the resulting state.
+package dagcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+// maxAge is how long an entry may go unused before Store removes it.
+const maxAge = 30 * 24 * time.Hour
+
+// Key returns the cache key of files (as returned by interp.MigrationFiles)
+// for toolVersion. It covers each file's name and content. toolVersion must
+// tell apart every build that may reconstruct state differently, including
+// builds from a modified checkout.
+func Key(files []string, toolVersion string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "makemigrations dag cache v1\n%s\n", toolVersion)
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("hashing %s: %w", path, err)
+		}
+		fh := sha256.New()
+		_, err = io.Copy(fh, f)
+		_ = f.Close()
+		if err != nil {
+			return "", fmt.Errorf("hashing %s: %w", path, err)
+		}
+		fmt.Fprintf(h, "%s %x\n", filepath.Base(path), fh.Sum(nil))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// DefaultDir returns the cache directory: makemigrations/dag under the
+// user cache directory, or .makemigrations-cache in migrationsDir when the
+// system has none.
+func DefaultDir(migrationsDir string) string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "makemigrations", "dag")
+	}
+	return filepath.Join(migrationsDir, ".makemigrations-cache")
+}
+
+// Load returns the entry for key in dir, if there is a readable one.
+func Load(dir, key string) (*migrate.DAGOutput, bool) {
+	path := filepath.Join(dir, key+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var out migrate.DAGOutput
+	if err := json.Unmarshal(data, &out); err != nil || out.SchemaState == nil {
+		return nil, false
+	}
+	// Mark the entry as used so Store keeps it.
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return &out, true
+}
+
+// Store saves out as the entry for key in dir and removes entries unused
+// for more than 30 days. The entry is written to a temporary file and
+// renamed, so concurrent runs never read a partial entry.
+func Store(dir, key string, out *migrate.DAGOutput) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, key+".json")); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	prune(dir, time.Now().Add(-maxAge))
+	return nil
+}
+
+// prune removes the entries in dir last used before cutoff.
+func prune(dir string, cutoff time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		if info, err := e.Info(); err == nil && info.ModTime().Before(cutoff) {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
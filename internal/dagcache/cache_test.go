@@ -0,0 +1,123 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package dagcache_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ocomsoft/makemigrations/internal/dagcache"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+func TestKey(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "0001_initial.go")
+	b := filepath.Join(dir, "0002_users.go")
+	for _, path := range []string{a, b} {
+		if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	key := func(files []string, version string) string {
+		t.Helper()
+		k, err := dagcache.Key(files, version)
+		if err != nil {
+			t.Fatalf("Key: %v", err)
+		}
+		return k
+	}
+
+	base := key([]string{a, b}, "v1")
+	if again := key([]string{a, b}, "v1"); again != base {
+		t.Fatal("expected the key to be stable")
+	}
+	if key([]string{a, b}, "v2") == base {
+		t.Error("expected a new tool version to change the key")
+	}
+	if key([]string{a}, "v1") == base {
+		t.Error("expected a removed file to change the key")
+	}
+	if err := os.WriteFile(b, []byte("package main // edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if key([]string{a, b}, "v1") == base {
+		t.Error("expected an edited file to change the key")
+	}
+	if _, err := dagcache.Key([]string{filepath.Join(dir, "missing.go")}, "v1"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestStoreLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	if _, ok := dagcache.Load(dir, "abc"); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+
+	state := migrate.NewSchemaState()
+	state.Tables["users"] = &migrate.TableState{
+		Name:    "users",
+		Fields:  []migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}},
+		Indexes: []migrate.Index{{Name: "users_id_idx", Fields: []string{"id"}}},
+	}
+	out := &migrate.DAGOutput{
+		Migrations:  []migrate.MigrationSummary{{Name: "0001_initial", Dependencies: []string{}, Operations: []migrate.OperationSummary{}}},
+		Roots:       []string{"0001_initial"},
+		Leaves:      []string{"0001_initial"},
+		SchemaState: state,
+	}
+	if err := dagcache.Store(dir, "abc", out); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	got, ok := dagcache.Load(dir, "abc")
+	if !ok {
+		t.Fatal("expected a hit after Store")
+	}
+	if !reflect.DeepEqual(got, out) {
+		t.Errorf("round trip changed the entry:\n got %+v\nwant %+v", got, out)
+	}
+
+	// A corrupt entry is a miss, and old entries are pruned on Store.
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dagcache.Load(dir, "bad"); ok {
+		t.Error("expected a corrupt entry to be a miss")
+	}
+	old := time.Now().Add(-31 * 24 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "abc.json"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := dagcache.Store(dir, "def", out); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "abc.json")); !os.IsNotExist(err) {
+		t.Error("expected an entry unused for 31 days to be pruned")
+	}
+}
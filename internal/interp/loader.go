@@ -62,59 +62,76 @@ const virtualPkg = "migrations"
 // global. This lets the function be called multiple times in a single process
 // without duplicate-registration panics.
 func LoadRegistry(migrationsDir string) (*migrate.Registry, error) {
-	files, err := filepath.Glob(filepath.Join(migrationsDir, "*.go"))
+	migFiles, err := MigrationFiles(migrationsDir)
 	if err != nil {
-		return nil, fmt.Errorf("scanning migrations directory: %w", err)
+		return nil, err
 	}
-
-	// Filter out main.go and *_test.go; sort for deterministic eval order.
-	var migFiles []string
-	for _, f := range files {
-		base := filepath.Base(f)
-		if base == "main.go" {
-			continue
-		}
-		if len(base) > 8 && base[len(base)-8:] == "_test.go" {
-			continue
-		}
-		migFiles = append(migFiles, f)
+	reg := migrate.NewRegistry()
+	if err := loadWithYaegi(reg, migFiles); err != nil {
+		return nil, err
 	}
-	sort.Strings(migFiles)
+	return reg, nil
+}
 
-	if len(migFiles) == 0 {
-		return migrate.NewRegistry(), nil
+// loadWithYaegi interprets the migration files at paths as one package and
+// registers their migrations in reg.
+func loadWithYaegi(reg *migrate.Registry, paths []string) error {
+	if len(paths) == 0 {
+		return nil
 	}
 
 	// Build an in-memory filesystem with each file rewritten to declare
 	// `package migrations`. yaegi treats the directory as a single multi-file
 	// package, dedupes imports, and runs all init() funcs in source order.
 	fsys := fstest.MapFS{}
-	for _, path := range migFiles {
+	for _, path := range paths {
 		data, readErr := os.ReadFile(path)
 		if readErr != nil {
-			return nil, fmt.Errorf("reading %s: %w", path, readErr)
+			return fmt.Errorf("reading %s: %w", path, readErr)
 		}
 		rewritten, rewriteErr := rewritePackage(path, data, virtualPkg)
 		if rewriteErr != nil {
-			return nil, fmt.Errorf("rewriting %s: %w", path, rewriteErr)
+			return fmt.Errorf("rewriting %s: %w", path, rewriteErr)
 		}
 		fsys["src/"+virtualPkg+"/"+filepath.Base(path)] = &fstest.MapFile{Data: rewritten}
 	}
 
-	reg := migrate.NewRegistry()
-
 	i := interp.New(interp.Options{SourcecodeFilesystem: fsys})
 	if err := i.Use(stdlib.Symbols); err != nil {
-		return nil, fmt.Errorf("registering stdlib symbols: %w", err)
+		return fmt.Errorf("registering stdlib symbols: %w", err)
 	}
 	if err := i.Use(perLoadSymbols(reg)); err != nil {
-		return nil, fmt.Errorf("registering migrate symbols: %w", err)
+		return fmt.Errorf("registering migrate symbols: %w", err)
 	}
 
 	if _, err := i.Eval(`import _ "` + virtualPkg + `"`); err != nil {
-		return nil, fmt.Errorf("loading migrations: %w", err)
+		return fmt.Errorf("loading migrations: %w", err)
 	}
-	return reg, nil
+	return nil
+}
+
+// MigrationFiles returns the paths of the files LoadRegistry reads from
+// migrationsDir: every *.go file except main.go and *_test.go, sorted.
+func MigrationFiles(migrationsDir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(migrationsDir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("scanning migrations directory: %w", err)
+	}
+
+	// Filter out main.go and *_test.go; sort for deterministic eval order.
+	var migFiles []string
+	for _, f := range files {
+		base := filepath.Base(f)
+		if base == "main.go" {
+			continue
+		}
+		if len(base) > 8 && base[len(base)-8:] == "_test.go" {
+			continue
+		}
+		migFiles = append(migFiles, f)
+	}
+	sort.Strings(migFiles)
+	return migFiles, nil
 }
 
 // perLoadSymbols clones symbols.Symbols and overrides the migrate package's
@@ -0,0 +1,558 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package interp
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"path"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/ocomsoft/makemigrations/migrate"
+	"github.com/ocomsoft/makemigrations/migrate/symbols"
+)
+
+// Loader names accepted by Load, set with migration.loader in the config.
+const (
+	// LoaderYaegi interprets every migration file with yaegi (the default).
+	LoaderYaegi = "yaegi"
+	// LoaderAST builds migrations from the syntax tree of each file and
+	// interprets only the files it cannot evaluate statically.
+	LoaderAST = "ast"
+)
+
+const (
+	migratePath = "github.com/ocomsoft/makemigrations/migrate"
+	// migrateSymbols is the key of the migrate package in symbols.Symbols.
+	migrateSymbols = migratePath + "/migrate"
+)
+
+// durations are the time package constants a static migration may use,
+// e.g. StatementTimeout: 30 * time.Second.
+var durations = map[string]time.Duration{
+	"Nanosecond":  time.Nanosecond,
+	"Microsecond": time.Microsecond,
+	"Millisecond": time.Millisecond,
+	"Second":      time.Second,
+	"Minute":      time.Minute,
+	"Hour":        time.Hour,
+}
+
+// Load loads the migrations in migrationsDir with the named loader; "" means
+// LoaderYaegi. With LoaderAST, fallback lists the files that had to be
+// interpreted with yaegi (see LoadRegistryAST); it is always nil otherwise.
+func Load(migrationsDir, loader string) (reg *migrate.Registry, fallback []string, err error) {
+	switch loader {
+	case "", LoaderYaegi:
+		reg, err = LoadRegistry(migrationsDir)
+		return reg, nil, err
+	case LoaderAST:
+		return LoadRegistryAST(migrationsDir)
+	default:
+		return nil, nil, fmt.Errorf("migration.loader: unknown loader %q (want %s or %s)", loader, LoaderYaegi, LoaderAST)
+	}
+}
+
+// LoadRegistryAST loads the same files as LoadRegistry, but builds each
+// migration directly from its file's syntax tree instead of interpreting it.
+// This covers files made only of imports and an init() whose statements are
+// m.Register calls on composite literals of migrate types, which is what
+// generate writes. Such files need no yaegi symbols and load much faster.
+//
+// Any other file (helper functions, variables, third-party imports, ...) is
+// interpreted with yaegi instead; their paths are returned as fallback. The
+// statically built migrations are registered first, in file order, followed
+// by those of the fallback files.
+func LoadRegistryAST(migrationsDir string) (reg *migrate.Registry, fallback []string, err error) {
+	files, err := MigrationFiles(migrationsDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	reg = migrate.NewRegistry()
+	for _, path := range files {
+		migs, err := parseStaticFile(path)
+		if err != nil {
+			fallback = append(fallback, path)
+			continue
+		}
+		for _, mig := range migs {
+			if _, exists := reg.Get(mig.Name); exists {
+				return nil, nil, fmt.Errorf("%s: duplicate migration name %q", path, mig.Name)
+			}
+			reg.Register(mig)
+		}
+	}
+	if len(fallback) > 0 {
+		if err := loadWithYaegi(reg, fallback); err != nil {
+			return nil, nil, err
+		}
+	}
+	return reg, fallback, nil
+}
+
+// parseStaticFile returns the migrations registered by the file filename, or
+// an error if the file does anything LoadRegistryAST cannot evaluate.
+func parseStaticFile(filename string) ([]*migrate.Migration, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	rewriteLegacyImports(file)
+
+	ev := &staticEval{fset: fset, imports: make(map[string]string)}
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+		if importPath != migratePath && importPath != "time" {
+			return nil, fmt.Errorf("import %q is not supported statically", importPath)
+		}
+		name := path.Base(importPath)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == "_" || name == "." {
+			return nil, fmt.Errorf("%s import of %q is not supported statically", name, importPath)
+		}
+		ev.imports[name] = importPath
+	}
+
+	var migs []*migrate.Migration
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok != token.IMPORT {
+				return nil, ev.errorf(d, "top-level %s declarations are not supported statically", d.Tok)
+			}
+		case *ast.FuncDecl:
+			if d.Name.Name != "init" || d.Recv != nil || d.Body == nil {
+				return nil, ev.errorf(d, "function %s is not supported statically", d.Name.Name)
+			}
+			for _, stmt := range d.Body.List {
+				mig, err := ev.register(stmt)
+				if err != nil {
+					return nil, err
+				}
+				migs = append(migs, mig)
+			}
+		}
+	}
+	return migs, nil
+}
+
+// staticEval evaluates the expressions of one migration file.
+type staticEval struct {
+	fset *token.FileSet
+	// imports maps each import's local name to its path.
+	imports map[string]string
+}
+
+// errorf returns an error prefixed with the position of node.
+func (e *staticEval) errorf(node ast.Node, format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %s", e.fset.Position(node.Pos()), fmt.Sprintf(format, args...))
+}
+
+// register evaluates an m.Register(&m.Migration{...}) statement.
+func (e *staticEval) register(stmt ast.Stmt) (*migrate.Migration, error) {
+	es, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return nil, e.errorf(stmt, "only m.Register calls are supported statically in init")
+	}
+	call, ok := es.X.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || call.Ellipsis.IsValid() {
+		return nil, e.errorf(stmt, "only m.Register calls are supported statically in init")
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || e.pkgOf(sel) != migratePath || sel.Sel.Name != "Register" {
+		return nil, e.errorf(stmt, "only m.Register calls are supported statically in init")
+	}
+	v, err := e.value(call.Args[0], reflect.TypeOf((*migrate.Migration)(nil)))
+	if err != nil {
+		return nil, err
+	}
+	mig := v.Interface().(*migrate.Migration)
+	if mig == nil || mig.Name == "" {
+		return nil, e.errorf(stmt, "migration without a name")
+	}
+	return mig, nil
+}
+
+// pkgOf returns the import path of the package sel refers to, if any.
+func (e *staticEval) pkgOf(sel *ast.SelectorExpr) string {
+	if id, ok := sel.X.(*ast.Ident); ok {
+		return e.imports[id.Name]
+	}
+	return ""
+}
+
+// value evaluates expr as a value of type t.
+func (e *staticEval) value(expr ast.Expr, t reflect.Type) (reflect.Value, error) {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return e.value(x.X, t)
+	case *ast.Ident:
+		if x.Name == "nil" {
+			switch t.Kind() {
+			case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+				return reflect.Zero(t), nil
+			}
+			return reflect.Value{}, e.errorf(x, "cannot use nil as %s", t)
+		}
+	case *ast.CompositeLit:
+		lt := t
+		if x.Type != nil {
+			var err error
+			if lt, err = e.typeOf(x.Type); err != nil {
+				return reflect.Value{}, err
+			}
+		} else if t.Kind() == reflect.Ptr {
+			// An elided &T in []*T{{...}}.
+			v, err := e.composite(x, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return e.assignable(x, v.Addr(), t)
+		}
+		v, err := e.composite(x, lt)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return e.assignable(x, v, t)
+	case *ast.UnaryExpr:
+		if x.Op == token.AND {
+			lit, ok := x.X.(*ast.CompositeLit)
+			if !ok {
+				return reflect.Value{}, e.errorf(x, "& is only supported on composite literals")
+			}
+			lt := t
+			if t.Kind() == reflect.Ptr {
+				lt = t.Elem()
+			}
+			if lit.Type != nil {
+				var err error
+				if lt, err = e.typeOf(lit.Type); err != nil {
+					return reflect.Value{}, err
+				}
+			}
+			v, err := e.composite(lit, lt)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return e.assignable(x, v.Addr(), t)
+		}
+	}
+
+	c, err := e.constant(expr)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return e.convert(expr, c, t)
+}
+
+// assignable returns v if it can be assigned to a t.
+func (e *staticEval) assignable(node ast.Node, v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	if !v.Type().AssignableTo(t) {
+		return reflect.Value{}, e.errorf(node, "cannot use %s as %s", v.Type(), t)
+	}
+	return v, nil
+}
+
+// composite evaluates the elements of lit into a new value of type t.
+func (e *staticEval) composite(lit *ast.CompositeLit, t reflect.Type) (reflect.Value, error) {
+	switch t.Kind() {
+	case reflect.Struct:
+		v := reflect.New(t).Elem()
+		for i, elt := range lit.Elts {
+			var field reflect.StructField
+			valueExpr := elt
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				key, ok := kv.Key.(*ast.Ident)
+				if !ok {
+					return reflect.Value{}, e.errorf(kv, "invalid field name")
+				}
+				f, found := t.FieldByName(key.Name)
+				if !found || len(f.Index) != 1 {
+					return reflect.Value{}, e.errorf(kv, "unknown field %s in %s", key.Name, t)
+				}
+				field, valueExpr = f, kv.Value
+			} else {
+				if i >= t.NumField() {
+					return reflect.Value{}, e.errorf(elt, "too many values in %s literal", t)
+				}
+				field = t.Field(i)
+			}
+			if field.PkgPath != "" {
+				return reflect.Value{}, e.errorf(elt, "field %s of %s is unexported", field.Name, t)
+			}
+			fv, err := e.value(valueExpr, field.Type)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Field(field.Index[0]).Set(fv)
+		}
+		return v, nil
+	case reflect.Slice:
+		v := reflect.MakeSlice(t, 0, len(lit.Elts))
+		for _, elt := range lit.Elts {
+			if _, ok := elt.(*ast.KeyValueExpr); ok {
+				return reflect.Value{}, e.errorf(elt, "indexed slice literals are not supported statically")
+			}
+			ev, err := e.value(elt, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v = reflect.Append(v, ev)
+		}
+		return v, nil
+	case reflect.Map:
+		v := reflect.MakeMapWithSize(t, len(lit.Elts))
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				return reflect.Value{}, e.errorf(elt, "missing key in map literal")
+			}
+			key, err := e.value(kv.Key, t.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			val, err := e.value(kv.Value, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.SetMapIndex(key, val)
+		}
+		return v, nil
+	}
+	return reflect.Value{}, e.errorf(lit, "%s literals are not supported statically", t)
+}
+
+// typeOf resolves a type expression.
+func (e *staticEval) typeOf(expr ast.Expr) (reflect.Type, error) {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return e.typeOf(x.X)
+	case *ast.Ident:
+		if t, ok := builtinTypes[x.Name]; ok {
+			return t, nil
+		}
+	case *ast.InterfaceType:
+		if x.Methods == nil || len(x.Methods.List) == 0 {
+			return builtinTypes["any"], nil
+		}
+	case *ast.StarExpr:
+		t, err := e.typeOf(x.X)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.PointerTo(t), nil
+	case *ast.ArrayType:
+		if x.Len != nil {
+			break
+		}
+		t, err := e.typeOf(x.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(t), nil
+	case *ast.MapType:
+		k, err := e.typeOf(x.Key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := e.typeOf(x.Value)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(k, v), nil
+	case *ast.SelectorExpr:
+		switch e.pkgOf(x) {
+		case migratePath:
+			sym, ok := symbols.Symbols[migrateSymbols][x.Sel.Name]
+			if ok && sym.Kind() == reflect.Ptr && sym.IsNil() {
+				return sym.Type().Elem(), nil
+			}
+		case "time":
+			if x.Sel.Name == "Duration" {
+				return reflect.TypeOf(time.Duration(0)), nil
+			}
+		}
+	}
+	return nil, e.errorf(expr, "type is not supported statically")
+}
+
+// builtinTypes are the predeclared types a static migration may use.
+var builtinTypes = map[string]reflect.Type{
+	"any":     reflect.TypeOf((*interface{})(nil)).Elem(),
+	"bool":    reflect.TypeOf(false),
+	"string":  reflect.TypeOf(""),
+	"int":     reflect.TypeOf(0),
+	"int8":    reflect.TypeOf(int8(0)),
+	"int16":   reflect.TypeOf(int16(0)),
+	"int32":   reflect.TypeOf(int32(0)),
+	"int64":   reflect.TypeOf(int64(0)),
+	"uint":    reflect.TypeOf(uint(0)),
+	"uint8":   reflect.TypeOf(uint8(0)),
+	"uint16":  reflect.TypeOf(uint16(0)),
+	"uint32":  reflect.TypeOf(uint32(0)),
+	"uint64":  reflect.TypeOf(uint64(0)),
+	"byte":    reflect.TypeOf(byte(0)),
+	"rune":    reflect.TypeOf(rune(0)),
+	"float32": reflect.TypeOf(float32(0)),
+	"float64": reflect.TypeOf(float64(0)),
+}
+
+// constant evaluates a constant expression: literals, true and false,
+// migrate and time package constants, and operators on them.
+func (e *staticEval) constant(expr ast.Expr) (constant.Value, error) {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		c := constant.MakeFromLiteral(x.Value, x.Kind, 0)
+		if c.Kind() == constant.Unknown {
+			return nil, e.errorf(x, "invalid literal %s", x.Value)
+		}
+		return c, nil
+	case *ast.ParenExpr:
+		return e.constant(x.X)
+	case *ast.Ident:
+		switch x.Name {
+		case "true":
+			return constant.MakeBool(true), nil
+		case "false":
+			return constant.MakeBool(false), nil
+		}
+		return nil, e.errorf(x, "identifier %s is not supported statically", x.Name)
+	case *ast.UnaryExpr:
+		c, err := e.constant(x.X)
+		if err != nil {
+			return nil, err
+		}
+		switch x.Op {
+		case token.ADD, token.SUB, token.NOT:
+			return constant.UnaryOp(x.Op, c, 0), nil
+		}
+	case *ast.BinaryExpr:
+		l, err := e.constant(x.X)
+		if err != nil {
+			return nil, err
+		}
+		r, err := e.constant(x.Y)
+		if err != nil {
+			return nil, err
+		}
+		op := x.Op
+		switch op {
+		case token.ADD, token.SUB, token.MUL, token.REM:
+		case token.QUO:
+			if l.Kind() == constant.Int && r.Kind() == constant.Int {
+				op = token.QUO_ASSIGN // integer division
+			}
+			if constant.Sign(r) == 0 {
+				return nil, e.errorf(x, "division by zero")
+			}
+		default:
+			return nil, e.errorf(x, "operator %s is not supported statically", x.Op)
+		}
+		if (l.Kind() == constant.String) != (r.Kind() == constant.String) || (op == token.REM && l.Kind() != constant.Int) {
+			return nil, e.errorf(x, "invalid operation %s", x.Op)
+		}
+		c := constant.BinaryOp(l, op, r)
+		if c.Kind() == constant.Unknown {
+			return nil, e.errorf(x, "invalid operation %s", x.Op)
+		}
+		return c, nil
+	case *ast.SelectorExpr:
+		switch e.pkgOf(x) {
+		case migratePath:
+			if sym, ok := symbols.Symbols[migrateSymbols][x.Sel.Name]; ok {
+				switch sym.Kind() {
+				case reflect.String:
+					return constant.MakeString(sym.String()), nil
+				case reflect.Bool:
+					return constant.MakeBool(sym.Bool()), nil
+				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+					return constant.MakeInt64(sym.Int()), nil
+				}
+			}
+		case "time":
+			if d, ok := durations[x.Sel.Name]; ok {
+				return constant.MakeInt64(int64(d)), nil
+			}
+		}
+	}
+	return nil, e.errorf(expr, "expression is not supported statically")
+}
+
+// convert returns c as a value of type t. For an interface t the value gets
+// the constant's default type (string, bool, int or float64), as in Go.
+func (e *staticEval) convert(node ast.Node, c constant.Value, t reflect.Type) (reflect.Value, error) {
+	target := t
+	if t.Kind() == reflect.Interface {
+		switch c.Kind() {
+		case constant.String:
+			target = builtinTypes["string"]
+		case constant.Bool:
+			target = builtinTypes["bool"]
+		case constant.Int:
+			target = builtinTypes["int"]
+		case constant.Float:
+			target = builtinTypes["float64"]
+		}
+	}
+	v := reflect.New(target).Elem()
+	switch target.Kind() {
+	case reflect.String:
+		if c.Kind() == constant.String {
+			v.SetString(constant.StringVal(c))
+			return e.assignable(node, v, t)
+		}
+	case reflect.Bool:
+		if c.Kind() == constant.Bool {
+			v.SetBool(constant.BoolVal(c))
+			return e.assignable(node, v, t)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, exact := constant.Int64Val(constant.ToInt(c)); exact && !v.OverflowInt(n) {
+			v.SetInt(n)
+			return e.assignable(node, v, t)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, exact := constant.Uint64Val(constant.ToInt(c)); exact && !v.OverflowUint(n) {
+			v.SetUint(n)
+			return e.assignable(node, v, t)
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := constant.Float64Val(constant.ToFloat(c)); ok || c.Kind() == constant.Int || c.Kind() == constant.Float {
+			v.SetFloat(f)
+			return e.assignable(node, v, t)
+		}
+	}
+	return reflect.Value{}, e.errorf(node, "cannot use %s as %s", c, t)
+}
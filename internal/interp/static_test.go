@@ -0,0 +1,176 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package interp_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ocomsoft/makemigrations/internal/interp"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+const file0003Data = `package main
+
+import (
+	"time"
+
+	mg "github.com/ocomsoft/makemigrations/migrate"
+)
+
+func init() {
+	mg.Register(&mg.Migration{
+		Name:             "0003_seed_roles",
+		Dependencies:     []string{"0002_add_index"},
+		StatementTimeout: 90 * time.Second,
+		Operations: []mg.Operation{
+			&mg.UpsertData{
+				Table:        "roles",
+				ConflictKeys: []string{"id"},
+				Rows: []map[string]any{
+					{"id": 1, "name": "admin", "weight": 1.5, "active": true, "note": nil},
+					{"id": -2, "name": "read" + "er", "weight": 3 / 2, "active": false, "note": "x"},
+				},
+			},
+			&mg.RunSQL{ForwardSQL: "SELECT 1", BackwardSQL: "SELECT 2"},
+		},
+	})
+}
+`
+
+const file0004Helper = `package main
+
+import (
+	"strings"
+
+	m "github.com/ocomsoft/makemigrations/migrate"
+)
+
+func init() {
+	m.Register(&m.Migration{
+		Name:         "0004_helper",
+		Dependencies: []string{"0003_seed_roles"},
+		Operations: []m.Operation{
+			&m.RunSQL{ForwardSQL: strings.ToUpper("select 1")},
+		},
+	})
+}
+`
+
+func writeStaticMigrations(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	mustWrite(t, filepath.Join(dir, "0001_initial.go"), file0001)
+	mustWrite(t, filepath.Join(dir, "0002_add_index.go"), file0002)
+	mustWrite(t, filepath.Join(dir, "0003_seed_roles.go"), file0003Data)
+	mustWrite(t, filepath.Join(dir, "0004_helper.go"), file0004Helper)
+	mustWrite(t, filepath.Join(dir, "main.go"), fileMain)
+	return dir
+}
+
+func TestLoadRegistryAST_MatchesYaegi(t *testing.T) {
+	dir := writeStaticMigrations(t)
+
+	static, fallback, err := interp.LoadRegistryAST(dir)
+	if err != nil {
+		t.Fatalf("LoadRegistryAST: %v", err)
+	}
+	if len(fallback) != 1 || filepath.Base(fallback[0]) != "0004_helper.go" {
+		t.Errorf("expected only 0004_helper.go to fall back, got %v", fallback)
+	}
+	interpreted, err := interp.LoadRegistry(dir)
+	if err != nil {
+		t.Fatalf("LoadRegistry: %v", err)
+	}
+	if !reflect.DeepEqual(static.All(), interpreted.All()) {
+		t.Errorf("static and interpreted migrations differ:\nstatic: %#v\nyaegi:  %#v", static.All(), interpreted.All())
+	}
+
+	mig, ok := static.Get("0003_seed_roles")
+	if !ok {
+		t.Fatal("0003_seed_roles not loaded")
+	}
+	if mig.StatementTimeout != 90*time.Second {
+		t.Errorf("expected a 90s statement timeout, got %v", mig.StatementTimeout)
+	}
+	upsert := mig.Operations[0].(*migrate.UpsertData)
+	want := map[string]any{"id": -2, "name": "reader", "weight": 1, "active": false, "note": "x"}
+	if !reflect.DeepEqual(upsert.Rows[1], want) {
+		t.Errorf("unexpected row %#v", upsert.Rows[1])
+	}
+}
+
+func TestLoadRegistryAST_Fallback(t *testing.T) {
+	cases := map[string]string{
+		"helper func": strings.Replace(file0001, "func init() {", "func table() string { return \"users\" }\n\nfunc init() {", 1),
+		"variable":    strings.Replace(file0001, "func init() {", "var name = \"0001_initial\"\n\nfunc init() {", 1),
+		"statement":   strings.Replace(file0001, "func init() {", "func init() {\n\t_ = 1", 1),
+		"identifier":  strings.Replace(file0001, `Length: 255`, `Length: int(255)`, 1),
+	}
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			mustWrite(t, filepath.Join(dir, "0001_initial.go"), src)
+			reg, fallback, err := interp.LoadRegistryAST(dir)
+			if err != nil {
+				t.Fatalf("LoadRegistryAST: %v", err)
+			}
+			if len(fallback) != 1 {
+				t.Errorf("expected the file to fall back to yaegi, got %v", fallback)
+			}
+			if _, ok := reg.Get("0001_initial"); !ok {
+				t.Error("0001_initial not loaded")
+			}
+		})
+	}
+}
+
+func TestLoadRegistryAST_Duplicate(t *testing.T) {
+	dir := t.TempDir()
+	mustWrite(t, filepath.Join(dir, "0001_initial.go"), file0001)
+	mustWrite(t, filepath.Join(dir, "0001_copy.go"), file0001)
+	if _, _, err := interp.LoadRegistryAST(dir); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("expected a duplicate name error, got %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeStaticMigrations(t)
+	for _, loader := range []string{"", interp.LoaderYaegi, interp.LoaderAST} {
+		reg, _, err := interp.Load(dir, loader)
+		if err != nil {
+			t.Fatalf("Load(%q): %v", loader, err)
+		}
+		if got := len(reg.All()); got != 4 {
+			t.Errorf("Load(%q): expected 4 migrations, got %d", loader, got)
+		}
+	}
+	if _, _, err := interp.Load(dir, "gopls"); err == nil {
+		t.Error("expected an unknown loader to be rejected")
+	}
+}
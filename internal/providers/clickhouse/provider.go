@@ -64,6 +64,12 @@ func (p *Provider) HistoryTableDDL() string {
 ) ENGINE = ReplacingMergeTree() ORDER BY name`
 }
 
+// AutoCommitsDDL reports that ClickHouse commits DDL statements implicitly, so
+// the runner tracks per-operation progress instead of relying on rollback.
+func (p *Provider) AutoCommitsDDL() bool {
+	return true
+}
+
 // QuoteName quotes database identifiers for ClickHouse (backticks like MySQL)
 func (p *Provider) QuoteName(name string) string {
 	return fmt.Sprintf("`%s`", name)
@@ -28,6 +28,7 @@ package mysql
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ocomsoft/makemigrations/internal/typemap"
 	"github.com/ocomsoft/makemigrations/internal/types"
@@ -64,6 +65,12 @@ func (p *Provider) HistoryTableDDL() string {
 )`
 }
 
+// AutoCommitsDDL reports that MySQL commits DDL statements implicitly, so
+// the runner tracks per-operation progress instead of relying on rollback.
+func (p *Provider) AutoCommitsDDL() bool {
+	return true
+}
+
 // QuoteName quotes database identifiers for MySQL
 func (p *Provider) QuoteName(name string) string {
 	return fmt.Sprintf("`%s`", name)
@@ -96,6 +103,54 @@ func (p *Provider) IsAlreadyExistsError(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "already exists")
 }
 
+// GenerateTimeouts sets the session's metadata lock and InnoDB row lock wait
+// timeouts, in whole seconds rounded up, after saving the current values in
+// user variables for GenerateResetTimeouts. MySQL cannot bound the run time
+// of DDL, so statement is ignored.
+func (p *Provider) GenerateTimeouts(statement, lock time.Duration) []string {
+	if lock <= 0 {
+		return nil
+	}
+	secs := int64((lock + time.Second - 1) / time.Second)
+	return []string{
+		"SET @makemigrations_lock_wait_timeout = @@session.lock_wait_timeout, " +
+			"@makemigrations_innodb_lock_wait_timeout = @@session.innodb_lock_wait_timeout",
+		fmt.Sprintf("SET SESSION lock_wait_timeout = %d", secs),
+		fmt.Sprintf("SET SESSION innodb_lock_wait_timeout = %d", secs),
+	}
+}
+
+// GenerateResetTimeouts restores the lock wait timeouts saved by
+// GenerateTimeouts.
+func (p *Provider) GenerateResetTimeouts(statement, lock time.Duration) []string {
+	if lock <= 0 {
+		return nil
+	}
+	return []string{
+		"SET SESSION lock_wait_timeout = @makemigrations_lock_wait_timeout, " +
+			"innodb_lock_wait_timeout = @makemigrations_innodb_lock_wait_timeout",
+	}
+}
+
+// IsLockTimeoutError returns true when err is MySQL error 1205.
+func (p *Provider) IsLockTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "Error 1205") || strings.Contains(msg, "Lock wait timeout exceeded")
+}
+
+// GenerateLock waits indefinitely for the named user-level lock.
+func (p *Provider) GenerateLock(key string) string {
+	return fmt.Sprintf("SELECT GET_LOCK('%s', -1)", strings.ReplaceAll(key, "'", "''"))
+}
+
+// GenerateUnlock releases the lock taken by GenerateLock.
+func (p *Provider) GenerateUnlock(key string) string {
+	return fmt.Sprintf("SELECT RELEASE_LOCK('%s')", strings.ReplaceAll(key, "'", "''"))
+}
+
 // ConvertFieldType converts YAML field type to MySQL-specific SQL type
 func (p *Provider) ConvertFieldType(field *types.Field) string {
 	// Check user-defined type mappings first
@@ -152,6 +207,28 @@ func (p *Provider) ConvertFieldType(field *types.Field) string {
 	}
 }
 
+// GenerateCast implements providers.CastProvider. MySQL's CAST accepts only
+// a few target types, so the field type is mapped onto the closest one; the
+// assignment to the new column converts the rest of the way.
+func (p *Provider) GenerateCast(expr string, field *types.Field) string {
+	switch field.Type {
+	case "integer", "bigint", "serial":
+		return fmt.Sprintf("CAST(%s AS SIGNED)", expr)
+	case "boolean":
+		return fmt.Sprintf("CAST(%s AS UNSIGNED)", expr)
+	case "float":
+		return fmt.Sprintf("CAST(%s AS DOUBLE)", expr)
+	case "decimal", "date", "time", "json", "jsonb":
+		return fmt.Sprintf("CAST(%s AS %s)", expr, p.ConvertFieldType(field))
+	case "timestamp":
+		return fmt.Sprintf("CAST(%s AS DATETIME)", expr)
+	case "bytes":
+		return fmt.Sprintf("CAST(%s AS BINARY)", expr)
+	default:
+		return fmt.Sprintf("CAST(%s AS CHAR)", expr)
+	}
+}
+
 // GetDefaultValue converts default value references to MySQL-specific values
 func (p *Provider) GetDefaultValue(defaultRef string, defaults map[string]string) (string, error) {
 	if value, exists := defaults[defaultRef]; exists {
@@ -210,7 +287,7 @@ func (p *Provider) GenerateDropTableCascade(tableName string) string {
 // The DEFAULT clause is emitted when field.Default is non-empty (already
 // resolved from symbolic keys by resolveFieldDefault before this is called).
 func (p *Provider) GenerateAddColumn(tableName string, field *types.Field) string {
-	fieldDef := fmt.Sprintf("%s %s", p.QuoteName(field.Name), p.ConvertFieldType(field))
+	fieldDef := fmt.Sprintf("%s %s%s", p.QuoteName(field.Name), p.ConvertFieldType(field), p.collateClause(field))
 
 	if field.PrimaryKey {
 		fieldDef += " PRIMARY KEY"
@@ -252,6 +329,13 @@ func (p *Provider) GenerateRenameColumn(tableName, oldName, newName string) stri
 		p.QuoteName(tableName), p.QuoteName(oldName), p.QuoteName(newName))
 }
 
+// GenerateRenameColumnWithField implements providers.FieldRenameProvider,
+// restating the column's full definition in the CHANGE statement.
+func (p *Provider) GenerateRenameColumnWithField(tableName string, field *types.Field, newName string) string {
+	return fmt.Sprintf("ALTER TABLE %s CHANGE %s %s %s;",
+		p.QuoteName(tableName), p.QuoteName(field.Name), p.QuoteName(newName), p.columnDefinition(field))
+}
+
 // GenerateCreateTable generates the CREATE TABLE SQL statement for the given table.
 func (p *Provider) GenerateCreateTable(schema *types.Schema, table *types.Table) (string, error) {
 	var fieldDefs []string
@@ -287,7 +371,18 @@ func (p *Provider) GenerateCreateTable(schema *types.Schema, table *types.Table)
 		sql.WriteString("\n")
 	}
 
-	sql.WriteString(") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;")
+	charset, collation := tableOptions(schema, table)
+	if charset == "" && collation == "" {
+		charset, collation = "utf8mb4", "utf8mb4_unicode_ci"
+	}
+	sql.WriteString(") ENGINE=InnoDB")
+	if charset != "" {
+		sql.WriteString(" DEFAULT CHARSET=" + charset)
+	}
+	if collation != "" {
+		sql.WriteString(" COLLATE=" + collation)
+	}
+	sql.WriteString(";")
 	for i := range table.Indexes {
 		sql.WriteString("\n")
 		sql.WriteString(p.GenerateCreateIndex(&table.Indexes[i], table.Name))
@@ -310,6 +405,7 @@ func (p *Provider) convertField(schema *types.Schema, field *types.Field) (strin
 	// Convert field type
 	sqlType := p.ConvertFieldType(field)
 	def.WriteString(sqlType)
+	def.WriteString(p.collateClause(field))
 
 	// Add NOT NULL constraint
 	if !field.IsNullable() {
@@ -349,32 +445,35 @@ func (p *Provider) GenerateAlterColumn(tableName string, oldField, newField *typ
 	if oldType == newType && oldField.IsNullable() == newField.IsNullable() &&
 		oldField.Default == newField.Default &&
 		oldField.AutoCreate == newField.AutoCreate &&
-		oldField.AutoUpdate == newField.AutoUpdate {
+		oldField.AutoUpdate == newField.AutoUpdate &&
+		oldField.Collation == newField.Collation && oldField.Charset == newField.Charset {
 		return "", nil
 	}
 
-	tbl := p.QuoteName(tableName)
-	col := p.QuoteName(newField.Name)
+	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s;",
+		p.QuoteName(tableName), p.QuoteName(newField.Name), p.columnDefinition(newField)), nil
+}
 
-	stmt := fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", tbl, col, newType)
-	if !newField.IsNullable() {
-		stmt += " NOT NULL"
+// columnDefinition returns the type and attributes of field as MODIFY COLUMN
+// and CHANGE restate them: type, collation, NOT NULL, DEFAULT and ON UPDATE.
+func (p *Provider) columnDefinition(field *types.Field) string {
+	def := p.ConvertFieldType(field) + p.collateClause(field)
+	if !field.IsNullable() {
+		def += " NOT NULL"
 	}
 	// AutoCreate: set DEFAULT CURRENT_TIMESTAMP for timestamp fields
-	if newField.AutoCreate && newField.Type == "timestamp" {
-		stmt += " DEFAULT CURRENT_TIMESTAMP"
-	} else if newField.Default != "" {
-		stmt += fmt.Sprintf(" DEFAULT %s", utils.FormatDefaultValue(newField.Default))
+	if field.AutoCreate && field.Type == "timestamp" {
+		def += " DEFAULT CURRENT_TIMESTAMP"
+	} else if field.Default != "" {
+		def += fmt.Sprintf(" DEFAULT %s", utils.FormatDefaultValue(field.Default))
 	}
 
 	// AutoUpdate: MySQL supports ON UPDATE CURRENT_TIMESTAMP natively
-	if newField.AutoUpdate && newField.Type == "timestamp" {
-		stmt += " ON UPDATE CURRENT_TIMESTAMP"
+	if field.AutoUpdate && field.Type == "timestamp" {
+		def += " ON UPDATE CURRENT_TIMESTAMP"
 	}
 
-	stmt += ";"
-
-	return stmt, nil
+	return def
 }
 
 // GenerateForeignKeyConstraint generates an ALTER TABLE statement to add a foreign key constraint.
@@ -399,6 +498,26 @@ func (p *Provider) GenerateDropForeignKeyConstraint(tableName, constraintName st
 	return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s;", p.QuoteName(tableName), p.QuoteName(constraintName))
 }
 
+// GenerateAddUniqueConstraint generates ALTER TABLE ... ADD CONSTRAINT ... UNIQUE.
+// MySQL always treats NULLs as distinct, so NullsNotDistinct is rejected.
+func (p *Provider) GenerateAddUniqueConstraint(tableName string, uc *types.UniqueConstraint) (string, error) {
+	if uc.NullsNotDistinct {
+		return "", fmt.Errorf("unique constraint %s: MySQL does not support NULLS NOT DISTINCT", uc.Name)
+	}
+	var quotedFields []string
+	for _, fieldName := range uc.Fields {
+		quotedFields = append(quotedFields, p.QuoteName(fieldName))
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);",
+		p.QuoteName(tableName), p.QuoteName(uc.Name), strings.Join(quotedFields, ", ")), nil
+}
+
+// GenerateDropUniqueConstraint generates ALTER TABLE ... DROP INDEX, since
+// MySQL implements unique constraints as indexes.
+func (p *Provider) GenerateDropUniqueConstraint(tableName, constraintName string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP INDEX %s;", p.QuoteName(tableName), p.QuoteName(constraintName))
+}
+
 // GenerateJunctionTable generates the CREATE TABLE SQL for a many-to-many junction table.
 func (p *Provider) GenerateJunctionTable(table1, table2 string, schema *types.Schema) (string, error) {
 	t1, t2 := table1, table2
@@ -525,3 +644,141 @@ func (p *Provider) GenerateUpsert(table string, conflictKeys []string, columns [
 		strings.Join(updates, ",\n"),
 	)
 }
+
+// GenerateCreateSequence emulates a sequence with a single-row counter table
+// holding the last issued value. When OwnedBy is set a BEFORE INSERT trigger
+// fills the owning column from the counter whenever no value is supplied.
+func (p *Provider) GenerateCreateSequence(seq *types.Sequence) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE %s (%s BIGINT NOT NULL);\n", p.QuoteName(seq.Name), p.QuoteName("value"))
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES (%d);", p.QuoteName(seq.Name), p.QuoteName("value"),
+		seq.StartValue()-seq.IncrementValue())
+	if trigger := p.sequenceTrigger(seq); trigger != "" {
+		b.WriteString("\n" + trigger)
+	}
+	return b.String()
+}
+
+// GenerateAlterSequence recreates the owned-by trigger when the increment or
+// owner changes. Start, bounds and cycle are not tracked by the emulation.
+func (p *Provider) GenerateAlterSequence(oldSeq, newSeq *types.Sequence) string {
+	if oldSeq.IncrementValue() == newSeq.IncrementValue() && oldSeq.OwnedBy == newSeq.OwnedBy {
+		return ""
+	}
+	var stmts []string
+	if oldSeq.OwnedBy != "" {
+		stmts = append(stmts, fmt.Sprintf("DROP TRIGGER IF EXISTS %s;", p.QuoteName(oldSeq.Name+"_nextval")))
+	}
+	if trigger := p.sequenceTrigger(newSeq); trigger != "" {
+		stmts = append(stmts, trigger)
+	}
+	return strings.Join(stmts, "\n")
+}
+
+// GenerateDropSequence drops the owned-by trigger (if any) and the counter table.
+func (p *Provider) GenerateDropSequence(seq *types.Sequence) string {
+	var stmts []string
+	if seq.OwnedBy != "" {
+		stmts = append(stmts, fmt.Sprintf("DROP TRIGGER IF EXISTS %s;", p.QuoteName(seq.Name+"_nextval")))
+	}
+	stmts = append(stmts, fmt.Sprintf("DROP TABLE IF EXISTS %s;", p.QuoteName(seq.Name)))
+	return strings.Join(stmts, "\n")
+}
+
+// NextValExpression returns "" — MySQL column defaults cannot read the
+// counter table, so owned columns are filled by the sequence trigger instead.
+func (p *Provider) NextValExpression(_ string) string {
+	return ""
+}
+
+// sequenceTrigger returns the BEFORE INSERT trigger that draws the owning
+// column's value from the counter table, or "" when the sequence has no owner.
+// LAST_INSERT_ID(expr) makes the increment and read atomic per connection.
+func (p *Provider) sequenceTrigger(seq *types.Sequence) string {
+	table, field, ok := seq.OwnerParts()
+	if !ok {
+		return ""
+	}
+	col := "NEW." + p.QuoteName(field)
+	return fmt.Sprintf("CREATE TRIGGER %s BEFORE INSERT ON %s FOR EACH ROW\nBEGIN\n"+
+		"  IF %s IS NULL THEN\n"+
+		"    UPDATE %s SET %s = LAST_INSERT_ID(%s + %d);\n"+
+		"    SET %s = LAST_INSERT_ID();\n"+
+		"  END IF;\nEND;",
+		p.QuoteName(seq.Name+"_nextval"), p.QuoteName(table),
+		col,
+		p.QuoteName(seq.Name), p.QuoteName("value"), p.QuoteName("value"), seq.IncrementValue(),
+		col)
+}
+
+// GenerateCreateFunction generates a CREATE FUNCTION/PROCEDURE statement.
+// MySQL has no CREATE OR REPLACE for routines, so replace drops first.
+// The body follows the signature verbatim (e.g. DETERMINISTIC BEGIN ... END).
+func (p *Provider) GenerateCreateFunction(fn *types.Function, body string, replace bool) (string, error) {
+	var b strings.Builder
+	if replace {
+		b.WriteString(p.GenerateDropFunction(fn) + "\n")
+	}
+	if fn.Procedure {
+		fmt.Fprintf(&b, "CREATE PROCEDURE %s(%s)\n%s;", p.QuoteName(fn.Name), fn.Arguments, strings.TrimSpace(body))
+	} else {
+		fmt.Fprintf(&b, "CREATE FUNCTION %s(%s) RETURNS %s\n%s;",
+			p.QuoteName(fn.Name), fn.Arguments, fn.Returns, strings.TrimSpace(body))
+	}
+	return b.String(), nil
+}
+
+// GenerateDropFunction generates a DROP FUNCTION/PROCEDURE IF EXISTS statement.
+func (p *Provider) GenerateDropFunction(fn *types.Function) string {
+	kind := "FUNCTION"
+	if fn.Procedure {
+		kind = "PROCEDURE"
+	}
+	return fmt.Sprintf("DROP %s IF EXISTS %s;", kind, p.QuoteName(fn.Name))
+}
+
+// GenerateCreateTrigger generates a row-level CREATE TRIGGER statement.
+// MySQL triggers fire on exactly one event and do not support INSTEAD OF.
+func (p *Provider) GenerateCreateTrigger(tr *types.Trigger, body string) (string, error) {
+	timing := strings.ToUpper(tr.Timing)
+	if timing == "INSTEAD OF" {
+		return "", fmt.Errorf("trigger %s: MySQL does not support INSTEAD OF triggers", tr.Name)
+	}
+	if len(tr.Events) != 1 {
+		return "", fmt.Errorf("trigger %s: MySQL triggers must have exactly one event", tr.Name)
+	}
+	if body == "" {
+		return "", fmt.Errorf("trigger %s: a mysql body is required", tr.Name)
+	}
+	return fmt.Sprintf("CREATE TRIGGER %s %s %s ON %s FOR EACH ROW\n%s;",
+		p.QuoteName(tr.Name), timing, strings.ToUpper(tr.Events[0]), p.QuoteName(tr.Table),
+		strings.TrimSuffix(strings.TrimSpace(body), ";")), nil
+}
+
+// GenerateDropTrigger generates a DROP TRIGGER IF EXISTS statement.
+func (p *Provider) GenerateDropTrigger(tr *types.Trigger) string {
+	return fmt.Sprintf("DROP TRIGGER IF EXISTS %s;", p.QuoteName(tr.Name))
+}
+
+// collateClause returns the " CHARACTER SET x COLLATE y" suffix for a text
+// column, or "" when the field sets neither.
+func (p *Provider) collateClause(field *types.Field) string {
+	var clause string
+	if field.Charset != "" {
+		clause += " CHARACTER SET " + field.Charset
+	}
+	if field.Collation != "" {
+		clause += " COLLATE " + field.Collation
+	}
+	return clause
+}
+
+// tableOptions returns the table-level charset and collation for table,
+// falling back to the schema's database-level defaults.
+func tableOptions(schema *types.Schema, table *types.Table) (charset, collation string) {
+	charset, collation = table.Charset, table.Collation
+	if charset == "" && collation == "" && schema != nil {
+		charset, collation = schema.Database.Charset, schema.Database.Collation
+	}
+	return charset, collation
+}
@@ -27,6 +27,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ocomsoft/makemigrations/internal/types"
 )
@@ -434,3 +435,114 @@ func TestProvider_GenerateAlterColumn_NoChange_WithAutoFlags(t *testing.T) {
 		t.Errorf("expected empty SQL when nothing changed, got: %s", got)
 	}
 }
+
+func TestProvider_Collation(t *testing.T) {
+	p := New()
+	schema := &types.Schema{Database: types.Database{Name: "app", Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"}}
+	table := &types.Table{Name: "users", Fields: []types.Field{
+		{Name: "email", Type: "varchar", Length: 255, Charset: "utf8mb4", Collation: "utf8mb4_bin"},
+	}}
+	got, err := p.GenerateCreateTable(schema, table)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"`email` VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin",
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+
+	table.Collation = "utf8mb4_general_ci"
+	got, err = p.GenerateCreateTable(schema, table)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(got, ") ENGINE=InnoDB COLLATE=utf8mb4_general_ci;") {
+		t.Errorf("expected table collation to override the database default in:\n%s", got)
+	}
+
+	old := types.Field{Name: "email", Type: "varchar", Length: 255}
+	alter, err := p.GenerateAlterColumn("users", &old, &table.Fields[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "ALTER TABLE `users` MODIFY COLUMN `email` VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin;"
+	if alter != want {
+		t.Errorf("got:\n%s\nwant:\n%s", alter, want)
+	}
+}
+
+func TestProvider_UniqueConstraint(t *testing.T) {
+	p := New()
+	uc := &types.UniqueConstraint{Name: "uq_users_email", Fields: []string{"tenant_id", "email"}}
+	got, err := p.GenerateAddUniqueConstraint("users", uc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "ALTER TABLE `users` ADD CONSTRAINT `uq_users_email` UNIQUE (`tenant_id`, `email`);"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := p.GenerateDropUniqueConstraint("users", "uq_users_email"); got != "ALTER TABLE `users` DROP INDEX `uq_users_email`;" {
+		t.Errorf("unexpected drop SQL: %q", got)
+	}
+
+	uc.NullsNotDistinct = true
+	if _, err := p.GenerateAddUniqueConstraint("users", uc); err == nil {
+		t.Error("expected NULLS NOT DISTINCT to be rejected")
+	}
+}
+
+func TestProvider_GenerateTimeouts(t *testing.T) {
+	p := New()
+	got := p.GenerateTimeouts(time.Minute, 1500*time.Millisecond)
+	want := []string{
+		"SET @makemigrations_lock_wait_timeout = @@session.lock_wait_timeout, " +
+			"@makemigrations_innodb_lock_wait_timeout = @@session.innodb_lock_wait_timeout",
+		"SET SESSION lock_wait_timeout = 2",
+		"SET SESSION innodb_lock_wait_timeout = 2",
+	}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	reset := p.GenerateResetTimeouts(time.Minute, 1500*time.Millisecond)
+	if len(reset) != 1 || !strings.Contains(reset[0], "lock_wait_timeout = @makemigrations_lock_wait_timeout") {
+		t.Errorf("expected the saved timeouts to be restored, got %q", reset)
+	}
+	if got := p.GenerateResetTimeouts(time.Minute, 0); got != nil {
+		t.Errorf("expected nothing to reset without a lock timeout, got %q", got)
+	}
+	if !p.IsLockTimeoutError(errors.New("Error 1205 (HY000): Lock wait timeout exceeded; try restarting transaction")) {
+		t.Error("expected error 1205 to be recognised")
+	}
+}
+
+func TestProvider_GenerateCast(t *testing.T) {
+	p := New()
+	tests := []struct {
+		field types.Field
+		want  string
+	}{
+		{types.Field{Type: "integer"}, "CAST(`c` AS SIGNED)"},
+		{types.Field{Type: "decimal", Precision: 10, Scale: 2}, "CAST(`c` AS DECIMAL(10,2))"},
+		{types.Field{Type: "timestamp"}, "CAST(`c` AS DATETIME)"},
+		{types.Field{Type: "varchar", Length: 50}, "CAST(`c` AS CHAR)"},
+	}
+	for _, tt := range tests {
+		if got := p.GenerateCast("`c`", &tt.field); got != tt.want {
+			t.Errorf("GenerateCast(%s) = %q, want %q", tt.field.Type, got, tt.want)
+		}
+	}
+}
+
+func TestProvider_GenerateRenameColumnWithField(t *testing.T) {
+	p := New()
+	notNull := false
+	field := &types.Field{Name: "name", Type: "varchar", Length: 100, Nullable: &notNull, Default: "anon"}
+	want := "ALTER TABLE `users` CHANGE `name` `name__old` VARCHAR(100) NOT NULL DEFAULT 'anon';"
+	if got := p.GenerateRenameColumnWithField("users", field, "name__old"); got != want {
+		t.Errorf("GenerateRenameColumnWithField = %q, want %q", got, want)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	_ "github.com/lib/pq" // PostgreSQL driver
 	"github.com/ocomsoft/makemigrations/internal/fkutils"
@@ -105,6 +106,51 @@ func (p *Provider) IsAlreadyExistsError(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "already exists")
 }
 
+// GenerateTimeouts returns SET LOCAL statements, which last until the
+// migration's transaction ends.
+func (p *Provider) GenerateTimeouts(statement, lock time.Duration) []string {
+	var stmts []string
+	if statement > 0 {
+		stmts = append(stmts, fmt.Sprintf("SET LOCAL statement_timeout = '%dms'", ceilMillis(statement)))
+	}
+	if lock > 0 {
+		stmts = append(stmts, fmt.Sprintf("SET LOCAL lock_timeout = '%dms'", ceilMillis(lock)))
+	}
+	return stmts
+}
+
+// GenerateResetTimeouts returns nil: SET LOCAL settings end with the
+// transaction.
+func (p *Provider) GenerateResetTimeouts(statement, lock time.Duration) []string {
+	return nil
+}
+
+// IsLockTimeoutError returns true when err is a lock_timeout cancellation
+// (SQLSTATE 55P03).
+func (p *Provider) IsLockTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "lock timeout") || strings.Contains(msg, "55P03")
+}
+
+// GenerateLock takes a session-level advisory lock keyed on a hash of key.
+func (p *Provider) GenerateLock(key string) string {
+	return fmt.Sprintf("SELECT pg_advisory_lock(hashtext('%s'))", strings.ReplaceAll(key, "'", "''"))
+}
+
+// GenerateUnlock releases the advisory lock taken by GenerateLock.
+func (p *Provider) GenerateUnlock(key string) string {
+	return fmt.Sprintf("SELECT pg_advisory_unlock(hashtext('%s'))", strings.ReplaceAll(key, "'", "''"))
+}
+
+// ceilMillis returns d in whole milliseconds, rounded up so that a positive
+// duration never becomes 0 (which PostgreSQL reads as "no timeout").
+func ceilMillis(d time.Duration) int64 {
+	return int64((d + time.Millisecond - 1) / time.Millisecond)
+}
+
 // ConvertFieldType converts YAML field type to PostgreSQL-specific SQL type
 func (p *Provider) ConvertFieldType(field *types.Field) string {
 	// Check user-defined type mappings first
@@ -177,6 +223,15 @@ func (p *Provider) getForeignKeyType(schema *types.Schema, referencedTableName s
 	return p.fkResolver.GetForeignKeyType(schema, referencedTableName)
 }
 
+// GenerateCast implements providers.CastProvider using PostgreSQL's ::
+// operator, the same conversion ALTER COLUMN ... TYPE ... USING applies.
+func (p *Provider) GenerateCast(expr string, field *types.Field) string {
+	if field.Type == "serial" {
+		return expr + "::INTEGER"
+	}
+	return fmt.Sprintf("%s::%s", expr, p.ConvertFieldType(field))
+}
+
 // GetDefaultValue converts default value references to PostgreSQL-specific values
 func (p *Provider) GetDefaultValue(defaultRef string, defaults map[string]string) (string, error) {
 	if value, exists := defaults[defaultRef]; exists {
@@ -233,7 +288,7 @@ func (p *Provider) GenerateDropTableCascade(tableName string) string {
 // The DEFAULT clause is emitted when field.Default is non-empty (already
 // resolved from symbolic keys by resolveFieldDefault before this is called).
 func (p *Provider) GenerateAddColumn(tableName string, field *types.Field) string {
-	fieldDef := fmt.Sprintf("%s %s", p.QuoteName(field.Name), p.ConvertFieldType(field))
+	fieldDef := fmt.Sprintf("%s %s%s", p.QuoteName(field.Name), p.ConvertFieldType(field), p.collateClause(field))
 
 	if field.PrimaryKey {
 		fieldDef += " PRIMARY KEY"
@@ -342,6 +397,7 @@ func (p *Provider) convertField(schema *types.Schema, field *types.Field) (strin
 	// Convert field type with schema context for foreign keys
 	sqlType := p.ConvertFieldTypeWithSchema(schema, field)
 	def.WriteString(sqlType)
+	def.WriteString(p.collateClause(field))
 
 	// Add NOT NULL constraint
 	if !field.IsNullable() {
@@ -453,11 +509,16 @@ func (p *Provider) GenerateAlterColumn(tableName string, oldField, newField *typ
 	tbl := p.QuoteName(tableName)
 	col := p.QuoteName(newField.Name)
 
-	// Type change
-	if p.ConvertFieldType(oldField) != p.ConvertFieldType(newField) {
+	// Type or collation change. Dropping a collation resets the column to
+	// the database default.
+	if p.ConvertFieldType(oldField) != p.ConvertFieldType(newField) || oldField.Collation != newField.Collation {
+		collate := p.collateClause(newField)
+		if collate == "" && oldField.Collation != "" {
+			collate = ` COLLATE "default"`
+		}
 		stmts = append(stmts, fmt.Sprintf(
-			"ALTER TABLE %s ALTER COLUMN %s TYPE %s;",
-			tbl, col, p.ConvertFieldType(newField)))
+			"ALTER TABLE %s ALTER COLUMN %s TYPE %s%s;",
+			tbl, col, p.ConvertFieldType(newField), collate))
 	}
 
 	// Nullability change
@@ -527,6 +588,26 @@ func (p *Provider) GenerateDropForeignKeyConstraint(tableName, constraintName st
 	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", p.QuoteName(tableName), p.QuoteName(constraintName))
 }
 
+// GenerateAddUniqueConstraint generates ALTER TABLE ... ADD CONSTRAINT ... UNIQUE.
+// NullsNotDistinct requires PostgreSQL 15 or later.
+func (p *Provider) GenerateAddUniqueConstraint(tableName string, uc *types.UniqueConstraint) (string, error) {
+	var quotedFields []string
+	for _, fieldName := range uc.Fields {
+		quotedFields = append(quotedFields, p.QuoteName(fieldName))
+	}
+	nulls := ""
+	if uc.NullsNotDistinct {
+		nulls = " NULLS NOT DISTINCT"
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE%s (%s);",
+		p.QuoteName(tableName), p.QuoteName(uc.Name), nulls, strings.Join(quotedFields, ", ")), nil
+}
+
+// GenerateDropUniqueConstraint generates ALTER TABLE ... DROP CONSTRAINT.
+func (p *Provider) GenerateDropUniqueConstraint(tableName, constraintName string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", p.QuoteName(tableName), p.QuoteName(constraintName))
+}
+
 // GenerateJunctionTable generates the CREATE TABLE SQL for a many-to-many junction table.
 func (p *Provider) GenerateJunctionTable(table1, table2 string, schema *types.Schema) (string, error) {
 	t1, t2 := table1, table2
@@ -630,7 +711,12 @@ func (p *Provider) GetDatabaseSchema(connectionString string) (*types.Schema, er
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
+	return p.InspectSchema(db)
+}
 
+// InspectSchema extracts the tables, columns and indexes of the public
+// schema through db.
+func (p *Provider) InspectSchema(db *sql.DB) (*types.Schema, error) {
 	schema := &types.Schema{
 		Database: types.Database{
 			Name:             "extracted_schema",
@@ -724,6 +810,7 @@ func (p *Provider) extractFields(db *sql.DB, tableName string) ([]types.Field, e
 			c.numeric_scale,
 			c.is_nullable,
 			c.column_default,
+			c.collation_name,
 			CASE WHEN pk.column_name IS NOT NULL THEN true ELSE false END as is_primary_key
 		FROM information_schema.columns c
 		LEFT JOIN (
@@ -756,10 +843,11 @@ func (p *Provider) extractFields(db *sql.DB, tableName string) ([]types.Field, e
 			numScale      sql.NullInt64
 			isNullable    string
 			columnDefault sql.NullString
+			collation     sql.NullString
 			isPrimaryKey  bool
 		)
 
-		if err := rows.Scan(&columnName, &dataType, &maxLength, &numPrecision, &numScale, &isNullable, &columnDefault, &isPrimaryKey); err != nil {
+		if err := rows.Scan(&columnName, &dataType, &maxLength, &numPrecision, &numScale, &isNullable, &columnDefault, &collation, &isPrimaryKey); err != nil {
 			return nil, fmt.Errorf("failed to scan field data: %w", err)
 		}
 
@@ -782,6 +870,11 @@ func (p *Provider) extractFields(db *sql.DB, tableName string) ([]types.Field, e
 			field.Scale = int(numScale.Int64)
 		}
 
+		// collation_name is NULL for columns using the database default
+		if collation.Valid && field.SupportsCollation() {
+			field.Collation = collation.String
+		}
+
 		// Handle default values
 		if columnDefault.Valid {
 			field.Default = p.convertSQLDefaultToYAML(columnDefault.String)
@@ -1091,3 +1184,157 @@ func (p *Provider) GenerateUpsert(table string, conflictKeys []string, columns [
 
 	return sb.String()
 }
+
+// GenerateCreateSequence generates a native CREATE SEQUENCE statement.
+// OWNED BY is included when the sequence declares an owning column.
+func (p *Provider) GenerateCreateSequence(seq *types.Sequence) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE SEQUENCE %s INCREMENT BY %d", p.QuoteName(seq.Name), seq.IncrementValue())
+	if lo, ok := seq.MinBound(); ok {
+		fmt.Fprintf(&b, " MINVALUE %d", lo)
+	}
+	if seq.MaxValue != 0 {
+		fmt.Fprintf(&b, " MAXVALUE %d", seq.MaxValue)
+	}
+	fmt.Fprintf(&b, " START WITH %d", seq.StartValue())
+	if seq.Cycle {
+		b.WriteString(" CYCLE")
+	}
+	if table, field, ok := seq.OwnerParts(); ok {
+		fmt.Fprintf(&b, " OWNED BY %s.%s", p.QuoteName(table), p.QuoteName(field))
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+// GenerateAlterSequence generates an ALTER SEQUENCE statement containing only
+// the clauses that differ between oldSeq and newSeq. START WITH only affects a
+// later RESTART, so the current position of the sequence is never reset.
+func (p *Provider) GenerateAlterSequence(oldSeq, newSeq *types.Sequence) string {
+	var clauses []string
+	if oldSeq.IncrementValue() != newSeq.IncrementValue() {
+		clauses = append(clauses, fmt.Sprintf("INCREMENT BY %d", newSeq.IncrementValue()))
+	}
+	oldMin, oldHasMin := oldSeq.MinBound()
+	newMin, newHasMin := newSeq.MinBound()
+	if oldMin != newMin || oldHasMin != newHasMin {
+		if newHasMin {
+			clauses = append(clauses, fmt.Sprintf("MINVALUE %d", newMin))
+		} else {
+			clauses = append(clauses, "NO MINVALUE")
+		}
+	}
+	if oldSeq.MaxValue != newSeq.MaxValue {
+		if newSeq.MaxValue == 0 {
+			clauses = append(clauses, "NO MAXVALUE")
+		} else {
+			clauses = append(clauses, fmt.Sprintf("MAXVALUE %d", newSeq.MaxValue))
+		}
+	}
+	if oldSeq.StartValue() != newSeq.StartValue() {
+		clauses = append(clauses, fmt.Sprintf("START WITH %d", newSeq.StartValue()))
+	}
+	if oldSeq.Cycle != newSeq.Cycle {
+		if newSeq.Cycle {
+			clauses = append(clauses, "CYCLE")
+		} else {
+			clauses = append(clauses, "NO CYCLE")
+		}
+	}
+	if oldSeq.OwnedBy != newSeq.OwnedBy {
+		if table, field, ok := newSeq.OwnerParts(); ok {
+			clauses = append(clauses, fmt.Sprintf("OWNED BY %s.%s", p.QuoteName(table), p.QuoteName(field)))
+		} else {
+			clauses = append(clauses, "OWNED BY NONE")
+		}
+	}
+	if len(clauses) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ALTER SEQUENCE %s %s;", p.QuoteName(newSeq.Name), strings.Join(clauses, " "))
+}
+
+// GenerateDropSequence generates a DROP SEQUENCE statement. IF EXISTS is used
+// because PostgreSQL drops owned sequences together with their owning table.
+func (p *Provider) GenerateDropSequence(seq *types.Sequence) string {
+	return fmt.Sprintf("DROP SEQUENCE IF EXISTS %s;", p.QuoteName(seq.Name))
+}
+
+// NextValExpression returns the nextval() call for the named sequence. The
+// name is quoted as an identifier, so its case is kept, inside an escaped
+// string literal.
+func (p *Provider) NextValExpression(sequenceName string) string {
+	return fmt.Sprintf("nextval('%s')", strings.ReplaceAll(p.QuoteName(sequenceName), "'", "''"))
+}
+
+// GenerateCreateFunction generates a CREATE [OR REPLACE] FUNCTION/PROCEDURE
+// statement with the body dollar-quoted. Language defaults to plpgsql.
+func (p *Provider) GenerateCreateFunction(fn *types.Function, body string, replace bool) (string, error) {
+	kind := "FUNCTION"
+	if fn.Procedure {
+		kind = "PROCEDURE"
+	}
+	create := "CREATE"
+	if replace {
+		create = "CREATE OR REPLACE"
+	}
+	language := fn.Language
+	if language == "" {
+		language = "plpgsql"
+	}
+	returns := ""
+	if !fn.Procedure {
+		returns = " RETURNS " + fn.Returns
+	}
+	return fmt.Sprintf("%s %s %s(%s)%s LANGUAGE %s AS $$\n%s\n$$;",
+		create, kind, p.QuoteName(fn.Name), fn.Arguments, returns, language, strings.TrimSpace(body)), nil
+}
+
+// GenerateDropFunction generates a DROP FUNCTION/PROCEDURE statement including
+// the argument list so the correct overload is targeted.
+func (p *Provider) GenerateDropFunction(fn *types.Function) string {
+	kind := "FUNCTION"
+	if fn.Procedure {
+		kind = "PROCEDURE"
+	}
+	return fmt.Sprintf("DROP %s IF EXISTS %s(%s);", kind, p.QuoteName(fn.Name), fn.Arguments)
+}
+
+// GenerateCreateTrigger generates a CREATE TRIGGER statement that executes
+// the trigger's function. PostgreSQL triggers cannot have inline bodies.
+func (p *Provider) GenerateCreateTrigger(tr *types.Trigger, _ string) (string, error) {
+	if tr.Function == "" {
+		return "", fmt.Errorf("trigger %s: PostgreSQL triggers must reference a function", tr.Name)
+	}
+	forEach := strings.ToUpper(tr.ForEach)
+	if forEach == "" {
+		forEach = "ROW"
+	}
+	events := make([]string, len(tr.Events))
+	for i, ev := range tr.Events {
+		events[i] = strings.ToUpper(ev)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TRIGGER %s %s %s ON %s FOR EACH %s",
+		p.QuoteName(tr.Name), strings.ToUpper(tr.Timing), strings.Join(events, " OR "), p.QuoteName(tr.Table), forEach)
+	if tr.When != "" {
+		fmt.Fprintf(&b, " WHEN (%s)", tr.When)
+	}
+	fmt.Fprintf(&b, " EXECUTE FUNCTION %s();", p.QuoteName(tr.Function))
+	return b.String(), nil
+}
+
+// GenerateDropTrigger generates a DROP TRIGGER ... ON table statement.
+func (p *Provider) GenerateDropTrigger(tr *types.Trigger) string {
+	return fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s;", p.QuoteName(tr.Name), p.QuoteName(tr.Table))
+}
+
+// collateClause returns the ` COLLATE "name"` suffix for a text column, or ""
+// when no collation is set. Charset is ignored: PostgreSQL encodings are
+// fixed per database.
+func (p *Provider) collateClause(field *types.Field) string {
+	if field.Collation == "" {
+		return ""
+	}
+	return " COLLATE " + p.QuoteName(field.Collation)
+}
@@ -27,6 +27,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ocomsoft/makemigrations/internal/types"
 )
@@ -596,3 +597,60 @@ func TestProvider_GenerateAlterColumn_AutoCreate_NoChange(t *testing.T) {
 		t.Errorf("expected empty SQL when nothing changed, got: %s", got)
 	}
 }
+
+func TestProvider_Collation(t *testing.T) {
+	p := New()
+	field := &types.Field{Name: "email", Type: "varchar", Length: 255, Collation: "und-x-icu", Charset: "utf8"}
+	got := p.GenerateAddColumn("users", field)
+	want := `ALTER TABLE "users" ADD COLUMN "email" VARCHAR(255) COLLATE "und-x-icu";`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	old := &types.Field{Name: "email", Type: "varchar", Length: 255}
+	got, err := p.GenerateAlterColumn("users", old, field)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = `ALTER TABLE "users" ALTER COLUMN "email" TYPE VARCHAR(255) COLLATE "und-x-icu";`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	got, err = p.GenerateAlterColumn("users", field, old)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = `ALTER TABLE "users" ALTER COLUMN "email" TYPE VARCHAR(255) COLLATE "default";`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestProvider_GenerateTimeouts(t *testing.T) {
+	p := New()
+	got := p.GenerateTimeouts(30*time.Second, 1500*time.Microsecond)
+	want := []string{"SET LOCAL statement_timeout = '30000ms'", "SET LOCAL lock_timeout = '2ms'"}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := p.GenerateTimeouts(0, 0); len(got) != 0 {
+		t.Errorf("expected no statements for zero timeouts, got %q", got)
+	}
+	if !p.IsLockTimeoutError(errors.New("pq: canceling statement due to lock timeout")) {
+		t.Error("expected lock timeout to be recognised")
+	}
+	if p.IsLockTimeoutError(errors.New("pq: canceling statement due to statement timeout")) {
+		t.Error("statement timeout must not be treated as a lock timeout")
+	}
+}
+
+func TestProvider_GenerateLock(t *testing.T) {
+	p := New()
+	if got, want := p.GenerateLock("makemigrations:o'neil"), "SELECT pg_advisory_lock(hashtext('makemigrations:o''neil'))"; got != want {
+		t.Errorf("GenerateLock = %q, want %q", got, want)
+	}
+	if got, want := p.GenerateUnlock("makemigrations"), "SELECT pg_advisory_unlock(hashtext('makemigrations'))"; got != want {
+		t.Errorf("GenerateUnlock = %q, want %q", got, want)
+	}
+}
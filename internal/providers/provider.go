@@ -24,6 +24,9 @@ SOFTWARE.
 package providers
 
 import (
+	"database/sql"
+	"time"
+
 	"github.com/ocomsoft/makemigrations/internal/types"
 )
 
@@ -64,6 +67,8 @@ type Provider interface {
 	Placeholder(n int) string
 	// HistoryTableDDL returns the CREATE TABLE IF NOT EXISTS statement for the
 	// makemigrations_history migration-tracking table using this provider's SQL dialect.
+	// It only needs the name and applied_at columns; the migrate recorder adds
+	// the duration, batch and run-metadata columns with GenerateAddColumn.
 	HistoryTableDDL() string
 	// IsNotFoundError returns true when err indicates that a DROP operation
 	// targeted an object that does not exist in the database.
@@ -117,3 +122,141 @@ type Provider interface {
 type TableRecreationProvider interface {
 	GenerateAlterColumnWithTable(currentTable *types.Table, fromField, toField *types.Field) (string, error)
 }
+
+// CastProvider is an optional interface implemented by providers with their
+// own syntax for converting a value to a column's type. AlterField with
+// Method "new_column" uses it to copy the old column into the new one;
+// providers without it get CAST(expr AS type).
+type CastProvider interface {
+	// GenerateCast returns an expression converting expr to field's type.
+	GenerateCast(expr string, field *types.Field) string
+}
+
+// ColumnCopyProvider is an optional interface implemented by providers that
+// implement TableRecreationProvider. It recreates the table like
+// GenerateAlterColumnWithTable but converts the altered column's values with
+// an explicit cast while copying the rows, for AlterField with Method
+// "new_column".
+type ColumnCopyProvider interface {
+	GenerateCopyColumnWithTable(currentTable *types.Table, fromField, toField *types.Field) (string, error)
+}
+
+// FieldRenameProvider is an optional interface implemented by providers whose
+// column rename must restate the column definition (MySQL and TiDB's CHANGE).
+// RenameField, and AlterField with Method "new_column", use it so the renamed
+// column keeps its type; GenerateRenameColumn is used otherwise.
+type FieldRenameProvider interface {
+	GenerateRenameColumnWithField(tableName string, field *types.Field, newName string) string
+}
+
+// AutoCommitDDLProvider is an optional interface implemented by providers
+// whose DDL statements commit implicitly, so a migration cannot be rolled
+// back as a unit (MySQL, TiDB, StarRocks, ClickHouse, Vertica). The runner
+// applies each operation of such a migration in its own transaction and
+// records per-operation progress so a failed migration can resume.
+type AutoCommitDDLProvider interface {
+	// AutoCommitsDDL reports whether DDL statements commit implicitly.
+	AutoCommitsDDL() bool
+}
+
+// TimeoutProvider is an optional interface implemented by providers that can
+// bound how long migration statements run and wait for locks. The runner
+// executes the generated statements at the start of each migration's
+// transaction, restores session settings once the migration's connection is
+// done with, and retries migrations that fail with a lock timeout.
+type TimeoutProvider interface {
+	// GenerateTimeouts returns the statements that apply the timeouts to the
+	// current transaction, or to the session where the database has no
+	// transaction-scoped setting. A zero duration leaves that timeout
+	// unchanged, as does one the database cannot enforce.
+	GenerateTimeouts(statement, lock time.Duration) []string
+	// GenerateResetTimeouts returns the statements that undo session
+	// settings made by GenerateTimeouts with the same arguments, so they do
+	// not carry over to later users of the pooled connection. It returns nil
+	// when the settings end with the transaction.
+	GenerateResetTimeouts(statement, lock time.Duration) []string
+	// IsLockTimeoutError reports whether err was caused by a statement giving
+	// up waiting for a lock.
+	IsLockTimeoutError(err error) bool
+}
+
+// SchemaInspector is an optional interface implemented by providers that can
+// read the live schema through an already open connection, unlike
+// GetDatabaseSchema which opens its own. Field types are the database's
+// declared column types rather than makemigrations types, so the result is
+// suited to comparing two snapshots of the same database.
+type SchemaInspector interface {
+	InspectSchema(db *sql.DB) (*types.Schema, error)
+}
+
+// MigrationLockProvider is an optional interface implemented by providers
+// with session-level named locks. The runner holds the lock on a dedicated
+// connection while migrating a target so two runs cannot migrate the same
+// database or schema at once.
+type MigrationLockProvider interface {
+	// GenerateLock returns a statement that blocks until the session holds
+	// the lock named key.
+	GenerateLock(key string) string
+	// GenerateUnlock returns a statement that releases the lock named key.
+	GenerateUnlock(key string) string
+}
+
+// SequenceProvider is an optional interface implemented by providers that can
+// manage standalone sequences. PostgreSQL, SQL Server and TiDB use native
+// CREATE SEQUENCE; MySQL and SQLite emulate a sequence with a single-row
+// counter table named after it.
+//
+// The CreateSequence, AlterSequence and DropSequence operations return an
+// error when the active provider does not implement this interface.
+type SequenceProvider interface {
+	// GenerateCreateSequence returns the SQL that creates the sequence and,
+	// when OwnedBy is set, ties it to the owning column.
+	GenerateCreateSequence(seq *types.Sequence) string
+	// GenerateAlterSequence returns the SQL that moves a sequence from
+	// oldSeq to newSeq, or "" when nothing the provider tracks has changed.
+	GenerateAlterSequence(oldSeq, newSeq *types.Sequence) string
+	// GenerateDropSequence returns the SQL that removes the sequence and any
+	// objects created alongside it (e.g. emulation triggers).
+	GenerateDropSequence(seq *types.Sequence) string
+	// NextValExpression returns the column default expression that draws the
+	// next value from the named sequence, or "" when the provider cannot use
+	// a sequence in a column default (the owned-by trigger fills it instead).
+	NextValExpression(sequenceName string) string
+}
+
+// FunctionProvider is an optional interface implemented by providers that can
+// manage stored functions and procedures. body is the provider-native routine
+// body already selected for the active database and is emitted verbatim;
+// the per-database fn.Body map is not consulted.
+type FunctionProvider interface {
+	// GenerateCreateFunction returns the SQL that creates fn. When replace is
+	// true the statement overwrites an existing routine of the same name
+	// (CREATE OR REPLACE, CREATE OR ALTER, or DROP + CREATE).
+	GenerateCreateFunction(fn *types.Function, body string, replace bool) (string, error)
+	// GenerateDropFunction returns the SQL that drops fn if it exists.
+	GenerateDropFunction(fn *types.Function) string
+}
+
+// TriggerProvider is an optional interface implemented by providers that can
+// manage table triggers. As with FunctionProvider, body is the active
+// database's trigger body (empty for function-based PostgreSQL triggers).
+type TriggerProvider interface {
+	// GenerateCreateTrigger returns the SQL that creates tr, or an error when
+	// the trigger uses timing or events the provider does not support.
+	GenerateCreateTrigger(tr *types.Trigger, body string) (string, error)
+	// GenerateDropTrigger returns the SQL that drops tr if it exists.
+	GenerateDropTrigger(tr *types.Trigger) string
+}
+
+// UniqueConstraintProvider is an optional interface implemented by providers
+// that can manage named UNIQUE constraints separately from unique indexes.
+// SQLite cannot add constraints to an existing table and backs them with a
+// unique index of the same name instead.
+type UniqueConstraintProvider interface {
+	// GenerateAddUniqueConstraint returns the SQL that adds uc to tableName, or
+	// an error when the constraint uses options the provider does not support
+	// (e.g. NullsNotDistinct).
+	GenerateAddUniqueConstraint(tableName string, uc *types.UniqueConstraint) (string, error)
+	// GenerateDropUniqueConstraint returns the SQL that removes the named constraint.
+	GenerateDropUniqueConstraint(tableName, constraintName string) string
+}
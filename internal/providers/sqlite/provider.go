@@ -26,8 +26,10 @@ SOFTWARE.
 package sqlite
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ocomsoft/makemigrations/internal/typemap"
 	"github.com/ocomsoft/makemigrations/internal/types"
@@ -99,6 +101,36 @@ func (p *Provider) IsAlreadyExistsError(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "already exists")
 }
 
+// GenerateTimeouts sets the connection's busy timeout, which is how long
+// SQLite waits for another connection's lock. SQLite has no statement
+// timeout, so statement is ignored.
+func (p *Provider) GenerateTimeouts(statement, lock time.Duration) []string {
+	if lock <= 0 {
+		return nil
+	}
+	ms := int64((lock + time.Millisecond - 1) / time.Millisecond)
+	return []string{fmt.Sprintf("PRAGMA busy_timeout = %d", ms)}
+}
+
+// GenerateResetTimeouts restores the go-sqlite3 driver's default busy
+// timeout of five seconds. A PRAGMA value cannot be saved in SQL, so a
+// _busy_timeout set in the DSN is not restored.
+func (p *Provider) GenerateResetTimeouts(statement, lock time.Duration) []string {
+	if lock <= 0 {
+		return nil
+	}
+	return []string{"PRAGMA busy_timeout = 5000"}
+}
+
+// IsLockTimeoutError returns true when err is SQLITE_BUSY or SQLITE_LOCKED.
+func (p *Provider) IsLockTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "database table is locked")
+}
+
 // ConvertFieldType converts YAML field type to SQLite-specific SQL type
 func (p *Provider) ConvertFieldType(field *types.Field) string {
 	// Check user-defined type mappings first
@@ -194,7 +226,7 @@ func (p *Provider) GenerateDropTableCascade(tableName string) string {
 // The DEFAULT clause is emitted when field.Default is non-empty (already
 // resolved from symbolic keys by resolveFieldDefault before this is called).
 func (p *Provider) GenerateAddColumn(tableName string, field *types.Field) string {
-	fieldDef := fmt.Sprintf("%s %s", p.QuoteName(field.Name), p.ConvertFieldType(field))
+	fieldDef := fmt.Sprintf("%s %s%s", p.QuoteName(field.Name), p.ConvertFieldType(field), p.collateClause(field))
 
 	if field.PrimaryKey {
 		fieldDef += " PRIMARY KEY"
@@ -289,6 +321,7 @@ func (p *Provider) convertField(schema *types.Schema, field *types.Field) (strin
 	// Convert field type
 	sqlType := p.ConvertFieldType(field)
 	def.WriteString(sqlType)
+	def.WriteString(p.collateClause(field))
 
 	// Add NOT NULL constraint
 	if !field.IsNullable() {
@@ -321,7 +354,8 @@ func (p *Provider) GenerateAlterColumn(tableName string, oldField, newField *typ
 	oldType := p.ConvertFieldType(oldField)
 	newType := p.ConvertFieldType(newField)
 
-	if oldType == newType && oldField.IsNullable() == newField.IsNullable() && oldField.Default == newField.Default {
+	if oldType == newType && oldField.IsNullable() == newField.IsNullable() && oldField.Default == newField.Default &&
+		oldField.Collation == newField.Collation {
 		return "", nil
 	}
 
@@ -344,6 +378,22 @@ func (p *Provider) GenerateDropForeignKeyConstraint(tableName, constraintName st
 	return ""
 }
 
+// GenerateAddUniqueConstraint generates a CREATE UNIQUE INDEX named after the
+// constraint. SQLite cannot add constraints to an existing table, and a unique
+// index enforces the same rule and satisfies ON CONFLICT targets.
+// SQLite always treats NULLs as distinct, so NullsNotDistinct is rejected.
+func (p *Provider) GenerateAddUniqueConstraint(tableName string, uc *types.UniqueConstraint) (string, error) {
+	if uc.NullsNotDistinct {
+		return "", fmt.Errorf("unique constraint %s: SQLite does not support NULLS NOT DISTINCT", uc.Name)
+	}
+	return p.GenerateCreateIndex(&types.Index{Name: uc.Name, Fields: uc.Fields, Unique: true}, tableName), nil
+}
+
+// GenerateDropUniqueConstraint drops the unique index backing the constraint.
+func (p *Provider) GenerateDropUniqueConstraint(tableName, constraintName string) string {
+	return p.GenerateDropIndex(constraintName, tableName)
+}
+
 // GenerateJunctionTable generates the CREATE TABLE SQL for a many-to-many junction table.
 func (p *Provider) GenerateJunctionTable(table1, table2 string, schema *types.Schema) (string, error) {
 	t1, t2 := table1, table2
@@ -442,10 +492,31 @@ func (p *Provider) GenerateAlterColumnWithTable(currentTable *types.Table, fromF
 	// No-op if the effective column definition has not changed.
 	if p.ConvertFieldType(fromField) == p.ConvertFieldType(toField) &&
 		fromField.IsNullable() == toField.IsNullable() &&
-		fromField.Default == toField.Default {
+		fromField.Default == toField.Default &&
+		fromField.Collation == toField.Collation {
 		return "", nil
 	}
+	return p.recreateTable(currentTable, fromField, toField, p.QuoteName(fromField.Name))
+}
+
+// GenerateCopyColumnWithTable implements providers.ColumnCopyProvider. It
+// recreates the table like GenerateAlterColumnWithTable, but copies the
+// altered column through an explicit CAST instead of relying on column
+// affinity.
+func (p *Provider) GenerateCopyColumnWithTable(currentTable *types.Table, fromField, toField *types.Field) (string, error) {
+	return p.recreateTable(currentTable, fromField, toField, p.GenerateCast(p.QuoteName(fromField.Name), toField))
+}
+
+// GenerateCast implements providers.CastProvider.
+func (p *Provider) GenerateCast(expr string, field *types.Field) string {
+	return fmt.Sprintf("CAST(%s AS %s)", expr, p.ConvertFieldType(field))
+}
 
+// recreateTable rebuilds currentTable with fromField replaced by toField:
+// creates a temp table, copies all rows (selecting copyExpr for the altered
+// column), drops the original, renames the temp table, and recreates any
+// indexes.
+func (p *Provider) recreateTable(currentTable *types.Table, fromField, toField *types.Field, copyExpr string) (string, error) {
 	tempName := currentTable.Name + "__migration"
 
 	// Build the new table definition, replacing the altered column.
@@ -466,26 +537,37 @@ func (p *Provider) GenerateAlterColumnWithTable(currentTable *types.Table, fromF
 	}
 
 	// Collect column names for the INSERT INTO … SELECT statement.
-	var cols []string
+	var cols, exprs []string
 	for _, f := range currentTable.Fields {
 		if f.Type != "many_to_many" {
 			cols = append(cols, p.QuoteName(f.Name))
+			if f.Name == fromField.Name {
+				exprs = append(exprs, copyExpr)
+			} else {
+				exprs = append(exprs, p.QuoteName(f.Name))
+			}
 		}
 	}
-	colList := strings.Join(cols, ", ")
 
 	var parts []string
 	parts = append(parts, createSQL)
 	parts = append(parts, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;",
-		p.QuoteName(tempName), colList, colList, p.QuoteName(currentTable.Name)))
+		p.QuoteName(tempName), strings.Join(cols, ", "), strings.Join(exprs, ", "), p.QuoteName(currentTable.Name)))
 	parts = append(parts, p.GenerateDropTable(currentTable.Name))
 	parts = append(parts, fmt.Sprintf("ALTER TABLE %s RENAME TO %s;",
 		p.QuoteName(tempName), p.QuoteName(currentTable.Name)))
 
-	// Recreate indexes on the restored table.
+	// Recreate indexes and the indexes backing unique constraints on the restored table.
 	for _, idx := range currentTable.Indexes {
 		parts = append(parts, p.GenerateCreateIndex(&idx, currentTable.Name))
 	}
+	for _, uc := range currentTable.UniqueConstraints {
+		stmt, err := p.GenerateAddUniqueConstraint(currentTable.Name, &uc)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, stmt)
+	}
 
 	return strings.Join(parts, "\n"), nil
 }
@@ -558,3 +640,232 @@ func (p *Provider) GenerateUpsert(table string, conflictKeys []string, columns [
 func (p *Provider) GetDatabaseSchema(connectionString string) (*types.Schema, error) {
 	return nil, fmt.Errorf("SQLite schema extraction not implemented yet")
 }
+
+// InspectSchema reads tables, columns, foreign keys and explicitly created
+// indexes from sqlite_master and the table_info, foreign_key_list and
+// index_list pragmas. Indexes SQLite creates for PRIMARY KEY and UNIQUE
+// column constraints are omitted.
+func (p *Provider) InspectSchema(db *sql.DB) (*types.Schema, error) {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scanning table name: %w", err)
+		}
+		names = append(names, name)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+
+	schema := &types.Schema{Tables: []types.Table{}}
+	for _, name := range names {
+		table := types.Table{Name: name}
+		if table.Fields, err = p.inspectColumns(db, name); err != nil {
+			return nil, fmt.Errorf("inspecting columns of %s: %w", name, err)
+		}
+		if table.Indexes, err = p.inspectIndexes(db, name); err != nil {
+			return nil, fmt.Errorf("inspecting indexes of %s: %w", name, err)
+		}
+		schema.Tables = append(schema.Tables, table)
+	}
+	return schema, nil
+}
+
+// inspectColumns returns the columns of table in declaration order, with
+// their declared types lower-cased.
+func (p *Provider) inspectColumns(db *sql.DB, table string) ([]types.Field, error) {
+	fks := make(map[string]*types.ForeignKey)
+	fkRows, err := db.Query(fmt.Sprintf("PRAGMA foreign_key_list(%s)", p.QuoteName(table)))
+	if err != nil {
+		return nil, err
+	}
+	for fkRows.Next() {
+		var id, seq int
+		var refTable, from, onUpdate, onDelete, match string
+		var to sql.NullString
+		if err := fkRows.Scan(&id, &seq, &refTable, &from, &to, &onUpdate, &onDelete, &match); err != nil {
+			_ = fkRows.Close()
+			return nil, err
+		}
+		fks[from] = &types.ForeignKey{Table: refTable, OnDelete: onDelete, OnUpdate: onUpdate}
+	}
+	_ = fkRows.Close()
+
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", p.QuoteName(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var fields []types.Field
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, declType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &declType, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		nullable := notNull == 0 && pk == 0
+		fields = append(fields, types.Field{
+			Name:       name,
+			Type:       strings.ToLower(declType),
+			PrimaryKey: pk > 0,
+			Nullable:   &nullable,
+			Default:    dflt.String,
+			ForeignKey: fks[name],
+		})
+	}
+	return fields, rows.Err()
+}
+
+// inspectIndexes returns the indexes created with CREATE INDEX on table.
+func (p *Provider) inspectIndexes(db *sql.DB, table string) ([]types.Index, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA index_list(%s)", p.QuoteName(table)))
+	if err != nil {
+		return nil, err
+	}
+	var indexes []types.Index
+	for rows.Next() {
+		var seq, unique, partial int
+		var name, origin string
+		if err := rows.Scan(&seq, &name, &unique, &origin, &partial); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		if origin == "c" {
+			indexes = append(indexes, types.Index{Name: name, Unique: unique == 1})
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range indexes {
+		cols, err := db.Query(fmt.Sprintf("PRAGMA index_info(%s)", p.QuoteName(indexes[i].Name)))
+		if err != nil {
+			return nil, err
+		}
+		for cols.Next() {
+			var seqno int
+			var cid sql.NullInt64
+			var col sql.NullString
+			if err := cols.Scan(&seqno, &cid, &col); err != nil {
+				_ = cols.Close()
+				return nil, err
+			}
+			indexes[i].Fields = append(indexes[i].Fields, col.String)
+		}
+		_ = cols.Close()
+	}
+	return indexes, nil
+}
+
+// GenerateCreateSequence emulates a sequence with a single-row counter table
+// holding the last issued value. When OwnedBy is set an AFTER INSERT trigger
+// fills the owning column from the counter for rows inserted without a value.
+// SQLite triggers cannot modify NEW, so the owning column must be nullable.
+func (p *Provider) GenerateCreateSequence(seq *types.Sequence) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE %s (%s INTEGER NOT NULL);\n", p.QuoteName(seq.Name), p.QuoteName("value"))
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES (%d);", p.QuoteName(seq.Name), p.QuoteName("value"),
+		seq.StartValue()-seq.IncrementValue())
+	if trigger := p.sequenceTrigger(seq); trigger != "" {
+		b.WriteString("\n" + trigger)
+	}
+	return b.String()
+}
+
+// GenerateAlterSequence recreates the owned-by trigger when the increment or
+// owner changes. Start, bounds and cycle are not tracked by the emulation.
+func (p *Provider) GenerateAlterSequence(oldSeq, newSeq *types.Sequence) string {
+	if oldSeq.IncrementValue() == newSeq.IncrementValue() && oldSeq.OwnedBy == newSeq.OwnedBy {
+		return ""
+	}
+	var stmts []string
+	if oldSeq.OwnedBy != "" {
+		stmts = append(stmts, fmt.Sprintf("DROP TRIGGER IF EXISTS %s;", p.QuoteName(oldSeq.Name+"_nextval")))
+	}
+	if trigger := p.sequenceTrigger(newSeq); trigger != "" {
+		stmts = append(stmts, trigger)
+	}
+	return strings.Join(stmts, "\n")
+}
+
+// GenerateDropSequence drops the owned-by trigger (if any) and the counter table.
+func (p *Provider) GenerateDropSequence(seq *types.Sequence) string {
+	var stmts []string
+	if seq.OwnedBy != "" {
+		stmts = append(stmts, fmt.Sprintf("DROP TRIGGER IF EXISTS %s;", p.QuoteName(seq.Name+"_nextval")))
+	}
+	stmts = append(stmts, fmt.Sprintf("DROP TABLE IF EXISTS %s;", p.QuoteName(seq.Name)))
+	return strings.Join(stmts, "\n")
+}
+
+// NextValExpression returns "" — SQLite column defaults cannot contain
+// subqueries, so owned columns are filled by the sequence trigger instead.
+func (p *Provider) NextValExpression(_ string) string {
+	return ""
+}
+
+// sequenceTrigger returns the AFTER INSERT trigger that assigns the next
+// counter value to the owning column, or "" when the sequence has no owner.
+func (p *Provider) sequenceTrigger(seq *types.Sequence) string {
+	table, field, ok := seq.OwnerParts()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT ON %s FOR EACH ROW WHEN NEW.%s IS NULL\nBEGIN\n"+
+		"  UPDATE %s SET %s = %s + %d;\n"+
+		"  UPDATE %s SET %s = (SELECT %s FROM %s) WHERE rowid = NEW.rowid;\n"+
+		"END;",
+		p.QuoteName(seq.Name+"_nextval"), p.QuoteName(table), p.QuoteName(field),
+		p.QuoteName(seq.Name), p.QuoteName("value"), p.QuoteName("value"), seq.IncrementValue(),
+		p.QuoteName(table), p.QuoteName(field), p.QuoteName("value"), p.QuoteName(seq.Name))
+}
+
+// GenerateCreateTrigger generates a row-level CREATE TRIGGER statement.
+// SQLite triggers fire on exactly one event; the body must be a complete
+// BEGIN ... END block and is emitted verbatim.
+func (p *Provider) GenerateCreateTrigger(tr *types.Trigger, body string) (string, error) {
+	if len(tr.Events) != 1 {
+		return "", fmt.Errorf("trigger %s: SQLite triggers must have exactly one event", tr.Name)
+	}
+	if strings.EqualFold(tr.ForEach, "STATEMENT") {
+		return "", fmt.Errorf("trigger %s: SQLite only supports FOR EACH ROW triggers", tr.Name)
+	}
+	if body == "" {
+		return "", fmt.Errorf("trigger %s: a sqlite body is required", tr.Name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TRIGGER %s %s %s ON %s FOR EACH ROW",
+		p.QuoteName(tr.Name), strings.ToUpper(tr.Timing), strings.ToUpper(tr.Events[0]), p.QuoteName(tr.Table))
+	if tr.When != "" {
+		fmt.Fprintf(&b, " WHEN %s", tr.When)
+	}
+	fmt.Fprintf(&b, "\n%s", strings.TrimSpace(body))
+	if !strings.HasSuffix(b.String(), ";") {
+		b.WriteString(";")
+	}
+	return b.String(), nil
+}
+
+// GenerateDropTrigger generates a DROP TRIGGER IF EXISTS statement.
+func (p *Provider) GenerateDropTrigger(tr *types.Trigger) string {
+	return fmt.Sprintf("DROP TRIGGER IF EXISTS %s;", p.QuoteName(tr.Name))
+}
+
+// collateClause returns the " COLLATE name" suffix for a text column (BINARY,
+// NOCASE, RTRIM or an application-registered collation), or "" when no
+// collation is set. Charset is ignored: SQLite text is always Unicode.
+func (p *Provider) collateClause(field *types.Field) string {
+	if field.Collation == "" {
+		return ""
+	}
+	return " COLLATE " + field.Collation
+}
@@ -285,3 +285,20 @@ func TestProvider_GenerateAddColumn_PrimaryKey(t *testing.T) {
 		t.Errorf("GenerateAddColumn() should contain quoted field name, got: %s", got)
 	}
 }
+
+func TestProvider_UniqueConstraint(t *testing.T) {
+	p := New()
+	got, err := p.GenerateAddUniqueConstraint("users", &types.UniqueConstraint{Name: "uq_users_email", Fields: []string{"email"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `CREATE UNIQUE INDEX "uq_users_email" ON "users" ("email");`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := p.GenerateDropUniqueConstraint("users", "uq_users_email"); got != `DROP INDEX "uq_users_email";` {
+		t.Errorf("unexpected drop SQL: %q", got)
+	}
+	if _, err := p.GenerateAddUniqueConstraint("users", &types.UniqueConstraint{Name: "uq", Fields: []string{"email"}, NullsNotDistinct: true}); err == nil {
+		t.Error("expected NULLS NOT DISTINCT to be rejected")
+	}
+}
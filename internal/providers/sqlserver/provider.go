@@ -28,6 +28,7 @@ package sqlserver
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ocomsoft/makemigrations/internal/typemap"
 	"github.com/ocomsoft/makemigrations/internal/types"
@@ -94,6 +95,31 @@ func (p *Provider) IsAlreadyExistsError(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "already exists")
 }
 
+// GenerateTimeouts sets the session's LOCK_TIMEOUT in milliseconds. SQL
+// Server has no server-side statement timeout, so statement is ignored.
+func (p *Provider) GenerateTimeouts(statement, lock time.Duration) []string {
+	if lock <= 0 {
+		return nil
+	}
+	ms := int64((lock + time.Millisecond - 1) / time.Millisecond)
+	return []string{fmt.Sprintf("SET LOCK_TIMEOUT %d;", ms)}
+}
+
+// GenerateResetTimeouts restores the default LOCK_TIMEOUT of -1 (wait
+// indefinitely). T-SQL variables only live for one batch, so the previous
+// value cannot be saved across statements.
+func (p *Provider) GenerateResetTimeouts(statement, lock time.Duration) []string {
+	if lock <= 0 {
+		return nil
+	}
+	return []string{"SET LOCK_TIMEOUT -1;"}
+}
+
+// IsLockTimeoutError returns true when err is error 1222.
+func (p *Provider) IsLockTimeoutError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "Lock request time out period exceeded")
+}
+
 // ConvertFieldType converts YAML field type to SQL Server-specific SQL type
 func (p *Provider) ConvertFieldType(field *types.Field) string {
 	// Check user-defined type mappings first
@@ -147,6 +173,14 @@ func (p *Provider) ConvertFieldType(field *types.Field) string {
 	}
 }
 
+// GenerateCast implements providers.CastProvider using CONVERT(type, expr).
+func (p *Provider) GenerateCast(expr string, field *types.Field) string {
+	if field.Type == "serial" {
+		return fmt.Sprintf("CONVERT(INT, %s)", expr)
+	}
+	return fmt.Sprintf("CONVERT(%s, %s)", p.ConvertFieldType(field), expr)
+}
+
 // GetDefaultValue converts default value references to SQL Server-specific values
 func (p *Provider) GetDefaultValue(defaultRef string, defaults map[string]string) (string, error) {
 	if value, exists := defaults[defaultRef]; exists {
@@ -202,7 +236,7 @@ func (p *Provider) GenerateDropTableCascade(tableName string) string {
 // The DEFAULT clause is emitted when field.Default is non-empty (already
 // resolved from symbolic keys by resolveFieldDefault before this is called).
 func (p *Provider) GenerateAddColumn(tableName string, field *types.Field) string {
-	fieldDef := fmt.Sprintf("%s %s", p.QuoteName(field.Name), p.ConvertFieldType(field))
+	fieldDef := fmt.Sprintf("%s %s%s", p.QuoteName(field.Name), p.ConvertFieldType(field), p.collateClause(field))
 
 	if field.PrimaryKey {
 		fieldDef += " PRIMARY KEY"
@@ -294,6 +328,7 @@ func (p *Provider) convertField(schema *types.Schema, field *types.Field) (strin
 	// Convert field type
 	sqlType := p.ConvertFieldType(field)
 	def.WriteString(sqlType)
+	def.WriteString(p.collateClause(field))
 
 	// Add NOT NULL constraint
 	if !field.IsNullable() {
@@ -328,7 +363,8 @@ func (p *Provider) GenerateAlterColumn(tableName string, oldField, newField *typ
 
 	if oldType == newType && oldField.IsNullable() == newField.IsNullable() &&
 		oldField.Default == newField.Default &&
-		oldField.AutoCreate == newField.AutoCreate {
+		oldField.AutoCreate == newField.AutoCreate &&
+		oldField.Collation == newField.Collation {
 		return "", nil
 	}
 
@@ -336,14 +372,15 @@ func (p *Provider) GenerateAlterColumn(tableName string, oldField, newField *typ
 	tbl := p.QuoteName(tableName)
 	col := p.QuoteName(newField.Name)
 
-	// Type or nullability change
-	if oldType != newType || oldField.IsNullable() != newField.IsNullable() {
+	// Type, nullability or collation change. Omitting COLLATE resets the
+	// column to the database default.
+	if oldType != newType || oldField.IsNullable() != newField.IsNullable() || oldField.Collation != newField.Collation {
 		nullClause := " NULL"
 		if !newField.IsNullable() {
 			nullClause = " NOT NULL"
 		}
-		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s%s;",
-			tbl, col, newType, nullClause))
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s%s%s;",
+			tbl, col, newType, p.collateClause(newField), nullClause))
 	}
 
 	// Default change — SQL Server uses ADD/DROP CONSTRAINT for defaults
@@ -399,6 +436,23 @@ func (p *Provider) GenerateDropForeignKeyConstraint(tableName, constraintName st
 	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", p.QuoteName(tableName), p.QuoteName(constraintName))
 }
 
+// GenerateAddUniqueConstraint generates ALTER TABLE ... ADD CONSTRAINT ... UNIQUE.
+// SQL Server unique constraints already allow a single NULL, so
+// NullsNotDistinct needs no extra syntax.
+func (p *Provider) GenerateAddUniqueConstraint(tableName string, uc *types.UniqueConstraint) (string, error) {
+	var quotedFields []string
+	for _, fieldName := range uc.Fields {
+		quotedFields = append(quotedFields, p.QuoteName(fieldName))
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);",
+		p.QuoteName(tableName), p.QuoteName(uc.Name), strings.Join(quotedFields, ", ")), nil
+}
+
+// GenerateDropUniqueConstraint generates ALTER TABLE ... DROP CONSTRAINT.
+func (p *Provider) GenerateDropUniqueConstraint(tableName, constraintName string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", p.QuoteName(tableName), p.QuoteName(constraintName))
+}
+
 // GenerateJunctionTable generates the CREATE TABLE SQL for a many-to-many junction table.
 func (p *Provider) GenerateJunctionTable(table1, table2 string, schema *types.Schema) (string, error) {
 	t1, t2 := table1, table2
@@ -567,3 +621,127 @@ func (p *Provider) GenerateUpsert(table string, conflictKeys []string, columns [
 func (p *Provider) GetDatabaseSchema(connectionString string) (*types.Schema, error) {
 	return nil, fmt.Errorf("SQL Server schema extraction not implemented yet")
 }
+
+// GenerateCreateSequence generates a native CREATE SEQUENCE statement for a
+// BIGINT sequence. SQL Server has no OWNED BY, so OwnedBy is ignored.
+func (p *Provider) GenerateCreateSequence(seq *types.Sequence) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE SEQUENCE %s AS BIGINT START WITH %d INCREMENT BY %d",
+		p.QuoteName(seq.Name), seq.StartValue(), seq.IncrementValue())
+	if lo, ok := seq.MinBound(); ok {
+		fmt.Fprintf(&b, " MINVALUE %d", lo)
+	}
+	if seq.MaxValue != 0 {
+		fmt.Fprintf(&b, " MAXVALUE %d", seq.MaxValue)
+	}
+	if seq.Cycle {
+		b.WriteString(" CYCLE")
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+// GenerateAlterSequence generates an ALTER SEQUENCE statement containing only
+// the clauses that differ between oldSeq and newSeq. SQL Server can only
+// change the start value by restarting the sequence, so a changed Start is
+// not applied.
+func (p *Provider) GenerateAlterSequence(oldSeq, newSeq *types.Sequence) string {
+	var clauses []string
+	if oldSeq.IncrementValue() != newSeq.IncrementValue() {
+		clauses = append(clauses, fmt.Sprintf("INCREMENT BY %d", newSeq.IncrementValue()))
+	}
+	oldMin, oldHasMin := oldSeq.MinBound()
+	newMin, newHasMin := newSeq.MinBound()
+	if oldMin != newMin || oldHasMin != newHasMin {
+		if newHasMin {
+			clauses = append(clauses, fmt.Sprintf("MINVALUE %d", newMin))
+		} else {
+			clauses = append(clauses, "NO MINVALUE")
+		}
+	}
+	if oldSeq.MaxValue != newSeq.MaxValue {
+		if newSeq.MaxValue == 0 {
+			clauses = append(clauses, "NO MAXVALUE")
+		} else {
+			clauses = append(clauses, fmt.Sprintf("MAXVALUE %d", newSeq.MaxValue))
+		}
+	}
+	if oldSeq.Cycle != newSeq.Cycle {
+		if newSeq.Cycle {
+			clauses = append(clauses, "CYCLE")
+		} else {
+			clauses = append(clauses, "NO CYCLE")
+		}
+	}
+	if len(clauses) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ALTER SEQUENCE %s %s;", p.QuoteName(newSeq.Name), strings.Join(clauses, " "))
+}
+
+// GenerateDropSequence generates a DROP SEQUENCE IF EXISTS statement.
+func (p *Provider) GenerateDropSequence(seq *types.Sequence) string {
+	return fmt.Sprintf("DROP SEQUENCE IF EXISTS %s;", p.QuoteName(seq.Name))
+}
+
+// NextValExpression returns the NEXT VALUE FOR expression for the named
+// sequence, which SQL Server accepts in a column default.
+func (p *Provider) NextValExpression(sequenceName string) string {
+	return "NEXT VALUE FOR " + p.QuoteName(sequenceName)
+}
+
+// GenerateCreateFunction generates a T-SQL CREATE [OR ALTER] FUNCTION or
+// PROCEDURE statement. The body follows AS verbatim (e.g. BEGIN ... END).
+func (p *Provider) GenerateCreateFunction(fn *types.Function, body string, replace bool) (string, error) {
+	create := "CREATE"
+	if replace {
+		create = "CREATE OR ALTER"
+	}
+	if fn.Procedure {
+		return fmt.Sprintf("%s PROCEDURE %s %s AS\n%s", create, p.QuoteName(fn.Name), fn.Arguments, strings.TrimSpace(body)), nil
+	}
+	return fmt.Sprintf("%s FUNCTION %s(%s) RETURNS %s AS\n%s",
+		create, p.QuoteName(fn.Name), fn.Arguments, fn.Returns, strings.TrimSpace(body)), nil
+}
+
+// GenerateDropFunction generates a DROP FUNCTION/PROCEDURE IF EXISTS statement.
+func (p *Provider) GenerateDropFunction(fn *types.Function) string {
+	kind := "FUNCTION"
+	if fn.Procedure {
+		kind = "PROCEDURE"
+	}
+	return fmt.Sprintf("DROP %s IF EXISTS %s;", kind, p.QuoteName(fn.Name))
+}
+
+// GenerateCreateTrigger generates a T-SQL CREATE TRIGGER statement.
+// SQL Server triggers are statement-level and support AFTER and INSTEAD OF only.
+func (p *Provider) GenerateCreateTrigger(tr *types.Trigger, body string) (string, error) {
+	timing := strings.ToUpper(tr.Timing)
+	if timing == "BEFORE" {
+		return "", fmt.Errorf("trigger %s: SQL Server does not support BEFORE triggers", tr.Name)
+	}
+	if body == "" {
+		return "", fmt.Errorf("trigger %s: a sqlserver body is required", tr.Name)
+	}
+	events := make([]string, len(tr.Events))
+	for i, ev := range tr.Events {
+		events[i] = strings.ToUpper(ev)
+	}
+	return fmt.Sprintf("CREATE TRIGGER %s ON %s %s %s AS\n%s",
+		p.QuoteName(tr.Name), p.QuoteName(tr.Table), timing, strings.Join(events, ", "), strings.TrimSpace(body)), nil
+}
+
+// GenerateDropTrigger generates a DROP TRIGGER IF EXISTS statement.
+func (p *Provider) GenerateDropTrigger(tr *types.Trigger) string {
+	return fmt.Sprintf("DROP TRIGGER IF EXISTS %s;", p.QuoteName(tr.Name))
+}
+
+// collateClause returns the " COLLATE name" suffix for a text column, or ""
+// when no collation is set. Charset is ignored: SQL Server derives the code
+// page from the collation.
+func (p *Provider) collateClause(field *types.Field) string {
+	if field.Collation == "" {
+		return ""
+	}
+	return " COLLATE " + field.Collation
+}
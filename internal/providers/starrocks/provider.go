@@ -64,6 +64,12 @@ func (p *Provider) HistoryTableDDL() string {
 ) ENGINE=OLAP DUPLICATE KEY(name) DISTRIBUTED BY HASH(name) BUCKETS 1`
 }
 
+// AutoCommitsDDL reports that StarRocks commits DDL statements implicitly, so
+// the runner tracks per-operation progress instead of relying on rollback.
+func (p *Provider) AutoCommitsDDL() bool {
+	return true
+}
+
 // QuoteName quotes database identifiers for StarRocks (backticks like MySQL)
 func (p *Provider) QuoteName(name string) string {
 	return fmt.Sprintf("`%s`", name)
@@ -26,6 +26,7 @@ package tidb
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ocomsoft/makemigrations/internal/typemap"
 	"github.com/ocomsoft/makemigrations/internal/types"
@@ -63,6 +64,12 @@ func (p *Provider) HistoryTableDDL() string {
 )`
 }
 
+// AutoCommitsDDL reports that TiDB commits DDL statements implicitly, so
+// the runner tracks per-operation progress instead of relying on rollback.
+func (p *Provider) AutoCommitsDDL() bool {
+	return true
+}
+
 // QuoteName quotes database identifiers for TiDB (same as MySQL)
 func (p *Provider) QuoteName(name string) string {
 	return fmt.Sprintf("`%s`", name)
@@ -93,6 +100,49 @@ func (p *Provider) IsAlreadyExistsError(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "already exists")
 }
 
+// GenerateTimeouts sets the session's pessimistic lock wait timeout, in whole
+// seconds rounded up, after saving the current value in a user variable for
+// GenerateResetTimeouts. TiDB cannot bound the run time of DDL, so statement
+// is ignored.
+func (p *Provider) GenerateTimeouts(statement, lock time.Duration) []string {
+	if lock <= 0 {
+		return nil
+	}
+	secs := int64((lock + time.Second - 1) / time.Second)
+	return []string{
+		"SET @makemigrations_innodb_lock_wait_timeout = @@session.innodb_lock_wait_timeout",
+		fmt.Sprintf("SET SESSION innodb_lock_wait_timeout = %d", secs),
+	}
+}
+
+// GenerateResetTimeouts restores the lock wait timeout saved by
+// GenerateTimeouts.
+func (p *Provider) GenerateResetTimeouts(statement, lock time.Duration) []string {
+	if lock <= 0 {
+		return nil
+	}
+	return []string{"SET SESSION innodb_lock_wait_timeout = @makemigrations_innodb_lock_wait_timeout"}
+}
+
+// IsLockTimeoutError returns true when err is error 1205.
+func (p *Provider) IsLockTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "Error 1205") || strings.Contains(msg, "Lock wait timeout exceeded")
+}
+
+// GenerateLock waits indefinitely for the named user-level lock.
+func (p *Provider) GenerateLock(key string) string {
+	return fmt.Sprintf("SELECT GET_LOCK('%s', -1)", strings.ReplaceAll(key, "'", "''"))
+}
+
+// GenerateUnlock releases the lock taken by GenerateLock.
+func (p *Provider) GenerateUnlock(key string) string {
+	return fmt.Sprintf("SELECT RELEASE_LOCK('%s')", strings.ReplaceAll(key, "'", "''"))
+}
+
 // ConvertFieldType converts YAML field type to TiDB-specific SQL type
 func (p *Provider) ConvertFieldType(field *types.Field) string {
 	// Check user-defined type mappings first
@@ -198,7 +248,7 @@ func (p *Provider) GenerateDropTableCascade(tableName string) string {
 // The DEFAULT clause is emitted when field.Default is non-empty (already
 // resolved from symbolic keys by resolveFieldDefault before this is called).
 func (p *Provider) GenerateAddColumn(tableName string, field *types.Field) string {
-	fieldDef := fmt.Sprintf("%s %s", p.QuoteName(field.Name), p.ConvertFieldType(field))
+	fieldDef := fmt.Sprintf("%s %s%s", p.QuoteName(field.Name), p.ConvertFieldType(field), p.collateClause(field))
 
 	if field.PrimaryKey {
 		fieldDef += " PRIMARY KEY"
@@ -240,6 +290,13 @@ func (p *Provider) GenerateRenameColumn(tableName, oldName, newName string) stri
 		p.QuoteName(tableName), p.QuoteName(oldName), p.QuoteName(newName))
 }
 
+// GenerateRenameColumnWithField implements providers.FieldRenameProvider,
+// restating the column's full definition in the CHANGE statement.
+func (p *Provider) GenerateRenameColumnWithField(tableName string, field *types.Field, newName string) string {
+	return fmt.Sprintf("ALTER TABLE %s CHANGE %s %s %s;",
+		p.QuoteName(tableName), p.QuoteName(field.Name), p.QuoteName(newName), p.columnDefinition(field))
+}
+
 // GenerateCreateTable generates CREATE TABLE statement for TiDB
 func (p *Provider) GenerateCreateTable(schema *types.Schema, table *types.Table) (string, error) {
 	var fieldDefs []string
@@ -275,7 +332,15 @@ func (p *Provider) GenerateCreateTable(schema *types.Schema, table *types.Table)
 		sql.WriteString("\n")
 	}
 
-	sql.WriteString(");")
+	sql.WriteString(")")
+	charset, collation := tableOptions(schema, table)
+	if charset != "" {
+		sql.WriteString(" DEFAULT CHARSET=" + charset)
+	}
+	if collation != "" {
+		sql.WriteString(" COLLATE=" + collation)
+	}
+	sql.WriteString(";")
 	for i := range table.Indexes {
 		sql.WriteString("\n")
 		sql.WriteString(p.GenerateCreateIndex(&table.Indexes[i], table.Name))
@@ -298,6 +363,7 @@ func (p *Provider) convertField(schema *types.Schema, field *types.Field) (strin
 	// Convert field type
 	sqlType := p.ConvertFieldType(field)
 	def.WriteString(sqlType)
+	def.WriteString(p.collateClause(field))
 
 	// Add NOT NULL constraint
 	if !field.IsNullable() || field.PrimaryKey {
@@ -336,32 +402,35 @@ func (p *Provider) GenerateAlterColumn(tableName string, oldField, newField *typ
 	if oldType == newType && oldField.IsNullable() == newField.IsNullable() &&
 		oldField.Default == newField.Default &&
 		oldField.AutoCreate == newField.AutoCreate &&
-		oldField.AutoUpdate == newField.AutoUpdate {
+		oldField.AutoUpdate == newField.AutoUpdate &&
+		oldField.Collation == newField.Collation && oldField.Charset == newField.Charset {
 		return "", nil
 	}
 
-	tbl := p.QuoteName(tableName)
-	col := p.QuoteName(newField.Name)
+	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s;",
+		p.QuoteName(tableName), p.QuoteName(newField.Name), p.columnDefinition(newField)), nil
+}
 
-	stmt := fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", tbl, col, newType)
-	if !newField.IsNullable() {
-		stmt += " NOT NULL"
+// columnDefinition returns the type and attributes of field as MODIFY COLUMN
+// and CHANGE restate them: type, collation, NOT NULL, DEFAULT and ON UPDATE.
+func (p *Provider) columnDefinition(field *types.Field) string {
+	def := p.ConvertFieldType(field) + p.collateClause(field)
+	if !field.IsNullable() {
+		def += " NOT NULL"
 	}
 	// AutoCreate: set DEFAULT CURRENT_TIMESTAMP for timestamp fields
-	if newField.AutoCreate && newField.Type == "timestamp" {
-		stmt += " DEFAULT CURRENT_TIMESTAMP"
-	} else if newField.Default != "" {
-		stmt += fmt.Sprintf(" DEFAULT %s", utils.FormatDefaultValue(newField.Default))
+	if field.AutoCreate && field.Type == "timestamp" {
+		def += " DEFAULT CURRENT_TIMESTAMP"
+	} else if field.Default != "" {
+		def += fmt.Sprintf(" DEFAULT %s", utils.FormatDefaultValue(field.Default))
 	}
 
 	// AutoUpdate: TiDB supports ON UPDATE CURRENT_TIMESTAMP natively (MySQL-compatible)
-	if newField.AutoUpdate && newField.Type == "timestamp" {
-		stmt += " ON UPDATE CURRENT_TIMESTAMP"
+	if field.AutoUpdate && field.Type == "timestamp" {
+		def += " ON UPDATE CURRENT_TIMESTAMP"
 	}
 
-	stmt += ";"
-
-	return stmt, nil
+	return def
 }
 
 func (p *Provider) GenerateForeignKeyConstraint(tableName, fieldName, referencedTable, constraintName, onDelete, onUpdate string) string {
@@ -472,6 +541,76 @@ func (p *Provider) GenerateForeignKeyConstraints(schema *types.Schema, junctionT
 	return strings.Join(constraints, "\n")
 }
 
+// GenerateCreateSequence generates a native CREATE SEQUENCE statement. TiDB
+// has no OWNED BY, so OwnedBy is ignored.
+func (p *Provider) GenerateCreateSequence(seq *types.Sequence) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE SEQUENCE %s INCREMENT BY %d", p.QuoteName(seq.Name), seq.IncrementValue())
+	if lo, ok := seq.MinBound(); ok {
+		fmt.Fprintf(&b, " MINVALUE %d", lo)
+	}
+	if seq.MaxValue != 0 {
+		fmt.Fprintf(&b, " MAXVALUE %d", seq.MaxValue)
+	}
+	fmt.Fprintf(&b, " START WITH %d", seq.StartValue())
+	if seq.Cycle {
+		b.WriteString(" CYCLE")
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+// GenerateAlterSequence generates an ALTER SEQUENCE statement containing only
+// the clauses that differ between oldSeq and newSeq. As on PostgreSQL, START
+// WITH only affects a later RESTART.
+func (p *Provider) GenerateAlterSequence(oldSeq, newSeq *types.Sequence) string {
+	var clauses []string
+	if oldSeq.IncrementValue() != newSeq.IncrementValue() {
+		clauses = append(clauses, fmt.Sprintf("INCREMENT BY %d", newSeq.IncrementValue()))
+	}
+	oldMin, oldHasMin := oldSeq.MinBound()
+	newMin, newHasMin := newSeq.MinBound()
+	if oldMin != newMin || oldHasMin != newHasMin {
+		if newHasMin {
+			clauses = append(clauses, fmt.Sprintf("MINVALUE %d", newMin))
+		} else {
+			clauses = append(clauses, "NO MINVALUE")
+		}
+	}
+	if oldSeq.MaxValue != newSeq.MaxValue {
+		if newSeq.MaxValue == 0 {
+			clauses = append(clauses, "NO MAXVALUE")
+		} else {
+			clauses = append(clauses, fmt.Sprintf("MAXVALUE %d", newSeq.MaxValue))
+		}
+	}
+	if oldSeq.StartValue() != newSeq.StartValue() {
+		clauses = append(clauses, fmt.Sprintf("START WITH %d", newSeq.StartValue()))
+	}
+	if oldSeq.Cycle != newSeq.Cycle {
+		if newSeq.Cycle {
+			clauses = append(clauses, "CYCLE")
+		} else {
+			clauses = append(clauses, "NO CYCLE")
+		}
+	}
+	if len(clauses) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ALTER SEQUENCE %s %s;", p.QuoteName(newSeq.Name), strings.Join(clauses, " "))
+}
+
+// GenerateDropSequence generates a DROP SEQUENCE IF EXISTS statement.
+func (p *Provider) GenerateDropSequence(seq *types.Sequence) string {
+	return fmt.Sprintf("DROP SEQUENCE IF EXISTS %s;", p.QuoteName(seq.Name))
+}
+
+// NextValExpression returns the NEXT VALUE FOR expression for the named
+// sequence, which TiDB accepts in a column default.
+func (p *Provider) NextValExpression(sequenceName string) string {
+	return "NEXT VALUE FOR " + p.QuoteName(sequenceName)
+}
+
 // GetDatabaseSchema extracts schema information from a TiDB database
 func (p *Provider) GetDatabaseSchema(connectionString string) (*types.Schema, error) {
 	return nil, fmt.Errorf("TiDB schema extraction not implemented yet")
@@ -508,3 +647,26 @@ func (p *Provider) GenerateUpsert(table string, conflictKeys []string, columns [
 		strings.Join(updates, ",\n"),
 	)
 }
+
+// collateClause returns the " CHARACTER SET x COLLATE y" suffix for a text
+// column, or "" when the field sets neither.
+func (p *Provider) collateClause(field *types.Field) string {
+	var clause string
+	if field.Charset != "" {
+		clause += " CHARACTER SET " + field.Charset
+	}
+	if field.Collation != "" {
+		clause += " COLLATE " + field.Collation
+	}
+	return clause
+}
+
+// tableOptions returns the table-level charset and collation for table,
+// falling back to the schema's database-level defaults.
+func tableOptions(schema *types.Schema, table *types.Table) (charset, collation string) {
+	charset, collation = table.Charset, table.Collation
+	if charset == "" && collation == "" && schema != nil {
+		charset, collation = schema.Database.Charset, schema.Database.Collation
+	}
+	return charset, collation
+}
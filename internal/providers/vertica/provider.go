@@ -65,6 +65,12 @@ func (p *Provider) HistoryTableDDL() string {
 )`
 }
 
+// AutoCommitsDDL reports that Vertica commits DDL statements implicitly, so
+// the runner tracks per-operation progress instead of relying on rollback.
+func (p *Provider) AutoCommitsDDL() bool {
+	return true
+}
+
 // QuoteName quotes database identifiers for Vertica (double quotes like PostgreSQL)
 func (p *Provider) QuoteName(name string) string {
 	return fmt.Sprintf(`"%s"`, name)
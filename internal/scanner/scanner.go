@@ -27,6 +27,7 @@ package scanner
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -37,6 +38,7 @@ import (
 	"golang.org/x/mod/modfile"
 
 	"github.com/ocomsoft/makemigrations/internal/errors"
+	"github.com/ocomsoft/makemigrations/internal/srcfs"
 )
 
 // SchemaFile represents a discovered schema file along with its module path and content.
@@ -60,15 +62,23 @@ const (
 // Scanner discovers schema files within a Go module directory tree.
 type Scanner struct {
 	verbose bool
+	fs      srcfs.FS
 }
 
 // New creates a new Scanner with optional verbose logging.
 func New(verbose bool) *Scanner {
 	return &Scanner{
 		verbose: verbose,
+		fs:      srcfs.OS,
 	}
 }
 
+// SetFS makes the scanner read files from fsys instead of the working tree,
+// e.g. from a git revision (see srcfs.Git).
+func (s *Scanner) SetFS(fsys srcfs.FS) {
+	s.fs = fsys
+}
+
 // ScanModules discovers SQL schema files in the current module directory.
 func (s *Scanner) ScanModules() ([]SchemaFile, error) {
 	return s.ScanModulesWithType(SchemaTypeSQL)
@@ -84,11 +94,11 @@ func (s *Scanner) ScanModulesWithType(schemaType SchemaType) ([]SchemaFile, erro
 	goModPath := "go.mod"
 
 	// Validate go.mod exists
-	if _, err := os.Stat(goModPath); os.IsNotExist(err) {
+	if _, err := s.fs.Stat(goModPath); os.IsNotExist(err) {
 		return nil, errors.NewValidationError("go.mod", "file not found - ensure you're in a Go module directory")
 	}
 
-	goModBytes, err := os.ReadFile(goModPath)
+	goModBytes, err := s.fs.ReadFile(goModPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read go.mod: %w", err)
 	}
@@ -195,12 +205,12 @@ func (s *Scanner) findAllSchemasInPathWithType(basePath, modulePath string, sche
 	// Load .gitignore if it exists
 	var gitignore *ignore.GitIgnore
 	gitignorePath := filepath.Join(basePath, ".gitignore")
-	if _, err := os.Stat(gitignorePath); err == nil {
-		gitignore, _ = ignore.CompileIgnoreFile(gitignorePath)
+	if data, err := s.fs.ReadFile(gitignorePath); err == nil {
+		gitignore = ignore.CompileIgnoreLines(strings.Split(string(data), "\n")...)
 	}
 
 	// Search recursively for all schema files
-	err := filepath.WalkDir(basePath, func(path string, d os.DirEntry, err error) error {
+	err := s.fs.WalkDir(basePath, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return nil // Continue walking even if there's an error with this path
 		}
@@ -219,13 +229,12 @@ func (s *Scanner) findAllSchemasInPathWithType(basePath, modulePath string, sche
 
 		// Check if this is the target file in a target directory
 		if d.Name() == targetFilename && filepath.Base(filepath.Dir(path)) == targetDirname {
-			file, err := os.Open(path)
+			data, err := s.fs.ReadFile(path)
 			if err != nil {
 				return nil // Continue walking
 			}
-			defer func() { _ = file.Close() }()
 
-			content, hasMarker, err := s.readSchemaFileWithType(file, schemaType)
+			content, hasMarker, err := s.readSchemaFileWithType(bytes.NewReader(data), schemaType)
 			if err != nil {
 				return nil // Continue walking
 			}
@@ -0,0 +1,258 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package srcfs
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Git reads the repository containing the working directory as it is at
+// ref, without checking it out. Paths inside the repository are resolved
+// against the tree of ref; paths outside it, such as the Go module cache,
+// are read from disk.
+func Git(ref string) (FS, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting working directory: %w", err)
+	}
+	prefix, err := git("rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	out, err := git("ls-tree", "-r", "-t", "-z", "--full-tree", "--end-of-options", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &gitTree{
+		ref:     ref,
+		wd:      wd,
+		prefix:  strings.TrimSuffix(strings.TrimSpace(string(prefix)), "/"),
+		entries: map[string]gitEntry{".": {dir: true}},
+		dirs:    make(map[string][]string),
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00") {
+		if line == "" {
+			continue
+		}
+		// <mode> SP <type> SP <object> TAB <path>
+		meta, name, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 {
+			return nil, fmt.Errorf("git ls-tree %s: unexpected output %q", ref, line)
+		}
+		switch fields[1] {
+		case "tree":
+			t.entries[name] = gitEntry{dir: true}
+		case "blob":
+			t.entries[name] = gitEntry{object: fields[2]}
+		default:
+			continue // submodules
+		}
+		t.dirs[path.Dir(name)] = append(t.dirs[path.Dir(name)], path.Base(name))
+	}
+	for _, names := range t.dirs {
+		sort.Strings(names)
+	}
+	return t, nil
+}
+
+// git runs git with args and returns its standard output.
+func git(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// gitTree is the tree of a git revision. Its entries are keyed by slash
+// separated paths relative to the repository root, "." being the root.
+type gitTree struct {
+	ref     string
+	wd      string // working directory
+	prefix  string // working directory relative to the repository root
+	entries map[string]gitEntry
+	dirs    map[string][]string // directory -> sorted child names
+}
+
+type gitEntry struct {
+	dir    bool
+	object string // blob id of a file
+}
+
+// resolve returns the repository path of name, or false if name lies
+// outside the repository.
+func (t *gitTree) resolve(name string) (string, bool) {
+	abs := name
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(t.wd, abs)
+	}
+	rel, err := filepath.Rel(t.wd, abs)
+	if err != nil {
+		return "", false
+	}
+	p := path.Join(t.prefix, filepath.ToSlash(rel))
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return "", false
+	}
+	if p == "" {
+		p = "."
+	}
+	return p, true
+}
+
+func (t *gitTree) ReadFile(name string) ([]byte, error) {
+	p, ok := t.resolve(name)
+	if !ok {
+		return os.ReadFile(name)
+	}
+	e, found := t.entries[p]
+	if !found || e.dir {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	data, err := git("cat-file", "blob", e.object)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+func (t *gitTree) Stat(name string) (fs.FileInfo, error) {
+	p, ok := t.resolve(name)
+	if !ok {
+		return os.Stat(name)
+	}
+	e, found := t.entries[p]
+	if !found {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return gitInfo{name: path.Base(p), dir: e.dir}, nil
+}
+
+// WalkDir walks the tree like filepath.WalkDir, passing fn paths that start
+// with root.
+func (t *gitTree) WalkDir(root string, fn fs.WalkDirFunc) error {
+	p, ok := t.resolve(root)
+	if !ok {
+		return filepath.WalkDir(root, fn)
+	}
+	return fs.WalkDir(treeFS{t}, p, func(name string, d fs.DirEntry, err error) error {
+		rel := name
+		if p != "." {
+			rel = strings.TrimPrefix(strings.TrimPrefix(name, p), "/")
+		}
+		return fn(filepath.Join(root, filepath.FromSlash(rel)), d, err)
+	})
+}
+
+// treeFS exposes a gitTree as an fs.ReadDirFS for fs.WalkDir.
+type treeFS struct{ t *gitTree }
+
+func (f treeFS) Open(name string) (fs.File, error) {
+	e, found := f.t.entries[name]
+	if !found {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	info := gitInfo{name: path.Base(name), dir: e.dir}
+	if e.dir {
+		return &gitFile{info: info}, nil
+	}
+	data, err := git("cat-file", "blob", e.object)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &gitFile{info: info, r: bytes.NewReader(data)}, nil
+}
+
+func (f treeFS) Stat(name string) (fs.FileInfo, error) {
+	e, found := f.t.entries[name]
+	if !found {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return gitInfo{name: path.Base(name), dir: e.dir}, nil
+}
+
+func (f treeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	e, found := f.t.entries[name]
+	if !found || !e.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	var out []fs.DirEntry
+	for _, child := range f.t.dirs[name] {
+		out = append(out, fs.FileInfoToDirEntry(gitInfo{name: child, dir: f.t.entries[path.Join(name, child)].dir}))
+	}
+	return out, nil
+}
+
+// gitFile is an open file or directory of a gitTree.
+type gitFile struct {
+	info gitInfo
+	r    *bytes.Reader // nil for a directory
+}
+
+func (f *gitFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *gitFile) Read(b []byte) (int, error) {
+	if f.r == nil {
+		return 0, &fs.PathError{Op: "read", Path: f.info.name, Err: fs.ErrInvalid}
+	}
+	return f.r.Read(b)
+}
+
+func (f *gitFile) Close() error { return nil }
+
+// gitInfo describes an entry of a gitTree. Sizes and times are not tracked.
+type gitInfo struct {
+	name string
+	dir  bool
+}
+
+func (i gitInfo) Name() string { return i.name }
+func (i gitInfo) Size() int64  { return 0 }
+func (i gitInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+func (i gitInfo) ModTime() time.Time { return time.Time{} }
+func (i gitInfo) IsDir() bool        { return i.dir }
+func (i gitInfo) Sys() any           { return nil }
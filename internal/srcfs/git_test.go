@@ -0,0 +1,124 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package srcfs_test
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/internal/srcfs"
+)
+
+// gitRun runs git in dir and fails the test on error.
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+		"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	gitRun(t, repo, "init", "-q")
+	writeFile(t, filepath.Join(repo, "app", "schema", "schema.yaml"), "v1")
+	writeFile(t, filepath.Join(repo, "app", ".hidden"), "h")
+	writeFile(t, filepath.Join(repo, "README"), "readme")
+	gitRun(t, repo, "add", "-A")
+	gitRun(t, repo, "commit", "-q", "-m", "first")
+	writeFile(t, filepath.Join(repo, "app", "schema", "schema.yaml"), "v2")
+	writeFile(t, filepath.Join(repo, "app", "new.yaml"), "untracked")
+
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	writeFile(t, outside, "disk")
+
+	t.Chdir(filepath.Join(repo, "app"))
+	fsys, err := srcfs.Git("HEAD")
+	if err != nil {
+		t.Fatalf("Git: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"schema/schema.yaml": "v1",
+		"../README":          "readme",
+		outside:              "disk",
+	} {
+		data, err := fsys.ReadFile(name)
+		if err != nil || string(data) != want {
+			t.Errorf("ReadFile(%s) = %q, %v; want %q", name, data, err, want)
+		}
+	}
+	if _, err := fsys.ReadFile("new.yaml"); !os.IsNotExist(err) {
+		t.Errorf("expected an untracked file to be missing, got %v", err)
+	}
+	if info, err := fsys.Stat("schema"); err != nil || !info.IsDir() {
+		t.Errorf("Stat(schema) = %v, %v; want a directory", info, err)
+	}
+
+	var walked []string
+	err = fsys.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		walked = append(walked, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkDir: %v", err)
+	}
+	want := []string{".", ".hidden", "schema", filepath.Join("schema", "schema.yaml")}
+	if len(walked) != len(want) {
+		t.Fatalf("WalkDir visited %v, want %v", walked, want)
+	}
+	for i := range want {
+		if walked[i] != want[i] {
+			t.Errorf("WalkDir visited %v, want %v", walked, want)
+			break
+		}
+	}
+
+	if _, err := srcfs.Git("no-such-ref"); err == nil {
+		t.Error("expected an unknown ref to be rejected")
+	}
+}
@@ -25,7 +25,12 @@ SOFTWARE.
 // Package types defines the shared schema data structures used throughout makemigrations.
 package types
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
 
 // Include represents an external schema to include
 type Include struct {
@@ -40,6 +45,9 @@ type Schema struct {
 	Defaults     Defaults     `yaml:"defaults"`
 	TypeMappings TypeMappings `yaml:"type_mappings"`
 	Tables       []Table      `yaml:"tables"`
+	Sequences    []Sequence   `yaml:"sequences,omitempty"`
+	Functions    []Function   `yaml:"functions,omitempty"`
+	Triggers     []Trigger    `yaml:"triggers,omitempty"`
 }
 
 // Database represents the database metadata
@@ -47,6 +55,9 @@ type Database struct {
 	Name             string `yaml:"name"`
 	Version          string `yaml:"version"`
 	MigrationVersion string `yaml:"migration_version,omitempty"`
+	// Collation and Charset are the schema-wide defaults for text columns.
+	Collation string `yaml:"collation,omitempty"`
+	Charset   string `yaml:"charset,omitempty"`
 }
 
 // Defaults represents default value mappings per database type.
@@ -114,6 +125,14 @@ type Table struct {
 	Name    string  `yaml:"name"`
 	Fields  []Field `yaml:"fields"`
 	Indexes []Index `yaml:"indexes,omitempty"`
+	// UniqueConstraints are table constraints (ALTER TABLE ... ADD CONSTRAINT
+	// ... UNIQUE) rather than unique indexes, so they can be named in
+	// ON CONFLICT ON CONSTRAINT clauses and referenced by foreign keys.
+	UniqueConstraints []UniqueConstraint `yaml:"unique_constraints,omitempty"`
+	// Collation and Charset are the defaults for this table's text columns,
+	// overriding the database-level defaults.
+	Collation string `yaml:"collation,omitempty"`
+	Charset   string `yaml:"charset,omitempty"`
 }
 
 // Field represents a database field/column definition
@@ -121,6 +140,7 @@ type Field struct {
 	Name       string      `yaml:"name"`
 	Type       string      `yaml:"type"`
 	PrimaryKey bool        `yaml:"primary_key,omitempty"`
+	Unique     bool        `yaml:"unique,omitempty"` // shorthand for a single-column unique constraint
 	Nullable   *bool       `yaml:"nullable,omitempty"`
 	Default    string      `yaml:"default,omitempty"`
 	Length     int         `yaml:"length,omitempty"`
@@ -130,6 +150,12 @@ type Field struct {
 	AutoUpdate bool        `yaml:"auto_update,omitempty"`
 	ForeignKey *ForeignKey `yaml:"foreign_key,omitempty"`
 	ManyToMany *ManyToMany `yaml:"many_to_many,omitempty"`
+	Collation  string      `yaml:"collation,omitempty"` // text columns only, provider-native name
+	Charset    string      `yaml:"charset,omitempty"`   // text columns only, MySQL/TiDB
+	// Overrides replaces selected properties of this field on individual
+	// databases. They are resolved for the target database when schemas are
+	// merged, so migrations and schema state only ever see the result.
+	Overrides map[DatabaseType]FieldOverride `yaml:"overrides,omitempty"`
 }
 
 // ForeignKey represents a foreign key relationship
@@ -165,8 +191,248 @@ type Index struct {
 	// managed automatically by AddForeignKey/DropForeignKey and excluded from
 	// schema diffs and generated migration code.
 	FromFK bool `yaml:"from_fk,omitempty"`
+	// Overrides replaces selected properties of this index on individual
+	// databases, resolved for the target database when schemas are merged.
+	Overrides map[DatabaseType]IndexOverride `yaml:"overrides,omitempty"`
+}
+
+// UniqueConstraint represents a named table-level UNIQUE constraint.
+type UniqueConstraint struct {
+	Name   string   `yaml:"name"`
+	Fields []string `yaml:"fields"`
+	// NullsNotDistinct treats NULLs as equal so at most one row may hold NULL
+	// in the constrained columns (PostgreSQL 15+; SQL Server always behaves
+	// this way). Other providers reject it.
+	NullsNotDistinct bool `yaml:"nulls_not_distinct,omitempty"`
+}
+
+// FieldOverride holds the field properties one database may override. Unset
+// values keep the base field's; Nullable, Default, AutoCreate and AutoUpdate
+// are pointers so an override can also switch them off or clear them.
+// Type may be a provider-native type (e.g. "citext") as well as a YAML type.
+type FieldOverride struct {
+	Type string `yaml:"type,omitempty"`
+	// Length replaces the base length when positive.
+	Length int `yaml:"length,omitempty"`
+	// Precision and Scale replace the base pair together when Precision is positive.
+	Precision  int     `yaml:"precision,omitempty"`
+	Scale      int     `yaml:"scale,omitempty"`
+	Nullable   *bool   `yaml:"nullable,omitempty"`
+	Default    *string `yaml:"default,omitempty"`
+	AutoCreate *bool   `yaml:"auto_create,omitempty"`
+	AutoUpdate *bool   `yaml:"auto_update,omitempty"`
+	Collation  string  `yaml:"collation,omitempty"`
+	Charset    string  `yaml:"charset,omitempty"`
+}
+
+// UnmarshalYAML rejects keys that are not overridable field properties.
+func (o *FieldOverride) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain FieldOverride
+	if err := checkOverrideKeys(unmarshal, reflect.TypeOf(plain{})); err != nil {
+		return fmt.Errorf("field override: %w", err)
+	}
+	return unmarshal((*plain)(o))
+}
+
+// IndexOverride holds the index properties one database may override.
+type IndexOverride struct {
+	Fields []string `yaml:"fields,omitempty"`
+	Unique *bool    `yaml:"unique,omitempty"`
+	Method string   `yaml:"method,omitempty"`
+	// Where replaces the partial index predicate; an empty string removes it.
+	Where *string `yaml:"where,omitempty"`
+}
+
+// UnmarshalYAML rejects keys that are not overridable index properties.
+func (o *IndexOverride) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain IndexOverride
+	if err := checkOverrideKeys(unmarshal, reflect.TypeOf(plain{})); err != nil {
+		return fmt.Errorf("index override: %w", err)
+	}
+	return unmarshal((*plain)(o))
+}
+
+// checkOverrideKeys decodes the YAML mapping and returns an error naming any
+// keys that do not match a yaml tag of the given struct type.
+func checkOverrideKeys(unmarshal func(interface{}) error, t reflect.Type) error {
+	var raw map[string]interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	allowed := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		allowed[name] = true
+	}
+	var unknown []string
+	for key := range raw {
+		if !allowed[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown keys: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// Sequence represents a standalone database sequence (e.g. for invoice numbers).
+// Native sequences are used on PostgreSQL, SQL Server and TiDB; MySQL and SQLite
+// emulate them with a single-row counter table of the same name.
+type Sequence struct {
+	Name string `yaml:"name"`
+	// Start is the first value returned by the sequence. Zero means 1 unless
+	// HasStart is set.
+	Start int64 `yaml:"start,omitempty"`
+	// Increment is the step between values. Zero means 1.
+	Increment int64 `yaml:"increment,omitempty"`
+	// MinValue and MaxValue bound the sequence. Zero means the database
+	// default, unless HasMinValue is set for MinValue.
+	// Bounds and Cycle are only enforced by providers with native sequences.
+	MinValue int64 `yaml:"min_value,omitempty"`
+	MaxValue int64 `yaml:"max_value,omitempty"`
+	// HasStart and HasMinValue mark an explicit zero Start or MinValue. They
+	// are set when the YAML gives the key as 0, and ignored for other values.
+	HasStart    bool `yaml:"-"`
+	HasMinValue bool `yaml:"-"`
+	// Cycle restarts the sequence at MinValue once MaxValue is reached.
+	Cycle bool `yaml:"cycle,omitempty"`
+	// OwnedBy ties the sequence to a column ("table.field"). On PostgreSQL this
+	// renders OWNED BY; emulating providers install an insert trigger that fills
+	// the column from the counter table when no value is supplied. SQL Server
+	// and TiDB have no equivalent and ignore it.
+	OwnedBy string `yaml:"owned_by,omitempty"`
+}
+
+// sequenceYAML is the YAML form of Sequence, with pointers to tell an
+// explicit zero Start or MinValue from an absent one.
+type sequenceYAML struct {
+	Name      string `yaml:"name"`
+	Start     *int64 `yaml:"start,omitempty"`
+	Increment int64  `yaml:"increment,omitempty"`
+	MinValue  *int64 `yaml:"min_value,omitempty"`
+	MaxValue  int64  `yaml:"max_value,omitempty"`
+	Cycle     bool   `yaml:"cycle,omitempty"`
+	OwnedBy   string `yaml:"owned_by,omitempty"`
+}
+
+// UnmarshalYAML decodes a sequence, setting HasStart and HasMinValue when
+// start or min_value is given as 0.
+func (s *Sequence) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw sequenceYAML
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*s = Sequence{Name: raw.Name, Increment: raw.Increment, MaxValue: raw.MaxValue, Cycle: raw.Cycle, OwnedBy: raw.OwnedBy}
+	if raw.Start != nil {
+		s.Start, s.HasStart = *raw.Start, *raw.Start == 0
+	}
+	if raw.MinValue != nil {
+		s.MinValue, s.HasMinValue = *raw.MinValue, *raw.MinValue == 0
+	}
+	return nil
+}
+
+// MarshalYAML encodes a sequence, keeping an explicit zero start or min_value.
+func (s Sequence) MarshalYAML() (interface{}, error) {
+	raw := sequenceYAML{Name: s.Name, Increment: s.Increment, MaxValue: s.MaxValue, Cycle: s.Cycle, OwnedBy: s.OwnedBy}
+	if s.Start != 0 || s.HasStart {
+		raw.Start = &s.Start
+	}
+	if lo, ok := s.MinBound(); ok {
+		raw.MinValue = &lo
+	}
+	return raw, nil
+}
+
+// StartValue returns the effective start value, defaulting to 1.
+func (s *Sequence) StartValue() int64 {
+	if s.Start == 0 && !s.HasStart {
+		return 1
+	}
+	return s.Start
+}
+
+// MinBound returns the sequence's minimum value and whether one is set.
+func (s *Sequence) MinBound() (int64, bool) {
+	return s.MinValue, s.MinValue != 0 || s.HasMinValue
 }
 
+// IncrementValue returns the effective increment, defaulting to 1.
+func (s *Sequence) IncrementValue() int64 {
+	if s.Increment == 0 {
+		return 1
+	}
+	return s.Increment
+}
+
+// OwnerParts splits OwnedBy into its table and field names.
+// ok is false when OwnedBy is empty or not of the form "table.field".
+func (s *Sequence) OwnerParts() (table, field string, ok bool) {
+	table, field, ok = strings.Cut(s.OwnedBy, ".")
+	if !ok || table == "" || field == "" {
+		return "", "", false
+	}
+	return table, field, true
+}
+
+// ParseNextValDefault reports whether a field default is a sequence reference of
+// the form nextval('name') (quotes optional) and returns the sequence name.
+// Providers resolve the reference to their own syntax unless the schema
+// defaults map contains an explicit override for the full expression.
+func ParseNextValDefault(value string) (string, bool) {
+	v := strings.TrimSpace(value)
+	if !strings.HasPrefix(strings.ToLower(v), "nextval(") || !strings.HasSuffix(v, ")") {
+		return "", false
+	}
+	name := strings.TrimSpace(v[len("nextval(") : len(v)-1])
+	name = strings.Trim(name, "'\"")
+	if name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// Function represents a stored function or procedure. Body holds the
+// provider-native routine body keyed by database type; only the body for the
+// active database is used, and functions without one are skipped there.
+// Functions are identified by name alone — overloads are not supported.
+type Function struct {
+	Name string `yaml:"name"`
+	// Arguments is the parameter list without parentheses, in the provider's
+	// syntax (e.g. "amount numeric, rate numeric" or "@amount DECIMAL(10,2)").
+	Arguments string `yaml:"arguments,omitempty"`
+	// Returns is the return type. Leave empty for procedures.
+	Returns string `yaml:"returns,omitempty"`
+	// Language is the routine language for PostgreSQL (default plpgsql).
+	Language string `yaml:"language,omitempty"`
+	// Procedure creates a stored procedure instead of a function.
+	Procedure bool                    `yaml:"procedure,omitempty"`
+	Body      map[DatabaseType]string `yaml:"body"`
+}
+
+// Trigger represents a table trigger. PostgreSQL triggers execute Function;
+// other providers use the provider-native Body for the active database.
+type Trigger struct {
+	Name  string `yaml:"name"`
+	Table string `yaml:"table"`
+	// Timing is BEFORE, AFTER or INSTEAD OF.
+	Timing string `yaml:"timing"`
+	// Events lists the firing events: INSERT, UPDATE, DELETE or TRUNCATE.
+	Events []string `yaml:"events"`
+	// ForEach is ROW (default) or STATEMENT.
+	ForEach string `yaml:"for_each,omitempty"`
+	// When is an optional condition (PostgreSQL and SQLite only).
+	When string `yaml:"when,omitempty"`
+	// Function is the trigger function to execute (PostgreSQL).
+	Function string                  `yaml:"function,omitempty"`
+	Body     map[DatabaseType]string `yaml:"body,omitempty"`
+}
+
+// validTriggerEvents lists the events a trigger may fire on.
+var validTriggerEvents = map[string]bool{"INSERT": true, "UPDATE": true, "DELETE": true, "TRUNCATE": true}
+
 // DatabaseType represents supported database types
 type DatabaseType string
 
@@ -231,6 +497,12 @@ func (f *Field) IsNullable() bool {
 	return *f.Nullable
 }
 
+// SupportsCollation reports whether the field is a text column that may carry
+// a collation and charset.
+func (f *Field) SupportsCollation() bool {
+	return f.Type == "varchar" || f.Type == "text"
+}
+
 // SetNullable sets the nullable field
 func (f *Field) SetNullable(nullable bool) {
 	f.Nullable = &nullable
@@ -256,6 +528,173 @@ func (s *Schema) GetTableByName(name string) *Table {
 	return nil
 }
 
+// GetSequenceByName finds a sequence by name in the schema
+func (s *Schema) GetSequenceByName(name string) *Sequence {
+	for i := range s.Sequences {
+		if s.Sequences[i].Name == name {
+			return &s.Sequences[i]
+		}
+	}
+	return nil
+}
+
+// GetFunctionByName finds a function by name in the schema
+func (s *Schema) GetFunctionByName(name string) *Function {
+	for i := range s.Functions {
+		if s.Functions[i].Name == name {
+			return &s.Functions[i]
+		}
+	}
+	return nil
+}
+
+// GetTriggerByName finds a trigger by name in the schema
+func (s *Schema) GetTriggerByName(name string) *Trigger {
+	for i := range s.Triggers {
+		if s.Triggers[i].Name == name {
+			return &s.Triggers[i]
+		}
+	}
+	return nil
+}
+
+// SelectDatabase narrows provider-specific parts of the schema to dbType:
+// function and trigger bodies keep only the active database's entry, and
+// functions with no body for it are removed along with triggers that have no
+// body of their own and depend on such a function.
+// It is applied to merged schemas so diffs compare like with like.
+func (s *Schema) SelectDatabase(dbType DatabaseType) {
+	skipped := make(map[string]bool)
+	functions := make([]Function, 0, len(s.Functions))
+	for _, fn := range s.Functions {
+		body, ok := fn.Body[dbType]
+		if !ok {
+			skipped[fn.Name] = true
+			continue
+		}
+		fn.Body = map[DatabaseType]string{dbType: body}
+		functions = append(functions, fn)
+	}
+	s.Functions = functions
+
+	triggers := make([]Trigger, 0, len(s.Triggers))
+	for _, tr := range s.Triggers {
+		body, ok := tr.Body[dbType]
+		if !ok && (tr.Function == "" || skipped[tr.Function]) {
+			continue
+		}
+		tr.Body = nil
+		if ok {
+			tr.Body = map[DatabaseType]string{dbType: body}
+		}
+		triggers = append(triggers, tr)
+	}
+	s.Triggers = triggers
+}
+
+// ApplyOverrides resolves field and index overrides for dbType and removes
+// every override block, so the schema describes exactly what dbType will get.
+// It runs after validation because overrides may use provider-native types.
+func (s *Schema) ApplyOverrides(dbType DatabaseType) {
+	for ti := range s.Tables {
+		t := &s.Tables[ti]
+		for fi := range t.Fields {
+			f := &t.Fields[fi]
+			*f = f.WithOverride(dbType)
+		}
+		for ii := range t.Indexes {
+			idx := &t.Indexes[ii]
+			*idx = idx.WithOverride(dbType)
+		}
+	}
+}
+
+// WithOverride returns a copy of the field with the override for dbType
+// applied and no overrides left. Collation and charset are dropped when the
+// resulting type does not support them.
+func (f Field) WithOverride(dbType DatabaseType) Field {
+	o, ok := f.Overrides[dbType]
+	f.Overrides = nil
+	if !ok {
+		return f
+	}
+	if o.Type != "" {
+		f.Type = o.Type
+	}
+	if o.Length > 0 {
+		f.Length = o.Length
+	}
+	if o.Precision > 0 {
+		f.Precision, f.Scale = o.Precision, o.Scale
+	}
+	if o.Nullable != nil {
+		f.SetNullable(*o.Nullable)
+	}
+	if o.Default != nil {
+		f.Default = *o.Default
+	}
+	if o.AutoCreate != nil {
+		f.AutoCreate = *o.AutoCreate
+	}
+	if o.AutoUpdate != nil {
+		f.AutoUpdate = *o.AutoUpdate
+	}
+	if o.Collation != "" || o.Charset != "" {
+		f.Collation, f.Charset = o.Collation, o.Charset
+	}
+	if !f.SupportsCollation() {
+		f.Collation, f.Charset = "", ""
+	}
+	return f
+}
+
+// WithOverride returns a copy of the index with the override for dbType
+// applied and no overrides left.
+func (i Index) WithOverride(dbType DatabaseType) Index {
+	o, ok := i.Overrides[dbType]
+	i.Overrides = nil
+	if !ok {
+		return i
+	}
+	if len(o.Fields) > 0 {
+		i.Fields = o.Fields
+	}
+	if o.Unique != nil {
+		i.Unique = *o.Unique
+	}
+	if o.Method != "" {
+		i.Method = o.Method
+	}
+	if o.Where != nil {
+		i.Where = *o.Where
+	}
+	return i
+}
+
+// ApplyCollationDefaults copies table-level collation and charset, or the
+// database-level defaults when the table sets neither, onto text fields that
+// set neither themselves. The pair is inherited together so a field never
+// mixes a charset from one level with a collation from another.
+// It is applied to merged schemas so providers and diffs work per column.
+func (s *Schema) ApplyCollationDefaults() {
+	for ti := range s.Tables {
+		t := &s.Tables[ti]
+		collation, charset := t.Collation, t.Charset
+		if collation == "" && charset == "" {
+			collation, charset = s.Database.Collation, s.Database.Charset
+		}
+		if collation == "" && charset == "" {
+			continue
+		}
+		for fi := range t.Fields {
+			f := &t.Fields[fi]
+			if f.SupportsCollation() && f.Collation == "" && f.Charset == "" {
+				f.Collation, f.Charset = collation, charset
+			}
+		}
+	}
+}
+
 // GetFieldByName finds a field by name in the table
 func (t *Table) GetFieldByName(name string) *Field {
 	for i := range t.Fields {
@@ -314,6 +753,39 @@ func (s *Schema) Validate() error {
 		return fmt.Errorf("at least one table or include is required")
 	}
 
+	seqNames := make(map[string]bool)
+	for i, seq := range s.Sequences {
+		if err := seq.Validate(); err != nil {
+			return fmt.Errorf("sequence %d: %w", i, err)
+		}
+		if seqNames[seq.Name] {
+			return fmt.Errorf("sequence %s: duplicate sequence name", seq.Name)
+		}
+		seqNames[seq.Name] = true
+	}
+
+	fnNames := make(map[string]bool)
+	for i, fn := range s.Functions {
+		if err := fn.Validate(); err != nil {
+			return fmt.Errorf("function %d: %w", i, err)
+		}
+		if fnNames[fn.Name] {
+			return fmt.Errorf("function %s: duplicate function name", fn.Name)
+		}
+		fnNames[fn.Name] = true
+	}
+
+	triggerNames := make(map[string]bool)
+	for i, tr := range s.Triggers {
+		if err := tr.Validate(); err != nil {
+			return fmt.Errorf("trigger %d: %w", i, err)
+		}
+		if triggerNames[tr.Name] {
+			return fmt.Errorf("trigger %s: duplicate trigger name", tr.Name)
+		}
+		triggerNames[tr.Name] = true
+	}
+
 	for i, table := range s.Tables {
 		if table.Name == "" {
 			return fmt.Errorf("table %d: name is required", i)
@@ -335,11 +807,88 @@ func (s *Schema) Validate() error {
 				return fmt.Errorf("table %s, index %d: %w", table.Name, j, err)
 			}
 		}
+
+		// Validate unique constraints
+		ucNames := make(map[string]bool)
+		for j, uc := range table.UniqueConstraints {
+			if err := uc.Validate(table); err != nil {
+				return fmt.Errorf("table %s, unique constraint %d: %w", table.Name, j, err)
+			}
+			if ucNames[uc.Name] {
+				return fmt.Errorf("table %s: duplicate unique constraint name %s", table.Name, uc.Name)
+			}
+			ucNames[uc.Name] = true
+		}
 	}
 
 	return nil
 }
 
+// Validate validates the sequence structure
+func (s *Sequence) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("sequence name is required")
+	}
+	if lo, ok := s.MinBound(); ok && s.MaxValue != 0 && lo >= s.MaxValue {
+		return fmt.Errorf("sequence %s: min_value must be less than max_value", s.Name)
+	}
+	if s.OwnedBy != "" {
+		if _, _, ok := s.OwnerParts(); !ok {
+			return fmt.Errorf("sequence %s: owned_by must be of the form table.field", s.Name)
+		}
+	}
+	return nil
+}
+
+// Validate validates the function structure
+func (f *Function) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("function name is required")
+	}
+	if len(f.Body) == 0 {
+		return fmt.Errorf("function %s: at least one provider body is required", f.Name)
+	}
+	if f.Procedure && f.Returns != "" {
+		return fmt.Errorf("function %s: procedures cannot declare a return type", f.Name)
+	}
+	if !f.Procedure && f.Returns == "" {
+		return fmt.Errorf("function %s: returns is required for functions", f.Name)
+	}
+	return nil
+}
+
+// Validate validates the trigger structure
+func (t *Trigger) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("trigger name is required")
+	}
+	if t.Table == "" {
+		return fmt.Errorf("trigger %s: table is required", t.Name)
+	}
+	switch strings.ToUpper(t.Timing) {
+	case "BEFORE", "AFTER", "INSTEAD OF":
+	default:
+		return fmt.Errorf("trigger %s: timing must be BEFORE, AFTER or INSTEAD OF", t.Name)
+	}
+	if len(t.Events) == 0 {
+		return fmt.Errorf("trigger %s: at least one event is required", t.Name)
+	}
+	for _, ev := range t.Events {
+		if !validTriggerEvents[strings.ToUpper(ev)] {
+			return fmt.Errorf("trigger %s: invalid event %s", t.Name, ev)
+		}
+	}
+	switch strings.ToUpper(t.ForEach) {
+	case "", "ROW", "STATEMENT":
+	default:
+		return fmt.Errorf("trigger %s: for_each must be ROW or STATEMENT", t.Name)
+	}
+	if t.Function == "" && len(t.Body) == 0 {
+		return fmt.Errorf("trigger %s: function or body is required", t.Name)
+	}
+	return nil
+}
+
 // Validate validates the field structure
 func (f *Field) Validate() error {
 	if f.Name == "" {
@@ -383,9 +932,71 @@ func (f *Field) Validate() error {
 		}
 	}
 
+	if (f.Collation != "" || f.Charset != "") && !f.SupportsCollation() {
+		return fmt.Errorf("collation and charset are only allowed on varchar and text fields")
+	}
+
+	if f.Unique && f.PrimaryKey {
+		return fmt.Errorf("unique is implied by primary_key")
+	}
+	if f.Unique && f.Type == "many_to_many" {
+		return fmt.Errorf("unique is not supported on many_to_many fields")
+	}
+
+	return f.validateOverrides()
+}
+
+// validateOverrides checks each override's database key and validates the
+// field it produces. Results with a provider-native type are only checked
+// for obvious mistakes since their rules are the database's own.
+func (f *Field) validateOverrides() error {
+	for _, dbType := range sortedKeys(f.Overrides) {
+		if _, err := ParseDatabaseType(string(dbType)); err != nil {
+			return fmt.Errorf("override: %w", err)
+		}
+		o := f.Overrides[dbType]
+		if o.Type == "foreign_key" || o.Type == "many_to_many" {
+			return fmt.Errorf("override %s: type cannot be changed to %s", dbType, o.Type)
+		}
+		if o.Length < 0 || o.Precision < 0 || o.Scale < 0 {
+			return fmt.Errorf("override %s: length, precision and scale must not be negative", dbType)
+		}
+		resolved := f.WithOverride(dbType)
+		if IsValidFieldType(resolved.Type) {
+			if err := resolved.Validate(); err != nil {
+				return fmt.Errorf("override %s: %w", dbType, err)
+			}
+		}
+	}
 	return nil
 }
 
+// Validate validates the unique constraint against its table.
+func (uc *UniqueConstraint) Validate(table Table) error {
+	if uc.Name == "" {
+		return fmt.Errorf("unique constraint name is required")
+	}
+	if len(uc.Fields) == 0 {
+		return fmt.Errorf("unique constraint %s: at least one field is required", uc.Name)
+	}
+	for _, fieldName := range uc.Fields {
+		if table.GetFieldByName(fieldName) == nil {
+			return fmt.Errorf("unique constraint %s: field '%s' does not exist in table", uc.Name, fieldName)
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of an override map in a stable order.
+func sortedKeys[V any](m map[DatabaseType]V) []DatabaseType {
+	keys := make([]DatabaseType, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
 // Validate validates the index structure
 func (i *Index) Validate(table Table) error {
 	if i.Name == "" {
@@ -408,5 +1019,16 @@ func (i *Index) Validate(table Table) error {
 		}
 	}
 
+	for _, dbType := range sortedKeys(i.Overrides) {
+		if _, err := ParseDatabaseType(string(dbType)); err != nil {
+			return fmt.Errorf("index %s: override: %w", i.Name, err)
+		}
+		for _, fieldName := range i.Overrides[dbType].Fields {
+			if !fieldMap[fieldName] {
+				return fmt.Errorf("index %s: override %s: field '%s' does not exist in table", i.Name, dbType, fieldName)
+			}
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,46 @@
+package types
+
+import "testing"
+
+func TestSchema_ApplyCollationDefaults(t *testing.T) {
+	s := Schema{
+		Database: Database{Name: "test", Collation: "utf8mb4_0900_ai_ci", Charset: "utf8mb4"},
+		Tables: []Table{
+			{Name: "users", Fields: []Field{
+				{Name: "id", Type: "serial"},
+				{Name: "email", Type: "varchar", Length: 255},
+				{Name: "slug", Type: "varchar", Length: 64, Collation: "utf8mb4_bin"},
+			}},
+			{Name: "tags", Collation: "utf8mb4_general_ci", Fields: []Field{
+				{Name: "label", Type: "text"},
+			}},
+		},
+	}
+	s.ApplyCollationDefaults()
+
+	users := s.GetTableByName("users")
+	if f := users.GetFieldByName("id"); f.Collation != "" || f.Charset != "" {
+		t.Errorf("expected non-text field to be left alone, got %+v", *f)
+	}
+	if f := users.GetFieldByName("email"); f.Collation != "utf8mb4_0900_ai_ci" || f.Charset != "utf8mb4" {
+		t.Errorf("expected database defaults on email, got %+v", *f)
+	}
+	if f := users.GetFieldByName("slug"); f.Collation != "utf8mb4_bin" || f.Charset != "" {
+		t.Errorf("expected explicit field collation to win without inheriting charset, got %+v", *f)
+	}
+	// The table sets a collation only, so the database charset is not mixed in.
+	if f := s.GetTableByName("tags").GetFieldByName("label"); f.Collation != "utf8mb4_general_ci" || f.Charset != "" {
+		t.Errorf("expected table defaults on label, got %+v", *f)
+	}
+}
+
+func TestField_Validate_Collation(t *testing.T) {
+	ok := Field{Name: "email", Type: "varchar", Length: 255, Collation: "C"}
+	if err := ok.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bad := Field{Name: "age", Type: "integer", Collation: "C"}
+	if err := bad.Validate(); err == nil {
+		t.Fatal("expected error for collation on a non-text field")
+	}
+}
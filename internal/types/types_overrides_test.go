@@ -0,0 +1,156 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const overridesYAML = `
+database:
+  name: test
+tables:
+  - name: users
+    fields:
+      - name: id
+        type: serial
+        primary_key: true
+      - name: email
+        type: varchar
+        length: 255
+        default: "''"
+        overrides:
+          postgresql:
+            type: citext
+          sqlite:
+            collation: NOCASE
+            default: ""
+      - name: active
+        type: boolean
+        overrides:
+          mysql:
+            nullable: false
+    indexes:
+      - name: users_email_idx
+        fields: [email]
+        overrides:
+          postgresql:
+            method: gin
+            where: "active"
+          mysql:
+            unique: true
+`
+
+func parseOverrides(t *testing.T, src string) *Schema {
+	t.Helper()
+	var s Schema
+	if err := yaml.Unmarshal([]byte(src), &s); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return &s
+}
+
+func TestSchema_ApplyOverrides(t *testing.T) {
+	s := parseOverrides(t, overridesYAML)
+	if err := s.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	pg := parseOverrides(t, overridesYAML)
+	pg.ApplyOverrides(DatabasePostgreSQL)
+	users := pg.GetTableByName("users")
+	email := users.GetFieldByName("email")
+	if email.Type != "citext" || email.Default != "''" || email.Overrides != nil {
+		t.Errorf("unexpected postgresql email field: %+v", *email)
+	}
+	if idx := users.Indexes[0]; idx.Method != "gin" || idx.Where != "active" || idx.Unique || idx.Overrides != nil {
+		t.Errorf("unexpected postgresql index: %+v", idx)
+	}
+
+	lite := parseOverrides(t, overridesYAML)
+	lite.ApplyOverrides(DatabaseSQLite)
+	email = lite.GetTableByName("users").GetFieldByName("email")
+	if email.Type != "varchar" || email.Collation != "NOCASE" || email.Default != "" {
+		t.Errorf("unexpected sqlite email field: %+v", *email)
+	}
+
+	my := parseOverrides(t, overridesYAML)
+	my.ApplyOverrides(DatabaseMySQL)
+	users = my.GetTableByName("users")
+	if users.GetFieldByName("active").IsNullable() {
+		t.Error("expected mysql override to make active NOT NULL")
+	}
+	if !users.Indexes[0].Unique || users.Indexes[0].Method != "" {
+		t.Errorf("unexpected mysql index: %+v", users.Indexes[0])
+	}
+}
+
+func TestFieldOverride_UnknownKey(t *testing.T) {
+	src := strings.Replace(overridesYAML, "type: citext", "typ: citext", 1)
+	var s Schema
+	err := yaml.Unmarshal([]byte(src), &s)
+	if err == nil || !strings.Contains(err.Error(), "unknown keys: typ") {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+
+	src = strings.Replace(overridesYAML, "method: gin", "using: gin", 1)
+	err = yaml.Unmarshal([]byte(src), &s)
+	if err == nil || !strings.Contains(err.Error(), "index override: unknown keys: using") {
+		t.Fatalf("expected unknown index key error, got %v", err)
+	}
+}
+
+func TestField_Validate_Overrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		wantErr string
+	}{
+		{
+			name:    "unknown database",
+			field:   Field{Name: "email", Type: "text", Overrides: map[DatabaseType]FieldOverride{"postgres": {Type: "citext"}}},
+			wantErr: "unsupported database type: postgres",
+		},
+		{
+			name:    "invalid resolved field",
+			field:   Field{Name: "code", Type: "text", Overrides: map[DatabaseType]FieldOverride{DatabaseMySQL: {Type: "varchar"}}},
+			wantErr: "override mysql: varchar field must have a positive length",
+		},
+		{
+			name:    "relationship type",
+			field:   Field{Name: "owner", Type: "integer", Overrides: map[DatabaseType]FieldOverride{DatabaseSQLite: {Type: "foreign_key"}}},
+			wantErr: "type cannot be changed to foreign_key",
+		},
+		{
+			name:  "native type",
+			field: Field{Name: "email", Type: "varchar", Length: 255, Overrides: map[DatabaseType]FieldOverride{DatabasePostgreSQL: {Type: "citext"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.field.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestField_WithOverride_DropsCollationForNativeType(t *testing.T) {
+	f := Field{Name: "email", Type: "varchar", Length: 255, Collation: "C",
+		Overrides: map[DatabaseType]FieldOverride{DatabasePostgreSQL: {Type: "citext"}}}
+	got := f.WithOverride(DatabasePostgreSQL)
+	if got.Type != "citext" || got.Collation != "" {
+		t.Errorf("unexpected resolved field: %+v", got)
+	}
+	if f.Type != "varchar" || f.Overrides == nil {
+		t.Error("expected the original field to be unchanged")
+	}
+}
@@ -0,0 +1,113 @@
+package types
+
+import (
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const routinesYAML = `
+database:
+  name: test
+functions:
+  - name: touch_updated_at
+    returns: trigger
+    body:
+      postgresql: |
+        BEGIN NEW.updated_at = now(); RETURN NEW; END;
+  - name: archive_logs
+    procedure: true
+    body:
+      postgresql: BEGIN DELETE FROM logs; END;
+      mysql: BEGIN DELETE FROM logs; END
+triggers:
+  - name: orders_touch
+    table: orders
+    timing: BEFORE
+    events: [UPDATE]
+    function: touch_updated_at
+    body:
+      mysql: SET NEW.updated_at = NOW()
+  - name: orders_touch_pg_only
+    table: orders
+    timing: BEFORE
+    events: [INSERT]
+    function: touch_updated_at
+tables:
+  - name: orders
+    fields:
+      - name: updated_at
+        type: timestamp
+`
+
+func TestFunctionsAndTriggers_ParseAndValidate(t *testing.T) {
+	var s Schema
+	if err := yaml.Unmarshal([]byte(routinesYAML), &s); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	fn := s.GetFunctionByName("touch_updated_at")
+	if fn == nil || fn.Returns != "trigger" || fn.Body[DatabasePostgreSQL] == "" {
+		t.Fatalf("unexpected function: %+v", fn)
+	}
+	tr := s.GetTriggerByName("orders_touch")
+	if tr == nil || tr.Table != "orders" || len(tr.Events) != 1 {
+		t.Fatalf("unexpected trigger: %+v", tr)
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestSchema_SelectDatabase(t *testing.T) {
+	var s Schema
+	if err := yaml.Unmarshal([]byte(routinesYAML), &s); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	s.SelectDatabase(DatabaseMySQL)
+
+	if len(s.Functions) != 1 || s.Functions[0].Name != "archive_logs" {
+		t.Fatalf("expected only archive_logs for mysql, got %+v", s.Functions)
+	}
+	if len(s.Functions[0].Body) != 1 {
+		t.Fatalf("expected a single body, got %v", s.Functions[0].Body)
+	}
+	// orders_touch has its own mysql body; orders_touch_pg_only depends on a
+	// function with no mysql body and is dropped.
+	if len(s.Triggers) != 1 || s.Triggers[0].Name != "orders_touch" {
+		t.Fatalf("unexpected triggers for mysql: %+v", s.Triggers)
+	}
+}
+
+func TestFunctionAndTrigger_Validate(t *testing.T) {
+	body := map[DatabaseType]string{DatabasePostgreSQL: "BEGIN END;"}
+	fnCases := []struct {
+		fn      Function
+		wantErr bool
+	}{
+		{Function{Name: "f", Returns: "int", Body: body}, false},
+		{Function{Name: "p", Procedure: true, Body: body}, false},
+		{Function{Name: "f", Body: body}, true},
+		{Function{Name: "p", Procedure: true, Returns: "int", Body: body}, true},
+		{Function{Name: "f", Returns: "int"}, true},
+	}
+	for _, c := range fnCases {
+		if err := c.fn.Validate(); (err != nil) != c.wantErr {
+			t.Errorf("Function.Validate(%+v) error = %v, wantErr %v", c.fn, err, c.wantErr)
+		}
+	}
+
+	trCases := []struct {
+		tr      Trigger
+		wantErr bool
+	}{
+		{Trigger{Name: "t", Table: "x", Timing: "AFTER", Events: []string{"insert"}, Function: "f"}, false},
+		{Trigger{Name: "t", Table: "x", Timing: "DURING", Events: []string{"INSERT"}, Function: "f"}, true},
+		{Trigger{Name: "t", Table: "x", Timing: "AFTER", Events: []string{"SELECT"}, Function: "f"}, true},
+		{Trigger{Name: "t", Table: "x", Timing: "AFTER", Events: []string{"INSERT"}}, true},
+	}
+	for _, c := range trCases {
+		if err := c.tr.Validate(); (err != nil) != c.wantErr {
+			t.Errorf("Trigger.Validate(%+v) error = %v, wantErr %v", c.tr, err, c.wantErr)
+		}
+	}
+}
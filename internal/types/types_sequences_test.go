@@ -0,0 +1,117 @@
+package types
+
+import (
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestSchema_Sequences_Unmarshal(t *testing.T) {
+	input := `
+database:
+  name: test
+sequences:
+  - name: invoice_seq
+    start: 1000
+    increment: 1
+    max_value: 999999
+    cycle: true
+    owned_by: invoices.number
+tables:
+  - name: invoices
+    fields:
+      - name: number
+        type: bigint
+        default: nextval('invoice_seq')
+`
+	var s Schema
+	if err := yaml.Unmarshal([]byte(input), &s); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	seq := s.GetSequenceByName("invoice_seq")
+	if seq == nil {
+		t.Fatal("expected invoice_seq to be parsed")
+	}
+	if seq.StartValue() != 1000 || seq.MaxValue != 999999 || !seq.Cycle {
+		t.Errorf("unexpected sequence: %+v", *seq)
+	}
+	if table, field, ok := seq.OwnerParts(); !ok || table != "invoices" || field != "number" {
+		t.Errorf("OwnerParts = %q, %q, %v", table, field, ok)
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestSequence_ExplicitZeroYAML(t *testing.T) {
+	var seqs []Sequence
+	if err := yaml.Unmarshal([]byte("- {name: zero, start: 0, min_value: 0}\n- {name: unset}\n- {name: five, start: 5}\n"), &seqs); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	zero, unset, five := seqs[0], seqs[1], seqs[2]
+	if !zero.HasStart || zero.StartValue() != 0 {
+		t.Errorf("expected an explicit zero start, got %+v", zero)
+	}
+	if lo, ok := zero.MinBound(); !ok || lo != 0 {
+		t.Errorf("expected an explicit zero min_value, got %+v", zero)
+	}
+	if unset.HasStart || unset.StartValue() != 1 {
+		t.Errorf("expected an absent start to mean 1, got %+v", unset)
+	}
+	if _, ok := unset.MinBound(); ok {
+		t.Errorf("expected no min_value, got %+v", unset)
+	}
+	if five.HasStart || five.StartValue() != 5 {
+		t.Errorf("expected HasStart to be kept for zero only, got %+v", five)
+	}
+
+	out, err := yaml.Marshal(seqs)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var back []Sequence
+	if err := yaml.Unmarshal(out, &back); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	for i := range seqs {
+		if back[i] != seqs[i] {
+			t.Errorf("round trip changed %+v to %+v:\n%s", seqs[i], back[i], out)
+		}
+	}
+}
+
+func TestSequence_Validate(t *testing.T) {
+	cases := []struct {
+		seq     Sequence
+		wantErr bool
+	}{
+		{Sequence{Name: "ok"}, false},
+		{Sequence{}, true},
+		{Sequence{Name: "bounds", MinValue: 10, MaxValue: 5}, true},
+		{Sequence{Name: "owner", OwnedBy: "invoices"}, true},
+	}
+	for _, c := range cases {
+		if err := c.seq.Validate(); (err != nil) != c.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", c.seq, err, c.wantErr)
+		}
+	}
+}
+
+func TestParseNextValDefault(t *testing.T) {
+	cases := map[string]string{
+		"nextval('invoice_seq')": "invoice_seq",
+		"nextval(invoice_seq)":   "invoice_seq",
+		`NEXTVAL("order_seq")`:   "order_seq",
+	}
+	for in, want := range cases {
+		got, ok := ParseNextValDefault(in)
+		if !ok || got != want {
+			t.Errorf("ParseNextValDefault(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "now()", "nextval()", "uuid"} {
+		if _, ok := ParseNextValDefault(in); ok {
+			t.Errorf("ParseNextValDefault(%q) unexpectedly matched", in)
+		}
+	}
+}
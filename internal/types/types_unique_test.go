@@ -0,0 +1,90 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const uniqueYAML = `
+database:
+  name: test
+tables:
+  - name: users
+    fields:
+      - name: id
+        type: serial
+        primary_key: true
+      - name: tenant_id
+        type: integer
+      - name: email
+        type: varchar
+        length: 255
+        unique: true
+    unique_constraints:
+      - name: uq_users_tenant_email
+        fields: [tenant_id, email]
+        nulls_not_distinct: true
+`
+
+func TestSchema_UniqueConstraints(t *testing.T) {
+	var s Schema
+	if err := yaml.Unmarshal([]byte(uniqueYAML), &s); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	users := s.GetTableByName("users")
+	if !users.GetFieldByName("email").Unique {
+		t.Error("expected unique shorthand on email")
+	}
+	if uc := users.UniqueConstraints[0]; uc.Name != "uq_users_tenant_email" || len(uc.Fields) != 2 || !uc.NullsNotDistinct {
+		t.Errorf("unexpected unique constraint: %+v", uc)
+	}
+}
+
+func TestSchema_Validate_UniqueConstraints(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(t *Table)
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			edit:    func(t *Table) { t.UniqueConstraints[0].Fields = []string{"missing"} },
+			wantErr: "field 'missing' does not exist",
+		},
+		{
+			name:    "missing name",
+			edit:    func(t *Table) { t.UniqueConstraints[0].Name = "" },
+			wantErr: "unique constraint name is required",
+		},
+		{
+			name: "duplicate name",
+			edit: func(t *Table) {
+				t.UniqueConstraints = append(t.UniqueConstraints, t.UniqueConstraints[0])
+			},
+			wantErr: "duplicate unique constraint name",
+		},
+		{
+			name:    "unique primary key",
+			edit:    func(t *Table) { t.Fields[0].Unique = true },
+			wantErr: "unique is implied by primary_key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Schema
+			if err := yaml.Unmarshal([]byte(uniqueYAML), &s); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			tt.edit(&s.Tables[0])
+			err := s.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -122,6 +122,9 @@ func MergeAndValidateSchemas(components *YAMLComponents, allSchemas []*yamlpkg.S
 		return nil, fmt.Errorf("failed to merge schemas: %w", err)
 	}
 
+	// Keep only the function/trigger bodies for the target database
+	mergedSchema.SelectDatabase(dbType)
+
 	if verbose {
 		color.Green("Merged schema: %d tables\n", len(mergedSchema.Tables))
 		color.Blue("Available tables:")
@@ -150,6 +153,13 @@ func MergeAndValidateSchemas(components *YAMLComponents, allSchemas []*yamlpkg.S
 		}
 	}
 
+	// Resolve per-database field and index overrides, push table and
+	// database collation/charset defaults down to text columns, and expand
+	// `unique: true` fields into named unique constraints
+	mergedSchema.ApplyOverrides(dbType)
+	mergedSchema.ApplyCollationDefaults()
+	yamlpkg.ExpandUniqueFields(mergedSchema)
+
 	return mergedSchema, nil
 }
 
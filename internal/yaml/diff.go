@@ -27,6 +27,8 @@ package yaml
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -136,26 +138,60 @@ type Change struct {
 	OldValue    interface{} `json:"old_value,omitempty"`
 	NewValue    interface{} `json:"new_value,omitempty"`
 	Destructive bool        `json:"destructive"`
+	// Property names the field property a field_modified change is about,
+	// since OldValue/NewValue alone cannot tell e.g. nullable from
+	// primary_key. It is empty for other change types.
+	Property FieldProperty `json:"property,omitempty"`
 }
 
+// FieldProperty identifies the field property changed by a field_modified change.
+type FieldProperty string
+
+// FieldProperty constants name the properties compareFieldsForChanges reports.
+const (
+	FieldPropertyType       FieldProperty = "type"
+	FieldPropertyLength     FieldProperty = "length"
+	FieldPropertyPrecision  FieldProperty = "precision"
+	FieldPropertyNullable   FieldProperty = "nullable"
+	FieldPropertyPrimaryKey FieldProperty = "primary_key"
+	FieldPropertyDefault    FieldProperty = "default"
+	FieldPropertyCollation  FieldProperty = "collation"
+	FieldPropertyForeignKey FieldProperty = "foreign_key"
+)
+
 // ChangeType represents the type of change
 type ChangeType string
 
 // ChangeType constants enumerate the kinds of schema changes that can be detected.
 const (
-	ChangeTypeTableAdded           ChangeType = "table_added"
-	ChangeTypeTableRemoved         ChangeType = "table_removed"
-	ChangeTypeTableRenamed         ChangeType = "table_renamed"
-	ChangeTypeFieldAdded           ChangeType = "field_added"
-	ChangeTypeFieldRemoved         ChangeType = "field_removed"
-	ChangeTypeFieldRenamed         ChangeType = "field_renamed"
-	ChangeTypeFieldModified        ChangeType = "field_modified"
-	ChangeTypeIndexAdded           ChangeType = "index_added"
-	ChangeTypeIndexRemoved         ChangeType = "index_removed"
-	ChangeTypeForeignKeyAdded      ChangeType = "foreign_key_added"
-	ChangeTypeForeignKeyRemoved    ChangeType = "foreign_key_removed"
-	ChangeTypeDefaultsModified     ChangeType = "defaults_modified"      // non-destructive: updates active schema defaults
-	ChangeTypeTypeMappingsModified ChangeType = "type_mappings_modified" // non-destructive: updates active provider type mappings
+	ChangeTypeTableAdded              ChangeType = "table_added"
+	ChangeTypeTableRemoved            ChangeType = "table_removed"
+	ChangeTypeTableRenamed            ChangeType = "table_renamed"
+	ChangeTypeFieldAdded              ChangeType = "field_added"
+	ChangeTypeFieldRemoved            ChangeType = "field_removed"
+	ChangeTypeFieldRenamed            ChangeType = "field_renamed"
+	ChangeTypeFieldModified           ChangeType = "field_modified"
+	ChangeTypeIndexAdded              ChangeType = "index_added"
+	ChangeTypeIndexRemoved            ChangeType = "index_removed"
+	ChangeTypeUniqueConstraintAdded   ChangeType = "unique_constraint_added"
+	ChangeTypeUniqueConstraintRemoved ChangeType = "unique_constraint_removed"
+	ChangeTypeForeignKeyAdded         ChangeType = "foreign_key_added"
+	ChangeTypeForeignKeyRemoved       ChangeType = "foreign_key_removed"
+	ChangeTypeDefaultsModified        ChangeType = "defaults_modified"      // non-destructive: updates active schema defaults
+	ChangeTypeTypeMappingsModified    ChangeType = "type_mappings_modified" // non-destructive: updates active provider type mappings
+	// Sequence changes carry the sequence name in Change.TableName and
+	// Sequence values in OldValue/NewValue.
+	ChangeTypeSequenceAdded    ChangeType = "sequence_added"
+	ChangeTypeSequenceRemoved  ChangeType = "sequence_removed"
+	ChangeTypeSequenceModified ChangeType = "sequence_modified"
+	// Function and trigger changes likewise carry the object name in
+	// Change.TableName and Function/Trigger values in OldValue/NewValue.
+	ChangeTypeFunctionAdded    ChangeType = "function_added"
+	ChangeTypeFunctionRemoved  ChangeType = "function_removed"
+	ChangeTypeFunctionModified ChangeType = "function_modified"
+	ChangeTypeTriggerAdded     ChangeType = "trigger_added"
+	ChangeTypeTriggerRemoved   ChangeType = "trigger_removed"
+	ChangeTypeTriggerModified  ChangeType = "trigger_modified"
 )
 
 // SchemaDiff represents the complete difference between two schemas
@@ -184,6 +220,24 @@ func (de *DiffEngine) CompareSchemas(oldSchema, newSchema *Schema) (*SchemaDiff,
 		HasChanges: false,
 	}
 
+	// Sequences are created before tables so nextval defaults resolve, and are
+	// altered/dropped after them so owners exist and dependent defaults are gone.
+	seqBefore, seqAfter := de.compareSequences(oldSchema, newSchema)
+	diff.Changes = append(diff.Changes, seqBefore...)
+
+	// Triggers are dropped before tables change so dropped tables and columns
+	// are not still referenced; functions and new or modified triggers follow
+	// the table changes. Routine changes run ahead of the trailing sequence
+	// changes so dropped sequences are no longer used by function bodies.
+	routinesBefore, routinesAfter := de.compareRoutines(oldSchema, newSchema)
+	diff.Changes = append(diff.Changes, routinesBefore...)
+	seqAfter = append(routinesAfter, seqAfter...)
+	for _, change := range routinesBefore {
+		if change.Destructive {
+			diff.IsDestructive = true
+		}
+	}
+
 	// Handle case where old schema is nil (initial migration)
 	if oldSchema == nil {
 		if newSchema != nil {
@@ -198,12 +252,14 @@ func (de *DiffEngine) CompareSchemas(oldSchema, newSchema *Schema) (*SchemaDiff,
 					Description: fmt.Sprintf("Add table '%s'", table.Name),
 					NewValue:    table,
 				})
+				diff.Changes = append(diff.Changes, uniqueChangesForNewTable(&table)...)
 				// Collect FK changes to emit after all CreateTable operations so that
 				// every referenced table exists before any FK constraint is added.
 				allFKChanges = append(allFKChanges, fkChangesForFields(table.Name, table.Fields, nil)...)
 			}
 			diff.Changes = append(diff.Changes, allFKChanges...)
 		}
+		diff.Changes = append(diff.Changes, seqAfter...)
 		diff.HasChanges = len(diff.Changes) > 0
 		return diff, nil
 	}
@@ -219,6 +275,7 @@ func (de *DiffEngine) CompareSchemas(oldSchema, newSchema *Schema) (*SchemaDiff,
 				Destructive: true,
 			})
 		}
+		diff.Changes = append(diff.Changes, seqAfter...)
 		diff.HasChanges = len(diff.Changes) > 0
 		diff.IsDestructive = true
 		return diff, nil
@@ -254,6 +311,7 @@ func (de *DiffEngine) CompareSchemas(oldSchema, newSchema *Schema) (*SchemaDiff,
 			Description: fmt.Sprintf("Add table '%s'", table.Name),
 			NewValue:    table,
 		})
+		diff.Changes = append(diff.Changes, uniqueChangesForNewTable(&table)...)
 		addedFKChanges = append(addedFKChanges, fkChangesForFields(table.Name, table.Fields, nil)...)
 		if de.verbose {
 			fmt.Printf("Table added: %s\n", table.Name)
@@ -295,6 +353,13 @@ func (de *DiffEngine) CompareSchemas(oldSchema, newSchema *Schema) (*SchemaDiff,
 		}
 	}
 
+	for _, change := range seqAfter {
+		if change.Destructive {
+			diff.IsDestructive = true
+		}
+	}
+	diff.Changes = append(diff.Changes, seqAfter...)
+
 	diff.HasChanges = len(diff.Changes) > 0
 
 	if de.verbose {
@@ -305,9 +370,248 @@ func (de *DiffEngine) CompareSchemas(oldSchema, newSchema *Schema) (*SchemaDiff,
 	return diff, nil
 }
 
+// compareSequences compares the sequences of two schemas. It returns the
+// changes to emit before table changes (new sequences) and after them
+// (option changes, deferred owner assignment and removals). A new sequence
+// whose owning column does not exist yet is created without an owner and
+// assigned to it by a follow-up sequence_modified change. Either schema may
+// be nil. Results are sorted by sequence name for deterministic output.
+func (de *DiffEngine) compareSequences(oldSchema, newSchema *Schema) (before, after []Change) {
+	oldSeqs := make(map[string]Sequence)
+	newSeqs := make(map[string]Sequence)
+	if oldSchema != nil {
+		for _, seq := range oldSchema.Sequences {
+			oldSeqs[seq.Name] = seq
+		}
+	}
+	if newSchema != nil {
+		for _, seq := range newSchema.Sequences {
+			newSeqs[seq.Name] = seq
+		}
+	}
+
+	var removed, modified []Change
+	for _, name := range sortedKeys(newSeqs) {
+		newSeq := newSeqs[name]
+		oldSeq, exists := oldSeqs[name]
+		if !exists {
+			created := newSeq
+			if table, field, ok := newSeq.OwnerParts(); ok && !schemaHasField(oldSchema, table, field) {
+				created.OwnedBy = ""
+				modified = append(modified, Change{
+					Type:        ChangeTypeSequenceModified,
+					TableName:   name,
+					Description: fmt.Sprintf("Set owner of sequence '%s' to %s", name, newSeq.OwnedBy),
+					OldValue:    created,
+					NewValue:    newSeq,
+				})
+			}
+			before = append(before, Change{
+				Type:        ChangeTypeSequenceAdded,
+				TableName:   name,
+				Description: fmt.Sprintf("Add sequence '%s'", name),
+				NewValue:    created,
+			})
+			if de.verbose {
+				fmt.Printf("Sequence added: %s\n", name)
+			}
+			continue
+		}
+		if oldSeq != newSeq {
+			modified = append(modified, Change{
+				Type:        ChangeTypeSequenceModified,
+				TableName:   name,
+				Description: fmt.Sprintf("Modify sequence '%s'", name),
+				OldValue:    oldSeq,
+				NewValue:    newSeq,
+			})
+			if de.verbose {
+				fmt.Printf("Sequence modified: %s\n", name)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(oldSeqs) {
+		if _, exists := newSeqs[name]; exists {
+			continue
+		}
+		removed = append(removed, Change{
+			Type:        ChangeTypeSequenceRemoved,
+			TableName:   name,
+			Description: fmt.Sprintf("Remove sequence '%s'", name),
+			OldValue:    oldSeqs[name],
+			Destructive: true,
+		})
+		if de.verbose {
+			fmt.Printf("Sequence removed: %s\n", name)
+		}
+	}
+
+	return before, append(modified, removed...)
+}
+
+// schemaHasField reports whether schema (which may be nil) contains table.field.
+func schemaHasField(schema *Schema, tableName, fieldName string) bool {
+	if schema == nil {
+		return false
+	}
+	table := schema.GetTableByName(tableName)
+	return table != nil && table.GetFieldByName(fieldName) != nil
+}
+
+// compareRoutines compares the functions and triggers of two schemas at body
+// level. It returns the changes to emit before table changes (trigger
+// removals) and after them, in the order: functions added, functions
+// modified, triggers modified, triggers added, functions removed. Either
+// schema may be nil. Results are sorted by name for deterministic output.
+func (de *DiffEngine) compareRoutines(oldSchema, newSchema *Schema) (before, after []Change) {
+	oldFns := make(map[string]Function)
+	newFns := make(map[string]Function)
+	oldTrs := make(map[string]Trigger)
+	newTrs := make(map[string]Trigger)
+	if oldSchema != nil {
+		for _, fn := range oldSchema.Functions {
+			oldFns[fn.Name] = fn
+		}
+		for _, tr := range oldSchema.Triggers {
+			oldTrs[tr.Name] = tr
+		}
+	}
+	if newSchema != nil {
+		for _, fn := range newSchema.Functions {
+			newFns[fn.Name] = fn
+		}
+		for _, tr := range newSchema.Triggers {
+			newTrs[tr.Name] = tr
+		}
+	}
+
+	var fnAdded, fnModified, fnRemoved []Change
+	for _, name := range sortedKeys(newFns) {
+		newFn := newFns[name]
+		oldFn, exists := oldFns[name]
+		switch {
+		case !exists:
+			fnAdded = append(fnAdded, Change{
+				Type:        ChangeTypeFunctionAdded,
+				TableName:   name,
+				Description: fmt.Sprintf("Add function '%s'", name),
+				NewValue:    newFn,
+			})
+		case !functionsEqual(oldFn, newFn):
+			fnModified = append(fnModified, Change{
+				Type:        ChangeTypeFunctionModified,
+				TableName:   name,
+				Description: fmt.Sprintf("Modify function '%s'", name),
+				OldValue:    oldFn,
+				NewValue:    newFn,
+			})
+		}
+	}
+	for _, name := range sortedKeys(oldFns) {
+		if _, exists := newFns[name]; !exists {
+			fnRemoved = append(fnRemoved, Change{
+				Type:        ChangeTypeFunctionRemoved,
+				TableName:   name,
+				Description: fmt.Sprintf("Remove function '%s'", name),
+				OldValue:    oldFns[name],
+				Destructive: true,
+			})
+		}
+	}
+
+	var trAdded, trModified []Change
+	for _, name := range sortedKeys(newTrs) {
+		newTr := newTrs[name]
+		oldTr, exists := oldTrs[name]
+		switch {
+		case !exists:
+			trAdded = append(trAdded, Change{
+				Type:        ChangeTypeTriggerAdded,
+				TableName:   name,
+				Description: fmt.Sprintf("Add trigger '%s' on '%s'", name, newTr.Table),
+				NewValue:    newTr,
+			})
+		case !triggersEqual(oldTr, newTr):
+			trModified = append(trModified, Change{
+				Type:        ChangeTypeTriggerModified,
+				TableName:   name,
+				Description: fmt.Sprintf("Modify trigger '%s' on '%s'", name, newTr.Table),
+				OldValue:    oldTr,
+				NewValue:    newTr,
+			})
+		}
+	}
+	for _, name := range sortedKeys(oldTrs) {
+		if _, exists := newTrs[name]; !exists {
+			before = append(before, Change{
+				Type:        ChangeTypeTriggerRemoved,
+				TableName:   name,
+				Description: fmt.Sprintf("Remove trigger '%s' from '%s'", name, oldTrs[name].Table),
+				OldValue:    oldTrs[name],
+				Destructive: true,
+			})
+		}
+	}
+
+	if de.verbose {
+		for _, group := range [][]Change{before, fnAdded, fnModified, trModified, trAdded, fnRemoved} {
+			for _, change := range group {
+				fmt.Printf("%s\n", change.Description)
+			}
+		}
+	}
+
+	after = append(after, fnAdded...)
+	after = append(after, fnModified...)
+	after = append(after, trModified...)
+	after = append(after, trAdded...)
+	after = append(after, fnRemoved...)
+	return before, after
+}
+
+// sortedKeys returns the keys of a name-keyed map in sorted order.
+func sortedKeys[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// functionsEqual reports whether two functions have the same signature and bodies.
+func functionsEqual(a, b Function) bool {
+	return a.Name == b.Name && a.Arguments == b.Arguments && a.Returns == b.Returns &&
+		a.Language == b.Language && a.Procedure == b.Procedure && maps.Equal(a.Body, b.Body)
+}
+
+// triggersEqual reports whether two triggers have the same definition and bodies.
+func triggersEqual(a, b Trigger) bool {
+	return a.Name == b.Name && a.Table == b.Table &&
+		strings.EqualFold(a.Timing, b.Timing) && strings.EqualFold(a.ForEach, b.ForEach) &&
+		slices.Equal(a.Events, b.Events) && a.When == b.When && a.Function == b.Function &&
+		maps.Equal(a.Body, b.Body)
+}
+
+// collationLabel describes a field's collation and charset for change output,
+// e.g. "utf8mb4/utf8mb4_bin" or "C".
+func collationLabel(f *Field) string {
+	if f.Charset == "" {
+		return f.Collation
+	}
+	if f.Collation == "" {
+		return f.Charset
+	}
+	return f.Charset + "/" + f.Collation
+}
+
 // compareTablesForChanges compares two tables and returns the field-level changes
 func (de *DiffEngine) compareTablesForChanges(oldTable, newTable *Table) ([]Change, error) {
-	var changes []Change
+	// Unique constraints are dropped before fields change so removed columns
+	// are no longer constrained, and added after so new columns exist.
+	ucRemoved, ucAdded := de.compareUniqueConstraints(oldTable, newTable)
+	changes := ucRemoved
 
 	// Compare fields
 	oldFields := make(map[string]*Field)
@@ -394,6 +698,7 @@ func (de *DiffEngine) compareTablesForChanges(oldTable, newTable *Table) ([]Chan
 	// Compare indexes
 	indexChanges := de.compareIndexes(oldTable, newTable)
 	changes = append(changes, indexChanges...)
+	changes = append(changes, ucAdded...)
 
 	return changes, nil
 }
@@ -412,6 +717,7 @@ func (de *DiffEngine) compareFieldsForChanges(tableName string, oldField, newFie
 			OldValue:    oldField.Type,
 			NewValue:    newField.Type,
 			Destructive: de.isTypeChangeDestructive(oldField.Type, newField.Type),
+			Property:    FieldPropertyType,
 		})
 	}
 
@@ -426,6 +732,7 @@ func (de *DiffEngine) compareFieldsForChanges(tableName string, oldField, newFie
 			OldValue:    oldField.Length,
 			NewValue:    newField.Length,
 			Destructive: destructive,
+			Property:    FieldPropertyLength,
 		})
 	}
 
@@ -441,6 +748,7 @@ func (de *DiffEngine) compareFieldsForChanges(tableName string, oldField, newFie
 			OldValue:    fmt.Sprintf("%d,%d", oldField.Precision, oldField.Scale),
 			NewValue:    fmt.Sprintf("%d,%d", newField.Precision, newField.Scale),
 			Destructive: destructive,
+			Property:    FieldPropertyPrecision,
 		})
 	}
 
@@ -455,6 +763,7 @@ func (de *DiffEngine) compareFieldsForChanges(tableName string, oldField, newFie
 			OldValue:    oldField.IsNullable(),
 			NewValue:    newField.IsNullable(),
 			Destructive: destructive,
+			Property:    FieldPropertyNullable,
 		})
 	}
 
@@ -468,6 +777,7 @@ func (de *DiffEngine) compareFieldsForChanges(tableName string, oldField, newFie
 			OldValue:    oldField.PrimaryKey,
 			NewValue:    newField.PrimaryKey,
 			Destructive: !newField.PrimaryKey, // Removing primary key is destructive
+			Property:    FieldPropertyPrimaryKey,
 		})
 	}
 
@@ -480,6 +790,22 @@ func (de *DiffEngine) compareFieldsForChanges(tableName string, oldField, newFie
 			Description: fmt.Sprintf("Change field '%s.%s' default from '%s' to '%s'", tableName, oldField.Name, oldField.Default, newField.Default),
 			OldValue:    oldField.Default,
 			NewValue:    newField.Default,
+			Property:    FieldPropertyDefault,
+		})
+	}
+
+	// Collation/charset changes — reported together since MySQL changes
+	// both with a single column redefinition.
+	if oldField.Collation != newField.Collation || oldField.Charset != newField.Charset {
+		changes = append(changes, Change{
+			Type:      ChangeTypeFieldModified,
+			TableName: tableName,
+			FieldName: oldField.Name,
+			Description: fmt.Sprintf("Change field '%s.%s' collation from '%s' to '%s'",
+				tableName, oldField.Name, collationLabel(oldField), collationLabel(newField)),
+			OldValue: collationLabel(oldField),
+			NewValue: collationLabel(newField),
+			Property: FieldPropertyCollation,
 		})
 	}
 
@@ -527,6 +853,7 @@ func (de *DiffEngine) compareFieldsForChanges(tableName string, oldField, newFie
 				OldValue:    oldField.ForeignKey,
 				NewValue:    newField.ForeignKey,
 				Destructive: newField.ForeignKey == nil && oldField.ForeignKey != nil,
+				Property:    FieldPropertyForeignKey,
 			})
 		}
 	}
@@ -590,6 +917,28 @@ func (de *DiffEngine) GenerateMigrationName(diff *SchemaDiff) string {
 			return fmt.Sprintf("remove_%s_from_%s", change.FieldName, change.TableName)
 		case ChangeTypeFieldModified:
 			return fmt.Sprintf("modify_%s_in_%s", change.FieldName, change.TableName)
+		case ChangeTypeSequenceAdded:
+			return fmt.Sprintf("add_%s_sequence", change.TableName)
+		case ChangeTypeSequenceRemoved:
+			return fmt.Sprintf("remove_%s_sequence", change.TableName)
+		case ChangeTypeSequenceModified:
+			return fmt.Sprintf("modify_%s_sequence", change.TableName)
+		case ChangeTypeFunctionAdded:
+			return fmt.Sprintf("add_%s_function", change.TableName)
+		case ChangeTypeFunctionRemoved:
+			return fmt.Sprintf("remove_%s_function", change.TableName)
+		case ChangeTypeFunctionModified:
+			return fmt.Sprintf("modify_%s_function", change.TableName)
+		case ChangeTypeTriggerAdded:
+			return fmt.Sprintf("add_%s_trigger", change.TableName)
+		case ChangeTypeTriggerRemoved:
+			return fmt.Sprintf("remove_%s_trigger", change.TableName)
+		case ChangeTypeTriggerModified:
+			return fmt.Sprintf("modify_%s_trigger", change.TableName)
+		case ChangeTypeUniqueConstraintAdded:
+			return fmt.Sprintf("add_%s_unique", change.FieldName)
+		case ChangeTypeUniqueConstraintRemoved:
+			return fmt.Sprintf("remove_%s_unique", change.FieldName)
 		}
 	}
 
@@ -760,6 +1109,82 @@ func isIndexEqual(idx1, idx2 *Index) bool {
 	return true
 }
 
+// uniqueChangesForNewTable returns a unique_constraint_added change for each
+// of a new table's unique constraints, in declaration order.
+func uniqueChangesForNewTable(table *Table) []Change {
+	var changes []Change
+	for _, uc := range table.UniqueConstraints {
+		changes = append(changes, Change{
+			Type:        ChangeTypeUniqueConstraintAdded,
+			TableName:   table.Name,
+			FieldName:   uc.Name,
+			Description: fmt.Sprintf("Add unique constraint '%s' on table '%s'", uc.Name, table.Name),
+			NewValue:    uc,
+		})
+	}
+	return changes
+}
+
+// compareUniqueConstraints compares the unique constraints of two versions of
+// a table. A changed definition is dropped and re-added under the same name.
+// Results are sorted by constraint name for deterministic output.
+func (de *DiffEngine) compareUniqueConstraints(oldTable, newTable *Table) (removed, added []Change) {
+	oldUCs := make(map[string]UniqueConstraint)
+	newUCs := make(map[string]UniqueConstraint)
+	for _, uc := range oldTable.UniqueConstraints {
+		oldUCs[uc.Name] = uc
+	}
+	for _, uc := range newTable.UniqueConstraints {
+		newUCs[uc.Name] = uc
+	}
+
+	for _, name := range sortedKeys(oldUCs) {
+		oldUC := oldUCs[name]
+		newUC, exists := newUCs[name]
+		if exists && uniqueConstraintsEqual(oldUC, newUC) {
+			continue
+		}
+		description := fmt.Sprintf("Remove unique constraint '%s' from table '%s'", name, newTable.Name)
+		if exists {
+			description += " (will be recreated)"
+		}
+		removed = append(removed, Change{
+			Type:        ChangeTypeUniqueConstraintRemoved,
+			TableName:   newTable.Name,
+			FieldName:   name,
+			Description: description,
+			OldValue:    oldUC,
+			Destructive: true,
+		})
+		if de.verbose {
+			fmt.Printf("  Unique constraint removed: %s from %s\n", name, newTable.Name)
+		}
+	}
+
+	for _, name := range sortedKeys(newUCs) {
+		newUC := newUCs[name]
+		if oldUC, exists := oldUCs[name]; exists && uniqueConstraintsEqual(oldUC, newUC) {
+			continue
+		}
+		added = append(added, Change{
+			Type:        ChangeTypeUniqueConstraintAdded,
+			TableName:   newTable.Name,
+			FieldName:   name,
+			Description: fmt.Sprintf("Add unique constraint '%s' on table '%s'", name, newTable.Name),
+			NewValue:    newUC,
+		})
+		if de.verbose {
+			fmt.Printf("  Unique constraint added: %s on %s\n", name, newTable.Name)
+		}
+	}
+	return removed, added
+}
+
+// uniqueConstraintsEqual reports whether two unique constraints are identical.
+func uniqueConstraintsEqual(a, b UniqueConstraint) bool {
+	return a.Name == b.Name && a.NullsNotDistinct == b.NullsNotDistinct && slices.Equal(a.Fields, b.Fields)
+}
+
 // fkChangesForFields emits FK change records for foreign_key fields that are
 // present in addedFields (ChangeTypeForeignKeyAdded) or removedFields (ChangeTypeForeignKeyRemoved).
 func fkChangesForFields(tableName string, addedFields, removedFields []Field) []Change {
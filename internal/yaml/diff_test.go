@@ -24,6 +24,7 @@ SOFTWARE.
 package yaml
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -395,3 +396,207 @@ func TestGetDestructiveChanges(t *testing.T) {
 		}
 	}
 }
+
+func TestCompareSchemas_Sequences(t *testing.T) {
+	de := NewDiffEngine(false)
+
+	oldSchema := &Schema{
+		Database:  Database{Name: "test", Version: "1.0"},
+		Tables:    []Table{{Name: "users", Fields: []Field{{Name: "id", Type: "serial", PrimaryKey: true}}}},
+		Sequences: []Sequence{{Name: "legacy_seq"}, {Name: "user_seq", Increment: 1}},
+	}
+	newSchema := &Schema{
+		Database: Database{Name: "test", Version: "1.0"},
+		Tables: []Table{
+			{Name: "users", Fields: []Field{{Name: "id", Type: "serial", PrimaryKey: true}}},
+			{Name: "invoices", Fields: []Field{
+				{Name: "id", Type: "serial", PrimaryKey: true},
+				{Name: "number", Type: "bigint", Default: "nextval('invoice_seq')"},
+			}},
+		},
+		Sequences: []Sequence{
+			{Name: "invoice_seq", Start: 1000, OwnedBy: "invoices.number"},
+			{Name: "user_seq", Increment: 5},
+		},
+	}
+
+	diff, err := de.CompareSchemas(oldSchema, newSchema)
+	if err != nil {
+		t.Fatalf("CompareSchemas: %v", err)
+	}
+
+	var order []ChangeType
+	for _, c := range diff.Changes {
+		order = append(order, c.Type)
+	}
+	// New sequence first (owner stripped), table next, then owner assignment,
+	// option change and removal.
+	want := []ChangeType{
+		ChangeTypeSequenceAdded,
+		ChangeTypeTableAdded,
+		ChangeTypeSequenceModified,
+		ChangeTypeSequenceModified,
+		ChangeTypeSequenceRemoved,
+	}
+	if len(order) != len(want) {
+		t.Fatalf("expected changes %v, got %v", want, order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("expected changes %v, got %v", want, order)
+		}
+	}
+
+	added := diff.Changes[0].NewValue.(Sequence)
+	if added.OwnedBy != "" {
+		t.Errorf("expected owner to be deferred for a new owning table, got %q", added.OwnedBy)
+	}
+	owner := diff.Changes[2].NewValue.(Sequence)
+	if owner.OwnedBy != "invoices.number" {
+		t.Errorf("expected deferred owner assignment, got %q", owner.OwnedBy)
+	}
+	if !diff.IsDestructive {
+		t.Error("expected sequence removal to mark the diff destructive")
+	}
+}
+
+func TestCompareSchemas_FunctionsAndTriggers(t *testing.T) {
+	de := NewDiffEngine(false)
+	pg := func(body string) map[DatabaseType]string { return map[DatabaseType]string{DatabasePostgreSQL: body} }
+	orders := Table{Name: "orders", Fields: []Field{{Name: "id", Type: "serial", PrimaryKey: true}}}
+
+	oldSchema := &Schema{
+		Database: Database{Name: "test", Version: "1.0"},
+		Tables:   []Table{orders},
+		Functions: []Function{
+			{Name: "legacy", Returns: "int", Body: pg("BEGIN RETURN 1; END;")},
+			{Name: "touch", Returns: "trigger", Body: pg("BEGIN RETURN NEW; END;")},
+		},
+		Triggers: []Trigger{
+			{Name: "orders_legacy", Table: "orders", Timing: "AFTER", Events: []string{"DELETE"}, Function: "legacy"},
+			{Name: "orders_touch", Table: "orders", Timing: "BEFORE", Events: []string{"UPDATE"}, Function: "touch"},
+		},
+	}
+	newSchema := &Schema{
+		Database: Database{Name: "test", Version: "1.0"},
+		Tables:   []Table{orders},
+		Functions: []Function{
+			{Name: "audit", Returns: "trigger", Body: pg("BEGIN RETURN NEW; END;")},
+			{Name: "touch", Returns: "trigger", Body: pg("BEGIN NEW.updated_at = now(); RETURN NEW; END;")},
+		},
+		Triggers: []Trigger{
+			{Name: "orders_audit", Table: "orders", Timing: "AFTER", Events: []string{"INSERT"}, Function: "audit"},
+			{Name: "orders_touch", Table: "orders", Timing: "BEFORE", Events: []string{"INSERT", "UPDATE"}, Function: "touch"},
+		},
+	}
+
+	diff, err := de.CompareSchemas(oldSchema, newSchema)
+	if err != nil {
+		t.Fatalf("CompareSchemas: %v", err)
+	}
+	var got []string
+	for _, c := range diff.Changes {
+		got = append(got, string(c.Type)+":"+c.TableName)
+	}
+	want := []string{
+		"trigger_removed:orders_legacy",
+		"function_added:audit",
+		"function_modified:touch",
+		"trigger_modified:orders_touch",
+		"trigger_added:orders_audit",
+		"function_removed:legacy",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected changes %v, got %v", want, got)
+	}
+	if !diff.IsDestructive {
+		t.Error("expected function and trigger removals to mark the diff destructive")
+	}
+
+	same, err := de.CompareSchemas(newSchema, newSchema)
+	if err != nil {
+		t.Fatalf("CompareSchemas: %v", err)
+	}
+	if same.HasChanges {
+		t.Fatalf("expected no changes for identical schemas, got %+v", same.Changes)
+	}
+}
+
+func TestCompareSchemas_CollationChange(t *testing.T) {
+	de := NewDiffEngine(false)
+	oldSchema := &Schema{
+		Database: Database{Name: "test", Version: "1.0"},
+		Tables: []Table{{Name: "users", Fields: []Field{
+			{Name: "email", Type: "varchar", Length: 255},
+		}}},
+	}
+	newSchema := &Schema{
+		Database: Database{Name: "test", Version: "1.0"},
+		Tables: []Table{{Name: "users", Fields: []Field{
+			{Name: "email", Type: "varchar", Length: 255, Charset: "utf8mb4", Collation: "utf8mb4_bin"},
+		}}},
+	}
+
+	diff, err := de.CompareSchemas(oldSchema, newSchema)
+	if err != nil {
+		t.Fatalf("CompareSchemas: %v", err)
+	}
+	if len(diff.Changes) != 1 {
+		t.Fatalf("expected 1 change, got %d: %+v", len(diff.Changes), diff.Changes)
+	}
+	c := diff.Changes[0]
+	if c.Type != ChangeTypeFieldModified || c.FieldName != "email" || c.NewValue != "utf8mb4/utf8mb4_bin" {
+		t.Fatalf("unexpected change: %+v", c)
+	}
+}
+
+func TestCompareSchemas_UniqueConstraints(t *testing.T) {
+	de := NewDiffEngine(false)
+	fields := []Field{
+		{Name: "id", Type: "serial", PrimaryKey: true},
+		{Name: "email", Type: "varchar", Length: 255},
+		{Name: "tenant_id", Type: "integer"},
+	}
+	oldSchema := &Schema{
+		Database: Database{Name: "test", Version: "1.0"},
+		Tables: []Table{{Name: "users", Fields: fields, UniqueConstraints: []UniqueConstraint{
+			{Name: "uq_users_email", Fields: []string{"email"}},
+			{Name: "uq_users_legacy", Fields: []string{"tenant_id"}},
+		}}},
+	}
+	newSchema := &Schema{
+		Database: Database{Name: "test", Version: "1.0"},
+		Tables: []Table{{Name: "users", Fields: fields, UniqueConstraints: []UniqueConstraint{
+			{Name: "uq_users_email", Fields: []string{"tenant_id", "email"}, NullsNotDistinct: true},
+		}}},
+	}
+
+	diff, err := de.CompareSchemas(oldSchema, newSchema)
+	if err != nil {
+		t.Fatalf("CompareSchemas: %v", err)
+	}
+	var got []string
+	for _, c := range diff.Changes {
+		got = append(got, string(c.Type)+":"+c.FieldName)
+	}
+	want := []string{
+		"unique_constraint_removed:uq_users_email",
+		"unique_constraint_removed:uq_users_legacy",
+		"unique_constraint_added:uq_users_email",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("changes = %v, want %v", got, want)
+	}
+	if !diff.IsDestructive {
+		t.Error("expected removing a unique constraint to be destructive")
+	}
+
+	// A new table gets its constraints right after CREATE TABLE.
+	diff, err = de.CompareSchemas(nil, newSchema)
+	if err != nil {
+		t.Fatalf("CompareSchemas: %v", err)
+	}
+	if len(diff.Changes) != 2 || diff.Changes[1].Type != ChangeTypeUniqueConstraintAdded {
+		t.Fatalf("unexpected initial changes: %+v", diff.Changes)
+	}
+}
@@ -25,15 +25,17 @@ package yaml
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 
 	yaml "gopkg.in/yaml.v3"
+
+	"github.com/ocomsoft/makemigrations/internal/srcfs"
 )
 
 // IncludeProcessor handles processing schema includes with circular dependency tracking
 type IncludeProcessor struct {
 	resolver   *ModuleResolver
+	fs         srcfs.FS
 	verbose    bool
 	processed  map[string]bool // Track processed files to prevent circular dependencies
 	processing map[string]bool // Track currently processing files to detect immediate cycles
@@ -43,6 +45,7 @@ type IncludeProcessor struct {
 func NewIncludeProcessor(verbose bool) *IncludeProcessor {
 	return &IncludeProcessor{
 		resolver:   NewModuleResolver(verbose),
+		fs:         srcfs.OS,
 		verbose:    verbose,
 		processed:  make(map[string]bool),
 		processing: make(map[string]bool),
@@ -171,9 +174,16 @@ func (ip *IncludeProcessor) processInclude(include Include, _ string) ([]*Schema
 	return allIncludedSchemas, nil
 }
 
+// SetFS makes the processor read included files, and the go.mod and go.work
+// files used to resolve them, from fsys instead of the working tree.
+func (ip *IncludeProcessor) SetFS(fsys srcfs.FS) {
+	ip.fs = fsys
+	ip.resolver.fs = fsys
+}
+
 // loadSchemaFile loads a YAML schema file from disk
 func (ip *IncludeProcessor) loadSchemaFile(filePath string) (*Schema, error) {
-	data, err := os.ReadFile(filePath)
+	data, err := ip.fs.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
 	}
@@ -208,6 +218,36 @@ func (ip *IncludeProcessor) mergeSchemas(schemas []*Schema) (*Schema, error) {
 		Tables:       make([]Table, 0),
 	}
 
+	// Sequences: main schema wins on name conflicts, includes add new ones
+	seenSequences := make(map[string]bool)
+	for _, schema := range schemas {
+		for _, seq := range schema.Sequences {
+			if seenSequences[seq.Name] {
+				continue
+			}
+			seenSequences[seq.Name] = true
+			result.Sequences = append(result.Sequences, seq)
+		}
+	}
+
+	// Functions and triggers: main schema wins on name conflicts
+	seenFunctions := make(map[string]bool)
+	seenTriggers := make(map[string]bool)
+	for _, schema := range schemas {
+		for _, fn := range schema.Functions {
+			if !seenFunctions[fn.Name] {
+				seenFunctions[fn.Name] = true
+				result.Functions = append(result.Functions, fn)
+			}
+		}
+		for _, tr := range schema.Triggers {
+			if !seenTriggers[tr.Name] {
+				seenTriggers[tr.Name] = true
+				result.Triggers = append(result.Triggers, tr)
+			}
+		}
+	}
+
 	// Track tables by name to handle conflicts
 	tableMap := make(map[string]*Table)
 
@@ -25,8 +25,10 @@ package yaml
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/ocomsoft/makemigrations/internal/errors"
+	"github.com/ocomsoft/makemigrations/internal/utils"
 )
 
 // Merger handles YAML schema merging with conflict resolution
@@ -57,6 +59,9 @@ func (m *Merger) MergeSchemas(schemas []*Schema) (*Schema, error) {
 		Defaults:     m.mergeDefaults(schemas),
 		TypeMappings: m.mergeTypeMappings(schemas),
 		Tables:       make([]Table, 0),
+		Sequences:    m.mergeSequences(schemas),
+		Functions:    m.mergeFunctions(schemas),
+		Triggers:     m.mergeTriggers(schemas),
 	}
 
 	// Collect all tables from all schemas
@@ -121,6 +126,55 @@ func (m *Merger) mergeTypeMappings(schemas []*Schema) TypeMappings {
 	return merged
 }
 
+// mergeSequences merges sequence definitions from multiple schemas.
+// Later schemas override earlier ones for the same sequence name.
+func (m *Merger) mergeSequences(schemas []*Schema) []Sequence {
+	return mergeNamed(m, "Sequence", schemas,
+		func(s *Schema) []Sequence { return s.Sequences },
+		func(seq Sequence) string { return seq.Name })
+}
+
+// mergeFunctions merges function definitions from multiple schemas.
+// Later schemas override earlier ones for the same function name.
+func (m *Merger) mergeFunctions(schemas []*Schema) []Function {
+	return mergeNamed(m, "Function", schemas,
+		func(s *Schema) []Function { return s.Functions },
+		func(fn Function) string { return fn.Name })
+}
+
+// mergeTriggers merges trigger definitions from multiple schemas.
+// Later schemas override earlier ones for the same trigger name.
+func (m *Merger) mergeTriggers(schemas []*Schema) []Trigger {
+	return mergeNamed(m, "Trigger", schemas,
+		func(s *Schema) []Trigger { return s.Triggers },
+		func(tr Trigger) string { return tr.Name })
+}
+
+// mergeNamed merges named schema objects (sequences, functions, triggers)
+// where a later definition replaces an earlier one with the same name. The
+// result is sorted by name so merged output is deterministic.
+func mergeNamed[T any](m *Merger, kind string, schemas []*Schema, items func(*Schema) []T, name func(T) string) []T {
+	byName := make(map[string]T)
+	for _, schema := range schemas {
+		for _, item := range items(schema) {
+			if _, exists := byName[name(item)]; exists && m.verbose {
+				fmt.Printf("%s %s defined more than once, later definition wins\n", kind, name(item))
+			}
+			byName[name(item)] = item
+		}
+	}
+	if len(byName) == 0 {
+		return nil
+	}
+
+	merged := make([]T, 0, len(byName))
+	for _, item := range byName {
+		merged = append(merged, item)
+	}
+	sort.Slice(merged, func(i, j int) bool { return name(merged[i]) < name(merged[j]) })
+	return merged
+}
+
 // mergeTables merges multiple table definitions with the same name
 func (m *Merger) mergeTables(tableName string, tables []Table) (*Table, error) {
 	if len(tables) == 1 {
@@ -139,9 +193,17 @@ func (m *Merger) mergeTables(tableName string, tables []Table) (*Table, error) {
 	// Collect all fields from all table definitions
 	fieldMap := make(map[string][]Field)
 	for _, table := range tables {
+		if err := mergeCollation(&merged.Collation, &merged.Charset, table.Collation, table.Charset); err != nil {
+			return nil, fmt.Errorf("table %s: %w", tableName, err)
+		}
 		for _, field := range table.Fields {
 			fieldMap[field.Name] = append(fieldMap[field.Name], field)
 		}
+		for _, uc := range table.UniqueConstraints {
+			if err := mergeUniqueConstraint(merged, uc); err != nil {
+				return nil, fmt.Errorf("table %s: %w", tableName, err)
+			}
+		}
 	}
 
 	// Merge fields
@@ -214,6 +276,11 @@ func (m *Merger) mergeFields(tableName, fieldName string, fields []Field) (*Fiel
 			merged.PrimaryKey = true
 		}
 
+		// Unique shorthand conflict resolution (any true wins)
+		if current.Unique {
+			merged.Unique = true
+		}
+
 		// Auto fields conflict resolution (any true wins)
 		if current.AutoCreate {
 			merged.AutoCreate = true
@@ -245,6 +312,23 @@ func (m *Merger) mergeFields(tableName, fieldName string, fields []Field) (*Fiel
 			}
 		}
 
+		// Collation/charset conflict resolution (must agree when both are set)
+		if err := mergeCollation(&merged.Collation, &merged.Charset, current.Collation, current.Charset); err != nil {
+			return nil, fmt.Errorf("field %s.%s: %w", tableName, fieldName, err)
+		}
+
+		// Per-database overrides (later definition wins per database)
+		if len(current.Overrides) > 0 {
+			overrides := make(map[DatabaseType]FieldOverride, len(merged.Overrides)+len(current.Overrides))
+			for db, o := range merged.Overrides {
+				overrides[db] = o
+			}
+			for db, o := range current.Overrides {
+				overrides[db] = o
+			}
+			merged.Overrides = overrides
+		}
+
 		// Many-to-many conflict resolution
 		if current.ManyToMany != nil {
 			if merged.ManyToMany == nil {
@@ -262,6 +346,78 @@ func (m *Merger) mergeFields(tableName, fieldName string, fields []Field) (*Fiel
 	return &merged, nil
 }
 
+// mergeUniqueConstraint adds uc to the merged table unless an identical
+// constraint of the same name is already present. Differing definitions
+// under one name conflict.
+func mergeUniqueConstraint(merged *Table, uc UniqueConstraint) error {
+	for _, existing := range merged.UniqueConstraints {
+		if existing.Name != uc.Name {
+			continue
+		}
+		if !uniqueConstraintsEqual(existing, uc) {
+			return fmt.Errorf("conflicting definitions for unique constraint %s", uc.Name)
+		}
+		return nil
+	}
+	merged.UniqueConstraints = append(merged.UniqueConstraints, uc)
+	return nil
+}
+
+// UniqueConstraintName returns the constraint name used for a field's
+// `unique: true` shorthand, e.g. uq_users_email.
+func UniqueConstraintName(tableName, fieldName string) string {
+	return utils.SafeConstraintName(fmt.Sprintf("uq_%s_%s", tableName, fieldName))
+}
+
+// ExpandUniqueFields turns each field's `unique: true` shorthand into a
+// single-column unique constraint named by UniqueConstraintName and clears
+// the flag. Constraints are appended in field order after the table's
+// explicit ones, and a shorthand already covered by an explicit constraint
+// on exactly that column is dropped, so the result is stable across runs.
+func ExpandUniqueFields(schema *Schema) {
+	for ti := range schema.Tables {
+		t := &schema.Tables[ti]
+		for fi := range t.Fields {
+			f := &t.Fields[fi]
+			if !f.Unique {
+				continue
+			}
+			f.Unique = false
+			covered := false
+			for _, uc := range t.UniqueConstraints {
+				if len(uc.Fields) == 1 && uc.Fields[0] == f.Name {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				t.UniqueConstraints = append(t.UniqueConstraints, UniqueConstraint{
+					Name:   UniqueConstraintName(t.Name, f.Name),
+					Fields: []string{f.Name},
+				})
+			}
+		}
+	}
+}
+
+// mergeCollation merges a collation/charset pair into the merged values. Unset
+// merged values take the current ones; differing non-empty values conflict.
+func mergeCollation(collation, charset *string, curCollation, curCharset string) error {
+	if curCollation != "" {
+		if *collation != "" && *collation != curCollation {
+			return fmt.Errorf("incompatible collations %s vs %s", *collation, curCollation)
+		}
+		*collation = curCollation
+	}
+	if curCharset != "" {
+		if *charset != "" && *charset != curCharset {
+			return fmt.Errorf("incompatible charsets %s vs %s", *charset, curCharset)
+		}
+		*charset = curCharset
+	}
+	return nil
+}
+
 // resolveTypeConflict resolves conflicts between different field types
 func (m *Merger) resolveTypeConflict(tableName, fieldName, type1, type2 string) (string, error) {
 	// Allow compatible type promotions
@@ -147,6 +147,27 @@ func TestMergeFieldsWithConflictResolution(t *testing.T) {
 	if merged.Type != "bigint" {
 		t.Errorf("Expected type 'bigint', got '%s'", merged.Type)
 	}
+
+	// Test per-database overrides (union, later definition wins per database)
+	field1 = Field{Name: "slug", Type: "text", Overrides: map[DatabaseType]FieldOverride{
+		DatabasePostgreSQL: {Type: "citext"},
+		DatabaseSQLite:     {Collation: "BINARY"},
+	}}
+	field2 = Field{Name: "slug", Type: "text", Overrides: map[DatabaseType]FieldOverride{
+		DatabaseSQLite: {Collation: "NOCASE"},
+	}}
+
+	merged, err = merger.mergeFields("users", "slug", []Field{field1, field2})
+	if err != nil {
+		t.Fatalf("Failed to merge fields: %v", err)
+	}
+
+	if merged.Overrides[DatabasePostgreSQL].Type != "citext" || merged.Overrides[DatabaseSQLite].Collation != "NOCASE" {
+		t.Errorf("Unexpected merged overrides: %+v", merged.Overrides)
+	}
+	if field1.Overrides[DatabaseSQLite].Collation != "BINARY" {
+		t.Error("Expected merging not to modify the first definition's overrides")
+	}
 }
 
 func TestIncompatibleTypeConflict(t *testing.T) {
@@ -354,3 +375,37 @@ func TestValidateMergedSchema(t *testing.T) {
 		t.Fatal("Expected validation error for multiple primary keys")
 	}
 }
+
+func TestExpandUniqueFields(t *testing.T) {
+	schema := &Schema{
+		Tables: []Table{{
+			Name: "users",
+			Fields: []Field{
+				{Name: "id", Type: "serial", PrimaryKey: true},
+				{Name: "username", Type: "varchar", Length: 50, Unique: true},
+				{Name: "email", Type: "varchar", Length: 255, Unique: true},
+			},
+			UniqueConstraints: []UniqueConstraint{
+				{Name: "users_email_key", Fields: []string{"email"}, NullsNotDistinct: true},
+			},
+		}},
+	}
+
+	ExpandUniqueFields(schema)
+
+	ucs := schema.Tables[0].UniqueConstraints
+	if len(ucs) != 2 {
+		t.Fatalf("Expected 2 unique constraints, got %+v", ucs)
+	}
+	if ucs[0].Name != "users_email_key" {
+		t.Errorf("Expected explicit constraint to stay first, got %+v", ucs[0])
+	}
+	if ucs[1].Name != "uq_users_username" || len(ucs[1].Fields) != 1 || ucs[1].Fields[0] != "username" {
+		t.Errorf("Unexpected shorthand constraint: %+v", ucs[1])
+	}
+	for _, f := range schema.Tables[0].Fields {
+		if f.Unique {
+			t.Errorf("Expected unique shorthand to be cleared on %s", f.Name)
+		}
+	}
+}
@@ -31,12 +31,15 @@ import (
 
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
+
+	"github.com/ocomsoft/makemigrations/internal/srcfs"
 )
 
 // ModuleResolver handles resolving Go module paths to filesystem paths
 // without shelling out to `go list`. It reads go.mod and go.work directly.
 type ModuleResolver struct {
 	verbose bool
+	fs      srcfs.FS
 	// Lazily populated: module path → absolute directory
 	moduleMap map[string]string
 }
@@ -45,6 +48,7 @@ type ModuleResolver struct {
 func NewModuleResolver(verbose bool) *ModuleResolver {
 	return &ModuleResolver{
 		verbose: verbose,
+		fs:      srcfs.OS,
 	}
 }
 
@@ -77,7 +81,7 @@ func (mr *ModuleResolver) ResolveIncludePath(include Include) (string, error) {
 
 	fullPath := filepath.Join(moduleDir, include.Path)
 
-	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+	if _, err := mr.fs.Stat(fullPath); os.IsNotExist(err) {
 		return "", fmt.Errorf("included file does not exist: %s (resolved from module %s, path %s)",
 			fullPath, include.Module, include.Path)
 	}
@@ -96,7 +100,7 @@ func (mr *ModuleResolver) buildModuleMap() error {
 	}
 
 	goModPath := filepath.Join(wd, "go.mod")
-	goModData, err := os.ReadFile(goModPath)
+	goModData, err := mr.fs.ReadFile(goModPath)
 	if err != nil {
 		return fmt.Errorf("reading go.mod: %w", err)
 	}
@@ -114,7 +118,7 @@ func (mr *ModuleResolver) buildModuleMap() error {
 				absDir = filepath.Join(wd, absDir)
 			}
 			absDir = filepath.Clean(absDir)
-			if dirExists(absDir) {
+			if dirExists(mr.fs, absDir) {
 				mr.moduleMap[rep.Old.Path] = absDir
 				if mr.verbose {
 					fmt.Printf("  replace: %s → %s\n", rep.Old.Path, absDir)
@@ -124,7 +128,7 @@ func (mr *ModuleResolver) buildModuleMap() error {
 	}
 
 	// 2. go.work workspace members
-	workPath := findGoWork(wd)
+	workPath := findGoWork(mr.fs, wd)
 	if workPath != "" {
 		if err := mr.loadWorkspaceModules(workPath); err != nil && mr.verbose {
 			fmt.Printf("  warning: failed to load go.work modules: %v\n", err)
@@ -150,7 +154,7 @@ func (mr *ModuleResolver) buildModuleMap() error {
 // loadWorkspaceModules reads go.work, then reads each workspace member's
 // go.mod to learn its module path.
 func (mr *ModuleResolver) loadWorkspaceModules(workPath string) error {
-	data, err := os.ReadFile(workPath)
+	data, err := mr.fs.ReadFile(workPath)
 	if err != nil {
 		return err
 	}
@@ -170,7 +174,7 @@ func (mr *ModuleResolver) loadWorkspaceModules(workPath string) error {
 		memberDir = filepath.Clean(memberDir)
 
 		memberModPath := filepath.Join(memberDir, "go.mod")
-		memberData, err := os.ReadFile(memberModPath)
+		memberData, err := mr.fs.ReadFile(memberModPath)
 		if err != nil {
 			if mr.verbose {
 				fmt.Printf("  warning: cannot read %s: %v\n", memberModPath, err)
@@ -202,7 +206,7 @@ func (mr *ModuleResolver) loadWorkspaceModules(workPath string) error {
 				absDir = filepath.Join(workDir, absDir)
 			}
 			absDir = filepath.Clean(absDir)
-			if dirExists(absDir) {
+			if dirExists(mr.fs, absDir) {
 				mr.moduleMap[rep.Old.Path] = absDir
 				if mr.verbose {
 					fmt.Printf("  work replace: %s → %s\n", rep.Old.Path, absDir)
@@ -215,11 +219,11 @@ func (mr *ModuleResolver) loadWorkspaceModules(workPath string) error {
 }
 
 // findGoWork walks from startDir upward looking for go.work.
-func findGoWork(startDir string) string {
+func findGoWork(fsys srcfs.FS, startDir string) string {
 	dir := startDir
 	for {
 		candidate := filepath.Join(dir, "go.work")
-		if _, err := os.Stat(candidate); err == nil {
+		if _, err := fsys.Stat(candidate); err == nil {
 			return candidate
 		}
 		parent := filepath.Dir(dir)
@@ -250,7 +254,7 @@ func moduleCachePath(modPath, version string) string {
 	}
 
 	cachePath := filepath.Join(goPath, "pkg", "mod", fmt.Sprintf("%s@%s", escaped, version))
-	if dirExists(cachePath) {
+	if dirExists(srcfs.OS, cachePath) {
 		return cachePath
 	}
 
@@ -261,7 +265,7 @@ func isLocalPath(p string) bool {
 	return strings.HasPrefix(p, ".") || strings.HasPrefix(p, "/")
 }
 
-func dirExists(path string) bool {
-	info, err := os.Stat(path)
+func dirExists(fsys srcfs.FS, path string) bool {
+	info, err := fsys.Stat(path)
 	return err == nil && info.IsDir()
 }
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/ocomsoft/makemigrations/internal/srcfs"
 )
 
 func TestFindGoWork(t *testing.T) {
@@ -16,7 +18,7 @@ func TestFindGoWork(t *testing.T) {
 	}
 
 	// No go.work anywhere — should return empty
-	if got := findGoWork(sub2); got != "" {
+	if got := findGoWork(srcfs.OS, sub2); got != "" {
 		t.Fatalf("expected empty, got %s", got)
 	}
 
@@ -27,13 +29,13 @@ func TestFindGoWork(t *testing.T) {
 	}
 
 	// Should find it from sub2
-	got := findGoWork(sub2)
+	got := findGoWork(srcfs.OS, sub2)
 	if got != workPath {
 		t.Fatalf("expected %s, got %s", workPath, got)
 	}
 
 	// Should find it from root itself
-	got = findGoWork(root)
+	got = findGoWork(srcfs.OS, root)
 	if got != workPath {
 		t.Fatalf("expected %s, got %s", workPath, got)
 	}
@@ -25,26 +25,34 @@ package yaml
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	yaml "gopkg.in/yaml.v3"
 
 	"github.com/ocomsoft/makemigrations/internal/errors"
+	"github.com/ocomsoft/makemigrations/internal/srcfs"
 )
 
 // Parser handles YAML schema parsing and validation
 type Parser struct {
 	verbose bool
+	fs      srcfs.FS
 }
 
 // NewParser creates a new YAML parser
 func NewParser(verbose bool) *Parser {
 	return &Parser{
 		verbose: verbose,
+		fs:      srcfs.OS,
 	}
 }
 
+// SetFS makes ParseSchemaFile read schema files and their includes from fsys
+// instead of the working tree, e.g. from a git revision (see srcfs.Git).
+func (p *Parser) SetFS(fsys srcfs.FS) {
+	p.fs = fsys
+}
+
 // ParseSchema parses a YAML schema string into a Schema struct
 func (p *Parser) ParseSchema(content string) (*Schema, error) {
 	if strings.TrimSpace(content) == "" {
@@ -99,6 +107,61 @@ func (p *Parser) ValidateSchemaStructure(schema *Schema) error {
 	return nil
 }
 
+// ValidateSequenceReferences checks that sequence owners exist and that every
+// nextval('name') field default refers to a declared sequence. Defaults that
+// are overridden in the schema defaults map are left to the user. Like FK
+// validation it runs post-merge, since owners may live in sibling files.
+func (p *Parser) ValidateSequenceReferences(schema *Schema) error {
+	for _, seq := range schema.Sequences {
+		tableName, fieldName, ok := seq.OwnerParts()
+		if !ok {
+			continue
+		}
+		table := schema.GetTableByName(tableName)
+		if table == nil {
+			return fmt.Errorf("sequence %s: owned_by references unknown table %s", seq.Name, tableName)
+		}
+		if table.GetFieldByName(fieldName) == nil {
+			return fmt.Errorf("sequence %s: owned_by references unknown field %s.%s", seq.Name, tableName, fieldName)
+		}
+	}
+
+	for _, table := range schema.Tables {
+		for _, field := range table.Fields {
+			seqName, ok := ParseNextValDefault(field.Default)
+			if !ok || schema.GetSequenceByName(seqName) != nil {
+				continue
+			}
+			overridden := false
+			for _, providerDefaults := range schema.Defaults {
+				if _, exists := providerDefaults[field.Default]; exists {
+					overridden = true
+					break
+				}
+			}
+			if !overridden {
+				return fmt.Errorf("table %s, field %s: default references unknown sequence %s", table.Name, field.Name, seqName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateTriggerReferences checks that every trigger targets a known table
+// and that referenced trigger functions are declared. Runs post-merge.
+func (p *Parser) ValidateTriggerReferences(schema *Schema) error {
+	for _, tr := range schema.Triggers {
+		if schema.GetTableByName(tr.Table) == nil {
+			return fmt.Errorf("trigger %s: unknown table %s", tr.Name, tr.Table)
+		}
+		if tr.Function != "" && schema.GetFunctionByName(tr.Function) == nil {
+			return fmt.Errorf("trigger %s: unknown function %s", tr.Name, tr.Function)
+		}
+	}
+	return nil
+}
+
 // ValidateForeignKeyReferences validates that all foreign key references exist
 func (p *Parser) ValidateForeignKeyReferences(schema *Schema) error {
 	// Build a map of all table names for quick lookup
@@ -299,6 +362,22 @@ func (p *Parser) ValidateComprehensive(schema *Schema, databaseType DatabaseType
 		})
 	}
 
+	// Sequence validation
+	if err := p.ValidateSequenceReferences(schema); err != nil {
+		errors = append(errors, ValidationError{
+			Type:    "sequence",
+			Message: err.Error(),
+		})
+	}
+
+	// Function and trigger validation
+	if err := p.ValidateTriggerReferences(schema); err != nil {
+		errors = append(errors, ValidationError{
+			Type:    "trigger",
+			Message: err.Error(),
+		})
+	}
+
 	// Database-specific validation
 	if err := p.ValidateDatabaseSpecificRules(schema, databaseType); err != nil {
 		errors = append(errors, ValidationError{
@@ -312,7 +391,7 @@ func (p *Parser) ValidateComprehensive(schema *Schema, databaseType DatabaseType
 
 // ValidationError represents a validation error with context
 type ValidationError struct {
-	Type     string // "schema", "structure", "foreign_key", "database_specific"
+	Type     string // "schema", "structure", "foreign_key", "sequence", "trigger", "database_specific"
 	Table    string
 	Field    string
 	Message  string
@@ -360,7 +439,7 @@ func (p *Parser) ParseAndValidate(content string) (*Schema, error) {
 
 // ParseSchemaFile parses a YAML schema file and processes includes
 func (p *Parser) ParseSchemaFile(filePath string) (*Schema, error) {
-	content, err := os.ReadFile(filePath)
+	content, err := p.fs.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read schema file %s: %w", filePath, err)
 	}
@@ -386,6 +465,7 @@ func (p *Parser) ParseSchemaFileWithContent(content, filePath string) (*Schema,
 
 	// Process includes
 	includeProcessor := NewIncludeProcessor(p.verbose)
+	includeProcessor.SetFS(p.fs)
 	processedSchema, err := includeProcessor.ProcessIncludes(schema, filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to process includes: %w", err)
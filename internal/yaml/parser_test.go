@@ -425,3 +425,47 @@ tables:
 		t.Fatal("Expected invalid schema to fail validation")
 	}
 }
+
+func TestValidateSequenceReferences(t *testing.T) {
+	p := NewParser(false)
+	schema := &Schema{
+		Tables: []Table{{Name: "invoices", Fields: []Field{
+			{Name: "id", Type: "serial", PrimaryKey: true},
+			{Name: "number", Type: "bigint", Default: "nextval('invoice_seq')"},
+		}}},
+	}
+	if err := p.ValidateSequenceReferences(schema); err == nil {
+		t.Fatal("expected error for default referencing an undeclared sequence")
+	}
+
+	schema.Sequences = []Sequence{{Name: "invoice_seq", OwnedBy: "invoices.missing"}}
+	if err := p.ValidateSequenceReferences(schema); err == nil {
+		t.Fatal("expected error for owned_by referencing an unknown field")
+	}
+
+	schema.Sequences[0].OwnedBy = "invoices.number"
+	if err := p.ValidateSequenceReferences(schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateTriggerReferences(t *testing.T) {
+	p := NewParser(false)
+	schema := &Schema{
+		Tables:   []Table{{Name: "orders", Fields: []Field{{Name: "id", Type: "serial", PrimaryKey: true}}}},
+		Triggers: []Trigger{{Name: "orders_touch", Table: "missing", Timing: "BEFORE", Events: []string{"UPDATE"}, Function: "touch"}},
+	}
+	if err := p.ValidateTriggerReferences(schema); err == nil {
+		t.Fatal("expected error for trigger on an unknown table")
+	}
+
+	schema.Triggers[0].Table = "orders"
+	if err := p.ValidateTriggerReferences(schema); err == nil {
+		t.Fatal("expected error for trigger referencing an undeclared function")
+	}
+
+	schema.Functions = []Function{{Name: "touch", Returns: "trigger", Body: map[DatabaseType]string{DatabasePostgreSQL: "BEGIN RETURN NEW; END;"}}}
+	if err := p.ValidateTriggerReferences(schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
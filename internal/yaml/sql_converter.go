@@ -103,7 +103,9 @@ func IsDestructiveOperation(changeType ChangeType) bool {
 func isDestructiveOperation(changeType ChangeType) bool {
 	switch changeType {
 	case ChangeTypeTableRemoved, ChangeTypeFieldRemoved, ChangeTypeIndexRemoved,
-		ChangeTypeTableRenamed, ChangeTypeFieldRenamed, ChangeTypeFieldModified:
+		ChangeTypeTableRenamed, ChangeTypeFieldRenamed, ChangeTypeFieldModified,
+		ChangeTypeSequenceRemoved, ChangeTypeFunctionRemoved, ChangeTypeTriggerRemoved,
+		ChangeTypeUniqueConstraintRemoved:
 		return true
 	case ChangeTypeTableAdded, ChangeTypeFieldAdded, ChangeTypeIndexAdded:
 		return false // These are safe operations
@@ -311,6 +311,8 @@ func (sm *StateManager) compareFields(field1, field2 *Field) bool {
 		field1.Scale == field2.Scale &&
 		field1.AutoCreate == field2.AutoCreate &&
 		field1.AutoUpdate == field2.AutoUpdate &&
+		field1.Collation == field2.Collation &&
+		field1.Charset == field2.Charset &&
 		sm.compareForeignKeys(field1.ForeignKey, field2.ForeignKey) &&
 		sm.compareManyToMany(field1.ManyToMany, field2.ManyToMany)
 }
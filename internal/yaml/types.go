@@ -57,6 +57,24 @@ type ManyToMany = types.ManyToMany
 // Index is an alias for types.Index for backwards compatibility.
 type Index = types.Index
 
+// UniqueConstraint is an alias for types.UniqueConstraint.
+type UniqueConstraint = types.UniqueConstraint
+
+// FieldOverride is an alias for types.FieldOverride.
+type FieldOverride = types.FieldOverride
+
+// IndexOverride is an alias for types.IndexOverride.
+type IndexOverride = types.IndexOverride
+
+// Sequence is an alias for types.Sequence.
+type Sequence = types.Sequence
+
+// Function is an alias for types.Function.
+type Function = types.Function
+
+// Trigger is an alias for types.Trigger.
+type Trigger = types.Trigger
+
 // DatabaseType is an alias for types.DatabaseType for backwards compatibility.
 type DatabaseType = types.DatabaseType
 
@@ -75,3 +93,4 @@ var ValidFieldTypes = types.ValidFieldTypes
 var ParseDatabaseType = types.ParseDatabaseType
 var IsValidFieldType = types.IsValidFieldType
 var IsValidDatabase = types.IsValidDatabase
+var ParseNextValDefault = types.ParseNextValDefault
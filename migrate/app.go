@@ -25,9 +25,11 @@ SOFTWARE.
 package migrate
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -58,8 +60,14 @@ func NewAppWithRegistry(cfg Config, reg *Registry) *App {
 
 // Run executes the CLI with the given arguments.
 func (a *App) Run(args []string) error {
+	return a.RunContext(context.Background(), args)
+}
+
+// RunContext executes the CLI with the given arguments. Cancelling ctx
+// aborts an in-progress up or down.
+func (a *App) RunContext(ctx context.Context, args []string) error {
 	a.root.SetArgs(args)
-	return a.root.Execute()
+	return a.root.ExecuteContext(ctx)
 }
 
 func (a *App) buildRootCommand() *cobra.Command {
@@ -75,6 +83,9 @@ func (a *App) buildRootCommand() *cobra.Command {
 	root.AddCommand(a.buildUpCommand())
 	root.AddCommand(a.buildDownCommand())
 	root.AddCommand(a.buildStatusCommand())
+	root.AddCommand(a.buildHistoryCommand())
+	root.AddCommand(a.buildPruneHistoryCommand())
+	root.AddCommand(a.buildResolveCommand())
 	root.AddCommand(a.buildShowSQLCommand())
 	root.AddCommand(a.buildFakeCommand())
 
@@ -111,35 +122,84 @@ func (a *App) buildDAGCommand() *cobra.Command {
 func (a *App) buildUpCommand() *cobra.Command {
 	var toMigration string
 	var warnOnMissingDrop bool
+	var ignoreUnknown bool
+	var allowOutOfOrder bool
+	var phase string
+	var timeouts Timeouts
+	var targets targetsOptions
 	cmd := &cobra.Command{
 		Use:   "up",
 		Short: "Apply pending migrations",
-		RunE: func(_ *cobra.Command, _ []string) error {
-			return a.runUp(toMigration, RunOptions{WarnOnMissingDrop: warnOnMissingDrop})
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts := RunOptions{
+				WarnOnMissingDrop: warnOnMissingDrop,
+				IgnoreUnknown:     ignoreUnknown,
+				AllowOutOfOrder:   allowOutOfOrder,
+				Timeouts:          timeouts,
+				Phase:             phase,
+			}
+			if err := validatePhase(phase); err != nil {
+				return err
+			}
+			if targets.enabled() {
+				return a.runUpTargets(cmd.Context(), toMigration, opts, targets)
+			}
+			return a.runUp(cmd.Context(), toMigration, opts)
 		},
 	}
+	a.addTimeoutFlags(cmd, &timeouts)
 	cmd.Flags().StringVar(&toMigration, "to", "", "Apply up to this migration name")
 	cmd.Flags().BoolVar(&warnOnMissingDrop, "warn-on-missing-drop", false, "Warn and continue when a drop fails because the object does not exist")
+	cmd.Flags().BoolVar(&ignoreUnknown, "ignore-unknown", false, "Apply even if the history records migrations that are not registered")
+	cmd.Flags().BoolVar(&allowOutOfOrder, "allow-out-of-order", false, "Apply pending migrations that precede already-applied ones")
+	cmd.Flags().StringVar(&phase, "phase", "", "Apply only one deploy stage of expand/contract migrations: expand or contract")
+	cmd.Flags().StringVar(&targets.File, "targets", "", "YAML file listing the databases or schemas to migrate")
+	cmd.Flags().StringArrayVar(&targets.DSNs, "target-dsn", nil, "Database URL to migrate; repeat for several targets")
+	cmd.Flags().StringVar(&targets.Query, "targets-query", "", "SQL query returning the schemas to migrate, one per row")
+	cmd.Flags().IntVar(&targets.Concurrency, "concurrency", 0, "Maximum number of targets migrated at once (default 4)")
+	cmd.Flags().StringVar(&targets.Report, "report", "", "Write a JSON report of the per-target results to this file")
+	cmd.Flags().StringVar(&targets.OnlyFailed, "only-failed", "", "Only migrate the targets that failed in this report")
 	return cmd
 }
 
 func (a *App) buildDownCommand() *cobra.Command {
 	var steps int
 	var toMigration string
+	var batch bool
 	var warnOnMissingDrop bool
+	var timeouts Timeouts
 	cmd := &cobra.Command{
 		Use:   "down",
 		Short: "Rollback migrations",
-		RunE: func(_ *cobra.Command, _ []string) error {
-			return a.runDown(steps, toMigration, RunOptions{WarnOnMissingDrop: warnOnMissingDrop})
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts := RunOptions{WarnOnMissingDrop: warnOnMissingDrop, Timeouts: timeouts}
+			if batch {
+				if cmd.Flags().Changed("steps") || toMigration != "" {
+					return fmt.Errorf("--batch cannot be combined with --steps or --to")
+				}
+				return a.runDownBatch(cmd.Context(), opts)
+			}
+			return a.runDown(cmd.Context(), steps, toMigration, opts)
 		},
 	}
 	cmd.Flags().IntVar(&steps, "steps", 1, "Number of migrations to roll back")
 	cmd.Flags().StringVar(&toMigration, "to", "", "Roll back to this migration name")
+	cmd.Flags().BoolVar(&batch, "batch", false, "Roll back every migration applied by the most recent 'up'")
 	cmd.Flags().BoolVar(&warnOnMissingDrop, "warn-on-missing-drop", false, "Warn and continue when a drop fails because the object does not exist")
+	a.addTimeoutFlags(cmd, &timeouts)
 	return cmd
 }
 
+// addTimeoutFlags registers the timeout and lock retry flags on cmd, with
+// defaults taken from the app config.
+func (a *App) addTimeoutFlags(cmd *cobra.Command, t *Timeouts) {
+	d := a.config.Timeouts
+	cmd.Flags().DurationVar(&t.StatementTimeout, "statement-timeout", d.StatementTimeout, "Maximum run time of each statement (0 keeps the database default)")
+	cmd.Flags().DurationVar(&t.LockTimeout, "lock-timeout", d.LockTimeout, "Maximum time a statement waits for a lock (0 keeps the database default)")
+	cmd.Flags().IntVar(&t.LockRetries, "lock-retries", d.LockRetries, "Times to retry a migration that fails with a lock timeout")
+	cmd.Flags().DurationVar(&t.LockRetryBackoff, "lock-retry-backoff", d.LockRetryBackoff, "Delay before the first lock retry; doubles on each further retry (default 1s)")
+}
+
 func (a *App) buildStatusCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "status",
@@ -150,6 +210,46 @@ func (a *App) buildStatusCommand() *cobra.Command {
 	}
 }
 
+func (a *App) buildHistoryCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "history",
+		Short: "Show applied migrations with batch, duration and who applied them",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return a.runHistory()
+		},
+	}
+}
+
+func (a *App) buildPruneHistoryCommand() *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "prune-history",
+		Short: "Remove history rows for unregistered or squashed-away migrations",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return a.runPruneHistory(cmd.Context(), dryRun)
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the rows that would be removed without deleting them")
+	return cmd
+}
+
+func (a *App) buildResolveCommand() *cobra.Command {
+	var markOp int
+	cmd := &cobra.Command{
+		Use:   "resolve [migration-name]",
+		Short: "Record how many operations of a partially applied migration are done",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("mark-op") {
+				return fmt.Errorf("--mark-op is required")
+			}
+			return a.runResolve(cmd.Context(), args[0], markOp)
+		},
+	}
+	cmd.Flags().IntVar(&markOp, "mark-op", 0, "Number of leading operations already applied (0 clears the record)")
+	return cmd
+}
+
 func (a *App) buildShowSQLCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "showsql",
@@ -220,44 +320,100 @@ func driverName(dbType string) string {
 // buildRunner creates a fully-wired Runner from the app config and registry.
 // It also returns the underlying *sql.DB so the caller can close it when done.
 func (a *App) buildRunner() (*Runner, *sql.DB, error) {
-	reg := a.registry
-	g, err := BuildGraph(reg)
-	if err != nil {
-		return nil, nil, fmt.Errorf("building graph: %w", err)
-	}
 	db, err := a.openDB()
 	if err != nil {
 		return nil, nil, err
 	}
-	p, err := BuildProviderFromType(a.config.DatabaseType)
+	runner, err := a.newRunner(context.Background(), db, os.Stdout)
 	if err != nil {
 		_ = db.Close()
 		return nil, nil, err
 	}
+	return runner, db, nil
+}
+
+// newRunner wires a Runner for the registry against db, writing progress to
+// out. The caller owns db.
+func (a *App) newRunner(ctx context.Context, db *sql.DB, out io.Writer) (*Runner, error) {
+	g, err := BuildGraph(a.registry)
+	if err != nil {
+		return nil, fmt.Errorf("building graph: %w", err)
+	}
+	p, err := BuildProviderFromType(a.config.DatabaseType)
+	if err != nil {
+		return nil, err
+	}
 	recorder := NewMigrationRecorder(db, p)
-	if err := recorder.EnsureTable(); err != nil {
-		_ = db.Close()
-		return nil, nil, err
+	if err := recorder.EnsureTableContext(ctx); err != nil {
+		return nil, err
+	}
+	runner := NewRunner(g, p, db, recorder, out)
+	for _, h := range a.config.Hooks {
+		runner.AddHook(h)
+	}
+	if a.config.Metadata != nil {
+		runner.SetMetadata(*a.config.Metadata)
+	}
+	return runner, nil
+}
+
+func (a *App) runUp(ctx context.Context, to string, opts RunOptions) error {
+	r, db, err := a.buildRunner()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return r.UpContext(ctx, to, opts)
+}
+
+func (a *App) runDown(ctx context.Context, steps int, to string, opts RunOptions) error {
+	r, db, err := a.buildRunner()
+	if err != nil {
+		return err
 	}
-	return NewRunner(g, p, db, recorder, os.Stdout), db, nil
+	defer func() { _ = db.Close() }()
+	return r.DownContext(ctx, steps, to, opts)
+}
+
+func (a *App) runDownBatch(ctx context.Context, opts RunOptions) error {
+	r, db, err := a.buildRunner()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return r.DownBatchContext(ctx, opts)
 }
 
-func (a *App) runUp(to string, opts RunOptions) error {
+func (a *App) runHistory() error {
 	r, db, err := a.buildRunner()
 	if err != nil {
 		return err
 	}
 	defer func() { _ = db.Close() }()
-	return r.Up(to, opts)
+	return r.History()
 }
 
-func (a *App) runDown(steps int, to string, opts RunOptions) error {
+func (a *App) runPruneHistory(ctx context.Context, dryRun bool) error {
+	r, db, err := a.buildRunner()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	_, err = r.PruneHistory(ctx, dryRun)
+	return err
+}
+
+func (a *App) runResolve(ctx context.Context, name string, opsDone int) error {
+	resolved, err := a.registry.Resolve(name)
+	if err != nil {
+		return err
+	}
 	r, db, err := a.buildRunner()
 	if err != nil {
 		return err
 	}
 	defer func() { _ = db.Close() }()
-	return r.Down(steps, to, opts)
+	return r.Resolve(ctx, resolved, opsDone)
 }
 
 func (a *App) runStatus() error {
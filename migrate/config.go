@@ -37,6 +37,15 @@ type Config struct {
 	DBPassword   string
 	DBName       string
 	DBSSLMode    string
+	// Hooks run around every migration and operation applied or rolled back
+	// by the up and down commands.
+	Hooks []Hook
+	// Metadata is recorded with each applied migration. When nil,
+	// DefaultRunMetadata is used.
+	Metadata *RunMetadata
+	// Timeouts are the defaults for the up and down commands' timeout and
+	// lock retry flags.
+	Timeouts Timeouts
 }
 
 // EnvOr returns the value of the named environment variable,
@@ -211,6 +211,43 @@ func (g *Graph) ReconstructState() (*SchemaState, error) {
 	return state, nil
 }
 
+// Dependents returns, sorted, the names of the migrations that list name as
+// a dependency.
+func (g *Graph) Dependents(name string) []string {
+	node, ok := g.nodes[name]
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, child := range node.children {
+		names = append(names, child.migration.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReconstructStateBefore is ReconstructState without the named migration and
+// the migrations that depend on it, directly or indirectly: the schema as it
+// was before name was written.
+func (g *Graph) ReconstructStateBefore(name string) (*SchemaState, error) {
+	if _, ok := g.nodes[name]; !ok {
+		return nil, fmt.Errorf("migration %q is not registered", name)
+	}
+	order, err := g.Linearize()
+	if err != nil {
+		return nil, fmt.Errorf("linearizing graph for state reconstruction: %w", err)
+	}
+	excluded := map[string]bool{name: true}
+	return replayState(order, func(m *Migration) bool {
+		for _, dep := range m.Dependencies {
+			if excluded[dep] {
+				excluded[m.Name] = true
+			}
+		}
+		return !excluded[m.Name]
+	}, nil)
+}
+
 // DAGOutput is the JSON-serialisable representation of the full migration graph.
 // This is what the compiled migration binary emits via the `dag --format json` command.
 type DAGOutput struct {
@@ -2,6 +2,7 @@
 package migrate_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ocomsoft/makemigrations/migrate"
@@ -230,3 +231,81 @@ func TestGraph_ToDAGOutput(t *testing.T) {
 		t.Fatal("expected 'users' in SchemaState")
 	}
 }
+
+func TestGraph_ReconstructStateBefore(t *testing.T) {
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{Name: "0001_initial", Dependencies: []string{}, Operations: []migrate.Operation{
+		&migrate.CreateTable{Name: "users", Fields: []migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}}},
+	}})
+	reg.Register(&migrate.Migration{Name: "0002_add_phone", Dependencies: []string{"0001_initial"}, Operations: []migrate.Operation{
+		&migrate.AddField{Table: "users", Field: migrate.Field{Name: "phone", Type: "varchar", Length: 20}},
+	}})
+	reg.Register(&migrate.Migration{Name: "0003_posts", Dependencies: []string{"0002_add_phone"}, Operations: []migrate.Operation{
+		&migrate.CreateTable{Name: "posts", Fields: []migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}}},
+	}})
+	g, err := migrate.BuildGraph(reg)
+	if err != nil {
+		t.Fatalf("BuildGraph: %v", err)
+	}
+	if deps := g.Dependents("0002_add_phone"); len(deps) != 1 || deps[0] != "0003_posts" {
+		t.Fatalf("expected 0003_posts to depend on 0002_add_phone, got %v", deps)
+	}
+	if deps := g.Dependents("0003_posts"); len(deps) != 0 {
+		t.Fatalf("expected no dependents for the leaf, got %v", deps)
+	}
+
+	state, err := g.ReconstructStateBefore("0002_add_phone")
+	if err != nil {
+		t.Fatalf("ReconstructStateBefore: %v", err)
+	}
+	if _, ok := state.Tables["posts"]; ok {
+		t.Error("expected the dependent 0003_posts to be excluded too")
+	}
+	if len(state.Tables["users"].Fields) != 1 {
+		t.Errorf("expected users without phone, got %+v", state.Tables["users"].Fields)
+	}
+	if _, err := g.ReconstructStateBefore("0009_missing"); err == nil {
+		t.Error("expected an error for an unknown migration")
+	}
+}
+
+func TestGraph_RenderSQL(t *testing.T) {
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{Name: "0001_initial", Dependencies: []string{}, Operations: []migrate.Operation{
+		&migrate.CreateTable{Name: "users", Fields: []migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}}},
+	}})
+	reg.Register(&migrate.Migration{Name: "0002_add_phone", Dependencies: []string{"0001_initial"}, Operations: []migrate.Operation{
+		&migrate.AddField{Table: "users", Field: migrate.Field{Name: "phone", Type: "varchar", Length: 20, Nullable: true}},
+		&migrate.RunSQL{ForwardSQL: "UPDATE users SET phone = ''", BackwardSQL: ""},
+	}})
+	g, err := migrate.BuildGraph(reg)
+	if err != nil {
+		t.Fatalf("BuildGraph: %v", err)
+	}
+	p, err := migrate.BuildProviderFromType("sqlite")
+	if err != nil {
+		t.Fatalf("BuildProviderFromType: %v", err)
+	}
+	rendered, err := g.RenderSQL(p)
+	if err != nil {
+		t.Fatalf("RenderSQL: %v", err)
+	}
+	if len(rendered) != 2 || rendered[0].Name != "0001_initial" || rendered[1].Name != "0002_add_phone" {
+		t.Fatalf("expected both migrations in order, got %+v", rendered)
+	}
+	if len(rendered[0].Up) != 1 || !strings.Contains(rendered[0].Up[0], "CREATE TABLE") {
+		t.Errorf("unexpected up SQL for 0001_initial: %q", rendered[0].Up)
+	}
+	if len(rendered[0].Down) != 1 || !strings.Contains(rendered[0].Down[0], "DROP TABLE") {
+		t.Errorf("unexpected down SQL for 0001_initial: %q", rendered[0].Down)
+	}
+	up := rendered[1].Up
+	if len(up) != 2 || !strings.Contains(up[0], "ADD COLUMN") || up[1] != "UPDATE users SET phone = ''" {
+		t.Errorf("unexpected up SQL for 0002_add_phone: %q", up)
+	}
+	// The empty BackwardSQL is skipped; dropping the column is rendered
+	// against the state that still has it.
+	if down := rendered[1].Down; len(down) != 1 || !strings.Contains(down[0], "phone") {
+		t.Errorf("unexpected down SQL for 0002_add_phone: %q", down)
+	}
+}
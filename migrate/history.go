@@ -0,0 +1,133 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate
+
+import (
+	"os"
+	"os/user"
+	"runtime/debug"
+	"time"
+
+	"github.com/ocomsoft/makemigrations/internal/types"
+	"github.com/ocomsoft/makemigrations/internal/version"
+)
+
+// historyTable is the name of the migration-tracking table.
+const historyTable = "makemigrations_history"
+
+// historyColumns are the metadata columns added to the base table created by
+// Provider.HistoryTableDDL. They are nullable so rows written by older
+// versions remain valid, and are added to existing tables on EnsureTable.
+var historyColumns = []types.Field{
+	{Name: "duration_ms", Type: "bigint", Nullable: boolPtr(true)},
+	{Name: "batch", Type: "integer", Nullable: boolPtr(true)},
+	{Name: "applied_by", Type: "text", Nullable: boolPtr(true)},
+	{Name: "ci_job", Type: "text", Nullable: boolPtr(true)},
+	{Name: "tool_version", Type: "text", Nullable: boolPtr(true)},
+	{Name: "git_commit", Type: "text", Nullable: boolPtr(true)},
+}
+
+// HistoryEntry is one row of the makemigrations_history table.
+type HistoryEntry struct {
+	Name string
+	// AppliedAt is the database's rendering of the applied_at column.
+	AppliedAt string
+	Duration  time.Duration
+	// Batch groups the migrations applied by one `migrate up` invocation.
+	// Zero for rows recorded before batches were tracked or by `fake`.
+	Batch int
+	RunMetadata
+}
+
+// RunMetadata identifies who and what applied a migration.
+type RunMetadata struct {
+	// AppliedBy is "user@host" of the process that ran the migration.
+	AppliedBy string
+	// CIJob identifies the CI job, when running under a recognised CI system.
+	CIJob string
+	// ToolVersion is the makemigrations version the runner was built with.
+	ToolVersion string
+	// GitCommit is the commit of the project the migrations came from.
+	GitCommit string
+}
+
+// ciJobEnv lists environment variables that identify a CI job, in order of
+// preference. The first one set wins.
+var ciJobEnv = []string{"MAKEMIGRATIONS_CI_JOB", "GITHUB_RUN_ID", "CI_JOB_ID", "CIRCLE_BUILD_NUM", "BUILD_TAG", "BUILDKITE_JOB_ID"}
+
+// gitCommitEnv lists environment variables that carry the project commit.
+var gitCommitEnv = []string{"MAKEMIGRATIONS_GIT_COMMIT", "GIT_COMMIT", "GITHUB_SHA", "CI_COMMIT_SHA", "CIRCLE_SHA1", "BUILDKITE_COMMIT"}
+
+// DefaultRunMetadata describes the current process: the OS user and host,
+// the CI job and commit from well-known CI environment variables, and the
+// makemigrations version. A standalone migration binary built from a git
+// checkout falls back to the commit Go stamped into the binary.
+func DefaultRunMetadata() RunMetadata {
+	meta := RunMetadata{
+		CIJob:       firstEnv(ciJobEnv),
+		ToolVersion: version.GetVersion(),
+		GitCommit:   firstEnv(gitCommitEnv),
+	}
+	if meta.GitCommit == "" {
+		meta.GitCommit = buildRevision()
+	}
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	switch {
+	case name != "" && host != "":
+		meta.AppliedBy = name + "@" + host
+	default:
+		meta.AppliedBy = name + host
+	}
+	return meta
+}
+
+// buildRevision returns the vcs.revision stamped into the running binary,
+// unless the binary is makemigrations itself (whose commit says nothing
+// about the project's migrations).
+func buildRevision() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok || bi.Main.Path == "github.com/ocomsoft/makemigrations" {
+		return ""
+	}
+	for _, setting := range bi.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
+
+// firstEnv returns the value of the first non-empty environment variable.
+func firstEnv(names []string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+func TestRecorder_UpgradesLegacyHistoryTable(t *testing.T) {
+	db := openTestDB(t)
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(`CREATE TABLE makemigrations_history (id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE, applied_at TEXT DEFAULT CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatalf("creating legacy table: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatalf("creating users: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO makemigrations_history (name) VALUES ('0001_initial')`); err != nil {
+		t.Fatalf("seeding legacy row: %v", err)
+	}
+
+	m, err := migrate.NewMigrator(db, "sqlite", migratorRegistry())
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	m.SetMetadata(migrate.RunMetadata{AppliedBy: "deploy@ci-1", CIJob: "42", ToolVersion: "9.9.9", GitCommit: "abc123"})
+	ctx := context.Background()
+	results, err := m.Up(ctx, migrate.UpOptions{})
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(results) != 1 || results[0].Batch != 1 {
+		t.Fatalf("expected 0002 in batch 1, got %+v", results)
+	}
+
+	history, err := m.History(ctx)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 history rows, got %+v", history)
+	}
+	legacy, added := history[0], history[1]
+	if legacy.Name != "0001_initial" || legacy.Batch != 0 || legacy.AppliedBy != "" {
+		t.Errorf("unexpected legacy row: %+v", legacy)
+	}
+	if added.Name != "0002_add_email" || added.Batch != 1 || added.AppliedAt == "" {
+		t.Errorf("unexpected new row: %+v", added)
+	}
+	if added.RunMetadata != (migrate.RunMetadata{AppliedBy: "deploy@ci-1", CIJob: "42", ToolVersion: "9.9.9", GitCommit: "abc123"}) {
+		t.Errorf("unexpected metadata: %+v", added.RunMetadata)
+	}
+}
+
+func TestMigrator_DownBatch(t *testing.T) {
+	reg := migratorRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0003_add_name",
+		Dependencies: []string{"0002_add_email"},
+		Operations: []migrate.Operation{
+			&migrate.AddField{Table: "users", Field: migrate.Field{Name: "name", Type: "text", Nullable: true}},
+		},
+	})
+	m, err := migrate.NewMigrator(openTestDB(t), "sqlite", reg)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := m.Down(ctx, migrate.DownOptions{Batch: true}); err == nil || !strings.Contains(err.Error(), "no migration batches") {
+		t.Fatalf("expected no-batch error, got %v", err)
+	}
+	if _, err := m.Up(ctx, migrate.UpOptions{To: "0001_initial"}); err != nil {
+		t.Fatalf("Up to 0001: %v", err)
+	}
+	results, err := m.Up(ctx, migrate.UpOptions{})
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(results) != 2 || results[0].Batch != 2 || results[1].Batch != 2 {
+		t.Fatalf("expected 0002 and 0003 in batch 2, got %+v", results)
+	}
+
+	results, err = m.Down(ctx, migrate.DownOptions{Batch: true})
+	if err != nil {
+		t.Fatalf("Down batch: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "0003_add_name" || results[1].Name != "0002_add_email" {
+		t.Fatalf("expected batch 2 rolled back, got %+v", results)
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if !statuses[0].Applied || statuses[1].Applied || statuses[2].Applied {
+		t.Fatalf("expected only 0001 applied, got %+v", statuses)
+	}
+}
+
+func TestApp_Run_HistoryAndDownBatch(t *testing.T) {
+	restore := suppressStdout(t)
+	defer restore()
+
+	dbPath := filepath.Join(t.TempDir(), "history.db")
+	run := func(args ...string) error {
+		// A fresh App per command so flag values do not carry over.
+		app := migrate.NewAppWithRegistry(migrate.Config{DatabaseType: "sqlite", DBName: dbPath}, migratorRegistry())
+		return app.Run(args)
+	}
+	if err := run("up"); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if err := run("history"); err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if err := run("down", "--batch", "--steps", "2"); err == nil {
+		t.Fatal("expected --batch with --steps to be rejected")
+	}
+	if err := run("down", "--batch"); err != nil {
+		t.Fatalf("down --batch: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("opening db: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM makemigrations_history").Scan(&n); err != nil {
+		t.Fatalf("counting history: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected the whole batch rolled back, %d rows remain", n)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Direction identifies whether a migration is being applied or rolled back.
+type Direction string
+
+const (
+	// DirectionUp is used while applying a migration.
+	DirectionUp Direction = "up"
+	// DirectionDown is used while rolling a migration back.
+	DirectionDown Direction = "down"
+)
+
+// HookEvent describes the point in a run at which a Hook is invoked.
+type HookEvent struct {
+	Direction Direction
+	Migration *Migration
+	// Operation and SQL are set for operation-level events and for OnError
+	// when the failure happened inside an operation.
+	Operation Operation
+	SQL       string
+	// Err is the failure passed to OnError.
+	Err error
+}
+
+// Hook is called around every migration and operation the Runner executes.
+// The Before/After methods run inside the migration's transaction, so any
+// SQL they execute through tx commits or rolls back with the migration; a
+// non-nil error aborts the migration. OnError runs after the transaction
+// has been rolled back.
+//
+// Embed NoopHook to implement only the methods you need.
+type Hook interface {
+	BeforeMigration(ctx context.Context, tx *sql.Tx, ev HookEvent) error
+	AfterMigration(ctx context.Context, tx *sql.Tx, ev HookEvent) error
+	BeforeOperation(ctx context.Context, tx *sql.Tx, ev HookEvent) error
+	AfterOperation(ctx context.Context, tx *sql.Tx, ev HookEvent) error
+	OnError(ctx context.Context, ev HookEvent)
+}
+
+// NoopHook implements Hook with methods that do nothing.
+type NoopHook struct{}
+
+// BeforeMigration does nothing.
+func (NoopHook) BeforeMigration(context.Context, *sql.Tx, HookEvent) error { return nil }
+
+// AfterMigration does nothing.
+func (NoopHook) AfterMigration(context.Context, *sql.Tx, HookEvent) error { return nil }
+
+// BeforeOperation does nothing.
+func (NoopHook) BeforeOperation(context.Context, *sql.Tx, HookEvent) error { return nil }
+
+// AfterOperation does nothing.
+func (NoopHook) AfterOperation(context.Context, *sql.Tx, HookEvent) error { return nil }
+
+// OnError does nothing.
+func (NoopHook) OnError(context.Context, HookEvent) {}
+
+// SQLHooks is a Hook that executes fixed SQL snippets inside the migration
+// transaction, e.g. `SET lock_timeout = '5s'` before each migration.
+// Operation snippets are skipped for state-only operations that generate no
+// SQL. It is what the hooks section of makemigrations.config.yaml becomes.
+type SQLHooks struct {
+	NoopHook
+	BeforeMigrationSQL []string
+	AfterMigrationSQL  []string
+	BeforeOperationSQL []string
+	AfterOperationSQL  []string
+}
+
+// BeforeMigration executes BeforeMigrationSQL.
+func (h *SQLHooks) BeforeMigration(ctx context.Context, tx *sql.Tx, _ HookEvent) error {
+	return execHookSQL(ctx, tx, h.BeforeMigrationSQL)
+}
+
+// AfterMigration executes AfterMigrationSQL.
+func (h *SQLHooks) AfterMigration(ctx context.Context, tx *sql.Tx, _ HookEvent) error {
+	return execHookSQL(ctx, tx, h.AfterMigrationSQL)
+}
+
+// BeforeOperation executes BeforeOperationSQL.
+func (h *SQLHooks) BeforeOperation(ctx context.Context, tx *sql.Tx, ev HookEvent) error {
+	if ev.SQL == "" {
+		return nil
+	}
+	return execHookSQL(ctx, tx, h.BeforeOperationSQL)
+}
+
+// AfterOperation executes AfterOperationSQL.
+func (h *SQLHooks) AfterOperation(ctx context.Context, tx *sql.Tx, ev HookEvent) error {
+	if ev.SQL == "" {
+		return nil
+	}
+	return execHookSQL(ctx, tx, h.AfterOperationSQL)
+}
+
+// execHookSQL executes each statement in order, stopping at the first failure.
+func execHookSQL(ctx context.Context, tx *sql.Tx, stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%w\n  SQL: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// AddHook registers h to be called around every migration and operation.
+// Hooks run in the order they were added.
+func (r *Runner) AddHook(h Hook) {
+	r.hooks = append(r.hooks, h)
+}
+
+// runHooks invokes the hook method selected by call on every registered
+// hook, stopping at the first error.
+func (r *Runner) runHooks(name string, call func(Hook) error) error {
+	for _, h := range r.hooks {
+		if err := call(h); err != nil {
+			return fmt.Errorf("%s hook: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// fireOnError notifies every registered hook of a failed migration.
+func (r *Runner) fireOnError(ctx context.Context, ev HookEvent) {
+	for _, h := range r.hooks {
+		h.OnError(ctx, ev)
+	}
+}
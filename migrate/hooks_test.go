@@ -0,0 +1,150 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+// recordingHook records every hook call as "<point> <migration> [<op>]".
+type recordingHook struct {
+	migrate.NoopHook
+	calls   []string
+	failOn  string
+	onError []migrate.HookEvent
+}
+
+func (h *recordingHook) record(point string, ev migrate.HookEvent) error {
+	call := fmt.Sprintf("%s %s %s", point, ev.Direction, ev.Migration.Name)
+	if ev.Operation != nil {
+		call += " " + ev.Operation.TypeName()
+	}
+	h.calls = append(h.calls, call)
+	if call == h.failOn {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func (h *recordingHook) BeforeMigration(_ context.Context, _ *sql.Tx, ev migrate.HookEvent) error {
+	return h.record("before_migration", ev)
+}
+
+func (h *recordingHook) AfterMigration(_ context.Context, _ *sql.Tx, ev migrate.HookEvent) error {
+	return h.record("after_migration", ev)
+}
+
+func (h *recordingHook) BeforeOperation(_ context.Context, _ *sql.Tx, ev migrate.HookEvent) error {
+	return h.record("before_operation", ev)
+}
+
+func (h *recordingHook) AfterOperation(_ context.Context, _ *sql.Tx, ev migrate.HookEvent) error {
+	return h.record("after_operation", ev)
+}
+
+func (h *recordingHook) OnError(_ context.Context, ev migrate.HookEvent) {
+	h.onError = append(h.onError, ev)
+}
+
+func TestRunner_HooksOrder(t *testing.T) {
+	reg := migratorRegistry()
+	runner, _, _ := buildTestRunner(t, reg)
+	h := &recordingHook{}
+	runner.AddHook(h)
+
+	if err := runner.Up("0001_initial", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if err := runner.Down(1, "", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	want := []string{
+		"before_migration up 0001_initial",
+		"before_operation up 0001_initial create_table",
+		"after_operation up 0001_initial create_table",
+		"after_migration up 0001_initial",
+		"before_migration down 0001_initial",
+		"before_operation down 0001_initial create_table",
+		"after_operation down 0001_initial create_table",
+		"after_migration down 0001_initial",
+	}
+	if !reflect.DeepEqual(h.calls, want) {
+		t.Fatalf("unexpected hook calls:\n got: %v\nwant: %v", h.calls, want)
+	}
+	if len(h.onError) != 0 {
+		t.Fatalf("unexpected OnError calls: %+v", h.onError)
+	}
+}
+
+func TestRunner_HookErrorRollsBack(t *testing.T) {
+	runner, recorder, db := buildTestRunner(t, migratorRegistry())
+	h := &recordingHook{failOn: "after_operation up 0001_initial create_table"}
+	runner.AddHook(h)
+
+	if err := runner.Up("", migrate.RunOptions{}); err == nil {
+		t.Fatal("expected hook error to abort the migration")
+	}
+	if _, err := db.Exec("SELECT 1 FROM users"); err == nil {
+		t.Fatal("expected users table creation to be rolled back")
+	}
+	applied, err := recorder.GetApplied()
+	if err != nil {
+		t.Fatalf("GetApplied: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Fatalf("expected nothing recorded, got %v", applied)
+	}
+	if len(h.onError) != 1 || h.onError[0].Operation == nil || h.onError[0].SQL == "" || h.onError[0].Err == nil {
+		t.Fatalf("expected OnError with the failing operation, got %+v", h.onError)
+	}
+}
+
+func TestSQLHooks(t *testing.T) {
+	runner, _, db := buildTestRunner(t, migratorRegistry())
+	runner.AddHook(&migrate.SQLHooks{
+		BeforeMigrationSQL: []string{"CREATE TABLE IF NOT EXISTS hook_audit (event TEXT)"},
+		AfterOperationSQL:  []string{"INSERT INTO hook_audit (event) VALUES ('op')"},
+		AfterMigrationSQL:  []string{"INSERT INTO hook_audit (event) VALUES ('migration')"},
+	})
+	if err := runner.Up("", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	var ops, migrations int
+	if err := db.QueryRow("SELECT COUNT(*) FROM hook_audit WHERE event = 'op'").Scan(&ops); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM hook_audit WHERE event = 'migration'").Scan(&migrations); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if ops != 2 || migrations != 2 {
+		t.Fatalf("expected 2 op and 2 migration events, got %d and %d", ops, migrations)
+	}
+}
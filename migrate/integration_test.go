@@ -227,3 +227,305 @@ func TestRoundTrip_WithMerge(t *testing.T) {
 		t.Fatalf("insert with both columns: %v", err)
 	}
 }
+
+// TestRoundTrip_BackfillNotNull applies the add-nullable / backfill / set NOT
+// NULL sequence generated for a NOT NULL column added to a populated table,
+// then rolls it back.
+func TestRoundTrip_BackfillNotNull(t *testing.T) {
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0001_initial",
+		Dependencies: []string{},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{Name: "users", Fields: []migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}}},
+			&migrate.RunSQL{ForwardSQL: "INSERT INTO users (id) VALUES (1), (2)"},
+		},
+	})
+	reg.Register(&migrate.Migration{
+		Name:         "0002_add_status",
+		Dependencies: []string{"0001_initial"},
+		Operations: []migrate.Operation{
+			&migrate.AddField{Table: "users", Field: migrate.Field{Name: "status", Type: "varchar", Nullable: true, Length: 20}},
+			&migrate.Backfill{Table: "users", Field: "status", Expr: "'active'"},
+			&migrate.AlterField{
+				Table:    "users",
+				OldField: migrate.Field{Name: "status", Type: "varchar", Nullable: true, Length: 20},
+				NewField: migrate.Field{Name: "status", Type: "varchar", Length: 20},
+			},
+		},
+	})
+	runner, _, db := buildTestRunner(t, reg)
+	db.SetMaxOpenConns(1)
+
+	if err := runner.Up("", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users WHERE status = 'active'").Scan(&n); err != nil || n != 2 {
+		t.Fatalf("expected 2 backfilled rows, got %d (%v)", n, err)
+	}
+	if _, err := db.Exec("INSERT INTO users (id) VALUES (3)"); err == nil {
+		t.Error("expected NOT NULL to be enforced after the backfill")
+	}
+
+	if err := runner.Down(1, "", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if _, err := db.Exec("SELECT status FROM users"); err == nil {
+		t.Error("expected status column to be dropped after rollback")
+	}
+}
+
+// TestRoundTrip_AlterFieldNewColumn changes a text column holding numbers to
+// integer by copying into a new column, then rolls it back.
+func TestRoundTrip_AlterFieldNewColumn(t *testing.T) {
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0001_initial",
+		Dependencies: []string{},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{
+				Name: "orders",
+				Fields: []migrate.Field{
+					{Name: "id", Type: "integer", PrimaryKey: true},
+					{Name: "code", Type: "varchar", Length: 20},
+				},
+				Indexes: []migrate.Index{{Name: "idx_orders_code", Fields: []string{"code"}}},
+			},
+			&migrate.RunSQL{ForwardSQL: "INSERT INTO orders (id, code) VALUES (1, '42'), (2, '007')"},
+		},
+	})
+	reg.Register(&migrate.Migration{
+		Name:         "0002_code_integer",
+		Dependencies: []string{"0001_initial"},
+		Operations: []migrate.Operation{
+			&migrate.AlterField{
+				Table:    "orders",
+				OldField: migrate.Field{Name: "code", Type: "varchar", Length: 20},
+				NewField: migrate.Field{Name: "code", Type: "integer"},
+				Method:   migrate.AlterMethodNewColumn,
+			},
+		},
+	})
+	runner, _, db := buildTestRunner(t, reg)
+	db.SetMaxOpenConns(1)
+
+	if err := runner.Up("", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	var kind string
+	var sum int
+	if err := db.QueryRow("SELECT typeof(code), (SELECT SUM(code) FROM orders) FROM orders WHERE id = 2").Scan(&kind, &sum); err != nil {
+		t.Fatal(err)
+	}
+	if kind != "integer" || sum != 49 {
+		t.Errorf("expected integer values summing to 49, got %s / %d", kind, sum)
+	}
+	var idx int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'idx_orders_code'").Scan(&idx); err != nil || idx != 1 {
+		t.Errorf("expected idx_orders_code to be recreated, got %d (%v)", idx, err)
+	}
+
+	if err := runner.Down(1, "", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if err := db.QueryRow("SELECT typeof(code) FROM orders WHERE id = 2").Scan(&kind); err != nil || kind != "text" {
+		t.Errorf("expected text after rollback, got %s (%v)", kind, err)
+	}
+}
+
+// TestRoundTrip_ExpandContractRename renames users.name to users.full_name
+// the way `generate --zero-downtime --rename` writes it for SQLite: old and
+// new code write different columns between the two phases and see each
+// other's data.
+func TestRoundTrip_ExpandContractRename(t *testing.T) {
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0001_initial",
+		Dependencies: []string{},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{Name: "users", Fields: []migrate.Field{
+				{Name: "id", Type: "integer", PrimaryKey: true},
+				{Name: "name", Type: "text", Nullable: true},
+			}},
+			&migrate.RunSQL{ForwardSQL: "INSERT INTO users (id, name) VALUES (1, 'Ann'), (2, 'Bo')"},
+		},
+	})
+	reg.Register(&migrate.Migration{
+		Name:         "0002_users_expand",
+		Dependencies: []string{"0001_initial"},
+		Phase:        migrate.PhaseExpand,
+		Operations: []migrate.Operation{
+			&migrate.AddField{Table: "users", Field: migrate.Field{Name: "full_name", Type: "text", Nullable: true}},
+			&migrate.RunSQL{
+				ForwardSQL: `CREATE TRIGGER "users_name_full_name_sync_ins" AFTER INSERT ON "users"
+BEGIN
+	UPDATE "users" SET "full_name" = COALESCE(NEW."full_name", NEW."name"), "name" = COALESCE(NEW."name", NEW."full_name") WHERE rowid = NEW.rowid;
+END`,
+				BackwardSQL: `DROP TRIGGER IF EXISTS "users_name_full_name_sync_ins"`,
+			},
+			&migrate.RunSQL{
+				ForwardSQL: `CREATE TRIGGER "users_name_full_name_sync_from" AFTER UPDATE OF "name" ON "users" WHEN NEW."name" IS NOT OLD."name"
+BEGIN
+	UPDATE "users" SET "full_name" = NEW."name" WHERE rowid = NEW.rowid;
+END`,
+				BackwardSQL: `DROP TRIGGER IF EXISTS "users_name_full_name_sync_from"`,
+			},
+			&migrate.RunSQL{
+				ForwardSQL: `CREATE TRIGGER "users_name_full_name_sync_to" AFTER UPDATE OF "full_name" ON "users" WHEN NEW."full_name" IS NOT OLD."full_name"
+BEGIN
+	UPDATE "users" SET "name" = NEW."full_name" WHERE rowid = NEW.rowid;
+END`,
+				BackwardSQL: `DROP TRIGGER IF EXISTS "users_name_full_name_sync_to"`,
+			},
+			&migrate.Backfill{Table: "users", Field: "full_name", Expr: `"name"`},
+		},
+	})
+	reg.Register(&migrate.Migration{
+		Name:         "0003_users_contract",
+		Dependencies: []string{"0002_users_expand"},
+		Phase:        migrate.PhaseContract,
+		Operations: []migrate.Operation{
+			&migrate.RunSQL{ForwardSQL: `DROP TRIGGER IF EXISTS "users_name_full_name_sync_to"`},
+			&migrate.RunSQL{ForwardSQL: `DROP TRIGGER IF EXISTS "users_name_full_name_sync_from"`},
+			&migrate.RunSQL{ForwardSQL: `DROP TRIGGER IF EXISTS "users_name_full_name_sync_ins"`},
+			&migrate.AlterField{
+				Table:    "users",
+				OldField: migrate.Field{Name: "full_name", Type: "text", Nullable: true},
+				NewField: migrate.Field{Name: "full_name", Type: "text"},
+			},
+			&migrate.DropField{Table: "users", Field: "name"},
+		},
+	})
+	runner, _, db := buildTestRunner(t, reg)
+	db.SetMaxOpenConns(1)
+
+	if err := runner.Up("", migrate.RunOptions{Phase: migrate.PhaseExpand}); err != nil {
+		t.Fatalf("Up expand: %v", err)
+	}
+	for _, stmt := range []string{
+		"INSERT INTO users (id, name) VALUES (3, 'Cy')",      // old code
+		"INSERT INTO users (id, full_name) VALUES (4, 'Di')", // new code
+		"UPDATE users SET name = 'Ann B' WHERE id = 1",       // old code
+		"UPDATE users SET full_name = 'Bo C' WHERE id = 2",   // new code
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	var mismatched int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users WHERE name IS NOT full_name OR full_name IS NULL").Scan(&mismatched); err != nil {
+		t.Fatalf("comparing columns: %v", err)
+	}
+	if mismatched != 0 {
+		t.Fatalf("expected name and full_name in sync, %d row(s) differ", mismatched)
+	}
+
+	if err := runner.Up("", migrate.RunOptions{Phase: migrate.PhaseContract}); err != nil {
+		t.Fatalf("Up contract: %v", err)
+	}
+	var fullName string
+	if err := db.QueryRow("SELECT full_name FROM users WHERE id = 2").Scan(&fullName); err != nil || fullName != "Bo C" {
+		t.Fatalf("expected full_name to survive the contract phase, got %q (%v)", fullName, err)
+	}
+	if _, err := db.Exec("SELECT name FROM users"); err == nil {
+		t.Error("expected users.name to be dropped")
+	}
+
+	if err := runner.Down(2, "", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if _, err := db.Exec("SELECT full_name FROM users"); err == nil {
+		t.Error("expected full_name to be dropped after rollback")
+	}
+}
+
+// TestRoundTrip_ExpandContractTypeChange applies the SQLite output of
+// generate --zero-downtime for users.age changing from varchar to integer:
+// writes through the old column reach the replacement during the window,
+// and contract swaps it in without altering the live column.
+func TestRoundTrip_ExpandContractTypeChange(t *testing.T) {
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0001_initial",
+		Dependencies: []string{},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{Name: "users", Fields: []migrate.Field{
+				{Name: "id", Type: "integer", PrimaryKey: true},
+				{Name: "age", Type: "varchar", Length: 10, Nullable: true},
+			}},
+			&migrate.RunSQL{ForwardSQL: "INSERT INTO users (id, age) VALUES (1, '41'), (2, '7')"},
+		},
+	})
+	reg.Register(&migrate.Migration{
+		Name:         "0002_users_expand",
+		Dependencies: []string{"0001_initial"},
+		Phase:        migrate.PhaseExpand,
+		Operations: []migrate.Operation{
+			&migrate.AddField{Table: "users", Field: migrate.Field{Name: "age__new", Type: "integer", Nullable: true}},
+			&migrate.RunSQL{
+				ForwardSQL: `CREATE TRIGGER "users_age_age__new_sync_ins" AFTER INSERT ON "users"
+BEGIN
+	UPDATE "users" SET "age__new" = COALESCE(NEW."age__new", NEW."age"), "age" = COALESCE(NEW."age", NEW."age__new") WHERE rowid = NEW.rowid;
+END`,
+				BackwardSQL: `DROP TRIGGER IF EXISTS "users_age_age__new_sync_ins"`,
+			},
+			&migrate.RunSQL{
+				ForwardSQL: `CREATE TRIGGER "users_age_age__new_sync_from" AFTER UPDATE OF "age" ON "users" WHEN NEW."age" IS NOT OLD."age"
+BEGIN
+	UPDATE "users" SET "age__new" = NEW."age" WHERE rowid = NEW.rowid;
+END`,
+				BackwardSQL: `DROP TRIGGER IF EXISTS "users_age_age__new_sync_from"`,
+			},
+			&migrate.RunSQL{
+				ForwardSQL: `CREATE TRIGGER "users_age_age__new_sync_to" AFTER UPDATE OF "age__new" ON "users" WHEN NEW."age__new" IS NOT OLD."age__new"
+BEGIN
+	UPDATE "users" SET "age" = NEW."age__new" WHERE rowid = NEW.rowid;
+END`,
+				BackwardSQL: `DROP TRIGGER IF EXISTS "users_age_age__new_sync_to"`,
+			},
+			&migrate.Backfill{Table: "users", Field: "age__new", Expr: `CAST("age" AS INTEGER)`},
+		},
+	})
+	reg.Register(&migrate.Migration{
+		Name:         "0003_users_contract",
+		Dependencies: []string{"0002_users_expand"},
+		Phase:        migrate.PhaseContract,
+		Operations: []migrate.Operation{
+			&migrate.RunSQL{ForwardSQL: `DROP TRIGGER IF EXISTS "users_age_age__new_sync_to"`},
+			&migrate.RunSQL{ForwardSQL: `DROP TRIGGER IF EXISTS "users_age_age__new_sync_from"`},
+			&migrate.RunSQL{ForwardSQL: `DROP TRIGGER IF EXISTS "users_age_age__new_sync_ins"`},
+			&migrate.DropField{Table: "users", Field: "age"},
+			&migrate.RenameField{Table: "users", OldName: "age__new", NewName: "age"},
+		},
+	})
+	runner, _, db := buildTestRunner(t, reg)
+	db.SetMaxOpenConns(1)
+
+	if err := runner.Up("", migrate.RunOptions{Phase: migrate.PhaseExpand}); err != nil {
+		t.Fatalf("Up expand: %v", err)
+	}
+	for _, stmt := range []string{
+		"INSERT INTO users (id, age) VALUES (3, '30')", // old code
+		"UPDATE users SET age = '8' WHERE id = 2",      // old code
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if err := runner.Up("", migrate.RunOptions{Phase: migrate.PhaseContract}); err != nil {
+		t.Fatalf("Up contract: %v", err)
+	}
+	var sum int
+	var typ string
+	if err := db.QueryRow("SELECT SUM(age), typeof(MIN(age)) FROM users").Scan(&sum, &typ); err != nil {
+		t.Fatalf("reading age: %v", err)
+	}
+	if sum != 41+8+30 || typ != "integer" {
+		t.Fatalf("expected the integer ages 41, 8 and 30 after contract, got sum %d of type %s", sum, typ)
+	}
+	if _, err := db.Exec("SELECT age__new FROM users"); err == nil {
+		t.Error("expected age__new to be renamed into place")
+	}
+}
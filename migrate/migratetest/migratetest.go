@@ -0,0 +1,392 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package migratetest checks that a project's migrations round-trip: each
+// one applies cleanly, leaves the database with the schema its operations
+// describe, and is exactly undone by its Down. One test covers the whole
+// history and catches broken hand-written RunSQL down paths:
+//
+//	func TestMigrations(t *testing.T) {
+//		migratetest.RoundTrip(t, migrate.GlobalRegistry(), migratetest.Options{})
+//	}
+//
+// By default the migrations run against a private in-memory SQLite database.
+package migratetest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver for the default database
+
+	"github.com/ocomsoft/makemigrations/internal/providers"
+	"github.com/ocomsoft/makemigrations/internal/types"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+// Options configure a round trip.
+type Options struct {
+	// DB is the database to migrate. It must not contain any of the
+	// registry's tables. When nil, a private in-memory SQLite database is used.
+	DB *sql.DB
+	// DatabaseType names the provider for DB. Defaults to "sqlite".
+	DatabaseType string
+}
+
+// Stage identifies the part of the round trip that failed.
+type Stage string
+
+const (
+	// StageUp is applying migrations one at a time.
+	StageUp Stage = "up"
+	// StageDown is rolling them back one at a time.
+	StageDown Stage = "down"
+	// StageReapply is applying them all again after the rollback.
+	StageReapply Stage = "reapply"
+)
+
+// Error reports the first migration whose round trip failed. Either Err is
+// set (running the migration failed) or Diff lists how the live schema
+// differed from the expected one.
+type Error struct {
+	Migration string
+	Stage     Stage
+	Err       error
+	Diff      []string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("migratetest: %s: %s failed: %v", e.Migration, e.Stage, e.Err)
+	}
+	var what string
+	switch e.Stage {
+	case StageUp:
+		what = "schema after up does not match the migration's operations"
+	case StageDown:
+		what = "down did not restore the schema from before up"
+	default:
+		what = "schema after re-applying does not match the reconstructed state"
+	}
+	return fmt.Sprintf("migratetest: %s: %s:\n  - %s", e.Migration, what, strings.Join(e.Diff, "\n  - "))
+}
+
+// Unwrap returns the error from running the migration, if any.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// RoundTrip runs Check and fails t with the first asymmetric migration.
+func RoundTrip(t testing.TB, reg *migrate.Registry, opts Options) {
+	t.Helper()
+	if err := Check(context.Background(), reg, opts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Check applies every migration in reg one at a time, comparing the live
+// schema after each with the state its operations describe. It then rolls
+// them back one at a time, checking that each Down restores exactly the
+// schema seen before its Up, and finally re-applies everything and compares
+// the result with Graph.ReconstructState. The first failure is returned as
+// an *Error.
+//
+// Live schemas are read through the provider's schema inspection, which is
+// available for SQLite and PostgreSQL. Tables and indexes that are not
+// tracked in the migration state (e.g. created by RunSQL) are tolerated
+// after Up but must still be removed by Down.
+func Check(ctx context.Context, reg *migrate.Registry, opts Options) error {
+	dbType := opts.DatabaseType
+	if dbType == "" {
+		dbType = "sqlite"
+	}
+	db := opts.DB
+	if db == nil {
+		var err error
+		if db, err = sql.Open("sqlite3", ":memory:"); err != nil {
+			return fmt.Errorf("opening SQLite: %w", err)
+		}
+		defer func() { _ = db.Close() }()
+		// Every connection to :memory: is a separate database.
+		db.SetMaxOpenConns(1)
+	}
+
+	p, err := migrate.BuildProviderFromType(dbType)
+	if err != nil {
+		return err
+	}
+	inspector, ok := p.(providers.SchemaInspector)
+	if !ok {
+		return fmt.Errorf("migratetest: schema inspection is not supported for %s", dbType)
+	}
+	live := func() (*types.Schema, error) {
+		schema, err := inspector.InspectSchema(db)
+		if err != nil {
+			return nil, fmt.Errorf("migratetest: inspecting schema: %w", err)
+		}
+		tables := schema.Tables[:0]
+		for _, t := range schema.Tables {
+			if !strings.HasPrefix(t.Name, "makemigrations_") {
+				tables = append(tables, t)
+			}
+		}
+		schema.Tables = tables
+		return schema, nil
+	}
+
+	g, err := migrate.BuildGraph(reg)
+	if err != nil {
+		return fmt.Errorf("migratetest: building graph: %w", err)
+	}
+	plan, err := g.Linearize()
+	if err != nil {
+		return fmt.Errorf("migratetest: linearizing graph: %w", err)
+	}
+	m, err := migrate.NewMigrator(db, dbType, reg)
+	if err != nil {
+		return err
+	}
+
+	before := make([]*types.Schema, len(plan))
+	state := migrate.NewSchemaState()
+	for i, mig := range plan {
+		if before[i], err = live(); err != nil {
+			return err
+		}
+		if _, err := m.Up(ctx, migrate.UpOptions{To: mig.Name}); err != nil {
+			return &Error{Migration: mig.Name, Stage: StageUp, Err: err}
+		}
+		for _, op := range mig.Operations {
+			if err := op.Mutate(state); err != nil {
+				return &Error{Migration: mig.Name, Stage: StageUp, Err: fmt.Errorf("mutating state: %w", err)}
+			}
+		}
+		after, err := live()
+		if err != nil {
+			return err
+		}
+		if diff := diffState(state, after); len(diff) > 0 {
+			return &Error{Migration: mig.Name, Stage: StageUp, Diff: diff}
+		}
+	}
+
+	for i := len(plan) - 1; i >= 0; i-- {
+		if _, err := m.Down(ctx, migrate.DownOptions{Steps: 1}); err != nil {
+			return &Error{Migration: plan[i].Name, Stage: StageDown, Err: err}
+		}
+		after, err := live()
+		if err != nil {
+			return err
+		}
+		if diff := diffLive(before[i], after); len(diff) > 0 {
+			return &Error{Migration: plan[i].Name, Stage: StageDown, Diff: diff}
+		}
+	}
+
+	if len(plan) == 0 {
+		return nil
+	}
+	applied, err := m.Up(ctx, migrate.UpOptions{})
+	if err != nil {
+		return &Error{Migration: plan[len(applied)].Name, Stage: StageReapply, Err: err}
+	}
+	want, err := g.ReconstructState()
+	if err != nil {
+		return fmt.Errorf("migratetest: %w", err)
+	}
+	after, err := live()
+	if err != nil {
+		return err
+	}
+	if diff := diffState(want, after); len(diff) > 0 {
+		return &Error{Migration: plan[len(plan)-1].Name, Stage: StageReapply, Diff: diff}
+	}
+	return nil
+}
+
+// diffState compares the live schema with the migration state by name: every
+// table, column and index in the state must exist, tracked tables must not
+// have extra columns, and index uniqueness must agree. Column types are not
+// compared because they are declared differently by each database.
+func diffState(state *migrate.SchemaState, got *types.Schema) []string {
+	var diff []string
+	tables := tablesByName(got)
+	for _, name := range sortedKeys(state.Tables) {
+		ts := state.Tables[name]
+		t, ok := tables[name]
+		if !ok {
+			diff = append(diff, fmt.Sprintf("table %q is missing", name))
+			continue
+		}
+		cols := make(map[string]bool, len(t.Fields))
+		for _, f := range t.Fields {
+			cols[f.Name] = true
+		}
+		for _, f := range ts.Fields {
+			if f.ManyToMany != nil {
+				continue // stored in a join table, not a column
+			}
+			if !cols[f.Name] {
+				diff = append(diff, fmt.Sprintf("table %q: column %q is missing", name, f.Name))
+			}
+			delete(cols, f.Name)
+		}
+		for _, col := range sortedKeys(cols) {
+			diff = append(diff, fmt.Sprintf("table %q: unexpected column %q", name, col))
+		}
+		indexes := make(map[string]types.Index, len(t.Indexes))
+		for _, idx := range t.Indexes {
+			indexes[idx.Name] = idx
+		}
+		for _, idx := range ts.Indexes {
+			if idx.FromFK {
+				continue
+			}
+			live, ok := indexes[idx.Name]
+			switch {
+			case !ok:
+				diff = append(diff, fmt.Sprintf("table %q: index %q is missing", name, idx.Name))
+			case live.Unique != idx.Unique:
+				diff = append(diff, fmt.Sprintf("table %q: index %q has unique=%t, want %t", name, idx.Name, live.Unique, idx.Unique))
+			}
+		}
+	}
+	return diff
+}
+
+// diffLive compares two snapshots of the same database in full.
+func diffLive(want, got *types.Schema) []string {
+	var diff []string
+	wantTables, gotTables := tablesByName(want), tablesByName(got)
+	for _, name := range sortedKeys(wantTables) {
+		wt := wantTables[name]
+		gt, ok := gotTables[name]
+		if !ok {
+			diff = append(diff, fmt.Sprintf("table %q is missing", name))
+			continue
+		}
+		diff = append(diff, diffColumns(name, wt.Fields, gt.Fields)...)
+		diff = append(diff, diffIndexes(name, wt.Indexes, gt.Indexes)...)
+	}
+	for _, name := range sortedKeys(gotTables) {
+		if _, ok := wantTables[name]; !ok {
+			diff = append(diff, fmt.Sprintf("unexpected table %q", name))
+		}
+	}
+	return diff
+}
+
+func diffColumns(table string, want, got []types.Field) []string {
+	var diff []string
+	gotCols := make(map[string]types.Field, len(got))
+	for _, f := range got {
+		gotCols[f.Name] = f
+	}
+	for _, w := range want {
+		g, ok := gotCols[w.Name]
+		if !ok {
+			diff = append(diff, fmt.Sprintf("table %q: column %q is missing", table, w.Name))
+			continue
+		}
+		delete(gotCols, w.Name)
+		if d := describeColumn(w); d != describeColumn(g) {
+			diff = append(diff, fmt.Sprintf("table %q: column %q is %s, want %s", table, w.Name, describeColumn(g), d))
+		}
+	}
+	for _, name := range sortedKeys(gotCols) {
+		diff = append(diff, fmt.Sprintf("table %q: unexpected column %q", table, name))
+	}
+	return diff
+}
+
+func diffIndexes(table string, want, got []types.Index) []string {
+	var diff []string
+	gotIdx := make(map[string]types.Index, len(got))
+	for _, idx := range got {
+		gotIdx[idx.Name] = idx
+	}
+	for _, w := range want {
+		g, ok := gotIdx[w.Name]
+		if !ok {
+			diff = append(diff, fmt.Sprintf("table %q: index %q is missing", table, w.Name))
+			continue
+		}
+		delete(gotIdx, w.Name)
+		if d := describeIndex(w); d != describeIndex(g) {
+			diff = append(diff, fmt.Sprintf("table %q: index %q is %s, want %s", table, w.Name, describeIndex(g), d))
+		}
+	}
+	for _, name := range sortedKeys(gotIdx) {
+		diff = append(diff, fmt.Sprintf("table %q: unexpected index %q", table, name))
+	}
+	return diff
+}
+
+func describeColumn(f types.Field) string {
+	var b strings.Builder
+	b.WriteString(f.Type)
+	if f.PrimaryKey {
+		b.WriteString(" primary key")
+	}
+	if !f.IsNullable() {
+		b.WriteString(" not null")
+	}
+	if f.Default != "" {
+		b.WriteString(" default " + f.Default)
+	}
+	if f.ForeignKey != nil {
+		fmt.Fprintf(&b, " references %s on delete %s", f.ForeignKey.Table, f.ForeignKey.OnDelete)
+	}
+	return b.String()
+}
+
+func describeIndex(idx types.Index) string {
+	s := "(" + strings.Join(idx.Fields, ", ") + ")"
+	if idx.Unique {
+		s = "unique " + s
+	}
+	return s
+}
+
+func tablesByName(s *types.Schema) map[string]types.Table {
+	m := make(map[string]types.Table, len(s.Tables))
+	for _, t := range s.Tables {
+		m[t.Name] = t
+	}
+	return m
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
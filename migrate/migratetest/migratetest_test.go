@@ -0,0 +1,119 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migratetest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/migrate"
+	"github.com/ocomsoft/makemigrations/migrate/migratetest"
+)
+
+func registry(extra ...*migrate.Migration) *migrate.Registry {
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0001_initial",
+		Dependencies: []string{},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{Name: "teams", Fields: []migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}}},
+			&migrate.CreateTable{Name: "users", Fields: []migrate.Field{
+				{Name: "id", Type: "integer", PrimaryKey: true},
+				{Name: "name", Type: "varchar", Length: 100},
+				{Name: "team_id", Type: "foreign_key", ForeignKey: &migrate.ForeignKey{Table: "teams", OnDelete: "CASCADE"}},
+			}},
+		},
+	})
+	reg.Register(&migrate.Migration{
+		Name:         "0002_add_email",
+		Dependencies: []string{"0001_initial"},
+		Operations: []migrate.Operation{
+			&migrate.AddField{Table: "users", Field: migrate.Field{Name: "email", Type: "varchar", Length: 255, Nullable: true}},
+			&migrate.AddIndex{Table: "users", Index: migrate.Index{Name: "idx_users_email", Fields: []string{"email"}, Unique: true}},
+			&migrate.AlterField{
+				Table:    "users",
+				OldField: migrate.Field{Name: "name", Type: "varchar", Length: 100},
+				NewField: migrate.Field{Name: "name", Type: "varchar", Length: 200, Nullable: true},
+			},
+		},
+	})
+	prev := "0002_add_email"
+	for _, m := range extra {
+		m.Dependencies = []string{prev}
+		reg.Register(m)
+		prev = m.Name
+	}
+	return reg
+}
+
+func TestRoundTrip(t *testing.T) {
+	migratetest.RoundTrip(t, registry(&migrate.Migration{
+		Name: "0003_name_index",
+		Operations: []migrate.Operation{
+			&migrate.RunSQL{
+				ForwardSQL:  "CREATE INDEX idx_users_name ON users (name)",
+				BackwardSQL: "DROP INDEX idx_users_name",
+			},
+		},
+	}), migratetest.Options{})
+}
+
+func TestCheck_ReportsBrokenDown(t *testing.T) {
+	reg := registry(&migrate.Migration{
+		Name: "0003_name_index",
+		Operations: []migrate.Operation{
+			&migrate.RunSQL{ForwardSQL: "CREATE INDEX idx_users_name ON users (name)"},
+		},
+	})
+	err := migratetest.Check(context.Background(), reg, migratetest.Options{})
+	var rtErr *migratetest.Error
+	if !errors.As(err, &rtErr) {
+		t.Fatalf("expected *migratetest.Error, got %v", err)
+	}
+	if rtErr.Migration != "0003_name_index" || rtErr.Stage != migratetest.StageDown {
+		t.Fatalf("unexpected failure: %v", err)
+	}
+	if !strings.Contains(err.Error(), `table "users": unexpected index "idx_users_name"`) {
+		t.Fatalf("expected the leftover index in the report, got %v", err)
+	}
+}
+
+func TestCheck_ReportsStateMismatch(t *testing.T) {
+	reg := registry(&migrate.Migration{
+		Name: "0003_phone",
+		Operations: []migrate.Operation{
+			&migrate.AddField{Table: "users", Field: migrate.Field{Name: "phone", Type: "text", Nullable: true}, SchemaOnly: true},
+		},
+	})
+	err := migratetest.Check(context.Background(), reg, migratetest.Options{})
+	var rtErr *migratetest.Error
+	if !errors.As(err, &rtErr) || rtErr.Migration != "0003_phone" || rtErr.Stage != migratetest.StageUp {
+		t.Fatalf("expected 0003_phone to fail at up, got %v", err)
+	}
+	if len(rtErr.Diff) != 1 || rtErr.Diff[0] != `table "users": column "phone" is missing` {
+		t.Fatalf("unexpected diff: %q", rtErr.Diff)
+	}
+}
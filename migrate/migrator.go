@@ -0,0 +1,160 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+)
+
+// UpOptions controls Migrator.Up.
+type UpOptions struct {
+	// To stops after applying the named migration. Empty applies everything.
+	To string
+	RunOptions
+}
+
+// DownOptions controls Migrator.Down.
+type DownOptions struct {
+	// Steps is the number of migrations to roll back. When both Steps and To
+	// are zero, a single migration is rolled back.
+	Steps int
+	// To rolls back until the named migration is reached (exclusive).
+	To string
+	// Batch rolls back every migration from the most recent batch and
+	// ignores Steps and To.
+	Batch bool
+	RunOptions
+}
+
+// Migrator is the embeddable migration API for applications that migrate
+// on startup. It runs against a caller-owned *sql.DB, honours context
+// cancellation and deadlines, and returns structured results instead of
+// printing progress.
+//
+//	m, err := migrate.NewMigrator(db, "postgresql", migrate.GlobalRegistry())
+//	if err != nil { ... }
+//	applied, err := m.Up(ctx, migrate.UpOptions{})
+type Migrator struct {
+	runner   *Runner
+	recorder *MigrationRecorder
+}
+
+// NewMigrator builds a Migrator for db using the provider for dbType and the
+// migrations in reg. A nil reg uses the global registry. The caller remains
+// responsible for closing db.
+func NewMigrator(db *sql.DB, dbType string, reg *Registry) (*Migrator, error) {
+	if reg == nil {
+		reg = GlobalRegistry()
+	}
+	g, err := BuildGraph(reg)
+	if err != nil {
+		return nil, fmt.Errorf("building graph: %w", err)
+	}
+	p, err := BuildProviderFromType(dbType)
+	if err != nil {
+		return nil, err
+	}
+	recorder := NewMigrationRecorder(db, p)
+	return &Migrator{
+		runner:   NewRunner(g, p, db, recorder, io.Discard),
+		recorder: recorder,
+	}, nil
+}
+
+// SetOutput directs the runner's progress messages to w. Output is
+// discarded by default.
+func (m *Migrator) SetOutput(w io.Writer) {
+	if w == nil {
+		w = io.Discard
+	}
+	m.runner.output = w
+}
+
+// AddHook registers h to run around every migration and operation. See Hook.
+func (m *Migrator) AddHook(h Hook) {
+	m.runner.AddHook(h)
+}
+
+// SetMetadata replaces the operator, CI job, tool version and commit
+// recorded with each applied migration. DefaultRunMetadata is used otherwise.
+func (m *Migrator) SetMetadata(meta RunMetadata) {
+	m.runner.SetMetadata(meta)
+}
+
+// Up applies pending migrations and returns the ones that were committed.
+// On error the returned slice still lists the migrations applied before the
+// failure.
+func (m *Migrator) Up(ctx context.Context, opts UpOptions) ([]MigrationResult, error) {
+	if err := m.recorder.EnsureTableContext(ctx); err != nil {
+		return nil, err
+	}
+	return m.runner.up(ctx, opts.To, opts.RunOptions)
+}
+
+// Down rolls back applied migrations and returns the ones that were rolled
+// back, most recent first.
+func (m *Migrator) Down(ctx context.Context, opts DownOptions) ([]MigrationResult, error) {
+	if err := m.recorder.EnsureTableContext(ctx); err != nil {
+		return nil, err
+	}
+	if opts.Batch {
+		return m.runner.downBatch(ctx, opts.RunOptions)
+	}
+	steps := opts.Steps
+	if steps == 0 && opts.To == "" {
+		steps = 1
+	}
+	return m.runner.down(ctx, steps, opts.To, opts.RunOptions)
+}
+
+// Status returns every migration in topological order with whether it has
+// been applied, followed by any applied-but-unregistered history entries
+// (Unknown set).
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.recorder.EnsureTableContext(ctx); err != nil {
+		return nil, err
+	}
+	return m.runner.status(ctx)
+}
+
+// History returns the rows of the migration history table, oldest batch
+// first, including duration, batch and run metadata.
+func (m *Migrator) History(ctx context.Context) ([]HistoryEntry, error) {
+	if err := m.recorder.EnsureTableContext(ctx); err != nil {
+		return nil, err
+	}
+	return m.recorder.History(ctx)
+}
+
+// PruneHistory deletes history rows for unregistered migrations and for
+// migrations replaced by an applied squash, returning the removed names.
+func (m *Migrator) PruneHistory(ctx context.Context) ([]string, error) {
+	if err := m.recorder.EnsureTableContext(ctx); err != nil {
+		return nil, err
+	}
+	return m.runner.PruneHistory(ctx, false)
+}
@@ -0,0 +1,134 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+func migratorRegistry() *migrate.Registry {
+	return testRegistry(
+		&migrate.Migration{Name: "0001_initial", Operations: []migrate.Operation{idTable("users")}},
+		&migrate.Migration{Name: "0002_add_email", Operations: []migrate.Operation{
+			&migrate.AddField{Table: "users", Field: migrate.Field{Name: "email", Type: "text", Nullable: true}},
+		}},
+	)
+}
+
+func TestMigrator_UpStatusDown(t *testing.T) {
+	db := openTestDB(t)
+	m, err := migrate.NewMigrator(db, "sqlite", migratorRegistry())
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	ctx := context.Background()
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Applied || statuses[1].Dependencies[0] != "0001_initial" {
+		t.Fatalf("unexpected initial status: %+v", statuses)
+	}
+
+	results, err := m.Up(ctx, migrate.UpOptions{})
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "0001_initial" || results[1].Name != "0002_add_email" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	statuses, err = m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, st := range statuses {
+		if !st.Applied {
+			t.Fatalf("expected %s to be applied", st.Name)
+		}
+	}
+
+	// Down with zero options rolls back exactly one migration.
+	results, err = m.Down(ctx, migrate.DownOptions{})
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "0002_add_email" {
+		t.Fatalf("unexpected down results: %+v", results)
+	}
+}
+
+func TestMigrator_UpReportsWarnings(t *testing.T) {
+	reg := migratorRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0003_drop_legacy",
+		Dependencies: []string{"0002_add_email"},
+		Operations:   []migrate.Operation{&migrate.DropTable{Name: "legacy"}},
+	})
+	m, err := migrate.NewMigrator(openTestDB(t), "sqlite", reg)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	results, err := m.Up(context.Background(), migrate.UpOptions{RunOptions: migrate.RunOptions{WarnOnMissingDrop: true}})
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(results) != 3 || len(results[0].Warnings) != 0 || len(results[2].Warnings) != 1 {
+		t.Fatalf("expected one warning for the missing legacy table, got %+v", results)
+	}
+}
+
+func TestMigrator_CancelledContext(t *testing.T) {
+	db := openTestDB(t)
+	m, err := migrate.NewMigrator(db, "sqlite", migratorRegistry())
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := m.Status(context.Background()); err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := m.Up(ctx, migrate.UpOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected no migrations applied, got %+v", results)
+	}
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if statuses[0].Applied {
+		t.Fatal("expected nothing to be recorded after cancellation")
+	}
+}
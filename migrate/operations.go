@@ -28,6 +28,8 @@ package migrate
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/ocomsoft/makemigrations/internal/providers"
@@ -68,11 +70,20 @@ func boolPtr(b bool) *bool { return &b }
 
 // resolveFieldDefault resolves a symbolic default key (e.g. "uuid") to its
 // SQL expression (e.g. "uuid_generate_v4()") using the active defaults map.
+// A nextval('seq') default with no explicit mapping is resolved through the
+// provider's SequenceProvider.NextValExpression.
 // The field is modified in place; if no mapping exists the value is unchanged.
-func resolveFieldDefault(f *types.Field, defaults map[string]string) {
-	if f.Default != "" && len(defaults) > 0 {
-		if resolved, ok := defaults[f.Default]; ok {
-			f.Default = resolved
+func resolveFieldDefault(p providers.Provider, f *types.Field, defaults map[string]string) {
+	if f.Default == "" {
+		return
+	}
+	if resolved, ok := defaults[f.Default]; ok {
+		f.Default = resolved
+		return
+	}
+	if seqName, ok := types.ParseNextValDefault(f.Default); ok {
+		if sp, ok := p.(providers.SequenceProvider); ok {
+			f.Default = sp.NextValExpression(seqName)
 		}
 	}
 }
@@ -91,6 +102,8 @@ func toTypesField(f Field) *types.Field {
 		Scale:      f.Scale,
 		AutoCreate: f.AutoCreate,
 		AutoUpdate: f.AutoUpdate,
+		Collation:  f.Collation,
+		Charset:    f.Charset,
 	}
 	if f.ForeignKey != nil {
 		tf.ForeignKey = &types.ForeignKey{
@@ -127,6 +140,9 @@ func stateToSchema(state *SchemaState) *types.Schema {
 				FromFK: idx.FromFK,
 			})
 		}
+		for _, uc := range ts.UniqueConstraints {
+			t.UniqueConstraints = append(t.UniqueConstraints, *toTypesUniqueConstraint(uc))
+		}
 		s.Tables = append(s.Tables, *t)
 	}
 	return s
@@ -179,7 +195,7 @@ func (op *CreateTable) Up(p providers.Provider, state *SchemaState, defaults map
 	table := &types.Table{Name: op.Name}
 	for _, f := range op.Fields {
 		tf := toTypesField(f)
-		resolveFieldDefault(tf, defaults)
+		resolveFieldDefault(p, tf, defaults)
 		table.Fields = append(table.Fields, *tf)
 	}
 	for _, idx := range op.Indexes {
@@ -255,13 +271,30 @@ func (op *DropTable) Down(p providers.Provider, state *SchemaState, defaults map
 	t := &types.Table{Name: ts.Name}
 	for _, f := range ts.Fields {
 		tf := toTypesField(f)
-		resolveFieldDefault(tf, defaults)
+		resolveFieldDefault(p, tf, defaults)
 		t.Fields = append(t.Fields, *tf)
 	}
 	for _, idx := range ts.Indexes {
 		t.Indexes = append(t.Indexes, types.Index{Name: idx.Name, Fields: idx.Fields, Unique: idx.Unique, Method: idx.Method, Where: idx.Where})
 	}
-	return p.GenerateCreateTable(schema, t)
+	createSQL, err := p.GenerateCreateTable(schema, t)
+	if err != nil || len(ts.UniqueConstraints) == 0 {
+		return createSQL, err
+	}
+	// Unique constraints are separate operations, so restore them after the table.
+	up, err := uniqueConstraintProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	parts := []string{createSQL}
+	for _, uc := range ts.UniqueConstraints {
+		stmt, err := up.GenerateAddUniqueConstraint(op.Name, toTypesUniqueConstraint(uc))
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, stmt)
+	}
+	return strings.Join(parts, "\n"), nil
 }
 
 // Mutate removes the table from the SchemaState.
@@ -334,7 +367,7 @@ func (op *AddField) Up(p providers.Provider, state *SchemaState, defaults map[st
 		return "", nil
 	}
 	tf := toTypesField(op.Field)
-	resolveFieldDefault(tf, defaults)
+	resolveFieldDefault(p, tf, defaults)
 	return p.GenerateAddColumn(op.Table, tf), nil
 }
 
@@ -405,7 +438,7 @@ func (op *DropField) Down(p providers.Provider, state *SchemaState, defaults map
 	for _, f := range ts.Fields {
 		if f.Name == op.Field {
 			tf := toTypesField(f)
-			resolveFieldDefault(tf, defaults)
+			resolveFieldDefault(p, tf, defaults)
 			return p.GenerateAddColumn(op.Table, tf), nil
 		}
 	}
@@ -419,11 +452,26 @@ func (op *DropField) Mutate(state *SchemaState) error {
 
 // --- AlterField ---
 
+// AlterField methods select how AlterField changes a column.
+const (
+	// AlterMethodColumn alters the column in place (ALTER COLUMN / MODIFY).
+	// This is the default.
+	AlterMethodColumn = "alter_column"
+	// AlterMethodNewColumn renames the column aside, adds a replacement with
+	// the new definition, copies the values across with a cast, recreates
+	// the indexes, unique constraints and foreign keys on it, and drops the
+	// old column. Use it for type changes the database cannot cast in place.
+	AlterMethodNewColumn = "new_column"
+)
+
 // AlterField is a migration operation that modifies an existing column's definition.
 type AlterField struct {
 	Table    string
 	OldField Field
 	NewField Field
+	// Method is AlterMethodColumn (the default when empty) or
+	// AlterMethodNewColumn.
+	Method string
 }
 
 // TypeName returns the operation type identifier.
@@ -445,7 +493,7 @@ func (op *AlterField) Describe() string {
 // so that providers implementing TableRecreationProvider can access the full
 // current table definition when performing column alterations that require
 // recreating the table (e.g. SQLite).
-func tableStateToTypesTable(state *SchemaState, tableName string, defaults map[string]string) *types.Table {
+func tableStateToTypesTable(p providers.Provider, state *SchemaState, tableName string, defaults map[string]string) *types.Table {
 	t := &types.Table{Name: tableName}
 	if state == nil {
 		return t
@@ -456,12 +504,15 @@ func tableStateToTypesTable(state *SchemaState, tableName string, defaults map[s
 	}
 	for _, f := range ts.Fields {
 		tf := toTypesField(f)
-		resolveFieldDefault(tf, defaults)
+		resolveFieldDefault(p, tf, defaults)
 		t.Fields = append(t.Fields, *tf)
 	}
 	for _, idx := range ts.Indexes {
 		t.Indexes = append(t.Indexes, types.Index{Name: idx.Name, Fields: idx.Fields, Unique: idx.Unique, Method: idx.Method, Where: idx.Where})
 	}
+	for _, uc := range ts.UniqueConstraints {
+		t.UniqueConstraints = append(t.UniqueConstraints, *toTypesUniqueConstraint(uc))
+	}
 	return t
 }
 
@@ -472,10 +523,17 @@ func tableStateToTypesTable(state *SchemaState, tableName string, defaults map[s
 func (op *AlterField) Up(p providers.Provider, state *SchemaState, defaults map[string]string) (string, error) {
 	oldF := toTypesField(op.OldField)
 	newF := toTypesField(op.NewField)
-	resolveFieldDefault(oldF, defaults)
-	resolveFieldDefault(newF, defaults)
+	resolveFieldDefault(p, oldF, defaults)
+	resolveFieldDefault(p, newF, defaults)
+	useNew, err := op.newColumn()
+	if err != nil {
+		return "", err
+	}
+	if useNew {
+		return copyColumnSQL(p, state, op.Table, oldF, newF, defaults)
+	}
 	if trp, ok := p.(providers.TableRecreationProvider); ok {
-		t := tableStateToTypesTable(state, op.Table, defaults)
+		t := tableStateToTypesTable(p, state, op.Table, defaults)
 		return trp.GenerateAlterColumnWithTable(t, oldF, newF)
 	}
 	return p.GenerateAlterColumn(op.Table, oldF, newF)
@@ -488,11 +546,18 @@ func (op *AlterField) Up(p providers.Provider, state *SchemaState, defaults map[
 func (op *AlterField) Down(p providers.Provider, state *SchemaState, defaults map[string]string) (string, error) {
 	oldF := toTypesField(op.OldField)
 	newF := toTypesField(op.NewField)
-	resolveFieldDefault(oldF, defaults)
-	resolveFieldDefault(newF, defaults)
+	resolveFieldDefault(p, oldF, defaults)
+	resolveFieldDefault(p, newF, defaults)
+	useNew, err := op.newColumn()
+	if err != nil {
+		return "", err
+	}
+	if useNew {
+		return copyColumnSQL(p, state, op.Table, newF, oldF, defaults)
+	}
 	if trp, ok := p.(providers.TableRecreationProvider); ok {
 		// For Down, state reflects the post-Up schema; pass newF→oldF to recreate to original.
-		t := tableStateToTypesTable(state, op.Table, defaults)
+		t := tableStateToTypesTable(p, state, op.Table, defaults)
 		return trp.GenerateAlterColumnWithTable(t, newF, oldF)
 	}
 	return p.GenerateAlterColumn(op.Table, newF, oldF)
@@ -503,6 +568,151 @@ func (op *AlterField) Mutate(state *SchemaState) error {
 	return state.AlterField(op.Table, op.NewField)
 }
 
+// newColumn reports whether op uses AlterMethodNewColumn, rejecting unknown
+// methods and primary keys, whose replacement would break referencing
+// foreign keys.
+func (op *AlterField) newColumn() (bool, error) {
+	switch op.Method {
+	case "", AlterMethodColumn:
+		return false, nil
+	case AlterMethodNewColumn:
+		if op.OldField.PrimaryKey || op.NewField.PrimaryKey {
+			return false, fmt.Errorf("alter field %s.%s: method %q cannot be used on a primary key", op.Table, op.NewField.Name, op.Method)
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("alter field %s.%s: unknown method %q", op.Table, op.NewField.Name, op.Method)
+	}
+}
+
+// copyColumnSQL returns the SQL that replaces column from with to by copying
+// its values through a cast (AlterMethodNewColumn). Providers that recreate
+// the table to alter a column (SQLite) do it in one rebuild. Elsewhere the
+// column's foreign keys, unique constraints, indexes and default are dropped,
+// the column is renamed aside, the replacement is added as nullable, filled
+// with the cast values and given its final nullability, then the old column
+// is dropped and the dependents are recreated on the new one. Swapping from
+// and to gives the reverse. A column that foreign keys reference is refused:
+// they would block dropping it, or be left pointing at nothing.
+func copyColumnSQL(p providers.Provider, state *SchemaState, table string, from, to *types.Field, defaults map[string]string) (string, error) {
+	if refs := inboundForeignKeys(state, table, from.Name); len(refs) > 0 {
+		return "", fmt.Errorf("alter field %s.%s: method %q cannot replace a column that foreign keys reference (%s); drop them first",
+			table, from.Name, AlterMethodNewColumn, strings.Join(refs, ", "))
+	}
+	if ccp, ok := p.(providers.ColumnCopyProvider); ok {
+		return ccp.GenerateCopyColumnWithTable(tableStateToTypesTable(p, state, table, defaults), from, to)
+	}
+
+	var fks []ForeignKeyConstraint
+	var ucs []UniqueConstraint
+	var idxs []Index
+	if ts, ok := state.Tables[table]; ok {
+		for _, fk := range ts.ForeignKeys {
+			if fk.FieldName == from.Name {
+				fks = append(fks, fk)
+			}
+		}
+		for _, uc := range ts.UniqueConstraints {
+			if slices.Contains(uc.Fields, from.Name) {
+				ucs = append(ucs, uc)
+			}
+		}
+		for _, idx := range ts.Indexes {
+			if slices.Contains(idx.Fields, from.Name) {
+				idxs = append(idxs, idx)
+			}
+		}
+	}
+
+	var stmts []string
+	add := func(sql string, err error) error {
+		if err != nil {
+			return err
+		}
+		if sql != "" {
+			stmts = append(stmts, sql)
+		}
+		return nil
+	}
+	for _, fk := range fks {
+		stmts = append(stmts, p.GenerateDropForeignKeyConstraint(table, fk.Name))
+	}
+	var up providers.UniqueConstraintProvider
+	if len(ucs) > 0 {
+		var err error
+		if up, err = uniqueConstraintProvider(p, "alter field"); err != nil {
+			return "", err
+		}
+	}
+	for _, uc := range ucs {
+		stmts = append(stmts, up.GenerateDropUniqueConstraint(table, uc.Name))
+	}
+	for _, idx := range idxs {
+		stmts = append(stmts, p.GenerateDropIndex(idx.Name, table))
+	}
+	noDefault := *from
+	noDefault.Default = ""
+	if from.Default != "" {
+		if err := add(p.GenerateAlterColumn(table, from, &noDefault)); err != nil {
+			return "", err
+		}
+	}
+
+	aside := from.Name + "__old"
+	if frp, ok := p.(providers.FieldRenameProvider); ok {
+		stmts = append(stmts, frp.GenerateRenameColumnWithField(table, &noDefault, aside))
+	} else {
+		stmts = append(stmts, p.GenerateRenameColumn(table, from.Name, aside))
+	}
+	nullable := *to
+	nullable.Nullable = boolPtr(true)
+	stmts = append(stmts, p.GenerateAddColumn(table, &nullable))
+	cast := fmt.Sprintf("CAST(%s AS %s)", p.QuoteName(aside), p.ConvertFieldType(to))
+	if cp, ok := p.(providers.CastProvider); ok {
+		cast = cp.GenerateCast(p.QuoteName(aside), to)
+	}
+	stmts = append(stmts, fmt.Sprintf("UPDATE %s SET %s = %s;", p.QuoteName(table), p.QuoteName(to.Name), cast))
+	if !to.IsNullable() {
+		if err := add(p.GenerateAlterColumn(table, &nullable, to)); err != nil {
+			return "", err
+		}
+	}
+	stmts = append(stmts, p.GenerateDropColumn(table, aside))
+
+	for _, idx := range idxs {
+		stmts = append(stmts, p.GenerateCreateIndex(&types.Index{Name: idx.Name, Fields: idx.Fields, Unique: idx.Unique, Method: idx.Method, Where: idx.Where}, table))
+	}
+	for _, uc := range ucs {
+		if err := add(up.GenerateAddUniqueConstraint(table, toTypesUniqueConstraint(uc))); err != nil {
+			return "", err
+		}
+	}
+	for _, fk := range fks {
+		stmts = append(stmts, p.GenerateForeignKeyConstraint(table, fk.FieldName, fk.ReferencedTable, fk.Name,
+			normalizeOnDelete(fk.OnDelete), normalizeOnDelete(fk.OnUpdate)))
+	}
+	return strings.Join(stmts, "\n"), nil
+}
+
+// inboundForeignKeys returns the foreign keys in state, including table's
+// own, that reference column field of table, as "table.constraint". Foreign keys
+// reference the primary key, so there are none unless field is it.
+func inboundForeignKeys(state *SchemaState, table, field string) []string {
+	ts, ok := state.Tables[table]
+	if !ok || !slices.ContainsFunc(ts.Fields, func(f Field) bool { return f.Name == field && f.PrimaryKey }) {
+		return nil
+	}
+	var refs []string
+	for _, name := range slices.Sorted(maps.Keys(state.Tables)) {
+		for _, fk := range state.Tables[name].ForeignKeys {
+			if fk.ReferencedTable == table {
+				refs = append(refs, name+"."+fk.Name)
+			}
+		}
+	}
+	return refs
+}
+
 // --- RenameField ---
 
 // RenameField is a migration operation that renames a column in an existing table.
@@ -528,12 +738,32 @@ func (op *RenameField) Describe() string {
 
 // Up generates the RENAME COLUMN SQL statement.
 func (op *RenameField) Up(p providers.Provider, state *SchemaState, defaults map[string]string) (string, error) {
-	return p.GenerateRenameColumn(op.Table, op.OldName, op.NewName), nil
+	return renameColumnSQL(p, state, op.Table, op.OldName, op.OldName, op.NewName, defaults), nil
 }
 
 // Down generates the reverse RENAME COLUMN SQL to restore the original name.
 func (op *RenameField) Down(p providers.Provider, state *SchemaState, defaults map[string]string) (string, error) {
-	return p.GenerateRenameColumn(op.Table, op.NewName, op.OldName), nil
+	return renameColumnSQL(p, state, op.Table, op.OldName, op.NewName, op.OldName, defaults), nil
+}
+
+// renameColumnSQL renames column from to to. Providers that restate the
+// column definition in a rename (FieldRenameProvider) get it from field in
+// state, the column's name before the operation; without it, or when the
+// field is not in state, GenerateRenameColumn is used.
+func renameColumnSQL(p providers.Provider, state *SchemaState, table, field, from, to string, defaults map[string]string) string {
+	if frp, ok := p.(providers.FieldRenameProvider); ok {
+		if ts, exists := state.Tables[table]; exists {
+			for _, f := range ts.Fields {
+				if f.Name == field {
+					tf := toTypesField(f)
+					tf.Name = from
+					resolveFieldDefault(p, tf, defaults)
+					return frp.GenerateRenameColumnWithField(table, tf, to)
+				}
+			}
+		}
+	}
+	return p.GenerateRenameColumn(table, from, to)
 }
 
 // Mutate updates the field name in the table's entry in SchemaState.
@@ -630,6 +860,131 @@ func (op *DropIndex) Mutate(state *SchemaState) error {
 	return state.DropIndex(op.Table, op.Index)
 }
 
+// toTypesUniqueConstraint converts a migrate.UniqueConstraint to the
+// *types.UniqueConstraint expected by UniqueConstraintProvider.
+func toTypesUniqueConstraint(uc UniqueConstraint) *types.UniqueConstraint {
+	return &types.UniqueConstraint{
+		Name:             uc.Name,
+		Fields:           append([]string(nil), uc.Fields...),
+		NullsNotDistinct: uc.NullsNotDistinct,
+	}
+}
+
+// uniqueConstraintProvider returns p as a UniqueConstraintProvider, or an
+// error naming the operation when the provider cannot manage unique constraints.
+func uniqueConstraintProvider(p providers.Provider, opName string) (providers.UniqueConstraintProvider, error) {
+	up, ok := p.(providers.UniqueConstraintProvider)
+	if !ok {
+		return nil, fmt.Errorf("%s: provider does not support unique constraints", opName)
+	}
+	return up, nil
+}
+
+// --- AddUniqueConstraint ---
+
+// AddUniqueConstraint is a migration operation that adds a named UNIQUE
+// constraint to an existing table.
+type AddUniqueConstraint struct {
+	Table      string
+	Constraint UniqueConstraint
+}
+
+// TypeName returns the operation type identifier.
+func (op *AddUniqueConstraint) TypeName() string { return "add_unique_constraint" }
+
+// TableName returns the name of the table the constraint is added to.
+func (op *AddUniqueConstraint) TableName() string { return op.Table }
+
+// IsDestructive returns false — adding a constraint is not destructive.
+func (op *AddUniqueConstraint) IsDestructive() bool { return false }
+
+// Describe returns a human-readable description of this operation.
+func (op *AddUniqueConstraint) Describe() string {
+	return fmt.Sprintf("Add unique constraint %s on %s(%s)", op.Constraint.Name, op.Table, joinFields(op.Constraint.Fields))
+}
+
+// Up generates the SQL that adds the constraint.
+func (op *AddUniqueConstraint) Up(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	up, err := uniqueConstraintProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	return up.GenerateAddUniqueConstraint(op.Table, toTypesUniqueConstraint(op.Constraint))
+}
+
+// Down generates the SQL that removes the constraint.
+func (op *AddUniqueConstraint) Down(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	up, err := uniqueConstraintProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	return up.GenerateDropUniqueConstraint(op.Table, op.Constraint.Name), nil
+}
+
+// Mutate records the constraint in the SchemaState.
+func (op *AddUniqueConstraint) Mutate(state *SchemaState) error {
+	return state.AddUniqueConstraint(op.Table, op.Constraint)
+}
+
+// --- DropUniqueConstraint ---
+
+// DropUniqueConstraint is a migration operation that removes a named UNIQUE
+// constraint. Down reads the pre-drop definition from SchemaState.
+type DropUniqueConstraint struct {
+	Table        string
+	Name         string
+	IgnoreErrors bool // when true, runner logs a warning and continues on SQL failure
+}
+
+// ShouldIgnoreErrors implements ErrorIgnorer.
+func (op *DropUniqueConstraint) ShouldIgnoreErrors() bool { return op.IgnoreErrors }
+
+// TypeName returns the operation type identifier.
+func (op *DropUniqueConstraint) TypeName() string { return "drop_unique_constraint" }
+
+// TableName returns the name of the table the constraint is removed from.
+func (op *DropUniqueConstraint) TableName() string { return op.Table }
+
+// IsDestructive returns true — removing a unique constraint relaxes data integrity.
+func (op *DropUniqueConstraint) IsDestructive() bool { return true }
+
+// Describe returns a human-readable description of this operation.
+func (op *DropUniqueConstraint) Describe() string {
+	return fmt.Sprintf("Drop unique constraint %s from %s", op.Name, op.Table)
+}
+
+// Up generates the SQL that removes the constraint.
+func (op *DropUniqueConstraint) Up(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	up, err := uniqueConstraintProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	return up.GenerateDropUniqueConstraint(op.Table, op.Name), nil
+}
+
+// Down recreates the constraint from its pre-drop state.
+func (op *DropUniqueConstraint) Down(p providers.Provider, state *SchemaState, _ map[string]string) (string, error) {
+	up, err := uniqueConstraintProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	ts, exists := state.Tables[op.Table]
+	if !exists {
+		return "", fmt.Errorf("table %q not found in state", op.Table)
+	}
+	for _, uc := range ts.UniqueConstraints {
+		if uc.Name == op.Name {
+			return up.GenerateAddUniqueConstraint(op.Table, toTypesUniqueConstraint(uc))
+		}
+	}
+	return "", fmt.Errorf("unique constraint %q not found in table %q state", op.Name, op.Table)
+}
+
+// Mutate removes the constraint from the SchemaState.
+func (op *DropUniqueConstraint) Mutate(state *SchemaState) error {
+	return state.DropUniqueConstraint(op.Table, op.Name)
+}
+
 // normalizeOnDelete converts Django-style on_delete values to their SQL
 // equivalents. SQL keywords are returned unchanged.
 func normalizeOnDelete(v string) string {
@@ -816,6 +1171,47 @@ func (op *RunSQL) Down(_ providers.Provider, _ *SchemaState, _ map[string]string
 // Mutate is a no-op for RunSQL — raw SQL does not alter the SchemaState.
 func (op *RunSQL) Mutate(_ *SchemaState) error { return nil }
 
+// --- Backfill ---
+
+// Backfill is a migration operation that sets Field to the SQL expression
+// Expr in every row of Table where it is NULL, typically before the column
+// is made NOT NULL. Unlike a RunSQL UPDATE, the table and column names are
+// quoted for the target database.
+type Backfill struct {
+	Table string
+	Field string
+	// Expr is a SQL expression evaluated per row, e.g. "''" or "created_at".
+	Expr string
+}
+
+// TypeName returns the operation type identifier.
+func (op *Backfill) TypeName() string { return "backfill" }
+
+// TableName returns the name of the table being backfilled.
+func (op *Backfill) TableName() string { return op.Table }
+
+// IsDestructive returns false — only NULL values are replaced.
+func (op *Backfill) IsDestructive() bool { return false }
+
+// Describe returns a human-readable description of this operation.
+func (op *Backfill) Describe() string {
+	return fmt.Sprintf("Backfill %s.%s with %s", op.Table, op.Field, op.Expr)
+}
+
+// Up generates the UPDATE that fills the NULL values.
+func (op *Backfill) Up(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	field := p.QuoteName(op.Field)
+	return fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s IS NULL;", p.QuoteName(op.Table), field, op.Expr, field), nil
+}
+
+// Down returns empty string — the backfilled values are left in place.
+func (op *Backfill) Down(_ providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	return "", nil
+}
+
+// Mutate is a no-op for Backfill — it changes data, not the schema.
+func (op *Backfill) Mutate(_ *SchemaState) error { return nil }
+
 // --- SetDefaults ---
 
 // SetDefaults is a migration operation that records the active schema defaults
@@ -1003,3 +1399,542 @@ func (op *UpsertData) Down(p providers.Provider, _ *SchemaState, _ map[string]st
 
 // Mutate is a no-op — UpsertData does not alter the schema state.
 func (op *UpsertData) Mutate(_ *SchemaState) error { return nil }
+
+// toTypesSequence converts a migrate.Sequence to a *types.Sequence for provider calls.
+func toTypesSequence(s Sequence) *types.Sequence {
+	return &types.Sequence{
+		Name:        s.Name,
+		Start:       s.Start,
+		Increment:   s.Increment,
+		MinValue:    s.MinValue,
+		MaxValue:    s.MaxValue,
+		Cycle:       s.Cycle,
+		OwnedBy:     s.OwnedBy,
+		HasStart:    s.HasStart,
+		HasMinValue: s.HasMinValue,
+	}
+}
+
+// sequenceProvider returns p as a SequenceProvider, or an error naming the
+// operation when the provider cannot manage sequences.
+func sequenceProvider(p providers.Provider, opName string) (providers.SequenceProvider, error) {
+	sp, ok := p.(providers.SequenceProvider)
+	if !ok {
+		return nil, fmt.Errorf("%s: provider does not support sequences", opName)
+	}
+	return sp, nil
+}
+
+// --- CreateSequence ---
+
+// CreateSequence is a migration operation that creates a standalone sequence.
+// Providers without native sequences (MySQL, SQLite) emulate it with a counter table.
+type CreateSequence struct {
+	Sequence   Sequence
+	SchemaOnly bool // when true, Up/Down return no SQL; Mutate still runs
+}
+
+// TypeName returns the operation type identifier.
+func (op *CreateSequence) TypeName() string { return "create_sequence" }
+
+// TableName returns "" — sequences are not tables.
+func (op *CreateSequence) TableName() string { return "" }
+
+// IsDestructive returns false — creating a sequence is not destructive.
+func (op *CreateSequence) IsDestructive() bool { return false }
+
+// Describe returns a human-readable description of this operation.
+func (op *CreateSequence) Describe() string {
+	return fmt.Sprintf("Create sequence %s", op.Sequence.Name)
+}
+
+// Up generates the CREATE SEQUENCE SQL, or returns empty string when SchemaOnly is set.
+func (op *CreateSequence) Up(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	sp, err := sequenceProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	return sp.GenerateCreateSequence(toTypesSequence(op.Sequence)), nil
+}
+
+// Down generates the DROP SEQUENCE SQL to reverse the creation.
+func (op *CreateSequence) Down(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	sp, err := sequenceProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	return sp.GenerateDropSequence(toTypesSequence(op.Sequence)), nil
+}
+
+// Mutate adds the sequence to the SchemaState.
+func (op *CreateSequence) Mutate(state *SchemaState) error {
+	return state.AddSequence(op.Sequence)
+}
+
+// --- AlterSequence ---
+
+// AlterSequence is a migration operation that changes a sequence's options.
+// OldSequence is kept so Down can restore the previous definition.
+type AlterSequence struct {
+	OldSequence Sequence
+	NewSequence Sequence
+}
+
+// TypeName returns the operation type identifier.
+func (op *AlterSequence) TypeName() string { return "alter_sequence" }
+
+// TableName returns "" — sequences are not tables.
+func (op *AlterSequence) TableName() string { return "" }
+
+// IsDestructive returns false — altering sequence options keeps issued values.
+func (op *AlterSequence) IsDestructive() bool { return false }
+
+// Describe returns a human-readable description of this operation.
+func (op *AlterSequence) Describe() string {
+	return fmt.Sprintf("Alter sequence %s", op.NewSequence.Name)
+}
+
+// Up generates the ALTER SEQUENCE SQL to apply the new definition.
+func (op *AlterSequence) Up(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	sp, err := sequenceProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	return sp.GenerateAlterSequence(toTypesSequence(op.OldSequence), toTypesSequence(op.NewSequence)), nil
+}
+
+// Down generates the ALTER SEQUENCE SQL to restore the old definition.
+func (op *AlterSequence) Down(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	sp, err := sequenceProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	return sp.GenerateAlterSequence(toTypesSequence(op.NewSequence), toTypesSequence(op.OldSequence)), nil
+}
+
+// Mutate replaces the sequence definition in the SchemaState.
+func (op *AlterSequence) Mutate(state *SchemaState) error {
+	return state.AlterSequence(op.NewSequence)
+}
+
+// --- DropSequence ---
+
+// DropSequence is a migration operation that drops a standalone sequence.
+// Up and Down read the sequence definition from the pre-drop SchemaState so
+// emulation triggers can be removed and the sequence recreated on rollback.
+type DropSequence struct {
+	Name         string
+	SchemaOnly   bool // when true, Up/Down return no SQL; Mutate still runs
+	IgnoreErrors bool // when true, runner logs a warning and continues on SQL failure
+}
+
+// ShouldIgnoreErrors implements ErrorIgnorer.
+func (op *DropSequence) ShouldIgnoreErrors() bool { return op.IgnoreErrors }
+
+// TypeName returns the operation type identifier.
+func (op *DropSequence) TypeName() string { return "drop_sequence" }
+
+// TableName returns "" — sequences are not tables.
+func (op *DropSequence) TableName() string { return "" }
+
+// IsDestructive returns true — dropping a sequence loses its current value.
+func (op *DropSequence) IsDestructive() bool { return true }
+
+// Describe returns a human-readable description of this operation.
+func (op *DropSequence) Describe() string { return fmt.Sprintf("Drop sequence %s", op.Name) }
+
+// Up generates the DROP SEQUENCE SQL, or returns empty string when SchemaOnly is set.
+func (op *DropSequence) Up(p providers.Provider, state *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	sp, err := sequenceProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	seq := Sequence{Name: op.Name}
+	if state != nil && state.Sequences[op.Name] != nil {
+		seq = *state.Sequences[op.Name]
+	}
+	return sp.GenerateDropSequence(toTypesSequence(seq)), nil
+}
+
+// Down recreates the sequence from its pre-drop state. The sequence restarts
+// at its configured start value; previously issued values are not restored.
+func (op *DropSequence) Down(p providers.Provider, state *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	sp, err := sequenceProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	if state == nil || state.Sequences[op.Name] == nil {
+		return "", fmt.Errorf("sequence %q not found in state for Down generation", op.Name)
+	}
+	return sp.GenerateCreateSequence(toTypesSequence(*state.Sequences[op.Name])), nil
+}
+
+// Mutate removes the sequence from the SchemaState.
+func (op *DropSequence) Mutate(state *SchemaState) error { return state.DropSequence(op.Name) }
+
+// toTypesFunction converts a migrate.Function to a *types.Function for provider
+// calls. The body is passed to providers separately.
+func toTypesFunction(f Function) *types.Function {
+	return &types.Function{
+		Name:      f.Name,
+		Arguments: f.Arguments,
+		Returns:   f.Returns,
+		Language:  f.Language,
+		Procedure: f.Procedure,
+	}
+}
+
+// toTypesTrigger converts a migrate.Trigger to a *types.Trigger for provider
+// calls. The body is passed to providers separately.
+func toTypesTrigger(t Trigger) *types.Trigger {
+	return &types.Trigger{
+		Name:     t.Name,
+		Table:    t.Table,
+		Timing:   t.Timing,
+		Events:   append([]string(nil), t.Events...),
+		ForEach:  t.ForEach,
+		When:     t.When,
+		Function: t.Function,
+	}
+}
+
+// functionProvider returns p as a FunctionProvider, or an error naming the
+// operation when the provider cannot manage stored functions.
+func functionProvider(p providers.Provider, opName string) (providers.FunctionProvider, error) {
+	fp, ok := p.(providers.FunctionProvider)
+	if !ok {
+		return nil, fmt.Errorf("%s: provider does not support stored functions", opName)
+	}
+	return fp, nil
+}
+
+// triggerProvider returns p as a TriggerProvider, or an error naming the
+// operation when the provider cannot manage triggers.
+func triggerProvider(p providers.Provider, opName string) (providers.TriggerProvider, error) {
+	tp, ok := p.(providers.TriggerProvider)
+	if !ok {
+		return nil, fmt.Errorf("%s: provider does not support triggers", opName)
+	}
+	return tp, nil
+}
+
+// sameFunctionSignature reports whether a and b can be swapped with a
+// create-or-replace statement, i.e. they share arguments, return type and kind.
+func sameFunctionSignature(a, b Function) bool {
+	return a.Arguments == b.Arguments && a.Returns == b.Returns && a.Procedure == b.Procedure
+}
+
+// replaceFunctionSQL returns the SQL that swaps the routine from into to. When
+// the signature changed the old routine is dropped first, since CREATE OR
+// REPLACE cannot change argument or return types.
+func replaceFunctionSQL(fp providers.FunctionProvider, from, to Function) (string, error) {
+	if sameFunctionSignature(from, to) {
+		return fp.GenerateCreateFunction(toTypesFunction(to), to.Body, true)
+	}
+	create, err := fp.GenerateCreateFunction(toTypesFunction(to), to.Body, false)
+	if err != nil {
+		return "", err
+	}
+	return fp.GenerateDropFunction(toTypesFunction(from)) + "\n" + create, nil
+}
+
+// --- CreateFunction ---
+
+// CreateFunction is a migration operation that creates a stored function or procedure.
+type CreateFunction struct {
+	Function   Function
+	SchemaOnly bool // when true, Up/Down return no SQL; Mutate still runs
+}
+
+// TypeName returns the operation type identifier.
+func (op *CreateFunction) TypeName() string { return "create_function" }
+
+// TableName returns "" — functions are not tables.
+func (op *CreateFunction) TableName() string { return "" }
+
+// IsDestructive returns false — creating a function is not destructive.
+func (op *CreateFunction) IsDestructive() bool { return false }
+
+// Describe returns a human-readable description of this operation.
+func (op *CreateFunction) Describe() string {
+	if op.Function.Procedure {
+		return fmt.Sprintf("Create procedure %s", op.Function.Name)
+	}
+	return fmt.Sprintf("Create function %s", op.Function.Name)
+}
+
+// Up generates the CREATE FUNCTION SQL, or returns empty string when SchemaOnly is set.
+func (op *CreateFunction) Up(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	fp, err := functionProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	return fp.GenerateCreateFunction(toTypesFunction(op.Function), op.Function.Body, false)
+}
+
+// Down generates the DROP FUNCTION SQL to reverse the creation.
+func (op *CreateFunction) Down(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	fp, err := functionProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	return fp.GenerateDropFunction(toTypesFunction(op.Function)), nil
+}
+
+// Mutate adds the function to the SchemaState.
+func (op *CreateFunction) Mutate(state *SchemaState) error {
+	return state.AddFunction(op.Function)
+}
+
+// --- ReplaceFunction ---
+
+// ReplaceFunction is a migration operation that changes the body or signature
+// of an existing function. The previous definition is read from SchemaState,
+// so Down restores exactly the body that was in place before this migration.
+type ReplaceFunction struct {
+	Function Function
+}
+
+// TypeName returns the operation type identifier.
+func (op *ReplaceFunction) TypeName() string { return "replace_function" }
+
+// TableName returns "" — functions are not tables.
+func (op *ReplaceFunction) TableName() string { return "" }
+
+// IsDestructive returns false — the previous body is recoverable from state.
+func (op *ReplaceFunction) IsDestructive() bool { return false }
+
+// Describe returns a human-readable description of this operation.
+func (op *ReplaceFunction) Describe() string {
+	if op.Function.Procedure {
+		return fmt.Sprintf("Replace procedure %s", op.Function.Name)
+	}
+	return fmt.Sprintf("Replace function %s", op.Function.Name)
+}
+
+// previous returns the function definition held in state before this operation.
+func (op *ReplaceFunction) previous(state *SchemaState) (Function, error) {
+	if state == nil || state.Functions[op.Function.Name] == nil {
+		return Function{}, fmt.Errorf("function %q not found in state", op.Function.Name)
+	}
+	return *state.Functions[op.Function.Name], nil
+}
+
+// Up generates SQL that replaces the previous definition with op.Function.
+func (op *ReplaceFunction) Up(p providers.Provider, state *SchemaState, _ map[string]string) (string, error) {
+	fp, err := functionProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	prev, err := op.previous(state)
+	if err != nil {
+		return "", err
+	}
+	return replaceFunctionSQL(fp, prev, op.Function)
+}
+
+// Down generates SQL that restores the previous definition from SchemaState.
+func (op *ReplaceFunction) Down(p providers.Provider, state *SchemaState, _ map[string]string) (string, error) {
+	fp, err := functionProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	prev, err := op.previous(state)
+	if err != nil {
+		return "", err
+	}
+	return replaceFunctionSQL(fp, op.Function, prev)
+}
+
+// Mutate replaces the function definition in the SchemaState.
+func (op *ReplaceFunction) Mutate(state *SchemaState) error {
+	return state.ReplaceFunction(op.Function)
+}
+
+// --- DropFunction ---
+
+// DropFunction is a migration operation that drops a stored function or procedure.
+// The definition is read from the pre-drop SchemaState so Down can recreate it.
+type DropFunction struct {
+	Name         string
+	SchemaOnly   bool // when true, Up/Down return no SQL; Mutate still runs
+	IgnoreErrors bool // when true, runner logs a warning and continues on SQL failure
+}
+
+// ShouldIgnoreErrors implements ErrorIgnorer.
+func (op *DropFunction) ShouldIgnoreErrors() bool { return op.IgnoreErrors }
+
+// TypeName returns the operation type identifier.
+func (op *DropFunction) TypeName() string { return "drop_function" }
+
+// TableName returns "" — functions are not tables.
+func (op *DropFunction) TableName() string { return "" }
+
+// IsDestructive returns true — dropping a function breaks its callers.
+func (op *DropFunction) IsDestructive() bool { return true }
+
+// Describe returns a human-readable description of this operation.
+func (op *DropFunction) Describe() string { return fmt.Sprintf("Drop function %s", op.Name) }
+
+// Up generates the DROP FUNCTION SQL, or returns empty string when SchemaOnly is set.
+func (op *DropFunction) Up(p providers.Provider, state *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	fp, err := functionProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	fn := Function{Name: op.Name}
+	if state != nil && state.Functions[op.Name] != nil {
+		fn = *state.Functions[op.Name]
+	}
+	return fp.GenerateDropFunction(toTypesFunction(fn)), nil
+}
+
+// Down recreates the function from its pre-drop state.
+func (op *DropFunction) Down(p providers.Provider, state *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	fp, err := functionProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	if state == nil || state.Functions[op.Name] == nil {
+		return "", fmt.Errorf("function %q not found in state for Down generation", op.Name)
+	}
+	fn := *state.Functions[op.Name]
+	return fp.GenerateCreateFunction(toTypesFunction(fn), fn.Body, false)
+}
+
+// Mutate removes the function from the SchemaState.
+func (op *DropFunction) Mutate(state *SchemaState) error { return state.DropFunction(op.Name) }
+
+// --- CreateTrigger ---
+
+// CreateTrigger is a migration operation that creates a table trigger.
+type CreateTrigger struct {
+	Trigger    Trigger
+	SchemaOnly bool // when true, Up/Down return no SQL; Mutate still runs
+}
+
+// TypeName returns the operation type identifier.
+func (op *CreateTrigger) TypeName() string { return "create_trigger" }
+
+// TableName returns the table the trigger is attached to.
+func (op *CreateTrigger) TableName() string { return op.Trigger.Table }
+
+// IsDestructive returns false — creating a trigger is not destructive.
+func (op *CreateTrigger) IsDestructive() bool { return false }
+
+// Describe returns a human-readable description of this operation.
+func (op *CreateTrigger) Describe() string {
+	return fmt.Sprintf("Create trigger %s on %s", op.Trigger.Name, op.Trigger.Table)
+}
+
+// Up generates the CREATE TRIGGER SQL, or returns empty string when SchemaOnly is set.
+func (op *CreateTrigger) Up(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	tp, err := triggerProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	return tp.GenerateCreateTrigger(toTypesTrigger(op.Trigger), op.Trigger.Body)
+}
+
+// Down generates the DROP TRIGGER SQL to reverse the creation.
+func (op *CreateTrigger) Down(p providers.Provider, _ *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	tp, err := triggerProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	return tp.GenerateDropTrigger(toTypesTrigger(op.Trigger)), nil
+}
+
+// Mutate adds the trigger to the SchemaState.
+func (op *CreateTrigger) Mutate(state *SchemaState) error {
+	return state.AddTrigger(op.Trigger)
+}
+
+// --- DropTrigger ---
+
+// DropTrigger is a migration operation that drops a table trigger.
+// The definition is read from the pre-drop SchemaState so Down can recreate it.
+type DropTrigger struct {
+	Name         string
+	SchemaOnly   bool // when true, Up/Down return no SQL; Mutate still runs
+	IgnoreErrors bool // when true, runner logs a warning and continues on SQL failure
+}
+
+// ShouldIgnoreErrors implements ErrorIgnorer.
+func (op *DropTrigger) ShouldIgnoreErrors() bool { return op.IgnoreErrors }
+
+// TypeName returns the operation type identifier.
+func (op *DropTrigger) TypeName() string { return "drop_trigger" }
+
+// TableName returns "" — the table is only known from state.
+func (op *DropTrigger) TableName() string { return "" }
+
+// IsDestructive returns true — dropping a trigger removes behaviour the data may rely on.
+func (op *DropTrigger) IsDestructive() bool { return true }
+
+// Describe returns a human-readable description of this operation.
+func (op *DropTrigger) Describe() string { return fmt.Sprintf("Drop trigger %s", op.Name) }
+
+// Up generates the DROP TRIGGER SQL, or returns empty string when SchemaOnly is set.
+func (op *DropTrigger) Up(p providers.Provider, state *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	tp, err := triggerProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	if state == nil || state.Triggers[op.Name] == nil {
+		return "", fmt.Errorf("trigger %q not found in state", op.Name)
+	}
+	return tp.GenerateDropTrigger(toTypesTrigger(*state.Triggers[op.Name])), nil
+}
+
+// Down recreates the trigger from its pre-drop state.
+func (op *DropTrigger) Down(p providers.Provider, state *SchemaState, _ map[string]string) (string, error) {
+	if op.SchemaOnly {
+		return "", nil
+	}
+	tp, err := triggerProvider(p, op.TypeName())
+	if err != nil {
+		return "", err
+	}
+	if state == nil || state.Triggers[op.Name] == nil {
+		return "", fmt.Errorf("trigger %q not found in state for Down generation", op.Name)
+	}
+	tr := *state.Triggers[op.Name]
+	return tp.GenerateCreateTrigger(toTypesTrigger(tr), tr.Body)
+}
+
+// Mutate removes the trigger from the SchemaState.
+func (op *DropTrigger) Mutate(state *SchemaState) error { return state.DropTrigger(op.Name) }
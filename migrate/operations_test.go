@@ -28,6 +28,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ocomsoft/makemigrations/internal/providers"
+	"github.com/ocomsoft/makemigrations/internal/providers/mysql"
 	"github.com/ocomsoft/makemigrations/internal/providers/postgresql"
 	"github.com/ocomsoft/makemigrations/internal/providers/sqlite"
 	"github.com/ocomsoft/makemigrations/migrate"
@@ -160,6 +162,23 @@ func TestRunSQL_Describe(t *testing.T) {
 	}
 }
 
+func TestBackfill_UpDown(t *testing.T) {
+	op := &migrate.Backfill{Table: "Order", Field: "user", Expr: "'guest'"}
+	sql, err := op.Up(postgresql.New(), nil, nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if want := `UPDATE "Order" SET "user" = 'guest' WHERE "user" IS NULL;`; sql != want {
+		t.Fatalf("expected %q, got %q", want, sql)
+	}
+	if back, _ := op.Down(postgresql.New(), nil, nil); back != "" {
+		t.Fatalf("expected no down SQL, got %q", back)
+	}
+	if op.TableName() != "Order" || op.IsDestructive() {
+		t.Fatal("expected a non-destructive operation on Order")
+	}
+}
+
 func TestDropField_Down_ReconstructsFromState(t *testing.T) {
 	p := sqlite.New()
 	state := migrate.NewSchemaState()
@@ -192,6 +211,7 @@ func TestAllTypenames(t *testing.T) {
 		{&migrate.AddIndex{Table: "t", Index: migrate.Index{Name: "i", Fields: []string{"f"}}}, "add_index"},
 		{&migrate.DropIndex{Table: "t", Index: "i"}, "drop_index"},
 		{&migrate.RunSQL{}, "run_sql"},
+		{&migrate.Backfill{Table: "t", Field: "f"}, "backfill"},
 		{&migrate.AddForeignKey{Table: "t", FieldName: "fk_id", ReferencedTable: "other", ConstraintName: "fk_t_other"}, "add_foreign_key"},
 		{&migrate.DropForeignKey{Table: "t", ConstraintName: "fk_t_other"}, "drop_foreign_key"},
 	}
@@ -690,3 +710,144 @@ func TestDropForeignKey_Down_WithOnUpdate(t *testing.T) {
 		t.Errorf("expected stored constraint name fk_orders_user_id in Down SQL, got: %s", downSQL)
 	}
 }
+
+func TestAlterField_NewColumn_PostgreSQL(t *testing.T) {
+	p := postgresql.New()
+	state := migrate.NewSchemaState()
+	if err := (&migrate.CreateTable{
+		Name: "orders",
+		Fields: []migrate.Field{
+			{Name: "id", Type: "integer", PrimaryKey: true},
+			{Name: "code", Type: "varchar", Length: 20, Default: "0"},
+		},
+		Indexes: []migrate.Index{{Name: "idx_orders_code", Fields: []string{"code"}}},
+	}).Mutate(state); err != nil {
+		t.Fatal(err)
+	}
+	op := &migrate.AlterField{
+		Table:    "orders",
+		OldField: migrate.Field{Name: "code", Type: "varchar", Length: 20, Default: "0"},
+		NewField: migrate.Field{Name: "code", Type: "integer"},
+		Method:   migrate.AlterMethodNewColumn,
+	}
+	up, err := op.Up(p, state, nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	want := []string{
+		`DROP INDEX`,
+		`ALTER COLUMN "code" DROP DEFAULT`,
+		`RENAME COLUMN "code" TO "code__old"`,
+		`ADD COLUMN "code" INTEGER`,
+		`UPDATE "orders" SET "code" = "code__old"::INTEGER;`,
+		`ALTER COLUMN "code" SET NOT NULL`,
+		`DROP COLUMN "code__old"`,
+		`CREATE INDEX "idx_orders_code"`,
+	}
+	last := -1
+	for _, w := range want {
+		i := strings.Index(up, w)
+		if i < 0 {
+			t.Fatalf("expected %q in Up SQL:\n%s", w, up)
+		}
+		if i < last {
+			t.Errorf("expected %q after the previous statement:\n%s", w, up)
+		}
+		last = i
+	}
+
+	down, err := op.Down(p, state, nil)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if !strings.Contains(down, `"code__old"::VARCHAR(20)`) || !strings.Contains(down, `VARCHAR(20) DEFAULT 0;`) {
+		t.Errorf("expected Down to copy back to VARCHAR(20) and restore the default:\n%s", down)
+	}
+
+	op.Method = "bogus"
+	if _, err := op.Up(p, state, nil); err == nil {
+		t.Error("expected an error for an unknown method")
+	}
+}
+
+func TestRenameField_MySQLKeepsDefinition(t *testing.T) {
+	p := mysql.New()
+	state := migrate.NewSchemaState()
+	if err := (&migrate.CreateTable{
+		Name: "users",
+		Fields: []migrate.Field{
+			{Name: "id", Type: "integer", PrimaryKey: true},
+			{Name: "age__new", Type: "integer", Nullable: true},
+		},
+	}).Mutate(state); err != nil {
+		t.Fatal(err)
+	}
+	op := &migrate.RenameField{Table: "users", OldName: "age__new", NewName: "age"}
+	up, err := op.Up(p, state, nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if want := "ALTER TABLE `users` CHANGE `age__new` `age` INT;"; up != want {
+		t.Errorf("Up = %q, want %q", up, want)
+	}
+	down, err := op.Down(p, state, nil)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if want := "ALTER TABLE `users` CHANGE `age` `age__new` INT;"; down != want {
+		t.Errorf("Down = %q, want %q", down, want)
+	}
+}
+
+func TestAlterField_NewColumn_RefusesReferencedColumn(t *testing.T) {
+	state := migrate.NewSchemaState()
+	for _, op := range []migrate.Operation{
+		&migrate.CreateTable{Name: "users", Fields: []migrate.Field{{Name: "code", Type: "varchar", Length: 20, PrimaryKey: true}}},
+		&migrate.CreateTable{Name: "orders", Fields: []migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}, {Name: "user_code", Type: "varchar", Length: 20}}},
+		&migrate.AddForeignKey{Table: "orders", FieldName: "user_code", ConstraintName: "fk_orders_user_code", ReferencedTable: "users"},
+	} {
+		if err := op.Mutate(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The op does not mark the column as the primary key; the state does.
+	op := &migrate.AlterField{
+		Table:    "users",
+		OldField: migrate.Field{Name: "code", Type: "varchar", Length: 20},
+		NewField: migrate.Field{Name: "code", Type: "integer"},
+		Method:   migrate.AlterMethodNewColumn,
+	}
+	for _, p := range []providers.Provider{postgresql.New(), sqlite.New()} {
+		if _, err := op.Up(p, state, nil); err == nil || !strings.Contains(err.Error(), "orders.fk_orders_user_code") {
+			t.Errorf("%T: expected the referencing foreign key to be named in an error, got %v", p, err)
+		}
+	}
+}
+
+func TestAlterField_NewColumn_MySQLRenamesAsideWithDefinition(t *testing.T) {
+	p := mysql.New()
+	state := migrate.NewSchemaState()
+	if err := (&migrate.CreateTable{
+		Name: "orders",
+		Fields: []migrate.Field{
+			{Name: "id", Type: "integer", PrimaryKey: true},
+			{Name: "code", Type: "text"},
+		},
+	}).Mutate(state); err != nil {
+		t.Fatal(err)
+	}
+	op := &migrate.AlterField{
+		Table:    "orders",
+		OldField: migrate.Field{Name: "code", Type: "text"},
+		NewField: migrate.Field{Name: "code", Type: "integer"},
+		Method:   migrate.AlterMethodNewColumn,
+	}
+	up, err := op.Up(p, state, nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	// A bare CHANGE would retype the old column to VARCHAR(255) and truncate it.
+	if !strings.Contains(up, "ALTER TABLE `orders` CHANGE `code` `code__old` TEXT NOT NULL;") {
+		t.Errorf("expected the old column renamed aside with its own definition:\n%s", up)
+	}
+}
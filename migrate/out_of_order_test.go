@@ -0,0 +1,129 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+// branchRegistry returns 0001_initial plus the migrations from two branches.
+// Names sort 0002_a_email before 0002_b_posts, so deploying branch b first
+// leaves 0002_a_email as an out-of-order gap once branch a is merged.
+func branchRegistry(withBranchA bool) *migrate.Registry {
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0001_initial",
+		Dependencies: []string{},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{Name: "users", Fields: []migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}}},
+		},
+	})
+	reg.Register(&migrate.Migration{
+		Name:         "0002_b_posts",
+		Dependencies: []string{"0001_initial"},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{Name: "posts", Fields: []migrate.Field{
+				{Name: "id", Type: "integer", PrimaryKey: true},
+				{Name: "title", Type: "text", Nullable: true},
+			}},
+		},
+	})
+	if !withBranchA {
+		return reg
+	}
+	reg.Register(&migrate.Migration{
+		Name:         "0002_a_email",
+		Dependencies: []string{"0001_initial"},
+		Operations: []migrate.Operation{
+			&migrate.AddField{Table: "users", Field: migrate.Field{Name: "email", Type: "text", Nullable: true}},
+		},
+	})
+	// The merge alters posts, which SQLite implements by recreating the table
+	// from state — so it only works if 0002_b_posts was replayed.
+	reg.Register(&migrate.Migration{
+		Name:         "0003_merge",
+		Dependencies: []string{"0002_a_email", "0002_b_posts"},
+		Operations: []migrate.Operation{
+			&migrate.AlterField{
+				Table:    "posts",
+				OldField: migrate.Field{Name: "title", Type: "text", Nullable: true},
+				NewField: migrate.Field{Name: "title", Type: "text"},
+			},
+		},
+	})
+	return reg
+}
+
+func TestMigrator_OutOfOrder(t *testing.T) {
+	db := openTestDB(t)
+	db.SetMaxOpenConns(1)
+	ctx := context.Background()
+
+	deployed, err := migrate.NewMigrator(db, "sqlite", branchRegistry(false))
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := deployed.Up(ctx, migrate.UpOptions{}); err != nil {
+		t.Fatalf("Up branch b: %v", err)
+	}
+
+	merged, err := migrate.NewMigrator(db, "sqlite", branchRegistry(true))
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	_, err = merged.Up(ctx, migrate.UpOptions{})
+	var gapErr *migrate.OutOfOrderError
+	if !errors.As(err, &gapErr) || !reflect.DeepEqual(gapErr.Names, []string{"0002_a_email"}) {
+		t.Fatalf("expected OutOfOrderError for 0002_a_email, got %v", err)
+	}
+
+	statuses, err := merged.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, st := range statuses {
+		if st.OutOfOrder != (st.Name == "0002_a_email") {
+			t.Fatalf("unexpected out-of-order flag on %+v", st)
+		}
+	}
+
+	results, err := merged.Up(ctx, migrate.UpOptions{RunOptions: migrate.RunOptions{AllowOutOfOrder: true}})
+	if err != nil {
+		t.Fatalf("Up with AllowOutOfOrder: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "0002_a_email" || !results[0].OutOfOrder || results[1].OutOfOrder {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if _, err := db.Exec("INSERT INTO users (id, email) VALUES (1, 'a@example.com')"); err != nil {
+		t.Fatalf("expected users.email to exist: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO posts (id, title) VALUES (1, NULL)"); err == nil {
+		t.Fatal("expected posts.title to be NOT NULL after the merge migration")
+	}
+}
@@ -0,0 +1,92 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package migrate
+
+import "fmt"
+
+// Deploy phases for expand/contract migrations. An expand migration makes
+// additive, backward-compatible changes that old and new application code
+// can both run against; its contract migration removes what the old code
+// needed once every instance runs the new code.
+const (
+	PhaseExpand   = "expand"
+	PhaseContract = "contract"
+)
+
+// validatePhase rejects a RunOptions.Phase other than "", PhaseExpand and
+// PhaseContract.
+func validatePhase(phase string) error {
+	switch phase {
+	case "", PhaseExpand, PhaseContract:
+		return nil
+	}
+	return fmt.Errorf("unknown phase %q (want %s or %s)", phase, PhaseExpand, PhaseContract)
+}
+
+// deferredContract returns the pending contract migrations in plan together
+// with every pending migration that depends on one, directly or through
+// other deferred migrations. plan must be in topological order.
+func deferredContract(plan []*Migration, applied map[string]bool) map[string]bool {
+	deferred := make(map[string]bool)
+	for _, mig := range plan {
+		if applied[mig.Name] {
+			continue
+		}
+		if mig.Phase == PhaseContract {
+			deferred[mig.Name] = true
+			continue
+		}
+		for _, dep := range mig.Dependencies {
+			if deferred[dep] {
+				deferred[mig.Name] = true
+				break
+			}
+		}
+	}
+	return deferred
+}
+
+// checkContractReady returns an error for the first pending contract
+// migration in plan that depends on an expand migration not yet applied.
+// Running both halves in one deploy would break instances still on the old
+// code, so `up --phase contract` insists the expand half shipped earlier.
+func checkContractReady(plan []*Migration, applied map[string]bool) error {
+	byName := make(map[string]*Migration, len(plan))
+	for _, mig := range plan {
+		byName[mig.Name] = mig
+	}
+	for _, mig := range plan {
+		if applied[mig.Name] || mig.Phase != PhaseContract {
+			continue
+		}
+		for _, dep := range mig.Dependencies {
+			if d := byName[dep]; d != nil && d.Phase == PhaseExpand && !applied[dep] {
+				return fmt.Errorf("contract migration %q requires expand migration %q to be applied first "+
+					"(run `migrate up --phase expand` and deploy the new code)", mig.Name, dep)
+			}
+		}
+	}
+	return nil
+}
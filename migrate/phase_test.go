@@ -0,0 +1,131 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package migrate_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+// phaseRegistry returns an expand/contract pair renaming users.name to
+// users.full_name, an unrelated migration that only needs the expand half,
+// and one that builds on the contract half.
+func phaseRegistry() *migrate.Registry {
+	return testRegistry(
+		&migrate.Migration{Name: "0001_initial", Operations: []migrate.Operation{
+			idTable("users", migrate.Field{Name: "name", Type: "text", Nullable: true}),
+		}},
+		&migrate.Migration{Name: "0002_full_name_expand", Phase: migrate.PhaseExpand, Operations: []migrate.Operation{
+			&migrate.AddField{Table: "users", Field: migrate.Field{Name: "full_name", Type: "text", Nullable: true}},
+			&migrate.RunSQL{ForwardSQL: "UPDATE users SET full_name = name"},
+		}},
+		&migrate.Migration{Name: "0003_full_name_contract", Phase: migrate.PhaseContract, Operations: []migrate.Operation{
+			&migrate.DropField{Table: "users", Field: "name"},
+		}},
+		&migrate.Migration{Name: "0004_posts", Dependencies: []string{"0002_full_name_expand"}, Operations: []migrate.Operation{idTable("posts")}},
+		&migrate.Migration{Name: "0005_tags", Dependencies: []string{"0003_full_name_contract"}, Operations: []migrate.Operation{idTable("tags")}},
+	)
+}
+
+func resultNames(results []migrate.MigrationResult) string {
+	names := make([]string, len(results))
+	for i, res := range results {
+		names[i] = res.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestMigrator_Phases(t *testing.T) {
+	defer suppressStdout(t)()
+	db := openTestDB(t)
+	db.SetMaxOpenConns(1)
+	ctx := context.Background()
+
+	mig, err := migrate.NewMigrator(db, "sqlite", phaseRegistry())
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+
+	_, err = mig.Up(ctx, migrate.UpOptions{RunOptions: migrate.RunOptions{Phase: migrate.PhaseContract}})
+	if err == nil || !strings.Contains(err.Error(), `"0002_full_name_expand" to be applied first`) {
+		t.Fatalf("expected contract phase to wait for the expand migration, got %v", err)
+	}
+
+	results, err := mig.Up(ctx, migrate.UpOptions{RunOptions: migrate.RunOptions{Phase: migrate.PhaseExpand}})
+	if err != nil {
+		t.Fatalf("Up expand: %v", err)
+	}
+	if got := resultNames(results); got != "0001_initial,0002_full_name_expand,0004_posts" {
+		t.Fatalf("expand phase applied %s", got)
+	}
+
+	statuses, err := mig.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, st := range statuses {
+		if st.OutOfOrder {
+			t.Fatalf("deferred contract migration reported out of order: %+v", st)
+		}
+	}
+
+	results, err = mig.Up(ctx, migrate.UpOptions{RunOptions: migrate.RunOptions{Phase: migrate.PhaseContract}})
+	if err != nil {
+		t.Fatalf("Up contract: %v", err)
+	}
+	if got := resultNames(results); got != "0003_full_name_contract,0005_tags" {
+		t.Fatalf("contract phase applied %s", got)
+	}
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('users') WHERE name = 'name'").Scan(&n); err != nil {
+		t.Fatalf("inspecting users: %v", err)
+	}
+	if n != 0 {
+		t.Fatal("expected users.name to be dropped by the contract migration")
+	}
+}
+
+func TestMigrator_PhaseNone(t *testing.T) {
+	defer suppressStdout(t)()
+	db := openTestDB(t)
+	mig, err := migrate.NewMigrator(db, "sqlite", phaseRegistry())
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	results, err := mig.Up(context.Background(), migrate.UpOptions{})
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected every migration without --phase, got %s", resultNames(results))
+	}
+	_, err = mig.Up(context.Background(), migrate.UpOptions{RunOptions: migrate.RunOptions{Phase: "deploy"}})
+	if err == nil || !strings.Contains(err.Error(), `unknown phase "deploy"`) {
+		t.Fatalf("expected unknown phase error, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package migrate
+
+import (
+	"fmt"
+
+	"github.com/ocomsoft/makemigrations/internal/providers"
+)
+
+// MigrationSQL is the SQL one migration runs on a provider, one statement
+// per element, as rendered by Graph.RenderSQL.
+type MigrationSQL struct {
+	Name string
+	// Up is the SQL of `migrate up`, in operation order.
+	Up []string
+	// Down is the SQL of `migrate down`, in reverse operation order.
+	Down []string
+}
+
+// RenderSQL renders the Up and Down SQL of every migration in g for
+// provider, in topological order, without a database. Each migration is
+// rendered against the state left by all the migrations before it, the
+// same way Runner.ShowSQL and Runner.Down render it on a fresh database.
+func (g *Graph) RenderSQL(provider providers.Provider) ([]MigrationSQL, error) {
+	plan, err := g.Linearize()
+	if err != nil {
+		return nil, err
+	}
+	state := NewSchemaState()
+	result := make([]MigrationSQL, 0, len(plan))
+	for _, mig := range plan {
+		down, err := renderDownSQL(provider, mig, state.Clone())
+		if err != nil {
+			return nil, err
+		}
+		up, err := renderUpSQL(provider, mig, state)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, MigrationSQL{Name: mig.Name, Up: up, Down: down})
+	}
+	return result, nil
+}
+
+// renderUpSQL returns the Up SQL of mig's operations, mutating state as
+// each one is applied. Operations without SQL are skipped.
+func renderUpSQL(provider providers.Provider, mig *Migration, state *SchemaState) ([]string, error) {
+	var stmts []string
+	for i, op := range mig.Operations {
+		provider.SetTypeMappings(state.TypeMappings)
+		sqlStr, err := op.Up(provider, state, state.Defaults)
+		if err != nil {
+			return nil, fmt.Errorf("%s operation %d/%d [%s]: %w", mig.Name, i+1, len(mig.Operations), op.Describe(), err)
+		}
+		if sqlStr != "" {
+			stmts = append(stmts, sqlStr)
+		}
+		if err := op.Mutate(state); err != nil {
+			return nil, fmt.Errorf("%s operation %d/%d [%s]: mutating state: %w", mig.Name, i+1, len(mig.Operations), op.Describe(), err)
+		}
+	}
+	return stmts, nil
+}
+
+// renderDownSQL returns the Down SQL of mig's operations in reverse order.
+// state is the state before mig and is modified.
+func renderDownSQL(provider providers.Provider, mig *Migration, state *SchemaState) ([]string, error) {
+	if err := preApplyStateOps(mig, state); err != nil {
+		return nil, err
+	}
+	var stmts []string
+	total := len(mig.Operations)
+	for i := total - 1; i >= 0; i-- {
+		op := mig.Operations[i]
+		provider.SetTypeMappings(state.TypeMappings)
+		sqlStr, err := op.Down(provider, state, state.Defaults)
+		if err != nil {
+			return nil, fmt.Errorf("%s operation %d/%d [%s]: generating down SQL: %w", mig.Name, total-i, total, op.Describe(), err)
+		}
+		if sqlStr != "" {
+			stmts = append(stmts, sqlStr)
+		}
+	}
+	return stmts, nil
+}
+
+// preApplyStateOps applies the state-only operations (SetDefaults,
+// SetTypeMappings) of mig to state so that Defaults and TypeMappings are
+// populated when generating Down SQL for its other operations.
+func preApplyStateOps(mig *Migration, state *SchemaState) error {
+	for _, op := range mig.Operations {
+		switch op.TypeName() {
+		case "set_defaults", "set_type_mappings":
+			if err := op.Mutate(state); err != nil {
+				return fmt.Errorf("pre-applying %s state for %q: %w", op.TypeName(), mig.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ocomsoft/makemigrations/internal/providers"
+	"github.com/ocomsoft/makemigrations/internal/types"
+)
+
+// progressTable records, for each partially applied migration, how many of
+// its operations have completed. Rows only exist between a failed run and
+// the run that finishes the migration.
+const progressTable = "makemigrations_progress"
+
+// progressTableDef describes progressTable; its DDL comes from the
+// provider's GenerateCreateTable so it suits every dialect.
+var progressTableDef = types.Table{
+	Name: progressTable,
+	Fields: []types.Field{
+		{Name: "name", Type: "varchar", Length: 255, PrimaryKey: true},
+		{Name: "ops_done", Type: "integer", Nullable: boolPtr(false)},
+	},
+}
+
+// autoCommitsDDL reports whether p commits DDL implicitly.
+func autoCommitsDDL(p providers.Provider) bool {
+	ac, ok := p.(providers.AutoCommitDDLProvider)
+	return ok && ac.AutoCommitsDDL()
+}
+
+// ensureProgressTable creates progressTable when it does not exist.
+func (r *MigrationRecorder) ensureProgressTable(ctx context.Context) error {
+	rows, err := r.db.QueryContext(ctx, "SELECT name FROM "+progressTable+" WHERE 1 = 0")
+	if err == nil {
+		_ = rows.Close()
+		return nil
+	}
+	table := progressTableDef
+	ddl, err := r.provider.GenerateCreateTable(&types.Schema{Tables: []types.Table{table}}, &table)
+	if err != nil {
+		return fmt.Errorf("generating %s DDL: %w", progressTable, err)
+	}
+	if _, err := r.db.ExecContext(ctx, ddl); err != nil {
+		return fmt.Errorf("creating %s table: %w", progressTable, err)
+	}
+	return nil
+}
+
+// GetProgress returns the number of completed operations for each
+// partially applied migration.
+func (r *MigrationRecorder) GetProgress(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT name, ops_done FROM "+progressTable)
+	if err != nil {
+		return nil, fmt.Errorf("querying migration progress: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	progress := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var done int
+		if err := rows.Scan(&name, &done); err != nil {
+			return nil, fmt.Errorf("scanning migration progress: %w", err)
+		}
+		progress[name] = done
+	}
+	return progress, rows.Err()
+}
+
+// SetProgressTx records that the first opsDone operations of name have
+// completed. Zero clears the record.
+func (r *MigrationRecorder) SetProgressTx(ctx context.Context, tx *sql.Tx, name string, opsDone int) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+progressTable+" WHERE name = "+r.provider.Placeholder(1), name); err != nil {
+		return fmt.Errorf("clearing progress for %q: %w", name, err)
+	}
+	if opsDone == 0 {
+		return nil
+	}
+	query := "INSERT INTO " + progressTable + " (name, ops_done) VALUES (" + r.provider.Placeholder(1) + ", " + r.provider.Placeholder(2) + ")"
+	if _, err := tx.ExecContext(ctx, query, name, opsDone); err != nil {
+		return fmt.Errorf("recording progress for %q: %w", name, err)
+	}
+	return nil
+}
+
+// SetProgress is SetProgressTx in a transaction of its own.
+func (r *MigrationRecorder) SetProgress(ctx context.Context, name string, opsDone int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := r.SetProgressTx(ctx, tx, name, opsDone); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// applyMigrationPerOp applies a migration on a provider whose DDL commits
+// implicitly. Each operation runs in its own transaction together with the
+// progress record for it, so after a failure the database and the progress
+// table agree on how far the migration got and the next Up resumes from
+// the failed operation. All transactions share one connection so session
+// settings made by hooks persist for the whole migration.
+func (r *Runner) applyMigrationPerOp(ctx context.Context, mig *Migration, state *SchemaState, opts RunOptions, res *MigrationResult) (err error) {
+	ev := HookEvent{Direction: DirectionUp, Migration: mig}
+	started := time.Now()
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring connection: %w", err)
+	}
+	defer func() {
+		r.releaseConn(conn, mig, opts)
+		if err != nil {
+			ev.Err = err
+			r.fireOnError(ctx, ev)
+		}
+	}()
+	inTx := func(fn func(tx *sql.Tx) error) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("beginning transaction: %w", err)
+		}
+		defer func() { _ = tx.Rollback() }()
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if err := inTx(func(tx *sql.Tx) error {
+		// Session-level timeouts set here hold for the whole connection.
+		if err := r.execTimeouts(ctx, tx, mig, opts, res); err != nil {
+			return err
+		}
+		return r.runHooks("before_migration", func(h Hook) error { return h.BeforeMigration(ctx, tx, ev) })
+	}); err != nil {
+		return err
+	}
+
+	for i, op := range mig.Operations {
+		if i < res.Resumed {
+			if err := op.Mutate(state); err != nil {
+				return fmt.Errorf("operation %d/%d [%s]: replaying state: %w", i+1, len(mig.Operations), op.Describe(), err)
+			}
+			continue
+		}
+		if err := inTx(func(tx *sql.Tx) error {
+			if err := r.applyOp(ctx, tx, &ev, i, state, opts, res); err != nil {
+				return err
+			}
+			return r.recorder.SetProgressTx(ctx, tx, mig.Name, i+1)
+		}); err != nil {
+			return err
+		}
+	}
+
+	ev.Operation, ev.SQL = nil, ""
+	return inTx(func(tx *sql.Tx) error {
+		if err := r.runHooks("after_migration", func(h Hook) error { return h.AfterMigration(ctx, tx, ev) }); err != nil {
+			return err
+		}
+		entry := HistoryEntry{Name: mig.Name, Duration: time.Since(started), Batch: res.Batch, RunMetadata: r.meta}
+		if err := r.recorder.RecordHistoryTx(ctx, tx, entry); err != nil {
+			return err
+		}
+		return r.recorder.SetProgressTx(ctx, tx, mig.Name, 0)
+	})
+}
+
+// Resolve records that the first opsDone operations of the named pending
+// migration have been completed, for manual recovery after a partial
+// failure: the next Up skips them and starts at operation opsDone+1. Zero
+// clears any recorded progress so the migration starts from the beginning.
+func (r *Runner) Resolve(ctx context.Context, name string, opsDone int) error {
+	plan, err := r.graph.Linearize()
+	if err != nil {
+		return fmt.Errorf("linearizing graph: %w", err)
+	}
+	var mig *Migration
+	for _, m := range plan {
+		if m.Name == name {
+			mig = m
+			break
+		}
+	}
+	if mig == nil {
+		return fmt.Errorf("no migration named %q", name)
+	}
+	applied, err := r.recorder.GetAppliedContext(ctx)
+	if err != nil {
+		return fmt.Errorf("getting applied migrations: %w", err)
+	}
+	if applied[name] {
+		return fmt.Errorf("migration %q is already applied", name)
+	}
+	if opsDone < 0 || opsDone > len(mig.Operations) {
+		return fmt.Errorf("migration %q has %d operations; --mark-op must be between 0 and %d", name, len(mig.Operations), len(mig.Operations))
+	}
+	if err := r.recorder.SetProgress(ctx, name, opsDone); err != nil {
+		return err
+	}
+	if opsDone == 0 {
+		r.printf("Cleared progress for %s; the next up starts at operation 1\n", name)
+	} else {
+		r.printf("Marked operations 1-%d of %s as done; the next up resumes at operation %d\n", opsDone, name, opsDone+1)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/internal/providers/mysql"
+	"github.com/ocomsoft/makemigrations/internal/providers/postgresql"
+	"github.com/ocomsoft/makemigrations/internal/providers/sqlite"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+// autoCommitSQLite is SQLite reporting implicit DDL commits, so the
+// per-operation path can be exercised against an in-memory database.
+type autoCommitSQLite struct{ *sqlite.Provider }
+
+func (autoCommitSQLite) AutoCommitsDDL() bool { return true }
+
+func progressRegistry() *migrate.Registry {
+	return testRegistry(&migrate.Migration{Name: "0001_initial", Operations: []migrate.Operation{
+		idTable("users"),
+		idTable("posts"),
+		&migrate.RunSQL{ForwardSQL: "INSERT INTO audit (id) VALUES (1)", BackwardSQL: "DELETE FROM audit"},
+	}})
+}
+
+func TestRunner_ResumesPartialMigration(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	db.SetMaxOpenConns(1)
+	p := autoCommitSQLite{sqlite.New()}
+	recorder := migrate.NewMigrationRecorder(db, p)
+	if err := recorder.EnsureTable(); err != nil {
+		t.Fatalf("EnsureTable: %v", err)
+	}
+	g, err := migrate.BuildGraph(progressRegistry())
+	if err != nil {
+		t.Fatalf("BuildGraph: %v", err)
+	}
+	runner := migrate.NewRunner(g, p, db, recorder, io.Discard)
+
+	if err := runner.UpContext(ctx, "", migrate.RunOptions{}); err == nil || !strings.Contains(err.Error(), "operation 3/3") {
+		t.Fatalf("expected operation 3 to fail, got %v", err)
+	}
+	progress, err := recorder.GetProgress(ctx)
+	if err != nil {
+		t.Fatalf("GetProgress: %v", err)
+	}
+	if progress["0001_initial"] != 2 {
+		t.Fatalf("expected 2 completed operations, got %v", progress)
+	}
+	if _, err := db.Exec("SELECT id FROM posts"); err != nil {
+		t.Fatalf("expected posts to survive the failure: %v", err)
+	}
+
+	if _, err := db.Exec("CREATE TABLE audit (id integer)"); err != nil {
+		t.Fatalf("creating audit: %v", err)
+	}
+	if err := runner.UpContext(ctx, "", migrate.RunOptions{}); err != nil {
+		t.Fatalf("resumed Up: %v", err)
+	}
+	applied, err := recorder.GetApplied()
+	if err != nil {
+		t.Fatalf("GetApplied: %v", err)
+	}
+	if !applied["0001_initial"] {
+		t.Fatal("expected 0001_initial to be recorded after resuming")
+	}
+	if progress, _ := recorder.GetProgress(ctx); len(progress) != 0 {
+		t.Fatalf("expected progress to be cleared, got %v", progress)
+	}
+}
+
+func TestRunner_Resolve(t *testing.T) {
+	restore := suppressStdout(t)
+	defer restore()
+	ctx := context.Background()
+
+	runner, _, db := buildTestRunner(t, progressRegistry())
+	db.SetMaxOpenConns(1)
+	// Simulate the first two operations having been applied by hand.
+	for _, stmt := range []string{
+		"CREATE TABLE users (id integer PRIMARY KEY)",
+		"CREATE TABLE posts (id integer PRIMARY KEY)",
+		"CREATE TABLE audit (id integer)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if err := runner.Resolve(ctx, "0001_initial", 4); err == nil {
+		t.Fatal("expected --mark-op beyond the operation count to fail")
+	}
+	if err := runner.Resolve(ctx, "0009_missing", 1); err == nil {
+		t.Fatal("expected unknown migration to fail")
+	}
+	if err := runner.Resolve(ctx, "0001_initial", 2); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	m, err := migrate.NewMigrator(db, "sqlite", progressRegistry())
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	m.SetOutput(io.Discard)
+	results, err := m.Up(ctx, migrate.UpOptions{})
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(results) != 1 || results[0].Resumed != 2 {
+		t.Fatalf("expected 0001_initial to resume after 2 operations, got %+v", results)
+	}
+	if err := runner.Resolve(ctx, "0001_initial", 0); err == nil || !strings.Contains(err.Error(), "already applied") {
+		t.Fatalf("expected applied migration to be rejected, got %v", err)
+	}
+}
+
+func TestAutoCommitsDDL(t *testing.T) {
+	if !mysql.New().AutoCommitsDDL() {
+		t.Error("expected MySQL to report implicit DDL commits")
+	}
+	if _, ok := any(postgresql.New()).(interface{ AutoCommitsDDL() bool }); ok {
+		t.Error("PostgreSQL has transactional DDL and should not implement AutoCommitsDDL")
+	}
+}
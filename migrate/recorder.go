@@ -25,14 +25,22 @@ SOFTWARE.
 package migrate
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/ocomsoft/makemigrations/internal/providers"
 )
 
 // MigrationRecorder manages the makemigrations_history table.
 // It records which migrations have been applied to the database.
+//
+// Methods that predate context support each have a ...Context variant that
+// accepts a context.Context so callers can cancel or bound the query; the
+// plain methods use context.Background(). RecordHistoryTx, History,
+// NextBatch and GetProgress were added later and only take a context.
 type MigrationRecorder struct {
 	db       *sql.DB
 	provider providers.Provider
@@ -47,16 +55,64 @@ func NewMigrationRecorder(db *sql.DB, p providers.Provider) *MigrationRecorder {
 // EnsureTable creates the makemigrations_history table if it does not exist.
 // The DDL is supplied by the provider so it is correct for the target database.
 func (r *MigrationRecorder) EnsureTable() error {
-	_, err := r.db.Exec(r.provider.HistoryTableDDL())
+	return r.EnsureTableContext(context.Background())
+}
+
+// EnsureTableContext is EnsureTable with a context. It also upgrades a
+// history table created by an older version by adding any missing
+// historyColumns, and creates the makemigrations_progress side table.
+func (r *MigrationRecorder) EnsureTableContext(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, r.provider.HistoryTableDDL())
 	if err != nil {
 		return fmt.Errorf("creating makemigrations_history table: %w", err)
 	}
+	if err := r.upgradeTable(ctx); err != nil {
+		return err
+	}
+	return r.ensureProgressTable(ctx)
+}
+
+// upgradeTable adds the historyColumns missing from makemigrations_history.
+// A column is considered missing when selecting it fails; the common case
+// of an up-to-date table costs a single probe query.
+func (r *MigrationRecorder) upgradeTable(ctx context.Context) error {
+	names := make([]string, len(historyColumns))
+	for i, col := range historyColumns {
+		names[i] = col.Name
+	}
+	if r.hasColumns(ctx, names...) {
+		return nil
+	}
+	for i := range historyColumns {
+		col := historyColumns[i]
+		if r.hasColumns(ctx, col.Name) {
+			continue
+		}
+		if _, err := r.db.ExecContext(ctx, r.provider.GenerateAddColumn(historyTable, &col)); err != nil {
+			return fmt.Errorf("adding %s column to %s: %w", col.Name, historyTable, err)
+		}
+	}
 	return nil
 }
 
+// hasColumns reports whether makemigrations_history has all the named columns.
+func (r *MigrationRecorder) hasColumns(ctx context.Context, names ...string) bool {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+strings.Join(names, ", ")+" FROM "+historyTable+" WHERE 1 = 0")
+	if err != nil {
+		return false
+	}
+	_ = rows.Close()
+	return true
+}
+
 // GetApplied returns a set of migration names that have been applied.
 func (r *MigrationRecorder) GetApplied() (map[string]bool, error) {
-	rows, err := r.db.Query("SELECT name FROM makemigrations_history")
+	return r.GetAppliedContext(context.Background())
+}
+
+// GetAppliedContext is GetApplied with a context.
+func (r *MigrationRecorder) GetAppliedContext(ctx context.Context) (map[string]bool, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT name FROM makemigrations_history")
 	if err != nil {
 		return nil, fmt.Errorf("querying applied migrations: %w", err)
 	}
@@ -75,8 +131,13 @@ func (r *MigrationRecorder) GetApplied() (map[string]bool, error) {
 
 // RecordApplied inserts a migration name into the history table.
 func (r *MigrationRecorder) RecordApplied(name string) error {
+	return r.RecordAppliedContext(context.Background(), name)
+}
+
+// RecordAppliedContext is RecordApplied with a context.
+func (r *MigrationRecorder) RecordAppliedContext(ctx context.Context, name string) error {
 	query := "INSERT INTO makemigrations_history (name) VALUES (" + r.provider.Placeholder(1) + ")"
-	_, err := r.db.Exec(query, name)
+	_, err := r.db.ExecContext(ctx, query, name)
 	if err != nil {
 		return fmt.Errorf("recording migration %q as applied: %w", name, err)
 	}
@@ -87,18 +148,85 @@ func (r *MigrationRecorder) RecordApplied(name string) error {
 // existing transaction, so the history record is committed or rolled back
 // atomically with the migration SQL.
 func (r *MigrationRecorder) RecordAppliedTx(tx *sql.Tx, name string) error {
+	return r.RecordAppliedTxContext(context.Background(), tx, name)
+}
+
+// RecordAppliedTxContext is RecordAppliedTx with a context.
+func (r *MigrationRecorder) RecordAppliedTxContext(ctx context.Context, tx *sql.Tx, name string) error {
 	query := "INSERT INTO makemigrations_history (name) VALUES (" + r.provider.Placeholder(1) + ")"
-	_, err := tx.Exec(query, name)
+	_, err := tx.ExecContext(ctx, query, name)
 	if err != nil {
 		return fmt.Errorf("recording migration %q as applied: %w", name, err)
 	}
 	return nil
 }
 
+// RecordHistoryTx inserts entry into the history table within an existing
+// transaction, including the duration, batch and run metadata columns.
+func (r *MigrationRecorder) RecordHistoryTx(ctx context.Context, tx *sql.Tx, entry HistoryEntry) error {
+	placeholders := make([]string, 7)
+	for i := range placeholders {
+		placeholders[i] = r.provider.Placeholder(i + 1)
+	}
+	query := "INSERT INTO makemigrations_history (name, duration_ms, batch, applied_by, ci_job, tool_version, git_commit) VALUES (" +
+		strings.Join(placeholders, ", ") + ")"
+	_, err := tx.ExecContext(ctx, query, entry.Name, entry.Duration.Milliseconds(), entry.Batch,
+		entry.AppliedBy, entry.CIJob, entry.ToolVersion, entry.GitCommit)
+	if err != nil {
+		return fmt.Errorf("recording migration %q as applied: %w", entry.Name, err)
+	}
+	return nil
+}
+
+// History returns every row of the history table ordered by batch and then
+// application time. Rows written before the metadata columns existed have
+// zero values for them.
+func (r *MigrationRecorder) History(ctx context.Context) ([]HistoryEntry, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT name, applied_at, duration_ms, batch, applied_by, ci_job, tool_version, git_commit "+
+		"FROM makemigrations_history ORDER BY batch, applied_at, name")
+	if err != nil {
+		return nil, fmt.Errorf("querying migration history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		var (
+			e                                            HistoryEntry
+			appliedAt, by, ciJob, toolVersion, gitCommit sql.NullString
+			durationMS, batch                            sql.NullInt64
+		)
+		if err := rows.Scan(&e.Name, &appliedAt, &durationMS, &batch, &by, &ciJob, &toolVersion, &gitCommit); err != nil {
+			return nil, fmt.Errorf("scanning migration history: %w", err)
+		}
+		e.AppliedAt = appliedAt.String
+		e.Duration = time.Duration(durationMS.Int64) * time.Millisecond
+		e.Batch = int(batch.Int64)
+		e.AppliedBy, e.CIJob, e.ToolVersion, e.GitCommit = by.String, ciJob.String, toolVersion.String, gitCommit.String
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// NextBatch returns the batch number for a new `migrate up` invocation: one
+// more than the highest batch recorded so far.
+func (r *MigrationRecorder) NextBatch(ctx context.Context) (int, error) {
+	var last sql.NullInt64
+	if err := r.db.QueryRowContext(ctx, "SELECT MAX(batch) FROM makemigrations_history").Scan(&last); err != nil {
+		return 0, fmt.Errorf("querying last migration batch: %w", err)
+	}
+	return int(last.Int64) + 1, nil
+}
+
 // RecordRolledBack removes a migration name from the history table.
 func (r *MigrationRecorder) RecordRolledBack(name string) error {
+	return r.RecordRolledBackContext(context.Background(), name)
+}
+
+// RecordRolledBackContext is RecordRolledBack with a context.
+func (r *MigrationRecorder) RecordRolledBackContext(ctx context.Context, name string) error {
 	query := "DELETE FROM makemigrations_history WHERE name = " + r.provider.Placeholder(1)
-	_, err := r.db.Exec(query, name)
+	_, err := r.db.ExecContext(ctx, query, name)
 	if err != nil {
 		return fmt.Errorf("recording migration %q as rolled back: %w", name, err)
 	}
@@ -109,8 +237,13 @@ func (r *MigrationRecorder) RecordRolledBack(name string) error {
 // existing transaction, so the history deletion is committed or rolled back
 // atomically with the rollback SQL.
 func (r *MigrationRecorder) RecordRolledBackTx(tx *sql.Tx, name string) error {
+	return r.RecordRolledBackTxContext(context.Background(), tx, name)
+}
+
+// RecordRolledBackTxContext is RecordRolledBackTx with a context.
+func (r *MigrationRecorder) RecordRolledBackTxContext(ctx context.Context, tx *sql.Tx, name string) error {
 	query := "DELETE FROM makemigrations_history WHERE name = " + r.provider.Placeholder(1)
-	_, err := tx.Exec(query, name)
+	_, err := tx.ExecContext(ctx, query, name)
 	if err != nil {
 		return fmt.Errorf("recording migration %q as rolled back: %w", name, err)
 	}
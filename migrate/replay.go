@@ -0,0 +1,50 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate
+
+import "fmt"
+
+// replayState replays, in plan order, the operations of every migration for
+// which include returns true and returns the resulting state. When before is
+// non-nil, the state immediately preceding each migration named in it is
+// stored there as a Clone. Because clones share tables copy-on-write, one
+// pass yields the state before each of n rolled-back migrations at the cost
+// of a single replay, instead of n replays of the whole chain.
+func replayState(plan []*Migration, include func(*Migration) bool, before map[string]*SchemaState) (*SchemaState, error) {
+	state := NewSchemaState()
+	for _, mig := range plan {
+		if _, want := before[mig.Name]; want {
+			before[mig.Name] = state.Clone()
+		}
+		if !include(mig) {
+			continue
+		}
+		for _, op := range mig.Operations {
+			if err := op.Mutate(state); err != nil {
+				return nil, fmt.Errorf("replaying state for %q: %w", mig.Name, err)
+			}
+		}
+	}
+	return state, nil
+}
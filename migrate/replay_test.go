@@ -0,0 +1,113 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate_test
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/internal/providers/sqlite"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+// chainRegistry returns n migrations: ten tables, then one added column
+// per migration spread across them. With schemaOnly set the operations only
+// change the schema state, leaving the replay as the main cost.
+func chainRegistry(n int, schemaOnly bool) *migrate.Registry {
+	reg := migrate.NewRegistry()
+	prev := []string{}
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("%04d_step", i+1)
+		table := fmt.Sprintf("t%d", i%10)
+		var op migrate.Operation
+		if i < 10 {
+			op = &migrate.CreateTable{Name: table, Fields: []migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}}, SchemaOnly: schemaOnly}
+		} else {
+			op = &migrate.AddField{Table: table, Field: migrate.Field{Name: fmt.Sprintf("c%d", i), Type: "text", Nullable: true}, SchemaOnly: schemaOnly}
+		}
+		reg.Register(&migrate.Migration{Name: name, Dependencies: prev, Operations: []migrate.Operation{op}})
+		prev = []string{name}
+	}
+	return reg
+}
+
+func TestRunner_DownManySteps(t *testing.T) {
+	reg := chainRegistry(40, false)
+	runner, recorder, _ := buildTestRunner(t, reg)
+	if err := runner.Up("", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if err := runner.Down(35, "", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	applied, err := recorder.GetApplied()
+	if err != nil {
+		t.Fatalf("GetApplied: %v", err)
+	}
+	if len(applied) != 5 || !applied["0005_step"] {
+		t.Fatalf("expected 0001-0005 to remain applied, got %v", applied)
+	}
+	if err := runner.Up("", migrate.RunOptions{}); err != nil {
+		t.Fatalf("re-applying: %v", err)
+	}
+}
+
+// BenchmarkRunner_Down rolls back 20 migrations of ever longer chains of
+// schema-only migrations. The state before each rolled-back migration comes
+// from a single replay, so the cost grows linearly with the chain rather
+// than with 20 × chain.
+func BenchmarkRunner_Down(b *testing.B) {
+	for _, n := range []int{250, 500, 1000} {
+		b.Run(fmt.Sprintf("migrations=%d", n), func(b *testing.B) {
+			db, err := sql.Open("sqlite3", ":memory:")
+			if err != nil {
+				b.Fatalf("opening SQLite: %v", err)
+			}
+			defer func() { _ = db.Close() }()
+			db.SetMaxOpenConns(1)
+			recorder := migrate.NewMigrationRecorder(db, sqlite.New())
+			if err := recorder.EnsureTable(); err != nil {
+				b.Fatalf("EnsureTable: %v", err)
+			}
+			g, err := migrate.BuildGraph(chainRegistry(n, true))
+			if err != nil {
+				b.Fatalf("BuildGraph: %v", err)
+			}
+			runner := migrate.NewRunner(g, sqlite.New(), db, recorder, io.Discard)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				if err := runner.Up("", migrate.RunOptions{}); err != nil {
+					b.Fatalf("Up: %v", err)
+				}
+				b.StartTimer()
+				if err := runner.Down(20, "", migrate.RunOptions{}); err != nil {
+					b.Fatalf("Down: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/internal/providers/postgresql"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+func TestCreateFunction_PostgreSQL(t *testing.T) {
+	p := postgresql.New()
+	op := &migrate.CreateFunction{Function: migrate.Function{
+		Name:      "add_tax",
+		Arguments: "amount numeric",
+		Returns:   "numeric",
+		Body:      "BEGIN RETURN amount * 1.2; END;",
+	}}
+
+	up, err := op.Up(p, nil, nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	want := "CREATE FUNCTION \"add_tax\"(amount numeric) RETURNS numeric LANGUAGE plpgsql AS $$\nBEGIN RETURN amount * 1.2; END;\n$$;"
+	if up != want {
+		t.Fatalf("Up SQL mismatch\n got: %s\nwant: %s", up, want)
+	}
+	down, err := op.Down(p, nil, nil)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if down != `DROP FUNCTION IF EXISTS "add_tax"(amount numeric);` {
+		t.Fatalf("unexpected Down SQL: %s", down)
+	}
+}
+
+func TestReplaceFunction_DownRestoresPreviousBody(t *testing.T) {
+	p := postgresql.New()
+	state := migrate.NewSchemaState()
+	prev := migrate.Function{Name: "add_tax", Arguments: "amount numeric", Returns: "numeric", Body: "BEGIN RETURN amount * 1.2; END;"}
+	if err := state.AddFunction(prev); err != nil {
+		t.Fatalf("AddFunction: %v", err)
+	}
+
+	next := prev
+	next.Body = "BEGIN RETURN amount * 1.25; END;"
+	op := &migrate.ReplaceFunction{Function: next}
+
+	up, err := op.Up(p, state, nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if !strings.HasPrefix(up, "CREATE OR REPLACE FUNCTION") || !strings.Contains(up, "1.25") {
+		t.Fatalf("unexpected Up SQL: %s", up)
+	}
+	down, err := op.Down(p, state, nil)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if !strings.HasPrefix(down, "CREATE OR REPLACE FUNCTION") || !strings.Contains(down, "amount * 1.2;") {
+		t.Fatalf("expected Down to restore the previous body, got: %s", down)
+	}
+
+	// A signature change cannot use CREATE OR REPLACE: the old overload is dropped first.
+	next.Arguments = "amount numeric, rate numeric"
+	op = &migrate.ReplaceFunction{Function: next}
+	up, err = op.Up(p, state, nil)
+	if err != nil {
+		t.Fatalf("Up (signature change): %v", err)
+	}
+	wantPrefix := "DROP FUNCTION IF EXISTS \"add_tax\"(amount numeric);\nCREATE FUNCTION \"add_tax\"(amount numeric, rate numeric)"
+	if !strings.HasPrefix(up, wantPrefix) {
+		t.Fatalf("unexpected Up SQL for signature change: %s", up)
+	}
+
+	if err := op.Mutate(state); err != nil {
+		t.Fatalf("Mutate: %v", err)
+	}
+	if state.Functions["add_tax"].Arguments != next.Arguments {
+		t.Fatalf("expected state to hold the new signature, got %q", state.Functions["add_tax"].Arguments)
+	}
+	if _, err := op.Down(p, migrate.NewSchemaState(), nil); err == nil {
+		t.Fatal("expected error generating Down without the previous definition in state")
+	}
+}
+
+func TestDropFunction_DownRecreatesFromState(t *testing.T) {
+	p := postgresql.New()
+	state := migrate.NewSchemaState()
+	if err := state.AddFunction(migrate.Function{Name: "archive", Procedure: true, Body: "BEGIN DELETE FROM logs; END;"}); err != nil {
+		t.Fatalf("AddFunction: %v", err)
+	}
+	op := &migrate.DropFunction{Name: "archive"}
+	up, err := op.Up(p, state, nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if up != `DROP PROCEDURE IF EXISTS "archive"();` {
+		t.Fatalf("unexpected Up SQL: %s", up)
+	}
+	down, err := op.Down(p, state, nil)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if !strings.HasPrefix(down, `CREATE PROCEDURE "archive"()`) || strings.Contains(down, "RETURNS") {
+		t.Fatalf("unexpected Down SQL: %s", down)
+	}
+}
+
+func TestCreateTrigger_PostgreSQL(t *testing.T) {
+	p := postgresql.New()
+	op := &migrate.CreateTrigger{Trigger: migrate.Trigger{
+		Name:     "orders_touch",
+		Table:    "orders",
+		Timing:   "BEFORE",
+		Events:   []string{"INSERT", "UPDATE"},
+		When:     "NEW.total > 0",
+		Function: "touch_updated_at",
+	}}
+	up, err := op.Up(p, nil, nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	want := `CREATE TRIGGER "orders_touch" BEFORE INSERT OR UPDATE ON "orders" FOR EACH ROW WHEN (NEW.total > 0) EXECUTE FUNCTION "touch_updated_at"();`
+	if up != want {
+		t.Fatalf("Up SQL mismatch\n got: %s\nwant: %s", up, want)
+	}
+	down, err := op.Down(p, nil, nil)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if down != `DROP TRIGGER IF EXISTS "orders_touch" ON "orders";` {
+		t.Fatalf("unexpected Down SQL: %s", down)
+	}
+}
+
+func TestSchemaState_DropTableRemovesTriggers(t *testing.T) {
+	state := migrate.NewSchemaState()
+	if err := state.AddTable("orders", nil, nil); err != nil {
+		t.Fatalf("AddTable: %v", err)
+	}
+	if err := state.AddTrigger(migrate.Trigger{Name: "orders_touch", Table: "orders"}); err != nil {
+		t.Fatalf("AddTrigger: %v", err)
+	}
+	if err := state.RenameTable("orders", "purchases"); err != nil {
+		t.Fatalf("RenameTable: %v", err)
+	}
+	if got := state.Triggers["orders_touch"].Table; got != "purchases" {
+		t.Fatalf("expected trigger to follow renamed table, got %q", got)
+	}
+	if err := state.DropTable("purchases"); err != nil {
+		t.Fatalf("DropTable: %v", err)
+	}
+	if _, ok := state.Triggers["orders_touch"]; ok {
+		t.Fatal("expected trigger to be removed with its table")
+	}
+	if err := state.AddTrigger(migrate.Trigger{Name: "orphan", Table: "missing"}); err == nil {
+		t.Fatal("expected error adding a trigger to a missing table")
+	}
+}
+
+func TestRunner_Trigger_SQLiteRecreateAndRollback(t *testing.T) {
+	restore := suppressStdout(t)
+	defer restore()
+
+	trigger := migrate.Trigger{
+		Name:   "orders_stamp",
+		Table:  "orders",
+		Timing: "AFTER",
+		Events: []string{"INSERT"},
+		Body:   "BEGIN UPDATE orders SET note = 'v1' WHERE id = NEW.id; END",
+	}
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0001_initial",
+		Dependencies: []string{},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{
+				Name: "orders",
+				Fields: []migrate.Field{
+					{Name: "id", Type: "integer", PrimaryKey: true},
+					{Name: "note", Type: "text", Nullable: true},
+				},
+			},
+			&migrate.CreateTrigger{Trigger: trigger},
+		},
+	})
+	v2 := trigger
+	v2.Body = "BEGIN UPDATE orders SET note = 'v2' WHERE id = NEW.id; END"
+	reg.Register(&migrate.Migration{
+		Name:         "0002_trigger_v2",
+		Dependencies: []string{"0001_initial"},
+		Operations: []migrate.Operation{
+			&migrate.DropTrigger{Name: "orders_stamp"},
+			&migrate.CreateTrigger{Trigger: v2},
+		},
+	})
+
+	runner, _, db := buildTestRunner(t, reg)
+	if err := runner.Up("", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	noteFor := func(id int) string {
+		t.Helper()
+		if _, err := db.Exec("INSERT INTO orders (id) VALUES (?)", id); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+		var note string
+		if err := db.QueryRow("SELECT note FROM orders WHERE id = ?", id).Scan(&note); err != nil {
+			t.Fatalf("query: %v", err)
+		}
+		return note
+	}
+	if got := noteFor(1); got != "v2" {
+		t.Fatalf("expected v2 trigger after Up, got %q", got)
+	}
+
+	if err := runner.Down(1, "", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if got := noteFor(2); got != "v1" {
+		t.Fatalf("expected v1 trigger restored after Down, got %q", got)
+	}
+}
@@ -25,11 +25,14 @@ SOFTWARE.
 package migrate
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ocomsoft/makemigrations/internal/providers"
 )
@@ -39,6 +42,49 @@ type RunOptions struct {
 	// WarnOnMissingDrop causes drop operations that fail because the target
 	// object does not exist to print a warning and continue rather than stop.
 	WarnOnMissingDrop bool
+	// IgnoreUnknown lets Up proceed when the history table records
+	// migrations that are not in the registry. See UnknownMigrationsError.
+	IgnoreUnknown bool
+	// AllowOutOfOrder lets Up apply pending migrations that precede
+	// already-applied ones in the plan. See OutOfOrderError.
+	AllowOutOfOrder bool
+	// Timeouts bound statement run time and lock waits, and configure
+	// retries after a lock timeout.
+	Timeouts
+	// Phase limits Up to one deploy stage of expand/contract migrations.
+	// PhaseExpand skips contract migrations and anything depending on them;
+	// PhaseContract refuses to start unless the expand migrations that
+	// pending contract migrations depend on are already applied. Empty
+	// applies everything.
+	Phase string
+}
+
+// OutOfOrderError is returned by Up when pending migrations sort before
+// migrations that are already applied, typically after merging a branch
+// whose migrations were written before ones already deployed.
+type OutOfOrderError struct {
+	Names []string
+}
+
+// Error implements error.
+func (e *OutOfOrderError) Error() string {
+	return fmt.Sprintf("%d pending migration(s) precede already-applied migrations: %s "+
+		"(use --allow-out-of-order to apply them)", len(e.Names), strings.Join(e.Names, ", "))
+}
+
+// UnknownMigrationsError is returned by Up when the history table records
+// applied migrations that are neither registered nor replaced by a
+// registered squash (deleted files, a branch switch, or someone else's
+// unmerged migration).
+type UnknownMigrationsError struct {
+	Names []string
+}
+
+// Error implements error.
+func (e *UnknownMigrationsError) Error() string {
+	return fmt.Sprintf("database has %d applied migration(s) that are not registered: %s "+
+		"(use --ignore-unknown to apply anyway, or prune-history to remove them)",
+		len(e.Names), strings.Join(e.Names, ", "))
 }
 
 // Runner executes migrations against a database in topological order.
@@ -48,6 +94,8 @@ type Runner struct {
 	db       *sql.DB
 	recorder *MigrationRecorder
 	output   io.Writer
+	hooks    []Hook
+	meta     RunMetadata
 }
 
 // NewRunner creates a Runner using the given graph, provider, db, recorder,
@@ -62,9 +110,25 @@ func NewRunner(graph *Graph, provider providers.Provider, db *sql.DB, recorder *
 		db:       db,
 		recorder: recorder,
 		output:   output,
+		meta:     DefaultRunMetadata(),
 	}
 }
 
+// SetMetadata replaces the operator, CI job, tool version and commit
+// recorded with each applied migration. NewRunner uses DefaultRunMetadata.
+func (r *Runner) SetMetadata(meta RunMetadata) {
+	r.meta = meta
+}
+
+// printDone ends a migration's progress line, noting any retries.
+func (r *Runner) printDone(res MigrationResult) {
+	if res.Attempts > 1 {
+		r.printf(" done (attempt %d)\n", res.Attempts)
+		return
+	}
+	r.printf(" done\n")
+}
+
 // printf writes formatted output to the runner's output writer.
 // Errors from writing are intentionally discarded since these are
 // informational messages (progress, warnings) and write failures
@@ -73,122 +137,435 @@ func (r *Runner) printf(format string, a ...interface{}) {
 	_, _ = fmt.Fprintf(r.output, format, a...)
 }
 
+// MigrationResult describes one migration applied or rolled back by the runner.
+type MigrationResult struct {
+	Name     string
+	Duration time.Duration
+	// Batch is the history batch the migration was recorded in (Up only).
+	Batch int
+	// OutOfOrder is set when the migration preceded already-applied
+	// migrations in the plan (Up only).
+	OutOfOrder bool
+	// Resumed is the number of leading operations skipped because an
+	// earlier, partially failed run had completed them (Up only).
+	Resumed int
+	// Attempts is the number of times the migration was tried; more than one
+	// means earlier attempts failed with a lock timeout and were retried.
+	Attempts int
+	// Warnings lists the operations that failed with an ignorable error and
+	// were skipped (see RunOptions.WarnOnMissingDrop and IgnoreErrors).
+	Warnings []string
+}
+
+// MigrationStatus reports whether a migration in the graph has been applied.
+type MigrationStatus struct {
+	Name         string
+	Dependencies []string
+	Applied      bool
+	// Unknown marks a history row with no registered migration. Unknown
+	// entries are listed after the graph and always have Applied set.
+	Unknown bool
+	// OutOfOrder marks a pending migration that precedes an applied one.
+	OutOfOrder bool
+}
+
 // Up applies all pending migrations in topological order.
 // If to is non-empty, stops after applying the named migration.
 func (r *Runner) Up(to string, opts RunOptions) error {
+	return r.UpContext(context.Background(), to, opts)
+}
+
+// UpContext is Up with a context. Cancelling ctx aborts the migration in
+// progress (its transaction is rolled back) and stops before the next one.
+func (r *Runner) UpContext(ctx context.Context, to string, opts RunOptions) error {
+	_, err := r.up(ctx, to, opts)
+	return err
+}
+
+// up applies pending migrations and returns one result per migration that
+// was committed, including when a later migration fails.
+func (r *Runner) up(ctx context.Context, to string, opts RunOptions) ([]MigrationResult, error) {
+	if err := validatePhase(opts.Phase); err != nil {
+		return nil, err
+	}
 	plan, err := r.graph.Linearize()
 	if err != nil {
-		return fmt.Errorf("linearizing graph: %w", err)
+		return nil, fmt.Errorf("linearizing graph: %w", err)
 	}
-	applied, err := r.recorder.GetApplied()
+	applied, err := r.recorder.GetAppliedContext(ctx)
 	if err != nil {
-		return fmt.Errorf("getting applied migrations: %w", err)
+		return nil, fmt.Errorf("getting applied migrations: %w", err)
 	}
-	state := NewSchemaState()
-
-	// Replay already-applied migrations to rebuild state
-	for _, mig := range plan {
-		if !applied[mig.Name] {
-			continue
-		}
-		for _, op := range mig.Operations {
-			if err := op.Mutate(state); err != nil {
-				return fmt.Errorf("replaying state for %q: %w", mig.Name, err)
-			}
+	if unknown := unknownApplied(plan, applied); len(unknown) > 0 && !opts.IgnoreUnknown {
+		return nil, &UnknownMigrationsError{Names: unknown}
+	}
+	gaps := outOfOrderPending(plan, applied)
+	if len(gaps) > 0 && !opts.AllowOutOfOrder {
+		return nil, &OutOfOrderError{Names: gaps}
+	}
+	isGap := make(map[string]bool, len(gaps))
+	for _, name := range gaps {
+		isGap[name] = true
+	}
+	var skip map[string]bool
+	switch opts.Phase {
+	case PhaseExpand:
+		skip = deferredContract(plan, applied)
+	case PhaseContract:
+		if err := checkContractReady(plan, applied); err != nil {
+			return nil, err
 		}
 	}
+	// Replay already-applied migrations to rebuild state. This includes
+	// migrations that sort after any out-of-order gaps, so each gap is
+	// applied against the schema the database actually has.
+	state, err := replayState(plan, func(m *Migration) bool { return applied[m.Name] }, nil)
+	if err != nil {
+		return nil, err
+	}
 
+	progress, err := r.recorder.GetProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []MigrationResult
+	batch := 0
 	for _, mig := range plan {
 		if applied[mig.Name] {
 			continue
 		}
-		r.printf("Applying %s...", mig.Name)
-		if err := r.applyMigration(mig, state, opts); err != nil {
+		if skip[mig.Name] {
+			r.printf("Skipping %s (contract phase)\n", mig.Name)
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		if batch == 0 {
+			if batch, err = r.recorder.NextBatch(ctx); err != nil {
+				return results, err
+			}
+		}
+		res := MigrationResult{Name: mig.Name, Batch: batch, OutOfOrder: isGap[mig.Name], Resumed: progress[mig.Name]}
+		switch {
+		case res.OutOfOrder:
+			r.printf("Applying %s (out of order)...", mig.Name)
+		case res.Resumed > 0:
+			r.printf("Applying %s (resuming at operation %d)...", mig.Name, res.Resumed+1)
+		default:
+			r.printf("Applying %s...", mig.Name)
+		}
+		started := time.Now()
+		if err := r.applyMigration(ctx, mig, state, opts, &res); err != nil {
 			r.printf(" FAILED\n")
-			return fmt.Errorf("applying migration %q: %w", mig.Name, err)
+			return results, fmt.Errorf("applying migration %q: %w", mig.Name, err)
 		}
-		r.printf(" done\n")
+		res.Duration = time.Since(started)
+		results = append(results, res)
+		r.printDone(res)
 		if to != "" && mig.Name == to {
 			break
 		}
 	}
-	return nil
+	return results, nil
 }
 
 // Down rolls back migrations. If steps > 0, rolls back that many.
 // If to is set, rolls back until that migration name is reached (exclusive).
 func (r *Runner) Down(steps int, to string, opts RunOptions) error {
-	plan, err := r.graph.Linearize()
+	return r.DownContext(context.Background(), steps, to, opts)
+}
+
+// DownContext is Down with a context. Cancelling ctx aborts the rollback in
+// progress (its transaction is rolled back) and stops before the next one.
+func (r *Runner) DownContext(ctx context.Context, steps int, to string, opts RunOptions) error {
+	_, err := r.down(ctx, steps, to, opts)
+	return err
+}
+
+// DownBatch rolls back every migration recorded in the most recent batch,
+// i.e. everything applied by the last `migrate up`.
+func (r *Runner) DownBatch(opts RunOptions) error {
+	return r.DownBatchContext(context.Background(), opts)
+}
+
+// DownBatchContext is DownBatch with a context.
+func (r *Runner) DownBatchContext(ctx context.Context, opts RunOptions) error {
+	_, err := r.downBatch(ctx, opts)
+	return err
+}
+
+// downBatch rolls back the most recent batch. The batch must be the tail of
+// the applied migrations in topological order; otherwise rolling it back
+// would leave later migrations applied on top of a missing schema.
+func (r *Runner) downBatch(ctx context.Context, opts RunOptions) ([]MigrationResult, error) {
+	entries, err := r.recorder.History(ctx)
 	if err != nil {
-		return fmt.Errorf("linearizing graph: %w", err)
+		return nil, err
 	}
-	applied, err := r.recorder.GetApplied()
+	last := 0
+	for _, e := range entries {
+		if e.Batch > last {
+			last = e.Batch
+		}
+	}
+	if last == 0 {
+		return nil, fmt.Errorf("no migration batches recorded; roll back with --steps or --to instead")
+	}
+	applied := make(map[string]bool, len(entries))
+	inBatch := make(map[string]bool)
+	for _, e := range entries {
+		applied[e.Name] = true
+		if e.Batch == last {
+			inBatch[e.Name] = true
+		}
+	}
+	plan, err := r.graph.Linearize()
 	if err != nil {
-		return fmt.Errorf("getting applied migrations: %w", err)
+		return nil, fmt.Errorf("linearizing graph: %w", err)
 	}
-
-	// Collect applied migrations in reverse topological order
-	var toRollback []*Migration
+	steps := 0
 	for i := len(plan) - 1; i >= 0; i-- {
-		if applied[plan[i].Name] {
-			toRollback = append(toRollback, plan[i])
+		name := plan[i].Name
+		if !applied[name] {
+			continue
+		}
+		if !inBatch[name] {
+			break
 		}
+		steps++
 	}
+	if steps != len(inBatch) {
+		return nil, fmt.Errorf("batch %d is not the most recently applied set of migrations; roll back with --to instead", last)
+	}
+	return r.down(ctx, steps, "", opts)
+}
 
-	for i, mig := range toRollback {
-		if steps > 0 && i >= steps {
-			break
+// down rolls back migrations and returns one result per migration that was
+// committed, including when a later rollback fails.
+func (r *Runner) down(ctx context.Context, steps int, to string, opts RunOptions) ([]MigrationResult, error) {
+	plan, err := r.graph.Linearize()
+	if err != nil {
+		return nil, fmt.Errorf("linearizing graph: %w", err)
+	}
+	applied, err := r.recorder.GetAppliedContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting applied migrations: %w", err)
+	}
+
+	// Collect the migrations to roll back in reverse topological order
+	var toRollback []*Migration
+	for i := len(plan) - 1; i >= 0; i-- {
+		mig := plan[i]
+		if !applied[mig.Name] {
+			continue
 		}
-		if to != "" && mig.Name == to {
+		if (steps > 0 && len(toRollback) >= steps) || (to != "" && mig.Name == to) {
 			break
 		}
-		// Reconstruct state just before this migration by replaying
-		// all applied migrations up to (but not including) this one
-		state := NewSchemaState()
-		for _, m := range plan {
-			if m.Name == mig.Name {
-				break
-			}
-			if applied[m.Name] {
-				for _, op := range m.Operations {
-					if err := op.Mutate(state); err != nil {
-						return fmt.Errorf("replaying state for %q: %w", m.Name, err)
-					}
-				}
-			}
+		toRollback = append(toRollback, mig)
+	}
+
+	// Capture the state just before each of them in a single replay of the
+	// applied migrations.
+	before := make(map[string]*SchemaState, len(toRollback))
+	for _, mig := range toRollback {
+		before[mig.Name] = nil
+	}
+	if _, err := replayState(plan, func(m *Migration) bool { return applied[m.Name] }, before); err != nil {
+		return nil, err
+	}
+
+	var results []MigrationResult
+	for _, mig := range toRollback {
+		if err := ctx.Err(); err != nil {
+			return results, err
 		}
+		state := before[mig.Name]
 		r.printf("Rolling back %s...", mig.Name)
-		if err := r.rollbackMigration(mig, state, opts); err != nil {
+		res := MigrationResult{Name: mig.Name}
+		started := time.Now()
+		if err := r.rollbackMigration(ctx, mig, state, opts, &res); err != nil {
 			r.printf(" FAILED\n")
-			return fmt.Errorf("rolling back migration %q: %w", mig.Name, err)
+			return results, fmt.Errorf("rolling back migration %q: %w", mig.Name, err)
 		}
-		r.printf(" done\n")
+		res.Duration = time.Since(started)
+		results = append(results, res)
+		r.printDone(res)
 	}
-	return nil
+	return results, nil
 }
 
 // Status prints migration status: applied vs pending.
 func (r *Runner) Status() error {
-	plan, err := r.graph.Linearize()
-	if err != nil {
-		return err
-	}
-	applied, err := r.recorder.GetApplied()
+	statuses, err := r.status(context.Background())
 	if err != nil {
 		return err
 	}
 	r.printf("%-50s %s\n", "Migration", "Status")
 	r.printf("%s\n", strings.Repeat("-", 60))
-	for _, mig := range plan {
+	for _, st := range statuses {
 		status := "Pending"
-		if applied[mig.Name] {
+		switch {
+		case st.Unknown:
+			status = "Unknown (not registered)"
+		case st.Applied:
 			status = "Applied"
+		case st.OutOfOrder:
+			status = "Pending (out of order)"
 		}
-		r.printf("%-50s %s\n", mig.Name, status)
+		r.printf("%-50s %s\n", st.Name, status)
 	}
 	return nil
 }
 
+// History prints the makemigrations_history table, oldest batch first.
+func (r *Runner) History() error {
+	entries, err := r.recorder.History(context.Background())
+	if err != nil {
+		return err
+	}
+	r.printf("%-40s %-5s %-20s %10s  %-24s %-10s %s\n", "Migration", "Batch", "Applied At", "Duration", "Applied By", "Version", "Commit")
+	r.printf("%s\n", strings.Repeat("-", 130))
+	for _, e := range entries {
+		batch, duration := "-", "-"
+		if e.Batch > 0 {
+			batch = fmt.Sprintf("%d", e.Batch)
+			duration = e.Duration.String()
+		}
+		commit := e.GitCommit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		by := e.AppliedBy
+		if e.CIJob != "" {
+			by += " (ci " + e.CIJob + ")"
+		}
+		r.printf("%-40s %-5s %-20s %10s  %-24s %-10s %s\n", e.Name, batch, e.AppliedAt, duration, by, e.ToolVersion, commit)
+	}
+	return nil
+}
+
+// status returns the applied/pending state of every migration in
+// topological order.
+func (r *Runner) status(ctx context.Context) ([]MigrationStatus, error) {
+	plan, err := r.graph.Linearize()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := r.recorder.GetAppliedContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gaps := make(map[string]bool)
+	for _, name := range outOfOrderPending(plan, applied) {
+		gaps[name] = true
+	}
+	statuses := make([]MigrationStatus, 0, len(plan))
+	for _, mig := range plan {
+		statuses = append(statuses, MigrationStatus{
+			Name:         mig.Name,
+			Dependencies: append([]string(nil), mig.Dependencies...),
+			Applied:      applied[mig.Name],
+			OutOfOrder:   gaps[mig.Name],
+		})
+	}
+	for _, name := range unknownApplied(plan, applied) {
+		statuses = append(statuses, MigrationStatus{Name: name, Applied: true, Unknown: true})
+	}
+	return statuses, nil
+}
+
+// PruneHistory removes history rows that no longer correspond to a
+// migration that needs tracking: names that are not registered at all, and
+// names replaced by a squash that is itself recorded as applied. It returns
+// the removed names. With dryRun the rows are listed but not deleted.
+func (r *Runner) PruneHistory(ctx context.Context, dryRun bool) ([]string, error) {
+	plan, err := r.graph.Linearize()
+	if err != nil {
+		return nil, fmt.Errorf("linearizing graph: %w", err)
+	}
+	applied, err := r.recorder.GetAppliedContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting applied migrations: %w", err)
+	}
+	prune := unknownApplied(plan, applied)
+	for _, mig := range plan {
+		if !applied[mig.Name] {
+			continue
+		}
+		for _, old := range mig.Replaces {
+			if applied[old] {
+				prune = append(prune, old)
+			}
+		}
+	}
+	sort.Strings(prune)
+	for _, name := range prune {
+		if dryRun {
+			r.printf("Would remove %s\n", name)
+			continue
+		}
+		if err := r.recorder.RecordRolledBackContext(ctx, name); err != nil {
+			return nil, err
+		}
+		r.printf("Removed %s\n", name)
+	}
+	if len(prune) == 0 {
+		r.printf("Nothing to prune.\n")
+	}
+	return prune, nil
+}
+
+// outOfOrderPending returns, in plan order, the pending migrations that
+// precede the last applied migration in plan. Contract migrations held back
+// by `up --phase expand`, and their dependents, are deferred by design and
+// not reported.
+func outOfOrderPending(plan []*Migration, applied map[string]bool) []string {
+	deferred := deferredContract(plan, applied)
+	last := -1
+	for i, mig := range plan {
+		if applied[mig.Name] {
+			last = i
+		}
+	}
+	var gaps []string
+	for _, mig := range plan[:last+1] {
+		if !applied[mig.Name] && !deferred[mig.Name] {
+			gaps = append(gaps, mig.Name)
+		}
+	}
+	return gaps
+}
+
+// unknownApplied returns, sorted, the applied names that are neither in
+// plan nor listed in the Replaces of a migration in plan.
+func unknownApplied(plan []*Migration, applied map[string]bool) []string {
+	known := make(map[string]bool, len(plan))
+	for _, mig := range plan {
+		known[mig.Name] = true
+		for _, old := range mig.Replaces {
+			known[old] = true
+		}
+	}
+	var unknown []string
+	for name := range applied {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// warn prints a skipped-operation warning and records it on res.
+func (r *Runner) warn(res *MigrationResult, format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	r.printf("[WARNING] %s\n", msg)
+	res.Warnings = append(res.Warnings, msg)
+}
+
 // ShowSQL prints all pending migration SQL without executing it.
 func (r *Runner) ShowSQL() error {
 	plan, err := r.graph.Linearize()
@@ -199,29 +576,23 @@ func (r *Runner) ShowSQL() error {
 	if err != nil {
 		return err
 	}
-	state := NewSchemaState()
+	// Replay every applied migration first, as Up does, so out-of-order
+	// gaps are rendered against the schema the database actually has.
+	state, err := replayState(plan, func(m *Migration) bool { return applied[m.Name] }, nil)
+	if err != nil {
+		return err
+	}
 	for _, mig := range plan {
 		if applied[mig.Name] {
-			for _, op := range mig.Operations {
-				if err := op.Mutate(state); err != nil {
-					return fmt.Errorf("replaying state for %q: %w", mig.Name, err)
-				}
-			}
 			continue
 		}
 		r.printf("-- %s\n", mig.Name)
-		for i, op := range mig.Operations {
-			r.provider.SetTypeMappings(state.TypeMappings)
-			sqlStr, err := op.Up(r.provider, state, state.Defaults)
-			if err != nil {
-				return fmt.Errorf("%s operation %d/%d [%s]: %w", mig.Name, i+1, len(mig.Operations), op.Describe(), err)
-			}
-			if sqlStr != "" {
-				r.printf("%s\n\n", sqlStr)
-			}
-			if err := op.Mutate(state); err != nil {
-				return fmt.Errorf("%s operation %d/%d [%s]: mutating state: %w", mig.Name, i+1, len(mig.Operations), op.Describe(), err)
-			}
+		stmts, err := renderUpSQL(r.provider, mig, state)
+		if err != nil {
+			return err
+		}
+		for _, sqlStr := range stmts {
+			r.printf("%s\n\n", sqlStr)
 		}
 	}
 	return nil
@@ -232,101 +603,229 @@ func (r *Runner) ShowSQL() error {
 // is rolled back and the database is left unchanged.
 // When opts.WarnOnMissingDrop is true, drop operations that fail because the object
 // does not exist are skipped with a warning instead of stopping the migration.
+// Registered hooks run inside the same transaction; OnError fires after rollback.
+// The first res.Resumed operations were completed by an earlier run (or marked
+// with `resolve --mark-op`) and only have their state replayed.
 //
-// Note: DDL statements in MySQL are auto-committed and cannot be rolled back
-// regardless of the transaction. PostgreSQL supports transactional DDL fully.
-func (r *Runner) applyMigration(mig *Migration, state *SchemaState, opts RunOptions) error {
-	tx, err := r.db.Begin()
+// Providers whose DDL auto-commits (MySQL and friends) cannot roll a
+// migration back as a unit, so they go through applyMigrationPerOp instead.
+//
+// A migration that fails with a lock timeout is retried per opts.Timeouts.
+// Each attempt starts from a copy of state; on auto-commit providers the
+// retry resumes after the operations the failed attempt completed.
+func (r *Runner) applyMigration(ctx context.Context, mig *Migration, state *SchemaState, opts RunOptions, res *MigrationResult) error {
+	return r.withLockRetry(ctx, opts, res, func() error {
+		attempt := state.Clone()
+		var err error
+		if autoCommitsDDL(r.provider) {
+			if res.Attempts > 1 {
+				progress, perr := r.recorder.GetProgress(ctx)
+				if perr != nil {
+					return perr
+				}
+				res.Resumed = progress[mig.Name]
+			}
+			err = r.applyMigrationPerOp(ctx, mig, attempt, opts, res)
+		} else {
+			err = r.applyMigrationTx(ctx, mig, attempt, opts, res)
+		}
+		if err != nil {
+			return err
+		}
+		*state = *attempt
+		return nil
+	})
+}
+
+// applyMigrationTx makes a single attempt at applying mig in one transaction.
+func (r *Runner) applyMigrationTx(ctx context.Context, mig *Migration, state *SchemaState, opts RunOptions, res *MigrationResult) (err error) {
+	ev := HookEvent{Direction: DirectionUp, Migration: mig}
+	started := time.Now()
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring connection: %w", err)
+	}
+	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
+		r.releaseConn(conn, mig, opts)
 		return fmt.Errorf("beginning transaction: %w", err)
 	}
-	defer func() { _ = tx.Rollback() }() // no-op if already committed
-
-	for i, op := range mig.Operations {
-		r.provider.SetTypeMappings(state.TypeMappings)
-		sqlStr, err := op.Up(r.provider, state, state.Defaults)
+	defer func() {
+		_ = tx.Rollback() // no-op if already committed
+		r.releaseConn(conn, mig, opts)
 		if err != nil {
-			return fmt.Errorf("operation %d/%d [%s]: generating SQL: %w", i+1, len(mig.Operations), op.Describe(), err)
+			ev.Err = err
+			r.fireOnError(ctx, ev)
 		}
-		skipped := false
-		if sqlStr != "" {
-			if execErr := execWithSavepoint(tx, sqlStr, canIgnoreError(op, opts)); execErr != nil {
-				if shouldIgnoreError(op, opts, r.provider, execErr) {
-					r.printf("[WARNING] op %d/%d %s — %v, skipping\n", i+1, len(mig.Operations), op.Describe(), execErr)
-					skipped = true
-				} else {
-					return fmt.Errorf("operation %d/%d [%s]: %w\n  SQL: %s", i+1, len(mig.Operations), op.Describe(), execErr, sqlStr)
-				}
-			}
-		}
-		// Skip state mutation when the drop operation was skipped — the object
-		// was never in the schema state either, so Mutate would fail.
-		if !skipped {
+	}()
+
+	if err := r.execTimeouts(ctx, tx, mig, opts, res); err != nil {
+		return err
+	}
+	if err := r.runHooks("before_migration", func(h Hook) error { return h.BeforeMigration(ctx, tx, ev) }); err != nil {
+		return err
+	}
+
+	for i, op := range mig.Operations {
+		if i < res.Resumed {
 			if err := op.Mutate(state); err != nil {
-				return fmt.Errorf("operation %d/%d [%s]: mutating state: %w", i+1, len(mig.Operations), op.Describe(), err)
+				return fmt.Errorf("operation %d/%d [%s]: replaying state: %w", i+1, len(mig.Operations), op.Describe(), err)
 			}
+			continue
+		}
+		if err := r.applyOp(ctx, tx, &ev, i, state, opts, res); err != nil {
+			return err
 		}
 	}
 
-	if err := r.recorder.RecordAppliedTx(tx, mig.Name); err != nil {
+	ev.Operation, ev.SQL = nil, ""
+	if err := r.runHooks("after_migration", func(h Hook) error { return h.AfterMigration(ctx, tx, ev) }); err != nil {
+		return err
+	}
+
+	entry := HistoryEntry{Name: mig.Name, Duration: time.Since(started), Batch: res.Batch, RunMetadata: r.meta}
+	if err := r.recorder.RecordHistoryTx(ctx, tx, entry); err != nil {
 		return err
 	}
+	if res.Resumed > 0 {
+		if err := r.recorder.SetProgressTx(ctx, tx, mig.Name, 0); err != nil {
+			return err
+		}
+	}
 
 	return tx.Commit()
 }
 
+// applyOp generates and executes the Up SQL of operation i of ev.Migration
+// inside tx, running the operation hooks around it and mutating state.
+// ev.Operation and ev.SQL are updated so OnError can report the failing
+// operation.
+func (r *Runner) applyOp(ctx context.Context, tx *sql.Tx, ev *HookEvent, i int, state *SchemaState, opts RunOptions, res *MigrationResult) error {
+	total := len(ev.Migration.Operations)
+	op := ev.Migration.Operations[i]
+	ev.Operation, ev.SQL = op, ""
+	r.provider.SetTypeMappings(state.TypeMappings)
+	sqlStr, err := op.Up(r.provider, state, state.Defaults)
+	if err != nil {
+		return fmt.Errorf("operation %d/%d [%s]: generating SQL: %w", i+1, total, op.Describe(), err)
+	}
+	ev.SQL = sqlStr
+	if err := r.runHooks("before_operation", func(h Hook) error { return h.BeforeOperation(ctx, tx, *ev) }); err != nil {
+		return fmt.Errorf("operation %d/%d [%s]: %w", i+1, total, op.Describe(), err)
+	}
+	skipped := false
+	if sqlStr != "" {
+		if execErr := execWithSavepoint(ctx, tx, sqlStr, canIgnoreError(op, opts)); execErr != nil {
+			if shouldIgnoreError(op, opts, r.provider, execErr) {
+				r.warn(res, "op %d/%d %s — %v, skipping", i+1, total, op.Describe(), execErr)
+				skipped = true
+			} else {
+				return fmt.Errorf("operation %d/%d [%s]: %w\n  SQL: %s", i+1, total, op.Describe(), execErr, sqlStr)
+			}
+		}
+	}
+	// Skip state mutation when the drop operation was skipped — the object
+	// was never in the schema state either, so Mutate would fail.
+	if !skipped {
+		if err := op.Mutate(state); err != nil {
+			return fmt.Errorf("operation %d/%d [%s]: mutating state: %w", i+1, total, op.Describe(), err)
+		}
+	}
+	if err := r.runHooks("after_operation", func(h Hook) error { return h.AfterOperation(ctx, tx, *ev) }); err != nil {
+		return fmt.Errorf("operation %d/%d [%s]: %w", i+1, total, op.Describe(), err)
+	}
+	return nil
+}
+
 // rollbackMigration reverses all operations in a migration within a transaction
 // and removes it from history atomically. If any operation fails the transaction
 // is rolled back and the database is left unchanged.
 // When opts.WarnOnMissingDrop is true, drop operations that fail because the object
 // does not exist are skipped with a warning instead of stopping the rollback.
+// Registered hooks run inside the same transaction; OnError fires after rollback.
+//
+// A rollback that fails with a lock timeout is retried per opts.Timeouts.
 //
 // Note: DDL statements in MySQL are auto-committed and cannot be rolled back
 // regardless of the transaction. PostgreSQL supports transactional DDL fully.
-func (r *Runner) rollbackMigration(mig *Migration, state *SchemaState, opts RunOptions) error {
-	tx, err := r.db.Begin()
+func (r *Runner) rollbackMigration(ctx context.Context, mig *Migration, state *SchemaState, opts RunOptions, res *MigrationResult) error {
+	return r.withLockRetry(ctx, opts, res, func() error {
+		return r.rollbackMigrationTx(ctx, mig, state.Clone(), opts, res)
+	})
+}
+
+// rollbackMigrationTx makes a single attempt at rolling back mig.
+func (r *Runner) rollbackMigrationTx(ctx context.Context, mig *Migration, state *SchemaState, opts RunOptions, res *MigrationResult) (err error) {
+	ev := HookEvent{Direction: DirectionDown, Migration: mig}
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring connection: %w", err)
+	}
+	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
+		r.releaseConn(conn, mig, opts)
 		return fmt.Errorf("beginning transaction: %w", err)
 	}
-	defer func() { _ = tx.Rollback() }() // no-op if already committed
-
-	// Pre-apply state-only ops (SetDefaults, SetTypeMappings) from this migration so that
-	// Defaults and TypeMappings are populated when generating Down SQL for other ops.
-	for _, op := range mig.Operations {
-		switch op.TypeName() {
-		case "set_defaults", "set_type_mappings":
-			if err := op.Mutate(state); err != nil {
-				return fmt.Errorf("pre-applying %s state for %q: %w", op.TypeName(), mig.Name, err)
-			}
+	defer func() {
+		_ = tx.Rollback() // no-op if already committed
+		r.releaseConn(conn, mig, opts)
+		if err != nil {
+			ev.Err = err
+			r.fireOnError(ctx, ev)
 		}
+	}()
+
+	if err := r.execTimeouts(ctx, tx, mig, opts, res); err != nil {
+		return err
+	}
+
+	if err := preApplyStateOps(mig, state); err != nil {
+		return err
+	}
+
+	if err := r.runHooks("before_migration", func(h Hook) error { return h.BeforeMigration(ctx, tx, ev) }); err != nil {
+		return err
 	}
 
 	total := len(mig.Operations)
 	for i := total - 1; i >= 0; i-- {
 		op := mig.Operations[i]
 		opNum := total - i
+		ev.Operation, ev.SQL = op, ""
 		r.provider.SetTypeMappings(state.TypeMappings)
 		sqlStr, err := op.Down(r.provider, state, state.Defaults)
 		if err != nil {
 			return fmt.Errorf("operation %d/%d [%s]: generating down SQL: %w", opNum, total, op.Describe(), err)
 		}
+		ev.SQL = sqlStr
+		if err := r.runHooks("before_operation", func(h Hook) error { return h.BeforeOperation(ctx, tx, ev) }); err != nil {
+			return fmt.Errorf("operation %d/%d [%s]: %w", opNum, total, op.Describe(), err)
+		}
 		if sqlStr != "" {
 			mayIgnore := canIgnoreError(op, opts) || isDropOp(op) || isCreateOp(op)
-			if execErr := execWithSavepoint(tx, sqlStr, mayIgnore); execErr != nil {
+			if execErr := execWithSavepoint(ctx, tx, sqlStr, mayIgnore); execErr != nil {
 				if shouldIgnoreError(op, opts, r.provider, execErr) {
-					r.printf("[WARNING] op %d/%d %s — %v, skipping\n", opNum, total, op.Describe(), execErr)
+					r.warn(res, "op %d/%d %s — %v, skipping", opNum, total, op.Describe(), execErr)
 				} else if isDropOp(op) && r.provider.IsAlreadyExistsError(execErr) {
-					r.printf("[WARNING] op %d/%d %s — object already exists in database, skipping\n", opNum, total, op.Describe())
+					r.warn(res, "op %d/%d %s — object already exists in database, skipping", opNum, total, op.Describe())
 				} else if isCreateOp(op) && r.provider.IsNotFoundError(execErr) {
-					r.printf("[WARNING] op %d/%d %s — object does not exist in database, skipping\n", opNum, total, op.Describe())
+					r.warn(res, "op %d/%d %s — object does not exist in database, skipping", opNum, total, op.Describe())
 				} else {
 					return fmt.Errorf("operation %d/%d [%s]: %w\n  SQL: %s", opNum, total, op.Describe(), execErr, sqlStr)
 				}
 			}
 		}
+		if err := r.runHooks("after_operation", func(h Hook) error { return h.AfterOperation(ctx, tx, ev) }); err != nil {
+			return fmt.Errorf("operation %d/%d [%s]: %w", opNum, total, op.Describe(), err)
+		}
+	}
+
+	ev.Operation, ev.SQL = nil, ""
+	if err := r.runHooks("after_migration", func(h Hook) error { return h.AfterMigration(ctx, tx, ev) }); err != nil {
+		return err
 	}
 
-	if err := r.recorder.RecordRolledBackTx(tx, mig.Name); err != nil {
+	if err := r.recorder.RecordRolledBackTxContext(ctx, tx, mig.Name); err != nil {
 		return err
 	}
 
@@ -359,20 +858,20 @@ func shouldIgnoreError(op Operation, opts RunOptions, p providers.Provider, exec
 // execWithSavepoint executes SQL within a SAVEPOINT when mayFail is true,
 // so that a failed statement does not poison the surrounding transaction
 // (required for PostgreSQL). When mayFail is false it executes directly.
-func execWithSavepoint(tx *sql.Tx, sqlStr string, mayFail bool) error {
+func execWithSavepoint(ctx context.Context, tx *sql.Tx, sqlStr string, mayFail bool) error {
 	if !mayFail {
-		_, err := tx.Exec(sqlStr)
+		_, err := tx.ExecContext(ctx, sqlStr)
 		return err
 	}
-	if _, err := tx.Exec("SAVEPOINT ignore_errors"); err != nil {
-		_, execErr := tx.Exec(sqlStr)
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT ignore_errors"); err != nil {
+		_, execErr := tx.ExecContext(ctx, sqlStr)
 		return execErr
 	}
-	if _, err := tx.Exec(sqlStr); err != nil {
-		_, _ = tx.Exec("ROLLBACK TO SAVEPOINT ignore_errors")
+	if _, err := tx.ExecContext(ctx, sqlStr); err != nil {
+		_, _ = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT ignore_errors")
 		return err
 	}
-	_, _ = tx.Exec("RELEASE SAVEPOINT ignore_errors")
+	_, _ = tx.ExecContext(ctx, "RELEASE SAVEPOINT ignore_errors")
 	return nil
 }
 
@@ -380,7 +879,8 @@ func execWithSavepoint(tx *sql.Tx, sqlStr string, mayFail bool) error {
 // Only drop operations trigger WarnOnMissingDrop — all other failures stop immediately.
 func isDropOp(op Operation) bool {
 	switch op.TypeName() {
-	case "drop_table", "drop_field", "drop_index", "drop_foreign_key":
+	case "drop_table", "drop_field", "drop_index", "drop_foreign_key", "drop_sequence",
+		"drop_function", "drop_trigger", "drop_unique_constraint":
 		return true
 	default:
 		return false
@@ -388,12 +888,14 @@ func isDropOp(op Operation) bool {
 }
 
 // isCreateOp returns true for operations that create a database object in
-// their Up direction (create_table, add_field, add_index, add_foreign_key).
+// their Up direction (create_table, add_field, add_index, add_foreign_key,
+// create_sequence, create_function, create_trigger, add_unique_constraint).
 // During rollback, these operations' Down SQL drops objects, so a "not found"
 // error can be safely skipped.
 func isCreateOp(op Operation) bool {
 	switch op.TypeName() {
-	case "create_table", "add_field", "add_index", "add_foreign_key":
+	case "create_table", "add_field", "add_index", "add_foreign_key", "create_sequence",
+		"create_function", "create_trigger", "add_unique_constraint":
 		return true
 	default:
 		return false
@@ -47,6 +47,27 @@ func openTestDB(t *testing.T) *sql.DB {
 	return db
 }
 
+// testRegistry registers migs in order. A migration whose Dependencies are
+// nil depends on the one registered before it, the first on nothing.
+func testRegistry(migs ...*migrate.Migration) *migrate.Registry {
+	reg := migrate.NewRegistry()
+	prev := []string{}
+	for _, mig := range migs {
+		if mig.Dependencies == nil {
+			mig.Dependencies = prev
+		}
+		reg.Register(mig)
+		prev = []string{mig.Name}
+	}
+	return reg
+}
+
+// idTable creates the table name with an integer id primary key followed by
+// fields.
+func idTable(name string, fields ...migrate.Field) *migrate.CreateTable {
+	return &migrate.CreateTable{Name: name, Fields: append([]migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}}, fields...)}
+}
+
 // buildTestRunner creates a Runner with the given registry against an in-memory SQLite db.
 // Returns the runner, the recorder (for verification), and the db.
 func buildTestRunner(t *testing.T, reg *migrate.Registry) (*migrate.Runner, *migrate.MigrationRecorder, *sql.DB) {
@@ -0,0 +1,319 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package migrate_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/internal/providers"
+	"github.com/ocomsoft/makemigrations/internal/providers/postgresql"
+	"github.com/ocomsoft/makemigrations/internal/providers/sqlite"
+	"github.com/ocomsoft/makemigrations/internal/providers/sqlserver"
+	"github.com/ocomsoft/makemigrations/internal/providers/tidb"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+func TestCreateSequence_PostgreSQL(t *testing.T) {
+	p := postgresql.New()
+	state := migrate.NewSchemaState()
+	op := &migrate.CreateSequence{Sequence: migrate.Sequence{Name: "invoice_seq", Start: 1000, Increment: 5, Cycle: true}}
+
+	up, err := op.Up(p, state, nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	want := `CREATE SEQUENCE "invoice_seq" INCREMENT BY 5 START WITH 1000 CYCLE;`
+	if up != want {
+		t.Fatalf("Up SQL mismatch\n got: %s\nwant: %s", up, want)
+	}
+	down, err := op.Down(p, state, nil)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if !strings.Contains(down, `DROP SEQUENCE IF EXISTS "invoice_seq"`) {
+		t.Fatalf("unexpected Down SQL: %s", down)
+	}
+	if err := op.Mutate(state); err != nil {
+		t.Fatalf("Mutate: %v", err)
+	}
+	if state.Sequences["invoice_seq"] == nil {
+		t.Fatal("expected invoice_seq in state after Mutate")
+	}
+	if err := op.Mutate(state); err == nil {
+		t.Fatal("expected error creating a duplicate sequence")
+	}
+}
+
+func TestCreateSequence_ExplicitZeroStartAndMinValue(t *testing.T) {
+	seq := migrate.Sequence{Name: "slot_seq", Start: 0, HasStart: true, MinValue: 0, HasMinValue: true}
+	up, err := (&migrate.CreateSequence{Sequence: seq}).Up(postgresql.New(), migrate.NewSchemaState(), nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if want := `CREATE SEQUENCE "slot_seq" INCREMENT BY 1 MINVALUE 0 START WITH 0;`; up != want {
+		t.Fatalf("Up SQL mismatch\n got: %s\nwant: %s", up, want)
+	}
+
+	// Dropping the explicit bound goes back to the database default.
+	unbounded := seq
+	unbounded.HasMinValue = false
+	alter, err := (&migrate.AlterSequence{OldSequence: seq, NewSequence: unbounded}).Up(postgresql.New(), nil, nil)
+	if err != nil {
+		t.Fatalf("Up (alter): %v", err)
+	}
+	if want := `ALTER SEQUENCE "slot_seq" NO MINVALUE;`; alter != want {
+		t.Fatalf("Alter SQL mismatch\n got: %s\nwant: %s", alter, want)
+	}
+}
+
+func TestAlterSequence_PostgreSQL_OnlyChangedClauses(t *testing.T) {
+	p := postgresql.New()
+	op := &migrate.AlterSequence{
+		OldSequence: migrate.Sequence{Name: "invoice_seq", Increment: 1},
+		NewSequence: migrate.Sequence{Name: "invoice_seq", Increment: 10, MaxValue: 99999, OwnedBy: "invoices.number"},
+	}
+	up, err := op.Up(p, nil, nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	want := `ALTER SEQUENCE "invoice_seq" INCREMENT BY 10 MAXVALUE 99999 OWNED BY "invoices"."number";`
+	if up != want {
+		t.Fatalf("Up SQL mismatch\n got: %s\nwant: %s", up, want)
+	}
+	down, err := op.Down(p, nil, nil)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	want = `ALTER SEQUENCE "invoice_seq" INCREMENT BY 1 NO MAXVALUE OWNED BY NONE;`
+	if down != want {
+		t.Fatalf("Down SQL mismatch\n got: %s\nwant: %s", down, want)
+	}
+}
+
+func TestSequence_NativeProviders(t *testing.T) {
+	seq := migrate.Sequence{Name: "invoice_seq", Start: 1000, Increment: 5, Cycle: true, OwnedBy: "invoices.number"}
+	tests := []struct {
+		name                             string
+		p                                providers.Provider
+		create, alter, drop, nextDefault string
+	}{
+		{
+			name:        "sqlserver",
+			p:           sqlserver.New(),
+			create:      "CREATE SEQUENCE [invoice_seq] AS BIGINT START WITH 1000 INCREMENT BY 5 CYCLE;",
+			alter:       "ALTER SEQUENCE [invoice_seq] INCREMENT BY 10 MAXVALUE 99999 NO CYCLE;",
+			drop:        "DROP SEQUENCE IF EXISTS [invoice_seq];",
+			nextDefault: "DEFAULT NEXT VALUE FOR [invoice_seq]",
+		},
+		{
+			name:        "tidb",
+			p:           tidb.New(),
+			create:      "CREATE SEQUENCE `invoice_seq` INCREMENT BY 5 START WITH 1000 CYCLE;",
+			alter:       "ALTER SEQUENCE `invoice_seq` INCREMENT BY 10 MAXVALUE 99999 START WITH 1 NO CYCLE;",
+			drop:        "DROP SEQUENCE IF EXISTS `invoice_seq`;",
+			nextDefault: "DEFAULT NEXT VALUE FOR `invoice_seq`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			create := &migrate.CreateSequence{Sequence: seq}
+			if up, err := create.Up(tt.p, migrate.NewSchemaState(), nil); err != nil || up != tt.create {
+				t.Errorf("CreateSequence Up = %q, %v; want %q", up, err, tt.create)
+			}
+			if down, err := create.Down(tt.p, migrate.NewSchemaState(), nil); err != nil || down != tt.drop {
+				t.Errorf("CreateSequence Down = %q, %v; want %q", down, err, tt.drop)
+			}
+			alter := &migrate.AlterSequence{
+				OldSequence: seq,
+				NewSequence: migrate.Sequence{Name: "invoice_seq", Increment: 10, MaxValue: 99999},
+			}
+			if up, err := alter.Up(tt.p, nil, nil); err != nil || up != tt.alter {
+				t.Errorf("AlterSequence Up = %q, %v; want %q", up, err, tt.alter)
+			}
+			owner := &migrate.AlterSequence{OldSequence: migrate.Sequence{Name: "invoice_seq"}, NewSequence: seq}
+			owner.NewSequence.Start, owner.NewSequence.Increment, owner.NewSequence.Cycle = 0, 0, false
+			if up, err := owner.Up(tt.p, nil, nil); err != nil || up != "" {
+				t.Errorf("expected an OwnedBy-only change to need no SQL, got %q, %v", up, err)
+			}
+			add := &migrate.AddField{
+				Table: "invoices",
+				Field: migrate.Field{Name: "number", Type: "bigint", Default: "nextval('invoice_seq')"},
+			}
+			if up, err := add.Up(tt.p, nil, nil); err != nil || !strings.Contains(up, tt.nextDefault) {
+				t.Errorf("AddField Up = %q, %v; want it to contain %q", up, err, tt.nextDefault)
+			}
+		})
+	}
+}
+
+func TestDropSequence_DownRecreatesFromState(t *testing.T) {
+	p := postgresql.New()
+	state := migrate.NewSchemaState()
+	if err := state.AddSequence(migrate.Sequence{Name: "invoice_seq", Start: 500}); err != nil {
+		t.Fatalf("AddSequence: %v", err)
+	}
+	op := &migrate.DropSequence{Name: "invoice_seq"}
+	down, err := op.Down(p, state, nil)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if !strings.Contains(down, "START WITH 500") {
+		t.Fatalf("expected Down to recreate with original start, got: %s", down)
+	}
+	if err := op.Mutate(state); err != nil {
+		t.Fatalf("Mutate: %v", err)
+	}
+	if _, err := op.Down(p, state, nil); err == nil {
+		t.Fatal("expected error generating Down for a sequence missing from state")
+	}
+}
+
+func TestNextValDefault_ResolvesPerProvider(t *testing.T) {
+	op := &migrate.AddField{
+		Table: "invoices",
+		Field: migrate.Field{Name: "number", Type: "bigint", Default: "nextval('invoice_seq')"},
+	}
+
+	pg, err := op.Up(postgresql.New(), nil, nil)
+	if err != nil {
+		t.Fatalf("Up (postgresql): %v", err)
+	}
+	if !strings.Contains(pg, `DEFAULT nextval('"invoice_seq"')`) {
+		t.Fatalf("expected nextval default on PostgreSQL, got: %s", pg)
+	}
+
+	lite, err := op.Up(sqlite.New(), nil, nil)
+	if err != nil {
+		t.Fatalf("Up (sqlite): %v", err)
+	}
+	if strings.Contains(lite, "DEFAULT") {
+		t.Fatalf("expected no column default on SQLite (filled by trigger), got: %s", lite)
+	}
+
+	if got, want := postgresql.New().NextValExpression("Invoice'Seq"), `nextval('"Invoice''Seq"')`; got != want {
+		t.Fatalf("NextValExpression = %s, want %s", got, want)
+	}
+
+	// An explicit defaults entry overrides the provider resolution.
+	override, err := op.Up(postgresql.New(), nil, map[string]string{"nextval('invoice_seq')": "next_invoice()"})
+	if err != nil {
+		t.Fatalf("Up (override): %v", err)
+	}
+	if !strings.Contains(override, "DEFAULT next_invoice()") {
+		t.Fatalf("expected defaults override to win, got: %s", override)
+	}
+}
+
+func TestRunner_Sequence_SQLiteEmulation(t *testing.T) {
+	restore := suppressStdout(t)
+	defer restore()
+
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0001_initial",
+		Dependencies: []string{},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{
+				Name: "invoices",
+				Fields: []migrate.Field{
+					{Name: "id", Type: "integer", PrimaryKey: true},
+					{Name: "number", Type: "bigint", Nullable: true, Default: "nextval('invoice_seq')"},
+				},
+			},
+			&migrate.CreateSequence{Sequence: migrate.Sequence{Name: "invoice_seq", Start: 100, Increment: 10, OwnedBy: "invoices.number"}},
+		},
+	})
+
+	runner, _, db := buildTestRunner(t, reg)
+	if err := runner.Up("", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := db.Exec("INSERT INTO invoices (id) VALUES (?)", i+1); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	rows, err := db.Query("SELECT number FROM invoices ORDER BY id")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var got []int64
+	for rows.Next() {
+		var n int64
+		if err := rows.Scan(&n); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, n)
+	}
+	if len(got) != 2 || got[0] != 100 || got[1] != 110 {
+		t.Fatalf("expected sequence values [100 110], got %v", got)
+	}
+
+	if err := runner.Down(1, "", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if _, err := db.Exec("SELECT 1 FROM invoice_seq"); err == nil {
+		t.Fatal("expected counter table to be dropped after Down")
+	}
+}
+
+func TestRunner_Sequence_SQLiteEmulationExplicitZeroStart(t *testing.T) {
+	restore := suppressStdout(t)
+	defer restore()
+
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0001_initial",
+		Dependencies: []string{},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{
+				Name: "slots",
+				Fields: []migrate.Field{
+					{Name: "id", Type: "integer", PrimaryKey: true},
+					{Name: "number", Type: "bigint", Nullable: true, Default: "nextval('slot_seq')"},
+				},
+			},
+			&migrate.CreateSequence{Sequence: migrate.Sequence{Name: "slot_seq", Start: 0, HasStart: true, OwnedBy: "slots.number"}},
+		},
+	})
+
+	runner, _, db := buildTestRunner(t, reg)
+	if err := runner.Up("", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO slots (id) VALUES (1)"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	var n int64
+	if err := db.QueryRow("SELECT number FROM slots").Scan(&n); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected the sequence to start at 0, got %d", n)
+	}
+}
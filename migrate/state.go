@@ -33,6 +33,9 @@ type SchemaState struct {
 	Tables       map[string]*TableState `json:"tables"`
 	Defaults     map[string]string      `json:"defaults,omitempty"`      // active DB-type defaults from SetDefaults operations
 	TypeMappings map[string]string      `json:"type_mappings,omitempty"` // active provider's type mappings from SetTypeMappings operations
+	Sequences    map[string]*Sequence   `json:"sequences,omitempty"`     // standalone sequences from CreateSequence operations
+	Functions    map[string]*Function   `json:"functions,omitempty"`     // stored functions and procedures
+	Triggers     map[string]*Trigger    `json:"triggers,omitempty"`      // table triggers
 }
 
 // TableState holds the state of a single table.
@@ -41,6 +44,13 @@ type TableState struct {
 	Fields      []Field                `json:"fields"`
 	Indexes     []Index                `json:"indexes"`
 	ForeignKeys []ForeignKeyConstraint `json:"foreign_keys,omitempty"`
+	// UniqueConstraints holds the table's named UNIQUE constraints.
+	UniqueConstraints []UniqueConstraint `json:"unique_constraints,omitempty"`
+
+	// refs counts the other states a Clone shares this table with. A state
+	// changing a shared table copies it first and gives up its reference,
+	// so the last holder changes it in place (see mutableTable).
+	refs int
 }
 
 // NewSchemaState returns an empty SchemaState.
@@ -48,6 +58,91 @@ func NewSchemaState() *SchemaState {
 	return &SchemaState{Tables: make(map[string]*TableState)}
 }
 
+// Clone returns a copy of s that can be mutated independently, so that a
+// failed migration attempt can be discarded or the state before each
+// migration kept while replaying. Tables are shared copy-on-write: the
+// clone costs one map copy, and a table is only duplicated when either
+// state next modifies it.
+func (s *SchemaState) Clone() *SchemaState {
+	c := &SchemaState{
+		Tables:       make(map[string]*TableState, len(s.Tables)),
+		Defaults:     s.Defaults,
+		TypeMappings: s.TypeMappings,
+	}
+	for name, t := range s.Tables {
+		t.refs++
+		c.Tables[name] = t
+	}
+	// Sequences, functions and triggers are replaced rather than modified in
+	// place, so copying the maps is enough.
+	if s.Sequences != nil {
+		c.Sequences = make(map[string]*Sequence, len(s.Sequences))
+		for k, v := range s.Sequences {
+			c.Sequences[k] = v
+		}
+	}
+	if s.Functions != nil {
+		c.Functions = make(map[string]*Function, len(s.Functions))
+		for k, v := range s.Functions {
+			c.Functions[k] = v
+		}
+	}
+	if s.Triggers != nil {
+		c.Triggers = make(map[string]*Trigger, len(s.Triggers))
+		for k, v := range s.Triggers {
+			c.Triggers[k] = v
+		}
+	}
+	return c
+}
+
+// mutableTable returns the named table for modification, first copying it
+// if it is shared with a clone. A state that discards its clone without
+// changing a table leaves the reference behind, costing one spare copy.
+func (s *SchemaState) mutableTable(name string) (*TableState, bool) {
+	t, exists := s.Tables[name]
+	if !exists {
+		return nil, false
+	}
+	if t.refs > 0 {
+		t.refs--
+		t = t.clone()
+		s.Tables[name] = t
+	}
+	return t, true
+}
+
+// clone returns an unshared deep copy of t.
+func (t *TableState) clone() *TableState {
+	c := &TableState{
+		Name:              t.Name,
+		Fields:            make([]Field, len(t.Fields)),
+		Indexes:           make([]Index, len(t.Indexes)),
+		ForeignKeys:       append([]ForeignKeyConstraint{}, t.ForeignKeys...),
+		UniqueConstraints: make([]UniqueConstraint, len(t.UniqueConstraints)),
+	}
+	for i, f := range t.Fields {
+		if f.ForeignKey != nil {
+			fk := *f.ForeignKey
+			f.ForeignKey = &fk
+		}
+		if f.ManyToMany != nil {
+			m2m := *f.ManyToMany
+			f.ManyToMany = &m2m
+		}
+		c.Fields[i] = f
+	}
+	for i, idx := range t.Indexes {
+		idx.Fields = append([]string(nil), idx.Fields...)
+		c.Indexes[i] = idx
+	}
+	for i, uc := range t.UniqueConstraints {
+		uc.Fields = append([]string(nil), uc.Fields...)
+		c.UniqueConstraints[i] = uc
+	}
+	return c
+}
+
 // SetDefaults updates the active schema defaults map on the state.
 // Called by SetDefaults operations during migration traversal.
 func (s *SchemaState) SetDefaults(defaults map[string]string) {
@@ -92,27 +187,39 @@ func (s *SchemaState) DropTable(name string) error {
 		return fmt.Errorf("table %q does not exist in schema state", name)
 	}
 	delete(s.Tables, name)
+	for trName, tr := range s.Triggers {
+		if tr.Table == name {
+			delete(s.Triggers, trName)
+		}
+	}
 	return nil
 }
 
 // RenameTable renames a table. Returns error if old name does not exist or new name already exists.
 func (s *SchemaState) RenameTable(oldName, newName string) error {
-	t, exists := s.Tables[oldName]
-	if !exists {
+	if _, exists := s.Tables[oldName]; !exists {
 		return fmt.Errorf("table %q does not exist in schema state", oldName)
 	}
 	if _, exists := s.Tables[newName]; exists {
 		return fmt.Errorf("table %q already exists in schema state", newName)
 	}
+	t, _ := s.mutableTable(oldName)
 	t.Name = newName
 	s.Tables[newName] = t
 	delete(s.Tables, oldName)
+	for name, tr := range s.Triggers {
+		if tr.Table == oldName {
+			renamed := *tr
+			renamed.Table = newName
+			s.Triggers[name] = &renamed
+		}
+	}
 	return nil
 }
 
 // AddField appends a field to an existing table. Returns error if the field name already exists.
 func (s *SchemaState) AddField(tableName string, field Field) error {
-	t, exists := s.Tables[tableName]
+	t, exists := s.mutableTable(tableName)
 	if !exists {
 		return fmt.Errorf("table %q does not exist in schema state", tableName)
 	}
@@ -127,7 +234,7 @@ func (s *SchemaState) AddField(tableName string, field Field) error {
 
 // DropField removes a named field from an existing table.
 func (s *SchemaState) DropField(tableName, fieldName string) error {
-	t, exists := s.Tables[tableName]
+	t, exists := s.mutableTable(tableName)
 	if !exists {
 		return fmt.Errorf("table %q does not exist in schema state", tableName)
 	}
@@ -142,7 +249,7 @@ func (s *SchemaState) DropField(tableName, fieldName string) error {
 
 // AlterField replaces a field (matched by name) in an existing table.
 func (s *SchemaState) AlterField(tableName string, newField Field) error {
-	t, exists := s.Tables[tableName]
+	t, exists := s.mutableTable(tableName)
 	if !exists {
 		return fmt.Errorf("table %q does not exist in schema state", tableName)
 	}
@@ -157,7 +264,7 @@ func (s *SchemaState) AlterField(tableName string, newField Field) error {
 
 // RenameField renames a field within an existing table.
 func (s *SchemaState) RenameField(tableName, oldName, newName string) error {
-	t, exists := s.Tables[tableName]
+	t, exists := s.mutableTable(tableName)
 	if !exists {
 		return fmt.Errorf("table %q does not exist in schema state", tableName)
 	}
@@ -172,7 +279,7 @@ func (s *SchemaState) RenameField(tableName, oldName, newName string) error {
 
 // AddIndex appends an index to an existing table. Returns error if the index name already exists.
 func (s *SchemaState) AddIndex(tableName string, index Index) error {
-	t, exists := s.Tables[tableName]
+	t, exists := s.mutableTable(tableName)
 	if !exists {
 		return fmt.Errorf("table %q does not exist in schema state", tableName)
 	}
@@ -187,7 +294,7 @@ func (s *SchemaState) AddIndex(tableName string, index Index) error {
 
 // DropIndex removes a named index from an existing table.
 func (s *SchemaState) DropIndex(tableName, indexName string) error {
-	t, exists := s.Tables[tableName]
+	t, exists := s.mutableTable(tableName)
 	if !exists {
 		return fmt.Errorf("table %q does not exist in schema state", tableName)
 	}
@@ -200,10 +307,41 @@ func (s *SchemaState) DropIndex(tableName, indexName string) error {
 	return fmt.Errorf("index %q does not exist in table %q", indexName, tableName)
 }
 
+// AddUniqueConstraint appends a unique constraint to an existing table.
+// Returns error if a constraint with the same name already exists.
+func (s *SchemaState) AddUniqueConstraint(tableName string, uc UniqueConstraint) error {
+	t, exists := s.mutableTable(tableName)
+	if !exists {
+		return fmt.Errorf("table %q does not exist in schema state", tableName)
+	}
+	for _, existing := range t.UniqueConstraints {
+		if existing.Name == uc.Name {
+			return fmt.Errorf("unique constraint %q already exists in table %q", uc.Name, tableName)
+		}
+	}
+	t.UniqueConstraints = append(t.UniqueConstraints, uc)
+	return nil
+}
+
+// DropUniqueConstraint removes a named unique constraint from an existing table.
+func (s *SchemaState) DropUniqueConstraint(tableName, name string) error {
+	t, exists := s.mutableTable(tableName)
+	if !exists {
+		return fmt.Errorf("table %q does not exist in schema state", tableName)
+	}
+	for i, uc := range t.UniqueConstraints {
+		if uc.Name == name {
+			t.UniqueConstraints = append(t.UniqueConstraints[:i], t.UniqueConstraints[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("unique constraint %q does not exist in table %q", name, tableName)
+}
+
 // AddForeignKey appends a foreign key constraint to an existing table.
 // If the constraint already exists it is updated in place (idempotent).
 func (s *SchemaState) AddForeignKey(tableName string, fk ForeignKeyConstraint) error {
-	t, exists := s.Tables[tableName]
+	t, exists := s.mutableTable(tableName)
 	if !exists {
 		return fmt.Errorf("table %q does not exist in schema state", tableName)
 	}
@@ -230,7 +368,7 @@ func (s *SchemaState) AddForeignKey(tableName string, fk ForeignKeyConstraint) e
 // DropForeignKey removes a named foreign key constraint from an existing table.
 // Also removes the auto-generated FromFK index for the FK column, if present.
 func (s *SchemaState) DropForeignKey(tableName, constraintName string) error {
-	t, exists := s.Tables[tableName]
+	t, exists := s.mutableTable(tableName)
 	if !exists {
 		return fmt.Errorf("table %q does not exist in schema state", tableName)
 	}
@@ -245,6 +383,92 @@ func (s *SchemaState) DropForeignKey(tableName, constraintName string) error {
 	return fmt.Errorf("foreign key %q does not exist in table %q", constraintName, tableName)
 }
 
+// AddSequence adds a new sequence. Returns error if the sequence already exists.
+func (s *SchemaState) AddSequence(seq Sequence) error {
+	if _, exists := s.Sequences[seq.Name]; exists {
+		return fmt.Errorf("sequence %q already exists in schema state", seq.Name)
+	}
+	if s.Sequences == nil {
+		s.Sequences = make(map[string]*Sequence)
+	}
+	s.Sequences[seq.Name] = &seq
+	return nil
+}
+
+// AlterSequence replaces an existing sequence definition (matched by name).
+func (s *SchemaState) AlterSequence(seq Sequence) error {
+	if _, exists := s.Sequences[seq.Name]; !exists {
+		return fmt.Errorf("sequence %q does not exist in schema state", seq.Name)
+	}
+	s.Sequences[seq.Name] = &seq
+	return nil
+}
+
+// DropSequence removes a sequence. Returns error if the sequence does not exist.
+func (s *SchemaState) DropSequence(name string) error {
+	if _, exists := s.Sequences[name]; !exists {
+		return fmt.Errorf("sequence %q does not exist in schema state", name)
+	}
+	delete(s.Sequences, name)
+	return nil
+}
+
+// AddFunction adds a new function. Returns error if the function already exists.
+func (s *SchemaState) AddFunction(fn Function) error {
+	if _, exists := s.Functions[fn.Name]; exists {
+		return fmt.Errorf("function %q already exists in schema state", fn.Name)
+	}
+	if s.Functions == nil {
+		s.Functions = make(map[string]*Function)
+	}
+	s.Functions[fn.Name] = &fn
+	return nil
+}
+
+// ReplaceFunction replaces an existing function definition (matched by name).
+func (s *SchemaState) ReplaceFunction(fn Function) error {
+	if _, exists := s.Functions[fn.Name]; !exists {
+		return fmt.Errorf("function %q does not exist in schema state", fn.Name)
+	}
+	s.Functions[fn.Name] = &fn
+	return nil
+}
+
+// DropFunction removes a function. Returns error if the function does not exist.
+func (s *SchemaState) DropFunction(name string) error {
+	if _, exists := s.Functions[name]; !exists {
+		return fmt.Errorf("function %q does not exist in schema state", name)
+	}
+	delete(s.Functions, name)
+	return nil
+}
+
+// AddTrigger adds a new trigger. Returns error if the trigger already exists
+// or its table does not.
+func (s *SchemaState) AddTrigger(tr Trigger) error {
+	if _, exists := s.Triggers[tr.Name]; exists {
+		return fmt.Errorf("trigger %q already exists in schema state", tr.Name)
+	}
+	if _, exists := s.Tables[tr.Table]; !exists {
+		return fmt.Errorf("table %q does not exist in schema state", tr.Table)
+	}
+	if s.Triggers == nil {
+		s.Triggers = make(map[string]*Trigger)
+	}
+	tr.Events = append([]string(nil), tr.Events...)
+	s.Triggers[tr.Name] = &tr
+	return nil
+}
+
+// DropTrigger removes a trigger. Returns error if the trigger does not exist.
+func (s *SchemaState) DropTrigger(name string) error {
+	if _, exists := s.Triggers[name]; !exists {
+		return fmt.Errorf("trigger %q does not exist in schema state", name)
+	}
+	delete(s.Triggers, name)
+	return nil
+}
+
 // ensureFKIndex adds a FromFK index on the given field if no index already
 // covers that field as its first column.
 func (s *SchemaState) ensureFKIndex(t *TableState, fieldName string) {
@@ -3,6 +3,8 @@
 package symbols
 
 import (
+	"context"
+	"database/sql"
 	"github.com/ocomsoft/makemigrations/internal/providers"
 	"github.com/ocomsoft/makemigrations/migrate"
 	"reflect"
@@ -11,63 +13,133 @@ import (
 func init() {
 	Symbols["github.com/ocomsoft/makemigrations/migrate/migrate"] = map[string]reflect.Value{
 		// function, constant and variable definitions
+		"AlterMethodColumn":     reflect.ValueOf(migrate.AlterMethodColumn),
+		"AlterMethodNewColumn":  reflect.ValueOf(migrate.AlterMethodNewColumn),
 		"BuildGraph":            reflect.ValueOf(migrate.BuildGraph),
 		"BuildProviderFromType": reflect.ValueOf(migrate.BuildProviderFromType),
+		"DefaultRunMetadata":    reflect.ValueOf(migrate.DefaultRunMetadata),
+		"DirectionDown":         reflect.ValueOf(migrate.DirectionDown),
+		"DirectionUp":           reflect.ValueOf(migrate.DirectionUp),
 		"EnvOr":                 reflect.ValueOf(migrate.EnvOr),
 		"FormatLiteral":         reflect.ValueOf(migrate.FormatLiteral),
 		"GlobalRegistry":        reflect.ValueOf(migrate.GlobalRegistry),
 		"NewApp":                reflect.ValueOf(migrate.NewApp),
 		"NewAppWithRegistry":    reflect.ValueOf(migrate.NewAppWithRegistry),
 		"NewMigrationRecorder":  reflect.ValueOf(migrate.NewMigrationRecorder),
+		"NewMigrator":           reflect.ValueOf(migrate.NewMigrator),
 		"NewRegistry":           reflect.ValueOf(migrate.NewRegistry),
 		"NewRunner":             reflect.ValueOf(migrate.NewRunner),
 		"NewSchemaState":        reflect.ValueOf(migrate.NewSchemaState),
+		"PhaseContract":         reflect.ValueOf(migrate.PhaseContract),
+		"PhaseExpand":           reflect.ValueOf(migrate.PhaseExpand),
 		"Register":              reflect.ValueOf(migrate.Register),
 		"RenderDAGASCII":        reflect.ValueOf(migrate.RenderDAGASCII),
 		"SortedKeys":            reflect.ValueOf(migrate.SortedKeys),
 
 		// type definitions
-		"AddField":             reflect.ValueOf((*migrate.AddField)(nil)),
-		"AddForeignKey":        reflect.ValueOf((*migrate.AddForeignKey)(nil)),
-		"AddIndex":             reflect.ValueOf((*migrate.AddIndex)(nil)),
-		"AlterField":           reflect.ValueOf((*migrate.AlterField)(nil)),
-		"App":                  reflect.ValueOf((*migrate.App)(nil)),
-		"Config":               reflect.ValueOf((*migrate.Config)(nil)),
-		"CreateTable":          reflect.ValueOf((*migrate.CreateTable)(nil)),
-		"DAGOutput":            reflect.ValueOf((*migrate.DAGOutput)(nil)),
-		"DefaultRef":           reflect.ValueOf((*migrate.DefaultRef)(nil)),
-		"DropField":            reflect.ValueOf((*migrate.DropField)(nil)),
-		"DropForeignKey":       reflect.ValueOf((*migrate.DropForeignKey)(nil)),
-		"DropIndex":            reflect.ValueOf((*migrate.DropIndex)(nil)),
-		"DropTable":            reflect.ValueOf((*migrate.DropTable)(nil)),
-		"Field":                reflect.ValueOf((*migrate.Field)(nil)),
-		"ForeignKey":           reflect.ValueOf((*migrate.ForeignKey)(nil)),
-		"ForeignKeyConstraint": reflect.ValueOf((*migrate.ForeignKeyConstraint)(nil)),
-		"Graph":                reflect.ValueOf((*migrate.Graph)(nil)),
-		"Index":                reflect.ValueOf((*migrate.Index)(nil)),
-		"ManyToMany":           reflect.ValueOf((*migrate.ManyToMany)(nil)),
-		"Migration":            reflect.ValueOf((*migrate.Migration)(nil)),
-		"MigrationRecorder":    reflect.ValueOf((*migrate.MigrationRecorder)(nil)),
-		"MigrationSummary":     reflect.ValueOf((*migrate.MigrationSummary)(nil)),
-		"Operation":            reflect.ValueOf((*migrate.Operation)(nil)),
-		"OperationSummary":     reflect.ValueOf((*migrate.OperationSummary)(nil)),
-		"Registry":             reflect.ValueOf((*migrate.Registry)(nil)),
-		"RenameField":          reflect.ValueOf((*migrate.RenameField)(nil)),
-		"RenameTable":          reflect.ValueOf((*migrate.RenameTable)(nil)),
-		"RunOptions":           reflect.ValueOf((*migrate.RunOptions)(nil)),
-		"RunSQL":               reflect.ValueOf((*migrate.RunSQL)(nil)),
-		"Runner":               reflect.ValueOf((*migrate.Runner)(nil)),
-		"SchemaState":          reflect.ValueOf((*migrate.SchemaState)(nil)),
-		"SetDefaults":          reflect.ValueOf((*migrate.SetDefaults)(nil)),
-		"SetTypeMappings":      reflect.ValueOf((*migrate.SetTypeMappings)(nil)),
-		"TableState":           reflect.ValueOf((*migrate.TableState)(nil)),
-		"UpsertData":           reflect.ValueOf((*migrate.UpsertData)(nil)),
+		"AddField":               reflect.ValueOf((*migrate.AddField)(nil)),
+		"AddForeignKey":          reflect.ValueOf((*migrate.AddForeignKey)(nil)),
+		"AddIndex":               reflect.ValueOf((*migrate.AddIndex)(nil)),
+		"AddUniqueConstraint":    reflect.ValueOf((*migrate.AddUniqueConstraint)(nil)),
+		"AlterField":             reflect.ValueOf((*migrate.AlterField)(nil)),
+		"AlterSequence":          reflect.ValueOf((*migrate.AlterSequence)(nil)),
+		"App":                    reflect.ValueOf((*migrate.App)(nil)),
+		"Backfill":               reflect.ValueOf((*migrate.Backfill)(nil)),
+		"Config":                 reflect.ValueOf((*migrate.Config)(nil)),
+		"CreateFunction":         reflect.ValueOf((*migrate.CreateFunction)(nil)),
+		"CreateSequence":         reflect.ValueOf((*migrate.CreateSequence)(nil)),
+		"CreateTable":            reflect.ValueOf((*migrate.CreateTable)(nil)),
+		"CreateTrigger":          reflect.ValueOf((*migrate.CreateTrigger)(nil)),
+		"DAGOutput":              reflect.ValueOf((*migrate.DAGOutput)(nil)),
+		"DefaultRef":             reflect.ValueOf((*migrate.DefaultRef)(nil)),
+		"Direction":              reflect.ValueOf((*migrate.Direction)(nil)),
+		"DownOptions":            reflect.ValueOf((*migrate.DownOptions)(nil)),
+		"DropField":              reflect.ValueOf((*migrate.DropField)(nil)),
+		"DropForeignKey":         reflect.ValueOf((*migrate.DropForeignKey)(nil)),
+		"DropFunction":           reflect.ValueOf((*migrate.DropFunction)(nil)),
+		"DropIndex":              reflect.ValueOf((*migrate.DropIndex)(nil)),
+		"DropSequence":           reflect.ValueOf((*migrate.DropSequence)(nil)),
+		"DropTable":              reflect.ValueOf((*migrate.DropTable)(nil)),
+		"DropTrigger":            reflect.ValueOf((*migrate.DropTrigger)(nil)),
+		"DropUniqueConstraint":   reflect.ValueOf((*migrate.DropUniqueConstraint)(nil)),
+		"Field":                  reflect.ValueOf((*migrate.Field)(nil)),
+		"ForeignKey":             reflect.ValueOf((*migrate.ForeignKey)(nil)),
+		"ForeignKeyConstraint":   reflect.ValueOf((*migrate.ForeignKeyConstraint)(nil)),
+		"Function":               reflect.ValueOf((*migrate.Function)(nil)),
+		"Graph":                  reflect.ValueOf((*migrate.Graph)(nil)),
+		"HistoryEntry":           reflect.ValueOf((*migrate.HistoryEntry)(nil)),
+		"Hook":                   reflect.ValueOf((*migrate.Hook)(nil)),
+		"HookEvent":              reflect.ValueOf((*migrate.HookEvent)(nil)),
+		"Index":                  reflect.ValueOf((*migrate.Index)(nil)),
+		"ManyToMany":             reflect.ValueOf((*migrate.ManyToMany)(nil)),
+		"Migration":              reflect.ValueOf((*migrate.Migration)(nil)),
+		"MigrationRecorder":      reflect.ValueOf((*migrate.MigrationRecorder)(nil)),
+		"MigrationResult":        reflect.ValueOf((*migrate.MigrationResult)(nil)),
+		"MigrationSQL":           reflect.ValueOf((*migrate.MigrationSQL)(nil)),
+		"MigrationStatus":        reflect.ValueOf((*migrate.MigrationStatus)(nil)),
+		"MigrationSummary":       reflect.ValueOf((*migrate.MigrationSummary)(nil)),
+		"Migrator":               reflect.ValueOf((*migrate.Migrator)(nil)),
+		"NoopHook":               reflect.ValueOf((*migrate.NoopHook)(nil)),
+		"Operation":              reflect.ValueOf((*migrate.Operation)(nil)),
+		"OperationSummary":       reflect.ValueOf((*migrate.OperationSummary)(nil)),
+		"OutOfOrderError":        reflect.ValueOf((*migrate.OutOfOrderError)(nil)),
+		"Registry":               reflect.ValueOf((*migrate.Registry)(nil)),
+		"RenameField":            reflect.ValueOf((*migrate.RenameField)(nil)),
+		"RenameTable":            reflect.ValueOf((*migrate.RenameTable)(nil)),
+		"ReplaceFunction":        reflect.ValueOf((*migrate.ReplaceFunction)(nil)),
+		"RunMetadata":            reflect.ValueOf((*migrate.RunMetadata)(nil)),
+		"RunOptions":             reflect.ValueOf((*migrate.RunOptions)(nil)),
+		"RunSQL":                 reflect.ValueOf((*migrate.RunSQL)(nil)),
+		"Runner":                 reflect.ValueOf((*migrate.Runner)(nil)),
+		"SQLHooks":               reflect.ValueOf((*migrate.SQLHooks)(nil)),
+		"SchemaState":            reflect.ValueOf((*migrate.SchemaState)(nil)),
+		"Sequence":               reflect.ValueOf((*migrate.Sequence)(nil)),
+		"SetDefaults":            reflect.ValueOf((*migrate.SetDefaults)(nil)),
+		"SetTypeMappings":        reflect.ValueOf((*migrate.SetTypeMappings)(nil)),
+		"TableState":             reflect.ValueOf((*migrate.TableState)(nil)),
+		"Target":                 reflect.ValueOf((*migrate.Target)(nil)),
+		"TargetResult":           reflect.ValueOf((*migrate.TargetResult)(nil)),
+		"TargetsFile":            reflect.ValueOf((*migrate.TargetsFile)(nil)),
+		"TargetsReport":          reflect.ValueOf((*migrate.TargetsReport)(nil)),
+		"Timeouts":               reflect.ValueOf((*migrate.Timeouts)(nil)),
+		"Trigger":                reflect.ValueOf((*migrate.Trigger)(nil)),
+		"UniqueConstraint":       reflect.ValueOf((*migrate.UniqueConstraint)(nil)),
+		"UnknownMigrationsError": reflect.ValueOf((*migrate.UnknownMigrationsError)(nil)),
+		"UpOptions":              reflect.ValueOf((*migrate.UpOptions)(nil)),
+		"UpsertData":             reflect.ValueOf((*migrate.UpsertData)(nil)),
 
 		// interface wrapper definitions
+		"_Hook":      reflect.ValueOf((*_github_com_ocomsoft_makemigrations_migrate_Hook)(nil)),
 		"_Operation": reflect.ValueOf((*_github_com_ocomsoft_makemigrations_migrate_Operation)(nil)),
 	}
 }
 
+// _github_com_ocomsoft_makemigrations_migrate_Hook is an interface wrapper for Hook type
+type _github_com_ocomsoft_makemigrations_migrate_Hook struct {
+	IValue           interface{}
+	WAfterMigration  func(ctx context.Context, tx *sql.Tx, ev migrate.HookEvent) error
+	WAfterOperation  func(ctx context.Context, tx *sql.Tx, ev migrate.HookEvent) error
+	WBeforeMigration func(ctx context.Context, tx *sql.Tx, ev migrate.HookEvent) error
+	WBeforeOperation func(ctx context.Context, tx *sql.Tx, ev migrate.HookEvent) error
+	WOnError         func(ctx context.Context, ev migrate.HookEvent)
+}
+
+func (W _github_com_ocomsoft_makemigrations_migrate_Hook) AfterMigration(ctx context.Context, tx *sql.Tx, ev migrate.HookEvent) error {
+	return W.WAfterMigration(ctx, tx, ev)
+}
+func (W _github_com_ocomsoft_makemigrations_migrate_Hook) AfterOperation(ctx context.Context, tx *sql.Tx, ev migrate.HookEvent) error {
+	return W.WAfterOperation(ctx, tx, ev)
+}
+func (W _github_com_ocomsoft_makemigrations_migrate_Hook) BeforeMigration(ctx context.Context, tx *sql.Tx, ev migrate.HookEvent) error {
+	return W.WBeforeMigration(ctx, tx, ev)
+}
+func (W _github_com_ocomsoft_makemigrations_migrate_Hook) BeforeOperation(ctx context.Context, tx *sql.Tx, ev migrate.HookEvent) error {
+	return W.WBeforeOperation(ctx, tx, ev)
+}
+func (W _github_com_ocomsoft_makemigrations_migrate_Hook) OnError(ctx context.Context, ev migrate.HookEvent) {
+	W.WOnError(ctx, ev)
+}
+
 // _github_com_ocomsoft_makemigrations_migrate_Operation is an interface wrapper for Operation type
 type _github_com_ocomsoft_makemigrations_migrate_Operation struct {
 	IValue         interface{}
@@ -0,0 +1,481 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/ocomsoft/makemigrations/internal/providers"
+)
+
+// defaultTargetConcurrency is the number of targets migrated at once when
+// neither --concurrency nor the targets file sets it.
+const defaultTargetConcurrency = 4
+
+// Target is one database, or one schema within a database, that the
+// registry's migrations are applied to. Each target keeps its own history
+// table: in its own database, or in its schema via the search path.
+type Target struct {
+	// Name identifies the target in output and reports. Defaults to Schema,
+	// then DSN.
+	Name string `yaml:"name" json:"name"`
+	// DSN is the connection string. Defaults to the app's database URL.
+	DSN string `yaml:"dsn,omitempty" json:"-"`
+	// Schema selects a PostgreSQL schema (via search_path) or a MySQL/TiDB
+	// database within DSN.
+	Schema string `yaml:"schema,omitempty" json:"schema,omitempty"`
+}
+
+// TargetsFile is the format of the file passed to `up --targets`.
+type TargetsFile struct {
+	// Concurrency caps how many targets are migrated at once (default 4).
+	Concurrency int `yaml:"concurrency,omitempty"`
+	// Targets lists the targets explicitly.
+	Targets []Target `yaml:"targets,omitempty"`
+	// Query is run against the default database; the first column of each
+	// row names a schema that becomes a target.
+	Query string `yaml:"query,omitempty"`
+}
+
+// TargetResult is the outcome of migrating one target.
+type TargetResult struct {
+	Target   string        `json:"target"`
+	Schema   string        `json:"schema,omitempty"`
+	OK       bool          `json:"ok"`
+	Applied  []string      `json:"applied"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// TargetsReport is the summary written by `up --report` and read back by
+// `up --only-failed`.
+type TargetsReport struct {
+	Results []TargetResult `json:"results"`
+}
+
+// Failed returns the names of the targets that did not succeed.
+func (r *TargetsReport) Failed() []string {
+	var names []string
+	for _, res := range r.Results {
+		if !res.OK {
+			names = append(names, res.Target)
+		}
+	}
+	return names
+}
+
+// targetsOptions holds the up command's multi-target flags.
+type targetsOptions struct {
+	File        string
+	DSNs        []string
+	Query       string
+	Concurrency int
+	Report      string
+	OnlyFailed  string
+}
+
+// enabled reports whether any target source was given, switching up into
+// multi-target mode.
+func (o targetsOptions) enabled() bool {
+	return o.File != "" || len(o.DSNs) > 0 || o.Query != "" || o.OnlyFailed != ""
+}
+
+// runUpTargets applies pending migrations to every target selected by topts,
+// then prints a summary and optionally writes a report. It returns an error
+// when any target failed; the others are still migrated.
+func (a *App) runUpTargets(ctx context.Context, to string, opts RunOptions, topts targetsOptions) error {
+	targets, concurrency, err := a.resolveTargets(ctx, topts)
+	if err != nil {
+		return err
+	}
+	if topts.OnlyFailed != "" {
+		report, err := loadTargetsReport(topts.OnlyFailed)
+		if err != nil {
+			return err
+		}
+		failed := make(map[string]bool)
+		for _, name := range report.Failed() {
+			failed[name] = true
+		}
+		var retry []Target
+		for _, t := range targets {
+			if failed[t.Name] {
+				retry = append(retry, t)
+			}
+		}
+		targets = retry
+	}
+	if len(targets) == 0 {
+		fmt.Println("No targets to migrate.")
+		return nil
+	}
+
+	results := runTargets(ctx, targets, concurrency, os.Stdout, func(ctx context.Context, t Target, out io.Writer) ([]MigrationResult, error) {
+		return a.upTarget(ctx, t, to, opts, out)
+	})
+	if topts.Report != "" {
+		data, err := json.MarshalIndent(TargetsReport{Results: results}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding targets report: %w", err)
+		}
+		if err := os.WriteFile(topts.Report, append(data, '\n'), 0o600); err != nil {
+			return fmt.Errorf("writing targets report: %w", err)
+		}
+	}
+	return printTargetsSummary(os.Stdout, results)
+}
+
+// resolveTargets collects the targets from the targets file, --target-dsn
+// and the targets query, filling in defaults and rejecting duplicate names.
+func (a *App) resolveTargets(ctx context.Context, topts targetsOptions) ([]Target, int, error) {
+	var targets []Target
+	concurrency := defaultTargetConcurrency
+	query := topts.Query
+	if topts.File != "" {
+		tf, err := loadTargetsFile(topts.File)
+		if err != nil {
+			return nil, 0, err
+		}
+		targets = append(targets, tf.Targets...)
+		if tf.Concurrency > 0 {
+			concurrency = tf.Concurrency
+		}
+		if query == "" {
+			query = tf.Query
+		}
+	}
+	if topts.Concurrency > 0 {
+		concurrency = topts.Concurrency
+	}
+	for _, dsn := range topts.DSNs {
+		targets = append(targets, Target{DSN: dsn})
+	}
+	if query != "" {
+		db, err := a.openDB()
+		if err != nil {
+			return nil, 0, err
+		}
+		found, err := queryTargets(ctx, db, query)
+		_ = db.Close()
+		if err != nil {
+			return nil, 0, err
+		}
+		targets = append(targets, found...)
+	}
+
+	seen := make(map[string]bool)
+	for i := range targets {
+		t := &targets[i]
+		if t.Name == "" {
+			t.Name = t.Schema
+		}
+		if t.Name == "" {
+			t.Name = redactDSN(t.DSN)
+		}
+		if t.Name == "" {
+			return nil, 0, fmt.Errorf("target %d has no name, dsn or schema", i+1)
+		}
+		if seen[t.Name] {
+			return nil, 0, fmt.Errorf("duplicate target %q", t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return targets, concurrency, nil
+}
+
+// upTarget applies pending migrations to a single target while holding its
+// migration lock, writing progress to out.
+func (a *App) upTarget(ctx context.Context, t Target, to string, opts RunOptions, out io.Writer) ([]MigrationResult, error) {
+	dsn := t.DSN
+	if dsn == "" {
+		dsn = a.config.DatabaseURL
+	}
+	if dsn == "" {
+		dsn = buildDSN(a.config)
+	}
+	dsn, err := targetDSN(a.config.DatabaseType, dsn, t.Schema)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(driverName(a.config.DatabaseType), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+	p, err := BuildProviderFromType(a.config.DatabaseType)
+	if err != nil {
+		return nil, err
+	}
+	var applied []MigrationResult
+	err = withTargetLock(ctx, db, p, targetLockKey(a.config.DatabaseType, dsn, t.Schema), func() error {
+		r, err := a.newRunner(ctx, db, out)
+		if err != nil {
+			return err
+		}
+		applied, err = r.up(ctx, to, opts)
+		return err
+	})
+	return applied, err
+}
+
+// redactDSN returns dsn with any password masked, for use as a target name
+// in output and reports. It handles URLs and MySQL's user:pass@ form.
+func redactDSN(dsn string) string {
+	if strings.Contains(dsn, "://") {
+		if u, err := url.Parse(dsn); err == nil {
+			return u.Redacted()
+		}
+	}
+	if at := strings.LastIndex(dsn, "@"); at >= 0 {
+		if colon := strings.Index(dsn[:at], ":"); colon >= 0 {
+			return dsn[:colon+1] + "xxxxx" + dsn[at:]
+		}
+	}
+	return dsn
+}
+
+// loadTargetsFile reads a TargetsFile from path.
+func loadTargetsFile(path string) (*TargetsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading targets file: %w", err)
+	}
+	var tf TargetsFile
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		return nil, fmt.Errorf("parsing targets file %s: %w", path, err)
+	}
+	return &tf, nil
+}
+
+// loadTargetsReport reads a TargetsReport written by a previous run.
+func loadTargetsReport(path string) (*TargetsReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading targets report: %w", err)
+	}
+	var report TargetsReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parsing targets report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// queryTargets runs query on db and returns one target per row, named after
+// the schema in its first column.
+func queryTargets(ctx context.Context, db *sql.DB, query string) ([]Target, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("running targets query: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var targets []Target
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			return nil, fmt.Errorf("scanning targets query: %w", err)
+		}
+		targets = append(targets, Target{Name: schema, Schema: schema})
+	}
+	return targets, rows.Err()
+}
+
+// targetDSN returns dsn adjusted to select schema: a search_path runtime
+// parameter on PostgreSQL, or the database name on MySQL and TiDB.
+func targetDSN(dbType, dsn, schema string) (string, error) {
+	if schema == "" {
+		return dsn, nil
+	}
+	switch dbType {
+	case "postgresql", "postgres", "redshift", "auroradsql":
+		if strings.Contains(dsn, "://") {
+			u, err := url.Parse(dsn)
+			if err != nil {
+				return "", fmt.Errorf("parsing database URL: %w", err)
+			}
+			q := u.Query()
+			q.Set("search_path", schema)
+			u.RawQuery = q.Encode()
+			return u.String(), nil
+		}
+		return strings.TrimSpace(dsn) + " search_path=" + schema, nil
+	case "mysql", "tidb":
+		prefix, _, params, ok := splitMySQLDSN(dsn)
+		if !ok {
+			return "", fmt.Errorf("cannot select database %q: DSN has no /dbname part", schema)
+		}
+		return prefix + schema + params, nil
+	default:
+		return "", fmt.Errorf("schema targets are not supported for %s", dbType)
+	}
+}
+
+// splitMySQLDSN splits a MySQL DSN of the form user:pass@tcp(host)/dbname?params
+// into everything up to and including the slash, the database name, and the
+// parameters including their leading "?". Parameters are cut off first, so a
+// slash in a value such as loc=Europe/Berlin is not taken for the database
+// separator. ok is false when the DSN has no /dbname part.
+func splitMySQLDSN(dsn string) (prefix, dbname, params string, ok bool) {
+	base := dsn
+	// Look for "?" only after the credentials, which may contain one.
+	at := strings.LastIndex(dsn, "@") + 1
+	if q := strings.Index(dsn[at:], "?"); q >= 0 {
+		base, params = dsn[:at+q], dsn[at+q:]
+	}
+	slash := strings.LastIndex(base, "/")
+	if slash < 0 {
+		return "", "", "", false
+	}
+	return base[:slash+1], base[slash+1:], params, true
+}
+
+// targetLockKey returns the name of the migration lock for a target
+// connecting with dsn. MySQL and TiDB named locks are server-wide, so there
+// the key carries the database the DSN selects; elsewhere locks are scoped to
+// the database already and the key only needs the schema.
+func targetLockKey(dbType, dsn, schema string) string {
+	if dbType == "mysql" || dbType == "tidb" {
+		if _, dbname, _, ok := splitMySQLDSN(dsn); ok && dbname != "" {
+			schema = dbname
+		}
+	}
+	if schema == "" {
+		return "makemigrations"
+	}
+	return "makemigrations:" + schema
+}
+
+// withTargetLock runs fn while holding the provider's named lock key on a
+// dedicated connection. Providers without named locks run fn directly.
+func withTargetLock(ctx context.Context, db *sql.DB, p providers.Provider, key string, fn func() error) error {
+	lp, ok := p.(providers.MigrationLockProvider)
+	if !ok {
+		return fn()
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring lock connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err := conn.ExecContext(ctx, lp.GenerateLock(key)); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer func() { _, _ = conn.ExecContext(context.Background(), lp.GenerateUnlock(key)) }()
+	return fn()
+}
+
+// runTargets calls run for each target with at most concurrency running at
+// once. Each target's output is buffered and written to out as a block when
+// it finishes, so parallel runs do not interleave. Results are returned in
+// target order.
+func runTargets(ctx context.Context, targets []Target, concurrency int, out io.Writer,
+	run func(ctx context.Context, t Target, out io.Writer) ([]MigrationResult, error)) []TargetResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]TargetResult, len(targets))
+	sem := make(chan struct{}, concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var buf bytes.Buffer
+			started := time.Now()
+			applied, err := run(ctx, t, &buf)
+			res := TargetResult{Target: t.Name, Schema: t.Schema, OK: err == nil, Applied: []string{}, Duration: time.Since(started)}
+			for _, a := range applied {
+				res.Applied = append(res.Applied, a.Name)
+			}
+			if err != nil {
+				res.Error = err.Error()
+			}
+			results[i] = res
+
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = fmt.Fprintf(out, "== %s ==\n", t.Name)
+			_, _ = out.Write(buf.Bytes())
+			if err != nil {
+				_, _ = fmt.Fprintf(out, "Error: %v\n", err)
+			}
+		}(i, t)
+	}
+	wg.Wait()
+	return results
+}
+
+// printTargetsSummary writes one line per target and returns an error when
+// any target failed.
+func printTargetsSummary(out io.Writer, results []TargetResult) error {
+	failed := 0
+	width := 0
+	for _, res := range results {
+		if len(res.Target) > width {
+			width = len(res.Target)
+		}
+		if !res.OK {
+			failed++
+		}
+	}
+	_, _ = fmt.Fprintf(out, "\nSummary: %d target(s), %d succeeded, %d failed\n", len(results), len(results)-failed, failed)
+	sorted := append([]TargetResult(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool { return !sorted[i].OK && sorted[j].OK })
+	for _, res := range sorted {
+		if res.OK {
+			_, _ = fmt.Fprintf(out, "  %-*s  ok      %d applied\n", width, res.Target, len(res.Applied))
+		} else {
+			_, _ = fmt.Fprintf(out, "  %-*s  FAILED  %s\n", width, res.Target, firstLine(res.Error))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d target(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+// firstLine returns s up to its first newline.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,157 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package migrate_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+// tenantRegistry creates the users table and then a second table.
+func tenantRegistry() *migrate.Registry {
+	return testRegistry(
+		&migrate.Migration{Name: "0001_initial", Operations: []migrate.Operation{idTable("users")}},
+		&migrate.Migration{Name: "0002_orders", Operations: []migrate.Operation{idTable("orders")}},
+	)
+}
+
+// execSQLite runs stmt against the SQLite file at path.
+func execSQLite(t *testing.T, path, stmt string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("opening %s: %v", path, err)
+	}
+	defer func() { _ = db.Close() }()
+	if _, err := db.Exec(stmt); err != nil {
+		t.Fatalf("exec %q: %v", stmt, err)
+	}
+}
+
+func readReport(t *testing.T, path string) migrate.TargetsReport {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading report: %v", err)
+	}
+	var report migrate.TargetsReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("parsing report: %v", err)
+	}
+	return report
+}
+
+func TestApp_UpTargets_ReportAndOnlyFailed(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.db")
+	b := filepath.Join(dir, "b.db")
+	c := filepath.Join(dir, "c.db")
+	// b already has an orders table, so 0002_orders fails there.
+	execSQLite(t, b, "CREATE TABLE orders (id integer)")
+
+	targetsFile := filepath.Join(dir, "targets.yaml")
+	yaml := "concurrency: 2\ntargets:\n  - name: a\n    dsn: " + a + "\n  - name: b\n    dsn: " + b + "\n  - name: c\n    dsn: " + c + "\n"
+	if err := os.WriteFile(targetsFile, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	reportPath := filepath.Join(dir, "report.json")
+	defer suppressStdout(t)()
+
+	cfg := migrate.Config{DatabaseType: "sqlite"}
+	err := migrate.NewAppWithRegistry(cfg, tenantRegistry()).Run([]string{"up", "--targets", targetsFile, "--report", reportPath})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 target(s) failed") {
+		t.Fatalf("expected one failed target, got %v", err)
+	}
+	report := readReport(t, reportPath)
+	if len(report.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(report.Results))
+	}
+	if failed := report.Failed(); len(failed) != 1 || failed[0] != "b" {
+		t.Fatalf("expected only b to fail, got %v", failed)
+	}
+	for _, res := range report.Results {
+		if res.Target == "b" {
+			if len(res.Applied) != 1 || res.Applied[0] != "0001_initial" || res.Error == "" {
+				t.Errorf("b: expected 0001_initial applied and an error, got %+v", res)
+			}
+		} else if !res.OK || len(res.Applied) != 2 {
+			t.Errorf("%s: expected both migrations applied, got %+v", res.Target, res)
+		}
+	}
+
+	// Fix b and retry only the failed target.
+	execSQLite(t, b, "DROP TABLE orders")
+	retryReport := filepath.Join(dir, "retry.json")
+	err = migrate.NewAppWithRegistry(cfg, tenantRegistry()).Run([]string{"up", "--targets", targetsFile, "--only-failed", reportPath, "--report", retryReport})
+	if err != nil {
+		t.Fatalf("retrying failed targets: %v", err)
+	}
+	retry := readReport(t, retryReport)
+	if len(retry.Results) != 1 || retry.Results[0].Target != "b" || !retry.Results[0].OK {
+		t.Fatalf("expected only b to be retried successfully, got %+v", retry.Results)
+	}
+	if got := retry.Results[0].Applied; len(got) != 1 || got[0] != "0002_orders" {
+		t.Errorf("expected 0002_orders applied on retry, got %v", got)
+	}
+}
+
+func TestApp_UpTargets_DSNFlags(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.db")
+	b := filepath.Join(dir, "b.db")
+	reportPath := filepath.Join(dir, "report.json")
+	defer suppressStdout(t)()
+
+	cfg := migrate.Config{DatabaseType: "sqlite"}
+	err := migrate.NewAppWithRegistry(cfg, tenantRegistry()).Run([]string{"up", "--target-dsn", a, "--target-dsn", b, "--concurrency", "1", "--report", reportPath})
+	if err != nil {
+		t.Fatalf("up --target-dsn: %v", err)
+	}
+	report := readReport(t, reportPath)
+	if len(report.Results) != 2 || report.Results[0].Target != a || report.Results[1].Target != b {
+		t.Fatalf("expected results for %s and %s in order, got %+v", a, b, report.Results)
+	}
+}
+
+func TestApp_UpTargets_SchemaUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	targetsFile := filepath.Join(dir, "targets.yaml")
+	if err := os.WriteFile(targetsFile, []byte("targets:\n  - schema: tenant_a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	defer suppressStdout(t)()
+
+	cfg := migrate.Config{DatabaseType: "sqlite", DBName: filepath.Join(dir, "main.db")}
+	err := migrate.NewAppWithRegistry(cfg, tenantRegistry()).Run([]string{"up", "--targets", targetsFile})
+	if err == nil {
+		t.Fatal("expected schema targets to fail on SQLite")
+	}
+}
@@ -0,0 +1,148 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/ocomsoft/makemigrations/internal/providers"
+)
+
+// defaultLockRetryBackoff is the delay before the first lock-timeout retry
+// when Timeouts.LockRetryBackoff is unset.
+const defaultLockRetryBackoff = time.Second
+
+// maxLockRetryBackoff caps the exponential backoff between retries.
+const maxLockRetryBackoff = 30 * time.Second
+
+// Timeouts bound how long migration statements may run and wait for locks,
+// and how migrations that hit a lock timeout are retried. Zero values keep
+// the database defaults and disable retries.
+type Timeouts struct {
+	// StatementTimeout caps the run time of each statement.
+	StatementTimeout time.Duration
+	// LockTimeout caps how long a statement waits to acquire a lock, so an
+	// ALTER TABLE queued behind a long transaction fails instead of
+	// blocking every query that queues behind it.
+	LockTimeout time.Duration
+	// LockRetries is how many more times a migration that failed with a
+	// lock timeout is attempted.
+	LockRetries int
+	// LockRetryBackoff is the delay before the first retry. It doubles with
+	// each further attempt, up to 30 seconds. Defaults to one second.
+	LockRetryBackoff time.Duration
+}
+
+// timeoutSQL returns the statements that apply the timeouts for mig, whose
+// own StatementTimeout and LockTimeout take precedence over opts. A warning
+// is recorded on res when timeouts are set but the provider cannot apply them.
+func (r *Runner) timeoutSQL(mig *Migration, opts RunOptions, res *MigrationResult) []string {
+	statement, lock := migrationTimeouts(mig, opts)
+	if statement <= 0 && lock <= 0 {
+		return nil
+	}
+	tp, ok := r.provider.(providers.TimeoutProvider)
+	if !ok {
+		r.warn(res, "statement and lock timeouts are not supported by this database, ignoring them")
+		return nil
+	}
+	return tp.GenerateTimeouts(statement, lock)
+}
+
+// migrationTimeouts returns the statement and lock timeouts for mig: its own,
+// or those of opts where mig sets none.
+func migrationTimeouts(mig *Migration, opts RunOptions) (statement, lock time.Duration) {
+	statement, lock = opts.StatementTimeout, opts.LockTimeout
+	if mig.StatementTimeout > 0 {
+		statement = mig.StatementTimeout
+	}
+	if mig.LockTimeout > 0 {
+		lock = mig.LockTimeout
+	}
+	return statement, lock
+}
+
+// releaseConn undoes any session timeouts applied for mig on conn and
+// returns conn to the pool, so they do not carry over to later migrations,
+// history writes or other users of the *sql.DB. A connection whose settings
+// could not be restored is discarded instead.
+func (r *Runner) releaseConn(conn *sql.Conn, mig *Migration, opts RunOptions) {
+	if tp, ok := r.provider.(providers.TimeoutProvider); ok {
+		for _, stmt := range tp.GenerateResetTimeouts(migrationTimeouts(mig, opts)) {
+			if _, err := conn.ExecContext(context.Background(), stmt); err != nil {
+				_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+				break
+			}
+		}
+	}
+	_ = conn.Close()
+}
+
+// execTimeouts applies the timeouts for mig inside tx.
+func (r *Runner) execTimeouts(ctx context.Context, tx *sql.Tx, mig *Migration, opts RunOptions, res *MigrationResult) error {
+	if err := execHookSQL(ctx, tx, r.timeoutSQL(mig, opts, res)); err != nil {
+		return fmt.Errorf("setting timeouts: %w", err)
+	}
+	return nil
+}
+
+// isLockTimeout reports whether err is a lock timeout on the active provider.
+func (r *Runner) isLockTimeout(err error) bool {
+	tp, ok := r.provider.(providers.TimeoutProvider)
+	return ok && tp.IsLockTimeoutError(err)
+}
+
+// withLockRetry calls attempt until it succeeds, fails with anything other
+// than a lock timeout, or opts.LockRetries retries have been used, sleeping
+// with exponential backoff in between. res.Attempts is set to the number of
+// attempts made, and warnings from failed attempts are discarded.
+func (r *Runner) withLockRetry(ctx context.Context, opts RunOptions, res *MigrationResult, attempt func() error) error {
+	backoff := opts.LockRetryBackoff
+	if backoff <= 0 {
+		backoff = defaultLockRetryBackoff
+	}
+	warnings := len(res.Warnings)
+	for n := 1; ; n++ {
+		res.Attempts = n
+		res.Warnings = res.Warnings[:warnings]
+		err := attempt()
+		if err == nil || n > opts.LockRetries || !r.isLockTimeout(err) {
+			return err
+		}
+		r.printf(" lock timeout (attempt %d/%d), retrying in %s...", n, opts.LockRetries+1, backoff)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (while waiting to retry after: %v)", ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxLockRetryBackoff {
+			backoff = maxLockRetryBackoff
+		}
+	}
+}
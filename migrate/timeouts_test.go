@@ -0,0 +1,227 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ocomsoft/makemigrations/internal/providers/sqlite"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+// releaseOnError commits the lock-holding transaction when a migration
+// fails, so the runner's retry finds the database free.
+type releaseOnError struct {
+	migrate.NoopHook
+	holder *sql.Tx
+	errs   []error
+}
+
+func (h *releaseOnError) OnError(_ context.Context, ev migrate.HookEvent) {
+	h.errs = append(h.errs, ev.Err)
+	if h.holder != nil {
+		_ = h.holder.Commit()
+		h.holder = nil
+	}
+}
+
+// lockedRunner returns a runner on a file database whose write lock is held
+// by another connection's open transaction.
+func lockedRunner(t *testing.T, reg *migrate.Registry) (*migrate.Runner, *sql.Tx, *bytes.Buffer) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "locked.db")
+	open := func() *sql.DB {
+		db, err := sql.Open("sqlite3", path)
+		if err != nil {
+			t.Fatalf("opening SQLite: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		return db
+	}
+	db := open()
+	recorder := migrate.NewMigrationRecorder(db, sqlite.New())
+	if err := recorder.EnsureTable(); err != nil {
+		t.Fatalf("EnsureTable: %v", err)
+	}
+	g, err := migrate.BuildGraph(reg)
+	if err != nil {
+		t.Fatalf("BuildGraph: %v", err)
+	}
+	var out bytes.Buffer
+	runner := migrate.NewRunner(g, sqlite.New(), db, recorder, &out)
+
+	holder, err := open().Begin()
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	t.Cleanup(func() { _ = holder.Rollback() })
+	if _, err := holder.Exec("CREATE TABLE lock_holder (id integer)"); err != nil {
+		t.Fatalf("taking write lock: %v", err)
+	}
+	return runner, holder, &out
+}
+
+func lockRegistry(lockTimeout time.Duration) *migrate.Registry {
+	return testRegistry(&migrate.Migration{Name: "0001_initial", LockTimeout: lockTimeout, Operations: []migrate.Operation{idTable("users")}})
+}
+
+func TestRunner_RetriesLockTimeout(t *testing.T) {
+	// The migration's own LockTimeout overrides the run-wide one, which
+	// would otherwise wait far longer than the test.
+	runner, holder, out := lockedRunner(t, lockRegistry(10*time.Millisecond))
+	hook := &releaseOnError{holder: holder}
+	runner.AddHook(hook)
+
+	opts := migrate.RunOptions{Timeouts: migrate.Timeouts{
+		LockTimeout:      time.Hour,
+		LockRetries:      2,
+		LockRetryBackoff: time.Millisecond,
+	}}
+	if err := runner.UpContext(context.Background(), "", opts); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(hook.errs) != 1 || !strings.Contains(hook.errs[0].Error(), "database is locked") {
+		t.Fatalf("expected one lock timeout before the retry, got %v", hook.errs)
+	}
+	got := out.String()
+	if !strings.Contains(got, "lock timeout (attempt 1/3), retrying in 1ms...") || !strings.Contains(got, "done (attempt 2)") {
+		t.Fatalf("unexpected output: %q", got)
+	}
+}
+
+func TestRunner_LockTimeoutWithoutRetries(t *testing.T) {
+	runner, _, _ := lockedRunner(t, lockRegistry(0))
+	opts := migrate.RunOptions{Timeouts: migrate.Timeouts{LockTimeout: 10 * time.Millisecond}}
+	err := runner.UpContext(context.Background(), "", opts)
+	if err == nil || !strings.Contains(err.Error(), "database is locked") {
+		t.Fatalf("expected lock timeout, got %v", err)
+	}
+}
+
+// busyTimeoutHook records the connection's busy timeout at the start of
+// each migration.
+type busyTimeoutHook struct {
+	migrate.NoopHook
+	seen map[string]int
+}
+
+func (h *busyTimeoutHook) BeforeMigration(ctx context.Context, tx *sql.Tx, ev migrate.HookEvent) error {
+	var ms int
+	if err := tx.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&ms); err != nil {
+		return err
+	}
+	h.seen[ev.Migration.Name] = ms
+	return nil
+}
+
+func TestRunner_LockTimeoutDoesNotCarryOver(t *testing.T) {
+	reg := lockRegistry(10 * time.Millisecond)
+	reg.Register(&migrate.Migration{
+		Name:         "0002_orders",
+		Dependencies: []string{"0001_initial"},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{Name: "orders", Fields: []migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}}},
+		},
+	})
+	runner, _, db := buildTestRunner(t, reg)
+	db.SetMaxOpenConns(1) // both migrations run on the same connection
+	hook := &busyTimeoutHook{seen: make(map[string]int)}
+	runner.AddHook(hook)
+
+	if err := runner.UpContext(context.Background(), "", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if hook.seen["0001_initial"] != 10 || hook.seen["0002_orders"] != 5000 {
+		t.Errorf("expected busy timeouts 10 then the default 5000, got %v", hook.seen)
+	}
+	var ms int
+	if err := db.QueryRow("PRAGMA busy_timeout").Scan(&ms); err != nil {
+		t.Fatalf("reading busy_timeout: %v", err)
+	}
+	if ms != 5000 {
+		t.Errorf("expected the pooled connection back at 5000ms, got %d", ms)
+	}
+}
+
+func TestSchemaState_Clone(t *testing.T) {
+	state := migrate.NewSchemaState()
+	fields := []migrate.Field{
+		{Name: "id", Type: "integer", PrimaryKey: true},
+		{Name: "team_id", Type: "foreign_key", ForeignKey: &migrate.ForeignKey{Table: "teams", OnDelete: "CASCADE"}},
+	}
+	if err := state.AddTable("users", fields, []migrate.Index{{Name: "idx_users_id", Fields: []string{"id"}}}); err != nil {
+		t.Fatalf("AddTable: %v", err)
+	}
+
+	c := state.Clone()
+	if err := c.DropIndex("users", "idx_users_id"); err != nil {
+		t.Fatalf("DropIndex: %v", err)
+	}
+	c.Tables["users"].Fields[1].ForeignKey.OnDelete = "SET NULL"
+	if err := c.AddTable("posts", nil, nil); err != nil {
+		t.Fatalf("AddTable: %v", err)
+	}
+
+	users := state.Tables["users"]
+	if len(users.Indexes) != 1 || users.Fields[1].ForeignKey.OnDelete != "CASCADE" || state.Tables["posts"] != nil {
+		t.Fatalf("mutating the clone changed the original: %+v", users)
+	}
+}
+
+func TestSchemaState_CloneUniqueConstraints(t *testing.T) {
+	state := migrate.NewSchemaState()
+	fields := []migrate.Field{{Name: "id", Type: "integer", PrimaryKey: true}, {Name: "email", Type: "varchar"}}
+	if err := state.AddTable("users", fields, nil); err != nil {
+		t.Fatalf("AddTable: %v", err)
+	}
+	if err := state.AddUniqueConstraint("users", migrate.UniqueConstraint{Name: "uq_users_email", Fields: []string{"email"}}); err != nil {
+		t.Fatalf("AddUniqueConstraint: %v", err)
+	}
+
+	c := state.Clone()
+	if err := c.AddIndex("users", migrate.Index{Name: "idx_users_email", Fields: []string{"email"}}); err != nil {
+		t.Fatalf("AddIndex: %v", err)
+	}
+	c.Tables["users"].UniqueConstraints[0].Fields[0] = "login"
+
+	if got := state.Tables["users"].UniqueConstraints[0].Fields[0]; got != "email" {
+		t.Fatalf("mutating the clone's unique constraint changed the original to %q", got)
+	}
+
+	// The clone took its own copy, so the original is no longer shared and
+	// changes its table in place.
+	users := state.Tables["users"]
+	if err := state.AddIndex("users", migrate.Index{Name: "idx_users_id", Fields: []string{"id"}}); err != nil {
+		t.Fatalf("AddIndex: %v", err)
+	}
+	if state.Tables["users"] != users {
+		t.Error("expected the original to stop copying once the clone has its own table")
+	}
+}
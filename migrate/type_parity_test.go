@@ -42,10 +42,14 @@ func TestFieldStructParity(t *testing.T) {
 	//   Nullable:   bool in migrate.Field vs *bool in types.Field (public API constraint)
 	//   ForeignKey: *migrate.ForeignKey vs *types.ForeignKey (separate FK types)
 	//   ManyToMany: *migrate.ManyToMany vs *types.ManyToMany (separate M2M types)
+	//   Overrides:  per-database YAML overrides, resolved before migrations are generated
+	//   Unique:     YAML shorthand, expanded into a UniqueConstraint before migrations are generated
 	exceptions := map[string]bool{
 		"Nullable":   true,
 		"ForeignKey": true,
 		"ManyToMany": true,
+		"Overrides":  true,
+		"Unique":     true,
 	}
 
 	migrateType := reflect.TypeOf(Field{})
@@ -81,8 +85,10 @@ func TestIndexStructParity(t *testing.T) {
 	//   ForeignKey: informational annotation on types.Index only (YAML concern,
 	//               indicates which FK relationship the index supports — does not
 	//               affect SQL generation and is not needed at runtime).
+	//   Overrides:  per-database YAML overrides, resolved before migrations are generated.
 	exceptions := map[string]bool{
 		"ForeignKey": true,
+		"Overrides":  true,
 	}
 
 	migrateType := reflect.TypeOf(Index{})
@@ -166,3 +172,89 @@ func TestManyToManyStructParity(t *testing.T) {
 		}
 	}
 }
+
+// TestSequenceStructParity verifies that migrate.Sequence and
+// types.Sequence have the same exported fields.
+func TestSequenceStructParity(t *testing.T) {
+	migrateType := reflect.TypeOf(Sequence{})
+	typesType := reflect.TypeOf(types.Sequence{})
+
+	for i := 0; i < typesType.NumField(); i++ {
+		field := typesType.Field(i)
+		if _, ok := migrateType.FieldByName(field.Name); !ok {
+			t.Errorf("types.Sequence has field %q but migrate.Sequence does not — add it to migrate.Sequence", field.Name)
+		}
+	}
+
+	for i := 0; i < migrateType.NumField(); i++ {
+		field := migrateType.Field(i)
+		if _, ok := typesType.FieldByName(field.Name); !ok {
+			t.Errorf("migrate.Sequence has field %q but types.Sequence does not — add it to types.Sequence", field.Name)
+		}
+	}
+}
+
+// TestFunctionStructParity verifies that migrate.Function and types.Function have the
+// same exported fields. Body differs in type only: migrate holds the active
+// database's body while types holds one body per database.
+func TestFunctionStructParity(t *testing.T) {
+	migrateType := reflect.TypeOf(Function{})
+	typesType := reflect.TypeOf(types.Function{})
+
+	for i := 0; i < typesType.NumField(); i++ {
+		field := typesType.Field(i)
+		if _, ok := migrateType.FieldByName(field.Name); !ok {
+			t.Errorf("types.Function has field %q but migrate.Function does not — add it to migrate.Function", field.Name)
+		}
+	}
+
+	for i := 0; i < migrateType.NumField(); i++ {
+		field := migrateType.Field(i)
+		if _, ok := typesType.FieldByName(field.Name); !ok {
+			t.Errorf("migrate.Function has field %q but types.Function does not — add it to types.Function", field.Name)
+		}
+	}
+}
+
+// TestTriggerStructParity verifies that migrate.Trigger and types.Trigger have the
+// same exported fields. Body differs in type only: migrate holds the active
+// database's body while types holds one body per database.
+func TestTriggerStructParity(t *testing.T) {
+	migrateType := reflect.TypeOf(Trigger{})
+	typesType := reflect.TypeOf(types.Trigger{})
+
+	for i := 0; i < typesType.NumField(); i++ {
+		field := typesType.Field(i)
+		if _, ok := migrateType.FieldByName(field.Name); !ok {
+			t.Errorf("types.Trigger has field %q but migrate.Trigger does not — add it to migrate.Trigger", field.Name)
+		}
+	}
+
+	for i := 0; i < migrateType.NumField(); i++ {
+		field := migrateType.Field(i)
+		if _, ok := typesType.FieldByName(field.Name); !ok {
+			t.Errorf("migrate.Trigger has field %q but types.Trigger does not — add it to types.Trigger", field.Name)
+		}
+	}
+}
+
+// TestUniqueConstraintStructParity verifies that migrate.UniqueConstraint and
+// types.UniqueConstraint have the same exported fields.
+func TestUniqueConstraintStructParity(t *testing.T) {
+	migrateType := reflect.TypeOf(UniqueConstraint{})
+	typesType := reflect.TypeOf(types.UniqueConstraint{})
+
+	for i := 0; i < typesType.NumField(); i++ {
+		field := typesType.Field(i)
+		if _, ok := migrateType.FieldByName(field.Name); !ok {
+			t.Errorf("types.UniqueConstraint has field %q but migrate.UniqueConstraint does not — add it to migrate.UniqueConstraint", field.Name)
+		}
+	}
+
+	for i := 0; i < migrateType.NumField(); i++ {
+		field := migrateType.Field(i)
+		if _, ok := typesType.FieldByName(field.Name); !ok {
+			t.Errorf("migrate.UniqueConstraint has field %q but types.UniqueConstraint does not — add it to types.UniqueConstraint", field.Name)
+		}
+	}
+}
@@ -26,12 +26,25 @@ SOFTWARE.
 // Generated migration files import this package and call Register() in their init() functions.
 package migrate
 
+import "time"
+
 // Migration represents a single database migration with its name, dependencies, and operations.
 type Migration struct {
 	Name         string      `json:"name"`               // Unique identifier e.g. "0001_initial"
 	Dependencies []string    `json:"dependencies"`       // Names of migrations this depends on
 	Operations   []Operation `json:"-"`                  // Ordered list of schema operations to apply
 	Replaces     []string    `json:"replaces,omitempty"` // For squashed migrations: names of migrations this replaces
+	// StatementTimeout and LockTimeout override RunOptions.Timeouts for this
+	// migration when non-zero, e.g. to give a large backfill more time.
+	StatementTimeout time.Duration `json:"statement_timeout,omitempty"`
+	LockTimeout      time.Duration `json:"lock_timeout,omitempty"`
+	// Phase tags the migration as one half of an expand/contract pair
+	// (PhaseExpand or PhaseContract) so `migrate up --phase` can apply the
+	// halves at different deploy stages. Empty for ordinary migrations.
+	Phase string `json:"phase,omitempty"`
+	// Draft marks a migration still being iterated on. `generate --amend`
+	// rewrites a draft even after it has been applied to a dev database.
+	Draft bool `json:"draft,omitempty"`
 }
 
 // Field represents a database column definition used in migration operations.
@@ -48,6 +61,8 @@ type Field struct {
 	AutoUpdate bool        `json:"auto_update,omitempty"` // auto-set on row update (updated_at)
 	ForeignKey *ForeignKey `json:"foreign_key,omitempty"`
 	ManyToMany *ManyToMany `json:"many_to_many,omitempty"`
+	Collation  string      `json:"collation,omitempty"` // provider-native collation for text columns
+	Charset    string      `json:"charset,omitempty"`   // character set for text columns (MySQL/TiDB)
 }
 
 // ForeignKey represents a foreign key constraint.
@@ -78,6 +93,13 @@ type Index struct {
 	FromFK bool `json:"from_fk,omitempty"`
 }
 
+// UniqueConstraint represents a named table-level UNIQUE constraint.
+type UniqueConstraint struct {
+	Name             string   `json:"name"`
+	Fields           []string `json:"fields"`
+	NullsNotDistinct bool     `json:"nulls_not_distinct,omitempty"` // NULLs compare equal (PostgreSQL 15+)
+}
+
 // ForeignKeyConstraint represents a FK constraint tracked in SchemaState.
 // Note: this is distinct from migrate.ForeignKey (the field-level FK metadata).
 // ForeignKeyConstraint tracks what constraints exist in the database at runtime.
@@ -88,3 +110,43 @@ type ForeignKeyConstraint struct {
 	OnDelete        string `json:"on_delete,omitempty"`
 	OnUpdate        string `json:"on_update,omitempty"`
 }
+
+// Sequence represents a standalone database sequence definition.
+// Zero Start/Increment mean 1; zero MinValue/MaxValue mean the database default.
+// HasStart and HasMinValue make a zero Start or MinValue explicit.
+type Sequence struct {
+	Name        string `json:"name"`
+	Start       int64  `json:"start,omitempty"`
+	Increment   int64  `json:"increment,omitempty"`
+	MinValue    int64  `json:"min_value,omitempty"`
+	MaxValue    int64  `json:"max_value,omitempty"`
+	Cycle       bool   `json:"cycle,omitempty"`
+	OwnedBy     string `json:"owned_by,omitempty"` // owning column as "table.field"
+	HasStart    bool   `json:"has_start,omitempty"`
+	HasMinValue bool   `json:"has_min_value,omitempty"`
+}
+
+// Function represents a stored function or procedure. Body holds the routine
+// body for the database the migration was generated against and is emitted
+// verbatim by the provider.
+type Function struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments,omitempty"` // raw argument list, e.g. "a integer, b integer"
+	Returns   string `json:"returns,omitempty"`   // return type; empty for procedures
+	Language  string `json:"language,omitempty"`  // PostgreSQL only; defaults to plpgsql
+	Procedure bool   `json:"procedure,omitempty"`
+	Body      string `json:"body"`
+}
+
+// Trigger represents a table trigger. Either Function (PostgreSQL) or Body
+// (MySQL, SQLite, SQL Server) supplies the action.
+type Trigger struct {
+	Name     string   `json:"name"`
+	Table    string   `json:"table"`
+	Timing   string   `json:"timing"` // BEFORE, AFTER or INSTEAD OF
+	Events   []string `json:"events"` // INSERT, UPDATE, DELETE, TRUNCATE
+	ForEach  string   `json:"for_each,omitempty"`
+	When     string   `json:"when,omitempty"`
+	Function string   `json:"function,omitempty"`
+	Body     string   `json:"body,omitempty"`
+}
@@ -0,0 +1,134 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/internal/providers/postgresql"
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+func TestAddUniqueConstraint_PostgreSQL(t *testing.T) {
+	p := postgresql.New()
+	op := &migrate.AddUniqueConstraint{
+		Table:      "users",
+		Constraint: migrate.UniqueConstraint{Name: "uq_users_email", Fields: []string{"email"}, NullsNotDistinct: true},
+	}
+
+	up, err := op.Up(p, nil, nil)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	want := `ALTER TABLE "users" ADD CONSTRAINT "uq_users_email" UNIQUE NULLS NOT DISTINCT ("email");`
+	if up != want {
+		t.Fatalf("Up SQL mismatch\n got: %s\nwant: %s", up, want)
+	}
+	down, err := op.Down(p, nil, nil)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if down != `ALTER TABLE "users" DROP CONSTRAINT "uq_users_email";` {
+		t.Fatalf("unexpected Down SQL: %s", down)
+	}
+}
+
+func TestDropUniqueConstraint_DownRecreatesFromState(t *testing.T) {
+	p := postgresql.New()
+	state := migrate.NewSchemaState()
+	if err := state.AddTable("users", []migrate.Field{{Name: "email", Type: "varchar", Length: 255}}, nil); err != nil {
+		t.Fatalf("AddTable: %v", err)
+	}
+	uc := migrate.UniqueConstraint{Name: "uq_users_email", Fields: []string{"email"}}
+	if err := state.AddUniqueConstraint("users", uc); err != nil {
+		t.Fatalf("AddUniqueConstraint: %v", err)
+	}
+	if err := state.AddUniqueConstraint("users", uc); err == nil {
+		t.Fatal("expected duplicate constraint name to fail")
+	}
+
+	op := &migrate.DropUniqueConstraint{Table: "users", Name: "uq_users_email"}
+	down, err := op.Down(p, state, nil)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if !strings.Contains(down, `ADD CONSTRAINT "uq_users_email" UNIQUE ("email")`) {
+		t.Fatalf("unexpected Down SQL: %s", down)
+	}
+	if err := op.Mutate(state); err != nil {
+		t.Fatalf("Mutate: %v", err)
+	}
+	if len(state.Tables["users"].UniqueConstraints) != 0 {
+		t.Fatal("expected constraint to be removed from state")
+	}
+}
+
+func TestRunner_UniqueConstraint_SQLite(t *testing.T) {
+	restore := suppressStdout(t)
+	defer restore()
+
+	reg := migrate.NewRegistry()
+	reg.Register(&migrate.Migration{
+		Name:         "0001_initial",
+		Dependencies: []string{},
+		Operations: []migrate.Operation{
+			&migrate.CreateTable{
+				Name: "users",
+				Fields: []migrate.Field{
+					{Name: "id", Type: "integer", PrimaryKey: true},
+					{Name: "email", Type: "varchar", Length: 255},
+					{Name: "name", Type: "text", Nullable: true},
+				},
+			},
+			&migrate.AddUniqueConstraint{
+				Table:      "users",
+				Constraint: migrate.UniqueConstraint{Name: "uq_users_email", Fields: []string{"email"}},
+			},
+		},
+	})
+	// Altering a column recreates the table on SQLite; the constraint must survive.
+	reg.Register(&migrate.Migration{
+		Name:         "0002_alter_name",
+		Dependencies: []string{"0001_initial"},
+		Operations: []migrate.Operation{
+			&migrate.AlterField{
+				Table:    "users",
+				OldField: migrate.Field{Name: "name", Type: "text", Nullable: true},
+				NewField: migrate.Field{Name: "name", Type: "text"},
+			},
+		},
+	})
+
+	runner, _, db := buildTestRunner(t, reg)
+	if err := runner.Up("", migrate.RunOptions{}); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO users (id, email, name) VALUES (1, 'a@example.com', 'A')"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO users (id, email, name) VALUES (2, 'a@example.com', 'B')"); err == nil {
+		t.Fatal("expected duplicate email to violate the unique constraint")
+	}
+}
@@ -0,0 +1,85 @@
+/*
+MIT License
+
+# Copyright (c) 2025 OcomSoft
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ocomsoft/makemigrations/migrate"
+)
+
+func TestMigrator_UnknownAppliedMigrations(t *testing.T) {
+	reg := migratorRegistry()
+	// 0001_initial stands in for a squash of two deleted migrations.
+	squash, _ := reg.Get("0001_initial")
+	squash.Replaces = []string{"0001_users", "0002_users_pk"}
+	db := openTestDB(t)
+	db.SetMaxOpenConns(1)
+	m, err := migrate.NewMigrator(db, "sqlite", reg)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := m.Status(ctx); err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, name := range []string{"0001_users", "0002_users_pk", "0099_other_branch"} {
+		if _, err := db.Exec("INSERT INTO makemigrations_history (name) VALUES (?)", name); err != nil {
+			t.Fatalf("seeding %s: %v", name, err)
+		}
+	}
+
+	_, err = m.Up(ctx, migrate.UpOptions{})
+	var unknownErr *migrate.UnknownMigrationsError
+	if !errors.As(err, &unknownErr) || !reflect.DeepEqual(unknownErr.Names, []string{"0099_other_branch"}) {
+		t.Fatalf("expected UnknownMigrationsError for 0099_other_branch only, got %v", err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	last := statuses[len(statuses)-1]
+	if len(statuses) != 3 || last.Name != "0099_other_branch" || !last.Unknown || !last.Applied {
+		t.Fatalf("expected the unknown entry listed last, got %+v", statuses)
+	}
+
+	if _, err := m.Up(ctx, migrate.UpOptions{RunOptions: migrate.RunOptions{IgnoreUnknown: true}}); err != nil {
+		t.Fatalf("Up with IgnoreUnknown: %v", err)
+	}
+
+	pruned, err := m.PruneHistory(ctx)
+	if err != nil {
+		t.Fatalf("PruneHistory: %v", err)
+	}
+	if want := []string{"0001_users", "0002_users_pk", "0099_other_branch"}; !reflect.DeepEqual(pruned, want) {
+		t.Fatalf("pruned %v, want %v", pruned, want)
+	}
+	if _, err := m.Up(ctx, migrate.UpOptions{}); err != nil {
+		t.Fatalf("Up after prune: %v", err)
+	}
+}